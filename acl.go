@@ -0,0 +1,411 @@
+package dn
+
+import (
+	"bufio"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//Decision is the result of DNACL.Evaluate and the action of a Rule.
+type Decision int
+
+//Decisions of DNACL.
+const (
+	DecisionDeny  Decision = 0
+	DecisionAllow Decision = 1
+)
+
+//String returns "deny" or "allow".
+func (d Decision) String() string {
+	switch d {
+	case DecisionDeny:
+		return "deny"
+	case DecisionAllow:
+		return "allow"
+	}
+	return fmt.Sprintf("Decision(%d)", int(d))
+}
+
+//DNMatcher is the condition of a Rule. It is created by MatchExact, MatchSubtree, MatchTemplate, MatchPattern or MatchAll.
+type DNMatcher interface {
+	//String returns the serialized form of the condition, which ParseDNACL accepts.
+	String() string
+	match(d dn) (result bool, err error)
+}
+
+//Rule is a rule of DNACL. If Matcher matches a distinguished name, the decision for it is Decision.
+type Rule struct {
+	Decision Decision
+	Matcher  DNMatcher
+}
+
+//String returns the serialized form of r, e.g. "deny pattern CN=revoked-*".
+func (r Rule) String() string {
+	return r.Decision.String() + " " + r.Matcher.String()
+}
+
+//DNACL is an ordered list of rules. The first rule which matches a distinguished name decides whether it is allowed.
+//Distinguished names which match no rules are denied.
+//
+//The serialized form of DNACL has a rule per line in the form of "<decision> <kind> <argument>":
+//  # comment
+//  deny pattern CN=revoked-*
+//  allow template O=Example&C=JP
+//  allow subtree 3017310b3009060355040613024a50...
+//  allow exact 301b310b3009060355040613024a50...
+//  deny all
+//decision is "allow" or "deny", and kind and argument are described in MatchExact, MatchSubtree, MatchTemplate,
+//MatchPattern and MatchAll. Empty lines and lines starting with "#" are ignored.
+type DNACL struct {
+	Rules []Rule
+}
+
+//NewDNACL returns DNACL which consists of rules.
+func NewDNACL(rules ...Rule) *DNACL {
+	return &DNACL{Rules: rules}
+}
+
+//ParseDNACL parses s, which is the serialized form of DNACL.
+func ParseDNACL(s string) (acl *DNACL, err error) {
+	acl = &DNACL{}
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var r Rule
+		if r, err = parseRule(line); err != nil {
+			return nil, fmt.Errorf("dn: line %d: %w", n, err)
+		}
+		acl.Rules = append(acl.Rules, r)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return acl, nil
+}
+
+//parseRule parses line, which is the serialized form of Rule.
+func parseRule(line string) (r Rule, err error) {
+	decision, rest, _ := strings.Cut(line, " ")
+	kind, arg, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	arg = strings.TrimSpace(arg)
+
+	switch decision {
+	case "allow":
+		r.Decision = DecisionAllow
+	case "deny":
+		r.Decision = DecisionDeny
+	default:
+		return Rule{}, fmt.Errorf("unknown decision %q", decision)
+	}
+
+	switch kind {
+	case "exact", "subtree":
+		var b []byte
+		if b, err = hex.DecodeString(arg); err != nil {
+			return Rule{}, err
+		}
+		if kind == "exact" {
+			r.Matcher, err = MatchExact(b)
+		} else {
+			r.Matcher, err = MatchSubtree(b)
+		}
+	case "template":
+		r.Matcher, err = MatchTemplate(arg)
+	case "pattern":
+		r.Matcher, err = MatchPattern(arg)
+	case "all":
+		if arg != "" {
+			return Rule{}, errors.New("all takes no argument")
+		}
+		r.Matcher = MatchAll()
+	default:
+		return Rule{}, fmt.Errorf("unknown kind %q", kind)
+	}
+	if err != nil {
+		return Rule{}, err
+	}
+	return r, nil
+}
+
+//String returns the serialized form of a.
+func (a *DNACL) String() string {
+	var sb strings.Builder
+	for _, r := range a.Rules {
+		sb.WriteString(r.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+//Evaluate returns the decision for dnBytes, which is encoded as Distinguished Name, and the index of the rule which decided it.
+//If no rules match dnBytes, Evaluate returns DecisionDeny and -1.
+//If an error occurs, Evaluate returns DecisionDeny and the index of the rule which caused the error, or -1 if dnBytes is broken.
+func (a *DNACL) Evaluate(dnBytes []byte) (decision Decision, ruleIndex int, err error) {
+	var d dn
+	if d, err = parseDn(dnBytes); err != nil {
+		return DecisionDeny, -1, err
+	}
+	for i, r := range a.Rules {
+		var ok bool
+		if ok, err = r.Matcher.match(d); err != nil {
+			return DecisionDeny, i, fmt.Errorf("dn: rule %d: %w", i, err)
+		}
+		if ok {
+			return r.Decision, i, nil
+		}
+	}
+	return DecisionDeny, -1, nil
+}
+
+type exactMatcher struct {
+	raw []byte
+	d   dn
+}
+
+//MatchExact returns DNMatcher which matches distinguished names matching dnBytes by the same rules as Compare.
+//The serialized form is "exact" followed by dnBytes in hexadecimal.
+func MatchExact(dnBytes []byte) (m DNMatcher, err error) {
+	em := &exactMatcher{raw: append([]byte(nil), dnBytes...)}
+	if em.d, err = parseDn(em.raw); err != nil {
+		return nil, err
+	}
+	return em, nil
+}
+
+func (m *exactMatcher) String() string {
+	return "exact " + hex.EncodeToString(m.raw)
+}
+
+func (m *exactMatcher) match(d dn) (result bool, err error) {
+	return compareDistinguishedName(m.d, d)
+}
+
+type subtreeMatcher struct {
+	raw  []byte
+	base dn
+}
+
+//MatchSubtree returns DNMatcher which matches distinguished names which are the same as or subordinate to base
+//in the same way as BaseMatch.
+//The serialized form is "subtree" followed by base in hexadecimal.
+func MatchSubtree(base []byte) (m DNMatcher, err error) {
+	sm := &subtreeMatcher{raw: append([]byte(nil), base...)}
+	if sm.base, err = parseDn(sm.raw); err != nil {
+		return nil, err
+	}
+	return sm, nil
+}
+
+func (m *subtreeMatcher) String() string {
+	return "subtree " + hex.EncodeToString(m.raw)
+}
+
+func (m *subtreeMatcher) match(d dn) (result bool, err error) {
+	return matchBase(d, m.base)
+}
+
+type templateMatcher struct {
+	template string
+	atvs     []Attribute
+}
+
+//MatchTemplate returns DNMatcher which matches distinguished names containing all attributes of template,
+//in any RDNs and in any order. template is a list of "type=value" joined by "&", e.g. "O=Example&C=JP",
+//where type is a short name in AttributeTypes or a dotted OID. "&" and "\" in value are escaped by "\".
+//The values are encoded by DefaultEncodingPolicy and compared by the same rules as Compare.
+//The serialized form is "template" followed by template.
+func MatchTemplate(template string) (m DNMatcher, err error) {
+	tm := &templateMatcher{template: template}
+	for _, tv := range splitEscaped(template, '&') {
+		name, value, ok := strings.Cut(tv, "=")
+		if !ok {
+			return nil, fmt.Errorf("dn: invalid template %q", template)
+		}
+		var atv Attribute
+		if atv.Oid, err = lookupAttributeType(name); err != nil {
+			return nil, err
+		}
+		if atv.RawValue, err = DefaultEncodingPolicy().Encode(atv.Oid, value); err != nil {
+			return nil, err
+		}
+		tm.atvs = append(tm.atvs, atv)
+	}
+	return tm, nil
+}
+
+func (m *templateMatcher) String() string {
+	return "template " + m.template
+}
+
+func (m *templateMatcher) match(d dn) (result bool, err error) {
+	for _, t := range m.atvs {
+		found := false
+		for _, r := range d {
+			if found, _, err = findMatchedAttribute(t, r, compareAttribute); err != nil {
+				return false, err
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+//splitEscaped splits s by sep which is not escaped by "\", and unescapes the results.
+func splitEscaped(s string, sep byte) (result []string) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			sb.WriteByte(s[i])
+		case s[i] == sep:
+			result = append(result, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return append(result, sb.String())
+}
+
+type patternMatcher struct {
+	pattern string
+	oid     asn1.ObjectIdentifier
+	parts   []string
+}
+
+//MatchPattern returns DNMatcher which matches distinguished names containing an attribute which matches pattern.
+//pattern is "type=value", e.g. "CN=revoked-*", where type is a short name in AttributeTypes or a dotted OID,
+//and "*" in value matches any sequence of characters. The value and the attribute values encoded as string are
+//prepared by DefaultStringPreparer with case folding before the wildcard matching, in the same way as the substrings
+//assertion of caseIgnoreSubstringsMatch(RFC4518 section-2.6.1), so that the values which Compare regards as the same,
+//e.g. "revoked\u200b-1" and full-width "\uff52evoked-1", match the pattern of "revoked-1".
+//It returns an error if value contains prohibited characters.
+//The serialized form is "pattern" followed by pattern.
+func MatchPattern(pattern string) (m DNMatcher, err error) {
+	name, value, ok := strings.Cut(pattern, "=")
+	if !ok {
+		return nil, fmt.Errorf("dn: invalid pattern %q", pattern)
+	}
+	pm := &patternMatcher{pattern: pattern}
+	if pm.oid, err = lookupAttributeType(name); err != nil {
+		return nil, err
+	}
+	if pm.parts, err = preparePattern(DefaultStringPreparer, value); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+func (m *patternMatcher) String() string {
+	return "pattern " + m.pattern
+}
+
+func (m *patternMatcher) match(d dn) (result bool, err error) {
+	for _, r := range d {
+		for _, atv := range r {
+			if !oidEqual(atv.Oid, m.oid) || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				return false, err
+			}
+			if s, err = DefaultStringPreparer.Prepare(s, true); err != nil {
+				return false, err
+			}
+			if matchWildcardParts(m.parts, s) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+//preparePattern splits value by "*" and prepares the parts by p with case folding.
+//A value without "*" is prepared as an attribute value. Otherwise, the parts are prepared as the initial, any and
+//final substrings(RFC4518 section-2.6.1): the initial substring starts with a space and the final substring ends with
+//a space, in the same way as the prepared attribute values, and the other ends of the parts keep a space only if they
+//end with spaces.
+func preparePattern(p StringPreparer, value string) (parts []string, err error) {
+	parts = strings.Split(value, "*")
+	if len(parts) == 1 {
+		if parts[0], err = p.Prepare(value, true); err != nil {
+			return nil, err
+		}
+		return parts, nil
+	}
+	last := len(parts) - 1
+	for i, part := range parts {
+		var u string
+		if u, err = p.Prepare(part, true); err != nil {
+			return nil, err
+		}
+		u = strings.Trim(u, " ")
+		hasLeadingSpace := i == 0 || strings.HasPrefix(part, " ")
+		hasTrailingSpace := i == last || strings.HasSuffix(part, " ")
+		switch {
+		case u == "" && (hasLeadingSpace || hasTrailingSpace):
+			u = " "
+		case u != "":
+			if hasLeadingSpace {
+				u = " " + u
+			}
+			if hasTrailingSpace {
+				u += " "
+			}
+		}
+		parts[i] = u
+	}
+	return parts, nil
+}
+
+//matchWildcard reports whether s matches pattern, in which "*" matches any sequence of characters.
+func matchWildcard(pattern string, s string) bool {
+	return matchWildcardParts(strings.Split(pattern, "*"), s)
+}
+
+//matchWildcardParts reports whether s matches the pattern which consists of parts joined by "*".
+func matchWildcardParts(parts []string, s string) bool {
+	if len(parts) == 1 {
+		return parts[0] == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+type allMatcher struct{}
+
+//MatchAll returns DNMatcher which matches any distinguished names. The serialized form is "all".
+func MatchAll() DNMatcher {
+	return allMatcher{}
+}
+
+func (allMatcher) String() string {
+	return "all"
+}
+
+func (allMatcher) match(dn) (result bool, err error) {
+	return true, nil
+}
@@ -0,0 +1,208 @@
+package dn
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	//C=JP,O=Example,CN=revoked-42
+	hdnRevoked    = "3034310b3009060355040613024a503110300e060355040a0c074578616d706c653113301106035504030c0a7265766f6b65642d3432"
+	dnRevokedb, _ = hex.DecodeString(hdnRevoked)
+	//C=JP,O=Example,CN=Revoked Host
+	hdnRevokedHost    = "3036310b3009060355040613024a503110300e060355040a0c074578616d706c653115301306035504030c0c5265766f6b656420486f7374"
+	dnRevokedHostb, _ = hex.DecodeString(hdnRevokedHost)
+)
+
+func TestDNACL_Evaluate(t *testing.T) {
+	acl, err := ParseDNACL(`
+# revoked hosts
+deny pattern CN=revoked-*
+allow template O=Example&C=JP
+allow subtree ` + hbase2 + `
+deny all
+`)
+	if err != nil {
+		t.Fatalf("ParseDNACL() error = %v", err)
+	}
+	tests := []struct {
+		name          string
+		dnBytes       []byte
+		wantDecision  Decision
+		wantRuleIndex int
+		wantErr       bool
+	}{
+		{"Denied by pattern", dnRevokedb, DecisionDeny, 0, false},
+		{"Pattern does not match", dnRevokedHostb, DecisionAllow, 1, false},
+		{"Allowed by template", dn15b, DecisionAllow, 1, false},
+		{"Allowed by subtree", dn2b, DecisionAllow, 2, false},
+		{"Denied by all", dn6b, DecisionDeny, 3, false},
+		{"Broken data", brdnb, DecisionDeny, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDecision, gotRuleIndex, err := acl.Evaluate(tt.dnBytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotDecision != tt.wantDecision {
+				t.Errorf("Evaluate() gotDecision = %v, want %v", gotDecision, tt.wantDecision)
+			}
+			if gotRuleIndex != tt.wantRuleIndex {
+				t.Errorf("Evaluate() gotRuleIndex = %v, want %v", gotRuleIndex, tt.wantRuleIndex)
+			}
+		})
+	}
+}
+
+func TestDNACL_Evaluate_Programmatic(t *testing.T) {
+	exact, err := MatchExact(dn4b)
+	if err != nil {
+		t.Fatalf("MatchExact() error = %v", err)
+	}
+	dc, err := MatchTemplate("DC=EXAMPLE")
+	if err != nil {
+		t.Fatalf("MatchTemplate() error = %v", err)
+	}
+	acl := NewDNACL(Rule{DecisionAllow, exact}, Rule{DecisionDeny, dc})
+	tests := []struct {
+		name          string
+		dnBytes       []byte
+		wantDecision  Decision
+		wantRuleIndex int
+		wantErr       bool
+	}{
+		{"Allowed by exact", dn2b, DecisionAllow, 0, false},
+		{"Denied by template", dn17b, DecisionDeny, 1, false},
+		{"No rules match", dn6b, DecisionDeny, -1, false},
+		{"Wrong Encoding domain component", dn7b, DecisionDeny, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDecision, gotRuleIndex, err := acl.Evaluate(tt.dnBytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotDecision != tt.wantDecision {
+				t.Errorf("Evaluate() gotDecision = %v, want %v", gotDecision, tt.wantDecision)
+			}
+			if gotRuleIndex != tt.wantRuleIndex {
+				t.Errorf("Evaluate() gotRuleIndex = %v, want %v", gotRuleIndex, tt.wantRuleIndex)
+			}
+		})
+	}
+}
+
+func TestParseDNACL(t *testing.T) {
+	tests := []struct {
+		name       string
+		s          string
+		wantResult string
+		wantErr    bool
+	}{
+		{"All kinds", "allow exact " + hdn2 + "\n  deny subtree " + hbase2 + "  \n\n# comment\nallow template O=A\\&B&C=JP\ndeny pattern CN=*\ndeny all",
+			"allow exact " + hdn2 + "\ndeny subtree " + hbase2 + "\nallow template O=A\\&B&C=JP\ndeny pattern CN=*\ndeny all\n", false},
+		{"Empty", "", "", false},
+		{"Unknown decision", "permit all", "", true},
+		{"Unknown kind", "allow regexp CN=.*", "", true},
+		{"Argument for all", "allow all CN=abc", "", true},
+		{"Broken hex", "allow exact 30zz", "", true},
+		{"Broken data", "allow subtree 300d310b3009", "", true},
+		{"Unknown attribute type", "allow template XX=abc", "", true},
+		{"Template without value", "allow template CN", "", true},
+		{"Template with wrong encoding", "allow template C=日本", "", true},
+		{"Pattern without value", "deny pattern CN", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDNACL(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDNACL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.String() != tt.wantResult {
+				t.Errorf("ParseDNACL() String() = %q, want %q", got.String(), tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	type args struct {
+		pattern string
+		dnBytes []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Prefix", args{"CN=revoked-*", mustMarshalString(t, "CN=revoked-1")}, true, false},
+		{"Zero-width space", args{"CN=revoked-*", mustMarshalString(t, "CN=revoked\u200b-1")}, true, false},
+		{"Full-width character", args{"CN=revoked-*", mustMarshalString(t, "CN=\uff52evoked-1")}, true, false},
+		{"Upper case", args{"CN=revoked-*", mustMarshalString(t, "CN=REVOKED-1")}, true, false},
+		{"Full-width pattern", args{"CN=\uff32\uff25\uff36\uff2f\uff2b\uff25\uff24-*", mustMarshalString(t, "CN=revoked-1")}, true, false},
+		{"Zero-width space in pattern", args{"CN=revo\u200bked-*", mustMarshalString(t, "CN=revoked-1")}, true, false},
+		{"Without wildcard, Insignificant spaces", args{"CN=revoked  host", mustMarshalString(t, "CN=revoked host")}, true, false},
+		{"Space before wildcard", args{"CN=revoked *", mustMarshalString(t, "CN=revoked  host")}, true, false},
+		{"Space before wildcard, Without space", args{"CN=revoked *", mustMarshalString(t, "CN=revoked-host")}, false, false},
+		{"Space after wildcard", args{"CN=* host", mustMarshalString(t, "CN=revoked   host")}, true, false},
+		{"Different value", args{"CN=revoked-*", mustMarshalString(t, "CN=revoke-1")}, false, false},
+		{"Different type", args{"CN=revoked-*", mustMarshalString(t, "O=revoked-1")}, false, false},
+		{"Prohibited character in pattern", args{"CN=revoked-\ufffd*", nil}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := MatchPattern(tt.args.pattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MatchPattern() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			acl := NewDNACL(Rule{Decision: DecisionDeny, Matcher: m}, Rule{Decision: DecisionAllow, Matcher: MatchAll()})
+			decision, _, err := acl.Evaluate(tt.args.dnBytes)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if got := decision == DecisionDeny; got != tt.wantResult {
+				t.Errorf("Evaluate() matched = %v, want %v", got, tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_matchWildcard(t *testing.T) {
+	type args struct {
+		pattern string
+		s       string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"Without wildcard", args{"abc", "abc"}, true},
+		{"Without wildcard, different", args{"abc", "abd"}, false},
+		{"Only wildcard", args{"*", ""}, true},
+		{"Prefix", args{"ab*", "abc"}, true},
+		{"Suffix", args{"*bc", "abc"}, true},
+		{"Middle", args{"a*c", "abbbc"}, true},
+		{"Multiple wildcards", args{"a*b*c", "axbyc"}, true},
+		{"Overlapped prefix and suffix", args{"ab*bc", "abc"}, false},
+		{"Missing middle", args{"a*x*c", "abc"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchWildcard(tt.args.pattern, tt.args.s); got != tt.want {
+				t.Errorf("matchWildcard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package dn
+
+import (
+	"fmt"
+)
+
+//AllowList is a fixed set of distinguished names, keyed by their canonical forms, for testing whether a distinguished
+//name matches any of them. It is built once by NewAllowList and is safe for concurrent use.
+type AllowList struct {
+	c    *Comparer
+	keys map[string]int //index of the first entry of each key
+}
+
+//NewAllowList returns an AllowList of dns, which are encoded as Distinguished Names. Each of dns is canonicalized by
+//Canonicalize, so that Contains is a lookup of the canonical form instead of calling Compare for each of dns.
+//It returns an error if any of dns cannot be canonicalized.
+func NewAllowList(dns [][]byte) (list *AllowList, err error) {
+	return NewComparer().NewAllowList(dns)
+}
+
+//NewAllowList returns an AllowList of dns in the same way as NewAllowList, applying the options of c.
+//Contains of the result matches the distinguished names which match any of dns by c. c must not be modified after
+//the call. The lookup of Contains does not run in constant time even if c has WithConstantTime, and the canonical
+//forms are not available for the attribute types registered by WithMatchingFunc.
+func (c *Comparer) NewAllowList(dns [][]byte) (list *AllowList, err error) {
+	list = &AllowList{c: c, keys: make(map[string]int, len(dns))}
+	for i, der := range dns {
+		var b []byte
+		if b, err = c.Canonicalize(der); err != nil {
+			return nil, fmt.Errorf("dn: failed to canonicalize allowed distinguished name %d: %w", i, err)
+		}
+		if _, ok := list.keys[string(b)]; !ok {
+			list.keys[string(b)] = i
+		}
+	}
+	return list, nil
+}
+
+//Contains reports whether der, which is encoded as Distinguished Name, matches any of the distinguished names of l.
+func (l *AllowList) Contains(der []byte) (result bool, err error) {
+	_, result, err = l.Index(der)
+	return result, err
+}
+
+//Index returns the index of the first distinguished name of l which der, which is encoded as Distinguished Name,
+//matches. isFound is false if der matches none of them.
+func (l *AllowList) Index(der []byte) (index int, isFound bool, err error) {
+	var b []byte
+	if b, err = l.c.Canonicalize(der); err != nil {
+		return -1, false, err
+	}
+	if index, isFound = l.keys[string(b)]; !isFound {
+		return -1, false, nil
+	}
+	return index, true, nil
+}
+
+//Len returns the number of distinct distinguished names of l. Distinguished names which match each other are
+//counted as one.
+func (l *AllowList) Len() int {
+	return len(l.keys)
+}
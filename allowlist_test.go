@@ -0,0 +1,96 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"testing"
+)
+
+func TestAllowList_Contains(t *testing.T) {
+	list, err := NewAllowList([][]byte{dn2b, dn6b, dn1b, dn3b})
+	if err != nil {
+		t.Fatalf("NewAllowList() error = %v", err)
+	}
+	if list.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", list.Len())
+	}
+	tests := []struct {
+		name       string
+		der        []byte
+		wantResult bool
+		wantIndex  int
+		wantErr    bool
+	}{
+		{"Same", dn2b, true, 0, false},
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", dn3b, true, 0, false},
+		{"Upper/Lower case characters", dn4b, true, 0, false},
+		{"Country name with spaces", dn20b, true, 0, false},
+		{"Second entry", dn6b, true, 1, false},
+		{"Multi RDN not in DER order", dn16b, true, 2, false},
+		{"Not in list", dn65b, false, -1, false},
+		{"Fewer RDNs", base2b, false, -1, false},
+		{"Broken data", brdnb, false, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := list.Contains(tt.der)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Contains() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Contains() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			gotIndex, _, _ := list.Index(tt.der)
+			if gotIndex != tt.wantIndex {
+				t.Errorf("Index() gotIndex = %v, want %v", gotIndex, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestNewAllowList_Error(t *testing.T) {
+	if _, err := NewAllowList([][]byte{dn2b, brdnb}); err == nil {
+		t.Errorf("NewAllowList() error = nil, want error")
+	}
+	oidCommonName := asn1.ObjectIdentifier{2, 5, 4, 3}
+	c := NewComparer(WithMatchingFunc(oidCommonName, func(x MatchingValue, y MatchingValue) (bool, error) {
+		return true, nil
+	}))
+	if _, err := c.NewAllowList([][]byte{dn2b}); err == nil {
+		t.Errorf("NewAllowList() with WithMatchingFunc error = nil, want error")
+	}
+}
+
+func TestComparer_NewAllowList(t *testing.T) {
+	oidCommonName := asn1.ObjectIdentifier{2, 5, 4, 3}
+	tests := []struct {
+		name       string
+		opts       []Option
+		der        []byte
+		wantResult bool
+	}{
+		{"Default, Upper/Lower case characters", nil, dn4b, true},
+		{"Case exact, Upper/Lower case characters", []Option{WithMatchingRule(oidCommonName, MatchingRuleCaseExact)}, dn4b, false},
+		{"Case exact, Different Encoding(PrintableString,UTF8String)", []Option{WithMatchingRule(oidCommonName, MatchingRuleCaseExact)}, dn3b, true},
+		{"Ignored types, Different characters", []Option{WithIgnoredTypes(oidCommonName)}, dn89b, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer(tt.opts...)
+			list, err := c.NewAllowList([][]byte{dn2b})
+			if err != nil {
+				t.Fatalf("NewAllowList() error = %v", err)
+			}
+			gotResult, err := list.Contains(tt.der)
+			if err != nil {
+				t.Fatalf("Contains() error = %v", err)
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Contains() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if want, _ := c.Compare(dn2b, tt.der); gotResult != want {
+				t.Errorf("Contains() = %v, but Compare() = %v", gotResult, want)
+			}
+		})
+	}
+}
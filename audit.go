@@ -0,0 +1,121 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+//AuditRule is a leniency or a fallback rule which the comparison used.
+type AuditRule int
+
+//Rules reported to the audit hook.
+const (
+	//AuditRuleBinaryComparison means the value is not a string which the comparison understands,
+	//and is compared by binary comparison(RFC5280 section-7.1).
+	AuditRuleBinaryComparison AuditRule = 0
+	//AuditRuleTeletexString means the value in TeletexString is converted to UTF8String by WithTeletexString.
+	AuditRuleTeletexString AuditRule = 1
+	//AuditRuleVisibleString means the value in VisibleString is converted to UTF8String by WithVisibleString.
+	AuditRuleVisibleString AuditRule = 2
+	//AuditRuleDomainComponentEncoding means the value of domainComponent which is not encoded in IA5String is
+	//converted to IA5String by WithLenientDomainComponent.
+	AuditRuleDomainComponentEncoding AuditRule = 3
+	//AuditRuleBMPString means the value in BMPString is converted to UTF8String by WithBMPString.
+	AuditRuleBMPString AuditRule = 4
+	//AuditRulePrintableString means the value in PrintableString with the characters which PrintableString does not
+	//allow is converted to UTF8String by WithLenientPrintableString.
+	AuditRulePrintableString AuditRule = 5
+)
+
+var auditRuleNames = []string{"binaryComparison", "teletexString", "visibleString", "domainComponentEncoding", "bmpString", "printableString"}
+
+//String returns the name of r.
+func (r AuditRule) String() string {
+	if r < 0 || int(r) >= len(auditRuleNames) {
+		return fmt.Sprintf("AuditRule(%d)", int(r))
+	}
+	return auditRuleNames[r]
+}
+
+//Side is the input which an Event or an error reported to Observer is about.
+type Side int
+
+//Sides of Event.
+const (
+	SideIssuer  Side = 0 //issuer of Compare or CompareExplain, or x of CompareAttribute
+	SideSubject Side = 1 //subject of Compare or CompareExplain, or y of CompareAttribute
+	SideInput   Side = 2 //input of Canonicalize or CompareMany
+	SideBoth    Side = 3 //both inputs, for errors in the comparison of their attributes reported to Observer
+)
+
+//Event describes a use of a leniency or a fallback rule.
+type Event struct {
+	Rule      AuditRule
+	Side      Side
+	RDN       int //index of the RDN in the input, or -1 for CompareAttribute
+	Attribute int //index of the attribute in the RDN, or -1 for CompareAttribute
+	Type      asn1.ObjectIdentifier
+}
+
+//WithAuditHook makes the Comparer call hook whenever a leniency or a fallback rule is used for an attribute,
+//e.g. to record evidence of the leniencies exercised in production. hook is called before the comparison,
+//for each attribute which is not ignored, and is never called for distinguished names which consist of only
+//domainComponent values in IA5String and values in UTF8String or PrintableString, unless WithLenientPrintableString
+//converts the PrintableString values.
+//hook is called synchronously, and must be safe for concurrent use if the Comparer is used concurrently.
+func WithAuditHook(hook func(Event)) Option {
+	return func(c *Comparer) {
+		c.auditHook = hook
+	}
+}
+
+//audit calls the audit hook of c for the attributes of d, which is on side.
+func (c *Comparer) audit(d dn, side Side) {
+	for i, r := range d {
+		for j, atv := range r {
+			if c.isIgnoredType(atv.Oid) {
+				continue
+			}
+			c.auditAttribute(atv, side, i, j)
+		}
+	}
+}
+
+//auditAttribute calls the audit hook of c if a leniency or a fallback rule is used for atv.
+func (c *Comparer) auditAttribute(atv Attribute, side Side, rdn int, attribute int) {
+	rule, ok := c.auditRule(atv)
+	if !ok {
+		return
+	}
+	c.auditHook(Event{Rule: rule, Side: side, RDN: rdn, Attribute: attribute, Type: cloneOid(atv.Oid)})
+}
+
+//auditRule returns the leniency or the fallback rule which c uses for atv. ok is false if c uses neither.
+//The rule is resolved by appliedRule in the same way as c.compareAttribute.
+func (c *Comparer) auditRule(atv Attribute) (rule AuditRule, ok bool) {
+	universal := atv.RawValue.Class == asn1.ClassUniversal
+	if c.lenientPrintable && isLenientPrintableString(atv) {
+		return AuditRulePrintableString, true
+	}
+	switch appliedRule(atv, atv, c.matchingRule(atv.Oid)) {
+	case AppliedRuleCaseInsensitiveExactMatch:
+		if c.lenientDC && universal && atv.RawValue.Tag != asn1.TagIA5String {
+			return AuditRuleDomainComponentEncoding, true
+		}
+		//the value is compared by the rule of domain components, or the comparison returns an error
+		return 0, false
+	case AppliedRuleBinaryComparison:
+	default:
+		return 0, false
+	}
+	if c.teletex && universal && atv.RawValue.Tag == asn1.TagT61String {
+		return AuditRuleTeletexString, true
+	}
+	if c.visible && universal && atv.RawValue.Tag == tagVisibleString {
+		return AuditRuleVisibleString, true
+	}
+	if c.bmp && universal && atv.RawValue.Tag == asn1.TagBMPString {
+		return AuditRuleBMPString, true
+	}
+	return AuditRuleBinaryComparison, true
+}
@@ -0,0 +1,101 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"reflect"
+	"testing"
+)
+
+func TestWithAuditHook(t *testing.T) {
+	oidCommonName := asn1.ObjectIdentifier{2, 5, 4, 3}
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantEvents []Event
+		wantErr    bool
+	}{
+		{"Wrong Encoding domain component, Lenient", []Option{WithLenientDomainComponent()}, args{dn7b, dn7b}, true, []Event{
+			{Rule: AuditRuleDomainComponentEncoding, Side: SideIssuer, RDN: 2, Attribute: 0, Type: oidDomainComponent},
+			{Rule: AuditRuleDomainComponentEncoding, Side: SideSubject, RDN: 2, Attribute: 0, Type: oidDomainComponent},
+		}, false},
+		{"Wrong Encoding domain component in issuer, Lenient", []Option{WithLenientDomainComponent()}, args{dn7b, dn17b}, true, []Event{
+			{Rule: AuditRuleDomainComponentEncoding, Side: SideIssuer, RDN: 2, Attribute: 0, Type: oidDomainComponent},
+		}, false},
+		{"Wrong Encoding domain component, Default", nil, args{dn7b, dn7b}, false, nil, true},
+		{"Domain components", []Option{WithLenientDomainComponent()}, args{dn17b, dn17b}, true, nil, false},
+		{"Multi RDN not in DER order", nil, args{dn1b, dn16b}, true, nil, false},
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", nil, args{dn2b, dn3b}, true, nil, false},
+		{"Different Encoding(BMPString,UTF8String)", nil, args{dn5b, dn2b}, false, []Event{
+			{Rule: AuditRuleBinaryComparison, Side: SideIssuer, RDN: 1, Attribute: 0, Type: oidCommonName},
+		}, false},
+		{"Different Encoding(BMPString,UTF8String), WithBMPString", []Option{WithBMPString()}, args{dn5b, dn2b}, true, []Event{
+			{Rule: AuditRuleBMPString, Side: SideIssuer, RDN: 1, Attribute: 0, Type: oidCommonName},
+		}, false},
+		{"'@' in PrintableString, WithLenientPrintableString", []Option{WithLenientPrintableString()}, args{dnPrintableAtb, dnUTF8Atb}, true, []Event{
+			{Rule: AuditRulePrintableString, Side: SideIssuer, RDN: 1, Attribute: 0, Type: oidOrganization},
+		}, false},
+		{"BMPString, Ignored types", []Option{WithIgnoredTypes(oidCommonName)}, args{dn5b, dn2b}, true, nil, false},
+		{"TeletexString, Default", nil, args{dnTeletexASCIIb, dnExamplePrintableb}, false, []Event{
+			{Rule: AuditRuleBinaryComparison, Side: SideIssuer, RDN: 1, Attribute: 0, Type: oidOrganization},
+		}, false},
+		{"TeletexString, WithTeletexString", []Option{WithTeletexString()}, args{dnTeletexASCIIb, dnExamplePrintableb}, true, []Event{
+			{Rule: AuditRuleTeletexString, Side: SideIssuer, RDN: 1, Attribute: 0, Type: oidOrganization},
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotEvents []Event
+			opts := append([]Option{WithAuditHook(func(e Event) { gotEvents = append(gotEvents, e) })}, tt.opts...)
+			gotResult, err := NewComparer(opts...).Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(gotEvents, tt.wantEvents) {
+				t.Errorf("Compare() events = %+v, want %+v", gotEvents, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestWithAuditHook_Canonicalize(t *testing.T) {
+	var gotEvents []Event
+	c := NewComparer(WithLenientDomainComponent(), WithAuditHook(func(e Event) { gotEvents = append(gotEvents, e) }))
+	if _, err := c.Canonicalize(dn7b); err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	want := []Event{{Rule: AuditRuleDomainComponentEncoding, Side: SideInput, RDN: 2, Attribute: 0, Type: oidDomainComponent}}
+	if !reflect.DeepEqual(gotEvents, want) {
+		t.Errorf("Canonicalize() events = %+v, want %+v", gotEvents, want)
+	}
+}
+
+func TestWithAuditHook_CompareAttribute(t *testing.T) {
+	var gotEvents []Event
+	c := NewComparer(WithAuditHook(func(e Event) { gotEvents = append(gotEvents, e) }))
+	if _, err := c.CompareAttribute(pAtv, bmpAtv); err != nil {
+		t.Fatalf("CompareAttribute() error = %v", err)
+	}
+	want := []Event{{Rule: AuditRuleBinaryComparison, Side: SideSubject, RDN: -1, Attribute: -1, Type: oidOrganization}}
+	if !reflect.DeepEqual(gotEvents, want) {
+		t.Errorf("CompareAttribute() events = %+v, want %+v", gotEvents, want)
+	}
+}
+
+func TestAuditRule_String(t *testing.T) {
+	if s := AuditRuleDomainComponentEncoding.String(); s != "domainComponentEncoding" {
+		t.Errorf("String() = %s, want domainComponentEncoding", s)
+	}
+	if s := AuditRule(9).String(); s != "AuditRule(9)" {
+		t.Errorf("String() = %s, want AuditRule(9)", s)
+	}
+}
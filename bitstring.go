@@ -0,0 +1,128 @@
+package dn
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+//oidX500UniqueIdentifier is x500UniqueIdentifier, whose values are UniqueIdentifier ::= BIT STRING.
+//https://tools.ietf.org/html/rfc4519#section-2.39
+var oidX500UniqueIdentifier = asn1.ObjectIdentifier{2, 5, 4, 45}
+
+//compareByBitStringMatch reports whether the values of x and y, which are encoded in BIT STRING, matches by bitStringMatch.
+func compareByBitStringMatch(x Attribute, y Attribute) (result bool, err error) {
+	//https://tools.ietf.org/html/rfc4517#section-4.2.1
+	//If the corresponding ASN.1 type of the attribute syntax does not have a
+	//named bit list, then the rule evaluates to TRUE if and only if the
+	//attribute value has the same number of bits as the assertion value and
+	//the bits match on a bitwise basis.
+	var s, t asn1.BitString
+	if s, err = decodeBitString(x); err != nil {
+		return false, err
+	}
+	if t, err = decodeBitString(y); err != nil {
+		return false, err
+	}
+	return s.BitLength == t.BitLength && bytes.Equal(s.Bytes, t.Bytes), nil
+}
+
+//compareByBitStringMatchConstantTime reports whether x and y matches in the same way as compareByBitStringMatch,
+//comparing the bits in constant time.
+func compareByBitStringMatchConstantTime(x Attribute, y Attribute) (result bool, err error) {
+	var kx, ky []byte
+	if kx, err = canonicalBitString(x); err != nil {
+		return false, err
+	}
+	if ky, err = canonicalBitString(y); err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(kx, ky) == 1, nil
+}
+
+//canonicalBitString returns the value of atv, which is encoded in BIT STRING, encoded in DER.
+//The values which match by bitStringMatch have the same DER encoding.
+func canonicalBitString(atv Attribute) (der []byte, err error) {
+	var s asn1.BitString
+	if s, err = decodeBitString(atv); err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(s)
+}
+
+//isBitString reports whether the value of atv is encoded in BIT STRING.
+//Some issuers encode x500UniqueIdentifier in other types, e.g. UTF8String, against its syntax, which appliedRule
+//compares by binary comparison instead of bitStringMatch.
+func isBitString(atv Attribute) bool {
+	return atv.RawValue.Class == asn1.ClassUniversal && atv.RawValue.Tag == asn1.TagBitString
+}
+
+//decodeBitString decodes the value of atv, which is encoded in BIT STRING in the primitive form or the constructed form.
+//The unused bits of the result are zero, whatever they are in the encoding.
+func decodeBitString(atv Attribute) (s asn1.BitString, err error) {
+	if !isBitString(atv) {
+		return asn1.BitString{}, fmt.Errorf("dn: value of attribute %s is not a BIT STRING", atv.Oid)
+	}
+	if !atv.RawValue.IsCompound {
+		return bitStringContent(atv.RawValue.Bytes)
+	}
+	return concatenateBitStringSegments(atv.RawValue.Bytes, 0)
+}
+
+//bitStringContent decodes b, which is the content of BIT STRING in the primitive form.
+func bitStringContent(b []byte) (s asn1.BitString, err error) {
+	//https://www.itu.int/rec/T-REC-X.690 section-8.6.2
+	//The initial octet shall encode, as an unsigned binary integer with bit 1 as the least significant bit,
+	//the number of unused bits in the final subsequent octet. The number shall be in the range zero to seven.
+	//If the bitstring is empty, there shall be no subsequent octets, and the initial octet shall be zero.
+	if len(b) == 0 {
+		return asn1.BitString{}, errors.New("dn: BIT STRING has no initial octet")
+	}
+	unused := int(b[0])
+	if unused > 7 || len(b) == 1 && unused != 0 {
+		return asn1.BitString{}, fmt.Errorf("dn: BIT STRING has invalid number of unused bits %d", unused)
+	}
+	s.Bytes = append([]byte{}, b[1:]...)
+	s.BitLength = len(s.Bytes)*8 - unused
+	if len(s.Bytes) != 0 {
+		//section-8.6.2.3: the unused bits may have any value in BER
+		s.Bytes[len(s.Bytes)-1] &= 0xff << unused
+	}
+	return s, nil
+}
+
+//concatenateBitStringSegments decodes b, which is the content of BIT STRING in the constructed form nested at depth.
+func concatenateBitStringSegments(b []byte, depth int) (s asn1.BitString, err error) {
+	//https://www.itu.int/rec/T-REC-X.690 section-8.6.4
+	//Each of the segments is a BIT STRING, and each of them except the last has no unused bits.
+	if depth == maxSegmentDepth {
+		return asn1.BitString{}, errors.New("dn: too deeply nested segments of constructed BIT STRING")
+	}
+	s.Bytes = []byte{}
+	for len(b) != 0 {
+		if s.BitLength%8 != 0 {
+			return asn1.BitString{}, errors.New("dn: segment of constructed BIT STRING has unused bits before the last")
+		}
+		var segment asn1.RawValue
+		if b, err = asn1.Unmarshal(b, &segment); err != nil {
+			return asn1.BitString{}, err
+		}
+		if segment.Class != asn1.ClassUniversal || segment.Tag != asn1.TagBitString {
+			return asn1.BitString{}, errors.New("dn: segment of constructed BIT STRING must be BIT STRING")
+		}
+		var t asn1.BitString
+		if segment.IsCompound {
+			t, err = concatenateBitStringSegments(segment.Bytes, depth+1)
+		} else {
+			t, err = bitStringContent(segment.Bytes)
+		}
+		if err != nil {
+			return asn1.BitString{}, err
+		}
+		s.Bytes = append(s.Bytes, t.Bytes...)
+		s.BitLength += t.BitLength
+	}
+	return s, nil
+}
@@ -0,0 +1,76 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func Test_decodeBitString(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    asn1.BitString
+		wantErr bool
+	}{
+		{"Primitive", "030404123450", asn1.BitString{Bytes: []byte{0x12, 0x34, 0x50}, BitLength: 20}, false},
+		{"Primitive, Non-zero unused bits", "03040412345f", asn1.BitString{Bytes: []byte{0x12, 0x34, 0x50}, BitLength: 20}, false},
+		{"Primitive, No unused bits", "030400123450", asn1.BitString{Bytes: []byte{0x12, 0x34, 0x50}, BitLength: 24}, false},
+		{"Primitive, Empty", "030100", asn1.BitString{Bytes: []byte{}, BitLength: 0}, false},
+		{"Primitive, No initial octet", "0300", asn1.BitString{}, true},
+		{"Primitive, Empty with unused bits", "030101", asn1.BitString{}, true},
+		{"Primitive, 8 unused bits", "03020800", asn1.BitString{}, true},
+		{"Constructed", "2309030300123403020450", asn1.BitString{Bytes: []byte{0x12, 0x34, 0x50}, BitLength: 20}, false},
+		{"Constructed, Nested", "230b2305030300123403020450", asn1.BitString{Bytes: []byte{0x12, 0x34, 0x50}, BitLength: 20}, false},
+		{"Constructed, Unused bits before the last segment", "2309030304123403020050", asn1.BitString{}, true},
+		{"Constructed, OCTET STRING segment", "2308040212340302045f", asn1.BitString{}, true},
+		{"UTF8String", "0c053132333435", asn1.BitString{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := hex.DecodeString(tt.value)
+			atv := Attribute{Oid: oidX500UniqueIdentifier}
+			if _, err := asn1.Unmarshal(b, &atv.RawValue); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			got, err := decodeBitString(atv)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeBitString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeBitString() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_canonicalBitString(t *testing.T) {
+	for _, value := range []string{"030404123450", "03040412345f", "2309030300123403020450"} {
+		b, _ := hex.DecodeString(value)
+		atv := Attribute{Oid: oidX500UniqueIdentifier}
+		if _, err := asn1.Unmarshal(b, &atv.RawValue); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		got, err := canonicalBitString(atv)
+		if err != nil {
+			t.Fatalf("canonicalBitString(%s) error = %v", value, err)
+		}
+		if h := hex.EncodeToString(got); h != "030404123450" {
+			t.Errorf("canonicalBitString(%s) = %s, want 030404123450", value, h)
+		}
+	}
+	//x500UniqueIdentifier not encoded in BIT STRING is compared by binary comparison, so its bytes are preserved
+	utf8 := Attribute{Oid: oidX500UniqueIdentifier}
+	if _, err := asn1.Unmarshal([]byte{0x0c, 0x02, 0x31, 0x32}, &utf8.RawValue); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	got, err := NewComparer().canonicalizeAttribute(utf8, 0)
+	if err != nil {
+		t.Fatalf("canonicalizeAttribute(0c023132) error = %v", err)
+	}
+	if h := hex.EncodeToString(got.RawValue.FullBytes); h != "0c023132" {
+		t.Errorf("canonicalizeAttribute(0c023132) = %s, want 0c023132", h)
+	}
+}
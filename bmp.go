@@ -0,0 +1,49 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+//WithBMPString makes the Comparer compare the values encoded in BMPString as DirectoryString,
+//so that they match the same values encoded in UTF8String or PrintableString by caseIgnoreMatch.
+//By default, BMPString values are compared by binary comparison, because the support of BMPString is OPTIONAL in
+//RFC 5280 section-7.1.
+//
+//BMPString values are decoded as UCS-2 in the same way as toString: surrogates and noncharacters are not decoded,
+//and the comparison returns an error for the values which contain them.
+func WithBMPString() Option {
+	return func(c *Comparer) {
+		c.bmp = true
+	}
+}
+
+//transcodeBMPStrings returns d whose values encoded in BMPString are converted to UTF8String.
+func transcodeBMPStrings(d dn) (result dn, err error) {
+	result = make(dn, len(d))
+	for i, r := range d {
+		result[i] = make(rdnSET, len(r))
+		for j, atv := range r {
+			if result[i][j], err = transcodeBMPString(atv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+//transcodeBMPString returns atv whose value is converted to UTF8String if it is encoded in BMPString.
+func transcodeBMPString(atv Attribute) (result Attribute, err error) {
+	if atv.RawValue.Class != asn1.ClassUniversal || atv.RawValue.Tag != asn1.TagBMPString {
+		return atv, nil
+	}
+	var content []byte
+	if content, err = stringContent(atv.RawValue); err != nil {
+		return Attribute{}, err
+	}
+	var s string
+	if s, err = decodeBMPString(content); err != nil {
+		return Attribute{}, fmt.Errorf("dn: attribute %s: %w", atv.Oid, err)
+	}
+	return newStringAttribute(atv.Oid, s, EncodingUTF8String)
+}
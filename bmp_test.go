@@ -0,0 +1,64 @@
+package dn
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	//C=JP(PrintableString),O=E U+D800(BMPString)
+	dnBMPSurrogateb, _ = hex.DecodeString("301c310b3009060355040613024a50310d300b060355040a1e040045d800")
+)
+
+func TestWithBMPString(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantResult     bool
+		wantDefault    bool
+		wantErr        bool
+		wantDefaultErr bool
+	}{
+		{"BMPString UTF8String", args{dn5b, dn2b}, true, false, false, false},
+		{"PrintableString BMPString", args{dn3b, dn5b}, true, false, false, false},
+		{"BMPString PrintableString, Upper/Lower case characters", args{dnExampleBMPb, dnExamplePrintableb}, true, false, false, false},
+		{"BMPString BMPString", args{dn5b, dn5b}, true, true, false, false},
+		{"BMPString in the constructed form of BER", args{dn38b, dnExamplePrintableb}, true, false, false, false},
+		{"BMPString TeletexString", args{dnExampleBMPb, dnTeletexASCIIb}, false, false, false, false},
+		{"Surrogate", args{dnBMPSurrogateb, dnExamplePrintableb}, false, false, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(WithBMPString()).Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+
+			gotResult, err = Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantDefaultErr {
+				t.Errorf("Compare() without WithBMPString error = %v, wantErr %v", err, tt.wantDefaultErr)
+				return
+			}
+			if gotResult != tt.wantDefault {
+				t.Errorf("Compare() without WithBMPString gotResult = %v, want %v", gotResult, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestWithBMPString_CompareAttribute(t *testing.T) {
+	d, _ := parseDn(dn5b)
+	e, _ := parseDn(dn2b)
+	gotResult, err := NewComparer(WithBMPString()).CompareAttribute(d[1][0], e[1][0])
+	if err != nil || !gotResult {
+		t.Errorf("CompareAttribute() = %v, %v, want true, nil", gotResult, err)
+	}
+}
@@ -0,0 +1,180 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"sort"
+)
+
+//valueEncoder holds the options of the functions which encode the values of attributes.
+type valueEncoder struct {
+	policy    EncodingPolicy
+	forceUTF8 bool
+}
+
+//BuildOption configures BuildFromMap, BuildFromMultiMap, FromPkixName, ParseString and MarshalStruct.
+type BuildOption func(*valueEncoder)
+
+//WithForceUTF8 makes the values of the attribute types whose syntax is DirectoryString encoded in UTF8String
+//regardless of their characters, as some CA profiles mandate, instead of PrintableString if possible.
+//The values of the attribute types which DefaultEncodingPolicy encodes in PrintableString or IA5String, e.g.
+//countryName, domainComponent and emailAddress, are not affected, and neither are the encodings given explicitly
+//by overrides of BuildFromMultiMap or the struct tags of MarshalStruct.
+//
+//Canonicalize always encodes DirectoryString values in UTF8String, so it needs no option.
+func WithForceUTF8() BuildOption {
+	return func(e *valueEncoder) {
+		e.forceUTF8 = true
+	}
+}
+
+//WithEncodingPolicy makes the values encoded according to p instead of DefaultEncodingPolicy.
+//The attribute types which are not in p are encoded as DirectoryString, as EncodingPolicy.Encoding returns.
+//p is copied, so that changing p afterward does not affect the option.
+//WithForceUTF8 still applies to the types which p encodes as DirectoryString.
+func WithEncodingPolicy(p EncodingPolicy) BuildOption {
+	policy := make(EncodingPolicy, len(p))
+	for k, v := range p {
+		policy[k] = v
+	}
+	return func(e *valueEncoder) {
+		e.policy = policy
+	}
+}
+
+//newValueEncoder returns the valueEncoder configured by opts, which encodes the values according to
+//DefaultEncodingPolicy unless opts give another policy.
+func newValueEncoder(opts []BuildOption) *valueEncoder {
+	e := &valueEncoder{policy: DefaultEncodingPolicy()}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+//encoding returns the encoding of the value of attribute type oid.
+func (e *valueEncoder) encoding(oid asn1.ObjectIdentifier) Encoding {
+	enc := e.policy.Encoding(oid)
+	if e.forceUTF8 && enc == EncodingDirectoryString {
+		return EncodingUTF8String
+	}
+	return enc
+}
+
+//buildOrder is the conventional order of RDNs used by BuildFromMap.
+var buildOrder = []asn1.ObjectIdentifier{
+	{2, 5, 4, 6},  //C
+	{2, 5, 4, 8},  //ST
+	{2, 5, 4, 7},  //L
+	{2, 5, 4, 10}, //O
+	{2, 5, 4, 11}, //OU
+	{2, 5, 4, 3},  //CN
+}
+
+//BuildFromMap encodes m, which maps attribute types to values, as Distinguished Name.
+//
+//The keys of m are short names in AttributeTypes, ignoring case, or the dotted string form of OIDs.
+//Each attribute becomes a single-valued RDN. The RDNs are ordered deterministically: C, ST, L, O, OU and CN first,
+//then the other types in AttributeTypes in the order of the table, and then the other OIDs in ascending order.
+//The values are encoded according to DefaultEncodingPolicy, unless opts change it, e.g. WithForceUTF8 or
+//WithEncodingPolicy.
+func BuildFromMap(m map[string]string, opts ...BuildOption) ([]byte, error) {
+	mm := make(map[string][]string, len(m))
+	for k, v := range m {
+		mm[k] = []string{v}
+	}
+	return BuildFromMultiMap(mm, nil, opts...)
+}
+
+//BuildFromMultiMap is like BuildFromMap but each key can have multiple values.
+//Each value becomes a single-valued RDN in the order of the slice, so that BuildFromMultiMap never produces
+//multi-valued RDNs, e.g. OU=a+OU=b. Use the "multi" option of MarshalStruct or the "+" separator of ParseString
+//to build them.
+//overrides maps keys of m to the encodings which are used instead of DefaultEncodingPolicy or the policy given by
+//WithEncodingPolicy.
+func BuildFromMultiMap(m map[string][]string, overrides map[string]Encoding, opts ...BuildOption) (result []byte, err error) {
+	type entry struct {
+		key    string
+		oid    asn1.ObjectIdentifier
+		values []string
+	}
+	entries := make([]entry, 0, len(m))
+	seen := make(map[string]string, len(m))
+	for k, v := range m {
+		var oid asn1.ObjectIdentifier
+		if oid, err = lookupAttributeType(k); err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[oid.String()]; ok {
+			return nil, fmt.Errorf("dn: keys %q and %q have the same attribute type", prev, k)
+		}
+		seen[oid.String()] = k
+		entries = append(entries, entry{key: k, oid: oid, values: v})
+	}
+	for k := range overrides {
+		if _, ok := m[k]; !ok {
+			return nil, fmt.Errorf("dn: encoding override for unknown key %q", k)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return lessBuildOrder(entries[i].oid, entries[j].oid)
+	})
+
+	ve := newValueEncoder(opts)
+	var d dn
+	for _, e := range entries {
+		enc := ve.encoding(e.oid)
+		if o, ok := overrides[e.key]; ok {
+			enc = o
+		}
+		for _, v := range e.values {
+			var atv Attribute
+			if atv, err = newStringAttribute(e.oid, v, enc); err != nil {
+				return nil, err
+			}
+			d = append(d, rdnSET{atv})
+		}
+	}
+	return marshalDn(d)
+}
+
+//lessBuildOrder reports whether the RDN of x is placed before the RDN of y by BuildFromMap.
+func lessBuildOrder(x asn1.ObjectIdentifier, y asn1.ObjectIdentifier) bool {
+	rx, ry := buildRank(x), buildRank(y)
+	if rx != ry {
+		return rx < ry
+	}
+	return compareOid(x, y) < 0
+}
+
+//buildRank returns the rank of oid in the order of BuildFromMap.
+func buildRank(oid asn1.ObjectIdentifier) int {
+	for i, t := range buildOrder {
+		if t.Equal(oid) {
+			return i
+		}
+	}
+	if i := attributeTypeIndex(oid); i >= 0 {
+		return len(buildOrder) + i
+	}
+	return len(buildOrder) + len(AttributeTypes)
+}
+
+//compareOid compares x and y arc by arc and returns -1, 0 or 1.
+func compareOid(x asn1.ObjectIdentifier, y asn1.ObjectIdentifier) int {
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			if x[i] < y[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(x) < len(y):
+		return -1
+	case len(x) > len(y):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,256 @@
+package dn
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"testing"
+)
+
+func TestBuildFromMap(t *testing.T) {
+	type args struct {
+		m map[string]string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult string
+		wantErr    bool
+	}{
+		{"C, O, CN", args{map[string]string{"CN": "svc-1", "O": "Example", "C": "JP"}}, "302f310b3009060355040613024a503110300e060355040a13074578616d706c65310e300c060355040313057376632d31", false},
+		{"Lower case names and dotted OID", args{map[string]string{"cn": "ABC", "2.5.4.6": "JP"}}, hdn3, false},
+		{"Unknown name", args{map[string]string{"FOO": "bar"}}, "", true},
+		{"Duplicate types", args{map[string]string{"CN": "a", "2.5.4.3": "b"}}, "", true},
+		{"Not printable country", args{map[string]string{"C": "日本"}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := BuildFromMap(tt.args.m)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BuildFromMap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if hex.EncodeToString(gotResult) != tt.wantResult {
+				t.Errorf("BuildFromMap() gotResult = %x, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestBuildFromMap_Deterministic(t *testing.T) {
+	m := map[string]string{"C": "JP", "ST": "Tokyo", "L": "Chiyoda", "O": "Example", "OU": "Dev", "CN": "svc-1", "DC": "com", "1.2.3.4": "x"}
+	want, err := BuildFromMap(m)
+	if err != nil {
+		t.Fatalf("BuildFromMap() error = %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		got, err := BuildFromMap(m)
+		if err != nil {
+			t.Fatalf("BuildFromMap() error = %v", err)
+		}
+		if string(got) != string(want) {
+			t.Fatalf("BuildFromMap() gotResult = %x, want %x", got, want)
+		}
+	}
+}
+
+func TestBuildFromMultiMap(t *testing.T) {
+	type args struct {
+		m         map[string][]string
+		overrides map[string]Encoding
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult string
+		wantErr    bool
+	}{
+		{"Multiple values, Override, Registered and unknown types", args{
+			map[string][]string{"1.2.3.4": {"x"}, "DC": {"com"}, "CN": {"svc-1"}, "OU": {"a", "b"}, "C": {"JP"}},
+			map[string]Encoding{"CN": EncodingUTF8String},
+		}, "3056310b3009060355040613024a50310a3008060355040b130161310a3008060355040b130162310e300c06035504030c057376632d3131133011060a0992268993f22c6401191603636f6d310a300806032a0304130178", false},
+		{"Override for unknown key", args{map[string][]string{"CN": {"a"}}, map[string]Encoding{"O": EncodingPrintableString}}, "", true},
+		{"Override not encodable", args{map[string][]string{"CN": {"a@b"}}, map[string]Encoding{"CN": EncodingPrintableString}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := BuildFromMultiMap(tt.args.m, tt.args.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BuildFromMultiMap() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if hex.EncodeToString(gotResult) != tt.wantResult {
+				t.Errorf("BuildFromMultiMap() gotResult = %x, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_compareOid(t *testing.T) {
+	type args struct {
+		x asn1.ObjectIdentifier
+		y asn1.ObjectIdentifier
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{"Equal", args{asn1.ObjectIdentifier{2, 5, 4, 3}, asn1.ObjectIdentifier{2, 5, 4, 3}}, 0},
+		{"Less", args{asn1.ObjectIdentifier{2, 5, 4, 3}, asn1.ObjectIdentifier{2, 5, 4, 10}}, -1},
+		{"Greater", args{asn1.ObjectIdentifier{2, 5, 4, 10}, asn1.ObjectIdentifier{2, 5, 4, 3}}, 1},
+		{"Prefix", args{asn1.ObjectIdentifier{2, 5, 4}, asn1.ObjectIdentifier{2, 5, 4, 3}}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareOid(tt.args.x, tt.args.y); got != tt.want {
+				t.Errorf("compareOid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithForceUTF8(t *testing.T) {
+	//C=JP(PrintableString),O=Example(UTF8String),CN=abc(UTF8String)
+	wantForced := "302d310b3009060355040613024a503110300e060355040a0c074578616d706c65310c300a06035504030c03616263"
+	//C=JP(PrintableString),O=Example(PrintableString),CN=abc(PrintableString)
+	wantDefault := "302d310b3009060355040613024a503110300e060355040a13074578616d706c65310c300a06035504031303616263"
+	type subject struct {
+		Country string `dn:"C"`
+		Org     string `dn:"O"`
+		CN      string `dn:"CN"`
+	}
+	tests := []struct {
+		name  string
+		build func(opts ...BuildOption) ([]byte, error)
+	}{
+		{"BuildFromMap", func(opts ...BuildOption) ([]byte, error) {
+			return BuildFromMap(map[string]string{"C": "JP", "O": "Example", "CN": "abc"}, opts...)
+		}},
+		{"BuildFromMultiMap", func(opts ...BuildOption) ([]byte, error) {
+			return BuildFromMultiMap(map[string][]string{"C": {"JP"}, "O": {"Example"}, "CN": {"abc"}}, nil, opts...)
+		}},
+		{"FromPkixName", func(opts ...BuildOption) ([]byte, error) {
+			return FromPkixName(pkix.Name{Country: []string{"JP"}, Organization: []string{"Example"}, CommonName: "abc"}, "", opts...)
+		}},
+		{"ParseString", func(opts ...BuildOption) ([]byte, error) {
+			d, err := ParseString("CN=abc,O=Example,C=JP", opts...)
+			if err != nil {
+				return nil, err
+			}
+			return d.Marshal()
+		}},
+		{"MarshalStruct", func(opts ...BuildOption) ([]byte, error) {
+			return MarshalStruct(subject{Country: "JP", Org: "Example", CN: "abc"}, opts...)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build(WithForceUTF8())
+			if err != nil {
+				t.Fatalf("%s() with WithForceUTF8 error = %v", tt.name, err)
+			}
+			if hex.EncodeToString(got) != wantForced {
+				t.Errorf("%s() with WithForceUTF8 = %x, want %v", tt.name, got, wantForced)
+			}
+			if got, err = tt.build(); err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			if hex.EncodeToString(got) != wantDefault {
+				t.Errorf("%s() = %x, want %v", tt.name, got, wantDefault)
+			}
+		})
+	}
+}
+
+func TestWithEncodingPolicy(t *testing.T) {
+	//C=JP(PrintableString),O=Example(UTF8String),CN=abc(BMPString)
+	want := "3030310b3009060355040613024a503110300e060355040a0c074578616d706c65310f300d06035504031e06006100620063"
+	type subject struct {
+		Country string `dn:"C"`
+		Org     string `dn:"O"`
+		CN      string `dn:"CN"`
+	}
+	tests := []struct {
+		name  string
+		build func(opts ...BuildOption) ([]byte, error)
+	}{
+		{"BuildFromMap", func(opts ...BuildOption) ([]byte, error) {
+			return BuildFromMap(map[string]string{"C": "JP", "O": "Example", "CN": "abc"}, opts...)
+		}},
+		{"BuildFromMultiMap", func(opts ...BuildOption) ([]byte, error) {
+			return BuildFromMultiMap(map[string][]string{"C": {"JP"}, "O": {"Example"}, "CN": {"abc"}}, nil, opts...)
+		}},
+		{"FromPkixName", func(opts ...BuildOption) ([]byte, error) {
+			return FromPkixName(pkix.Name{Country: []string{"JP"}, Organization: []string{"Example"}, CommonName: "abc"}, "", opts...)
+		}},
+		{"ParseString", func(opts ...BuildOption) ([]byte, error) {
+			d, err := ParseString("CN=abc,O=Example,C=JP", opts...)
+			if err != nil {
+				return nil, err
+			}
+			return d.Marshal()
+		}},
+		{"MarshalStruct", func(opts ...BuildOption) ([]byte, error) {
+			return MarshalStruct(subject{Country: "JP", Org: "Example", CN: "abc"}, opts...)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//countryName is not in the policy, so that it is encoded as DirectoryString
+			p := EncodingPolicy{"2.5.4.10": EncodingUTF8String, "2.5.4.3": EncodingBMPString}
+			opt := WithEncodingPolicy(p)
+			//the option copies the policy
+			p["2.5.4.3"] = EncodingIA5String
+			got, err := tt.build(opt)
+			if err != nil {
+				t.Fatalf("%s() with WithEncodingPolicy error = %v", tt.name, err)
+			}
+			if hex.EncodeToString(got) != want {
+				t.Errorf("%s() with WithEncodingPolicy = %x, want %v", tt.name, got, want)
+			}
+		})
+	}
+}
+
+func TestWithForceUTF8_NotDirectoryString(t *testing.T) {
+	tests := []struct {
+		name      string
+		m         map[string][]string
+		overrides map[string]Encoding
+		want      string
+	}{
+		//DC=com(IA5String),1.2.840.113549.1.9.1=a@example.com(IA5String)
+		{"Domain component and emailAddress", map[string][]string{"DC": {"com"}, "1.2.840.113549.1.9.1": {"a@example.com"}}, nil,
+			"303331133011060a0992268993f22c6401191603636f6d311c301a06092a864886f70d010901160d61406578616d706c652e636f6d"},
+		//C=JP(PrintableString),CN=abc(PrintableString)
+		{"Override", map[string][]string{"C": {"JP"}, "CN": {"abc"}}, map[string]Encoding{"CN": EncodingPrintableString},
+			"301b310b3009060355040613024a50310c300a06035504031303616263"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildFromMultiMap(tt.m, tt.overrides, WithForceUTF8())
+			if err != nil {
+				t.Fatalf("BuildFromMultiMap() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("BuildFromMultiMap() = %x, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithForceUTF8_Canonicalize(t *testing.T) {
+	der, err := BuildFromMap(map[string]string{"C": "JP", "CN": "ABC"})
+	if err != nil {
+		t.Fatalf("BuildFromMap() error = %v", err)
+	}
+	got, err := Canonicalize(der)
+	if err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	//C= jp (UTF8String),CN= abc (UTF8String)
+	if want := "301f310d300b06035504060c04206a7020310e300c06035504030c052061626320"; hex.EncodeToString(got) != want {
+		t.Errorf("Canonicalize() = %x, want %v", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"strings"
+)
+
+//Names of the lint rules of ProfileCABFv2. They run only if Profile.Rules names them.
+//A later version of the Baseline Requirements gets new rules and a new profile, so that the findings of
+//ProfileCABFv2 do not change silently.
+const (
+	LintRuleCABFv2EmailAddress       = "cabf-v2-email-address"        //emailAddress attributes
+	LintRuleCABFv2CountryCode        = "cabf-v2-country-code"         //countryName values which are not ISO 3166-1 alpha-2 codes
+	LintRuleCABFv2LocalityWithoutOrg = "cabf-v2-locality-without-org" //address attributes without organizationName
+	LintRuleCABFv2CommonName         = "cabf-v2-common-name"          //commonName attributes after the first one
+	LintRuleCABFv2OrganizationalUnit = "cabf-v2-organizational-unit"  //organizationalUnitName attributes
+)
+
+//ProfileCABFv2 is the Profile of the subject of TLS subscriber certificates by the CA/Browser Forum Baseline
+//Requirements version 2.0.0 for Organization Validated certificates. Each finding cites the section of the Baseline
+//Requirements which it violates.
+//It returns a new Profile for each call, so that changing the result does not affect the other callers.
+//https://cabforum.org/baseline-requirements-documents/
+func ProfileCABFv2() Profile {
+	return Profile{
+		Rules: []string{
+			LintRuleEmptyValues,
+			LintRuleUpperBounds,
+			LintRuleSetOrder,
+			LintRuleControlCharacters,
+			LintRuleCABFv2EmailAddress,
+			LintRuleCABFv2CountryCode,
+			LintRuleCABFv2LocalityWithoutOrg,
+			LintRuleCABFv2CommonName,
+			LintRuleCABFv2OrganizationalUnit,
+		},
+	}
+}
+
+//https://tools.ietf.org/html/rfc5280#appendix-A.1
+//id-at-localityName      AttributeType ::= { id-at 7 }
+//id-at-stateOrProvinceName AttributeType ::= { id-at 8 }
+//id-at-organizationalUnitName AttributeType ::= { id-at 11 }
+var (
+	oidLocalityName           = asn1.ObjectIdentifier{2, 5, 4, 7}
+	oidStateOrProvinceName    = asn1.ObjectIdentifier{2, 5, 4, 8}
+	oidOrganizationalUnitName = asn1.ObjectIdentifier{2, 5, 4, 11}
+)
+
+//https://www.itu.int/rec/T-REC-X.520
+//id-at-streetAddress     AttributeType ::= { id-at 9 }
+//id-at-postalCode        AttributeType ::= { id-at 17 }
+var (
+	oidStreetAddress = asn1.ObjectIdentifier{2, 5, 4, 9}
+	oidPostalCode    = asn1.ObjectIdentifier{2, 5, 4, 17}
+)
+
+//iso3166Alpha2 is the set of the officially assigned ISO 3166-1 alpha-2 codes.
+var iso3166Alpha2 = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, code := range strings.Fields(`
+		AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ
+		BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ
+		CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ
+		DE DJ DK DM DO DZ
+		EC EE EG EH ER ES ET
+		FI FJ FK FM FO FR
+		GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY
+		HK HM HN HR HT HU
+		ID IE IL IM IN IO IQ IR IS IT
+		JE JM JO JP
+		KE KG KH KI KM KN KP KR KW KY KZ
+		LA LB LC LI LK LR LS LT LU LV LY
+		MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ
+		NA NC NE NF NG NI NL NO NP NR NU NZ
+		OM
+		PA PE PF PG PH PK PL PM PN PR PS PT PW PY
+		QA
+		RE RO RS RU RW
+		SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ
+		TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ
+		UA UG UM US UY UZ
+		VA VC VE VG VI VN VU
+		WF WS
+		YE YT
+		ZA ZM ZW`) {
+		m[code] = true
+	}
+	return m
+}()
+
+//lintAttributeTypes reports the attributes of d whose types are in oids with message.
+func lintAttributeTypes(d dn, message string, oids ...asn1.ObjectIdentifier) (findings []Finding) {
+	for i, r := range d {
+		for j, atv := range r {
+			for _, oid := range oids {
+				if oidEqual(atv.Oid, oid) {
+					findings = append(findings, Finding{
+						RDN:       i,
+						Attribute: j,
+						Type:      atv.Oid,
+						Message:   fmt.Sprintf("attribute %s %s", atv.Oid, message),
+					})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+//lintCABFv2EmailAddress reports emailAddress attributes, which are not in the attributes the subject may contain.
+func lintCABFv2EmailAddress(d dn) []Finding {
+	return lintAttributeTypes(d, "is not allowed in the subject (BR 7.1.2.7.4: any other attribute MUST NOT be present)", oidEmailAddress)
+}
+
+//lintCABFv2OrganizationalUnit reports organizationalUnitName attributes, which are prohibited since 2022-09-01.
+func lintCABFv2OrganizationalUnit(d dn) []Finding {
+	return lintAttributeTypes(d, "is prohibited since 2022-09-01 (BR 7.1.2.7.4: organizationalUnitName MUST NOT be present, Ballot SC47)", oidOrganizationalUnitName)
+}
+
+//lintCABFv2LocalityWithoutOrg reports the address attributes of d if d does not contain organizationName.
+func lintCABFv2LocalityWithoutOrg(d dn) []Finding {
+	if countAttribute(d, oidOrganizationName) != 0 {
+		return nil
+	}
+	return lintAttributeTypes(d, "is present without organizationName (BR 7.1.2.7.4: the address attributes MUST NOT be present)",
+		oidStreetAddress, oidLocalityName, oidStateOrProvinceName, oidPostalCode)
+}
+
+//lintCABFv2CommonName reports commonName attributes after the first one. commonName is NOT RECOMMENDED but allowed,
+//and if present it MUST contain exactly one entry.
+func lintCABFv2CommonName(d dn) (findings []Finding) {
+	n := 0
+	for i, r := range d {
+		for j, atv := range r {
+			if !oidEqual(atv.Oid, oidCommonName) {
+				continue
+			}
+			if n++; n > 1 {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("attribute %s appears more than once (BR 7.1.2.7.4: commonName, if present, MUST contain exactly one entry)", atv.Oid),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+//lintCABFv2CountryCode reports countryName attributes whose values are neither ISO 3166-1 alpha-2 codes nor the
+//user-assigned code "XX".
+func lintCABFv2CountryCode(d dn) (findings []Finding, err error) {
+	for i, r := range d {
+		for j, atv := range r {
+			if !oidEqual(atv.Oid, oidCountryName) || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				return nil, err
+			}
+			if !iso3166Alpha2[s] && s != "XX" {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("attribute %s is not an ISO 3166-1 alpha-2 code: %q (BR 7.1.2.7.4: countryName MUST contain the two-letter ISO 3166-1 country code)", atv.Oid, s),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,71 @@
+package dn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateProfile_CABFv2(t *testing.T) {
+	//issued before 2022-09-01, when organizationalUnitName was still allowed
+	pre2022 := mustMarshalString(t, "CN=www.example.com,OU=IT,O=Example Inc,L=Mountain View,ST=California,C=US")
+	ouFinding := Finding{RDN: 4, Attribute: 0, Type: oidOrganizationalUnitName, Message: "attribute 2.5.4.11 is prohibited since 2022-09-01 (BR 7.1.2.7.4: organizationalUnitName MUST NOT be present, Ballot SC47)"}
+	type args struct {
+		dnBytes []byte
+		p       Profile
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantFindings []Finding
+		wantErr      bool
+	}{
+		{"Organization validated", args{mustMarshalString(t, "CN=www.example.com,O=Example Inc,L=Mountain View,ST=California,C=US"), ProfileCABFv2()}, nil, false},
+		{"Domain validated", args{mustMarshalString(t, "CN=www.example.com"), ProfileCABFv2()}, nil, false},
+		{"No commonName", args{mustMarshalString(t, "O=Example Inc,C=JP"), ProfileCABFv2()}, nil, false},
+		{"User-assigned country code", args{mustMarshalString(t, "CN=www.example.com,C=XX"), ProfileCABFv2()}, nil, false},
+		{"Pre-2022 subject with OU", args{pre2022, ProfileCABFv2()}, []Finding{ouFinding}, false},
+		{"Pre-2022 subject with OU, Default profile", args{pre2022, DefaultProfile()}, nil, false},
+		{"Pre-2022 subject with OU, Suppressed", args{pre2022, Profile{Rules: ProfileCABFv2().Rules, SuppressedRules: []string{LintRuleCABFv2OrganizationalUnit}}}, nil, false},
+		{"emailAddress", args{mustMarshalString(t, "1.2.840.113549.1.9.1=admin@example.com,CN=www.example.com,O=Example Inc,C=US"), ProfileCABFv2()},
+			[]Finding{{RDN: 3, Attribute: 0, Type: oidEmailAddress, Message: "attribute 1.2.840.113549.1.9.1 is not allowed in the subject (BR 7.1.2.7.4: any other attribute MUST NOT be present)"}}, false},
+		{"Not ISO 3166 country code", args{mustMarshalString(t, "CN=www.example.com,O=Example Inc,C=UK"), ProfileCABFv2()},
+			[]Finding{{RDN: 0, Attribute: 0, Type: oidCountryName, Message: `attribute 2.5.4.6 is not an ISO 3166-1 alpha-2 code: "UK" (BR 7.1.2.7.4: countryName MUST contain the two-letter ISO 3166-1 country code)`}}, false},
+		{"Lowercase country code", args{mustMarshalString(t, "CN=www.example.com,O=Example Inc,C=jp"), ProfileCABFv2()},
+			[]Finding{{RDN: 0, Attribute: 0, Type: oidCountryName, Message: `attribute 2.5.4.6 is not an ISO 3166-1 alpha-2 code: "jp" (BR 7.1.2.7.4: countryName MUST contain the two-letter ISO 3166-1 country code)`}}, false},
+		{"Locality without organizationName", args{mustMarshalString(t, "CN=www.example.com,L=Chiyoda,ST=Tokyo,C=JP"), ProfileCABFv2()},
+			[]Finding{
+				{RDN: 1, Attribute: 0, Type: oidStateOrProvinceName, Message: "attribute 2.5.4.8 is present without organizationName (BR 7.1.2.7.4: the address attributes MUST NOT be present)"},
+				{RDN: 2, Attribute: 0, Type: oidLocalityName, Message: "attribute 2.5.4.7 is present without organizationName (BR 7.1.2.7.4: the address attributes MUST NOT be present)"},
+			}, false},
+		{"Multiple commonName", args{mustMarshalString(t, "CN=b.example.com,CN=a.example.com,O=Example Inc,C=US"), ProfileCABFv2()},
+			[]Finding{{RDN: 3, Attribute: 0, Type: oidCommonName, Message: "attribute 2.5.4.3 appears more than once (BR 7.1.2.7.4: commonName, if present, MUST contain exactly one entry)"}}, false},
+		{"Broken data", args{brdnb, ProfileCABFv2()}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFindings, err := ValidateProfile(tt.args.dnBytes, tt.args.p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotFindings, tt.wantFindings) {
+				t.Errorf("ValidateProfile() gotFindings = %v, want %v", gotFindings, tt.wantFindings)
+			}
+		})
+	}
+}
+
+func TestProfileCABFv2(t *testing.T) {
+	p := ProfileCABFv2()
+	p.Rules[0] = LintRuleMultiplicity
+	if got := ProfileCABFv2().Rules[0]; got != LintRuleEmptyValues {
+		t.Errorf("ProfileCABFv2().Rules[0] = %v, want %v", got, LintRuleEmptyValues)
+	}
+}
+
+func Test_iso3166Alpha2(t *testing.T) {
+	//the officially assigned codes of ISO 3166-1
+	if got := len(iso3166Alpha2); got != 249 {
+		t.Errorf("len(iso3166Alpha2) = %v, want 249", got)
+	}
+}
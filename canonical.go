@@ -0,0 +1,172 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//Canonicalize converts dnBytes, which is encoded as Distinguished Name, to the canonical form.
+//Distinguished names which match by Compare have the same canonical form. The rules for conversion are:
+//  1. The values of domain component are converted to lower case.
+//  2. The values encoded in UTF8String or PrintableString are converted to UTF8String of the string prepared by the string preparation algorithm(RFC4518).
+//  3. The values of unstructuredName encoded in IA5String are converted to IA5String of the string prepared without case folding.
+//  4. The other values encoded in IA5String are converted to IA5String in the primitive form.
+//  5. The values in any other cases are not converted.
+//  6. The attributes in each RDN are sorted in the order required by DER.
+func Canonicalize(dnBytes []byte) (result []byte, err error) {
+	return NewComparer().Canonicalize(dnBytes)
+}
+
+//Canonicalize converts dnBytes, which is encoded as Distinguished Name, to the canonical form in the same way as
+//Canonicalize, after applying the options of c. Distinguished names which match by c have the same canonical form.
+//The case of the values is preserved for attribute types registered with MatchingRuleCaseExact.
+func (c *Comparer) Canonicalize(dnBytes []byte) (result []byte, err error) {
+	var d dn
+	if err = c.checkInputBytes(dnBytes); err != nil {
+		return nil, err
+	}
+	if d, err = c.parseDn(dnBytes); err != nil {
+		return nil, err
+	}
+	if d, err = c.prepare(d, SideInput); err != nil {
+		return nil, err
+	}
+	if d, err = c.canonicalize(d); err != nil {
+		return nil, err
+	}
+	return marshalDn(d)
+}
+
+//CanonicalKey returns the canonical form of dnBytes, which is encoded as Distinguished Name, as a string
+//for keys of maps. Distinguished names which match by Compare have the same key.
+func CanonicalKey(dnBytes []byte) (key string, err error) {
+	var b []byte
+	if b, err = Canonicalize(dnBytes); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+//canonicalize converts each attribute of d to the canonical form.
+func (c *Comparer) canonicalize(d dn) (result dn, err error) {
+	return c.canonicalizeNested(d, 0)
+}
+
+//canonicalizeNested converts each attribute of d, which is nested at depth, to the canonical form.
+func (c *Comparer) canonicalizeNested(d dn, depth int) (result dn, err error) {
+	result = make(dn, len(d))
+	for i, r := range d {
+		result[i] = make(rdnSET, len(r))
+		for j, atv := range r {
+			if result[i][j], err = c.canonicalizeAttribute(atv, depth); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+//canonicalizeAttribute converts the value of atv, which is in a distinguished name nested at depth, to the canonical
+//form by the rule which appliedRule resolves in the same way as c.compareAttribute.
+func (c *Comparer) canonicalizeAttribute(atv Attribute, depth int) (result Attribute, err error) {
+	rule := c.matchingRule(atv.Oid)
+	applied := appliedRule(atv, atv, rule)
+	switch applied {
+	case AppliedRuleMatchingFunc:
+		return Attribute{}, fmt.Errorf("dn: attribute %s compared by MatchingFunc has no canonical form", atv.Oid)
+	case AppliedRuleDistinguishedNameMatch:
+		var d dn
+		if d, err = parseNestedDn(atv, depth); err != nil {
+			return Attribute{}, err
+		}
+		if d, err = c.canonicalizeNested(d, depth+1); err != nil {
+			return Attribute{}, err
+		}
+		var b []byte
+		if b, err = marshalDn(d); err != nil {
+			return Attribute{}, err
+		}
+		return newRawAttribute(atv.Oid, b)
+	case AppliedRuleEmailAddressMatch:
+		var key string
+		if key, err = emailKey(atv, rule == MatchingRuleEmailAddressCaseIgnore, c.stringPreparer()); err != nil {
+			return Attribute{}, err
+		}
+		return newStringAttribute(atv.Oid, key, EncodingUTF8String)
+	case AppliedRuleBitStringMatch:
+		var b []byte
+		if b, err = canonicalBitString(atv); err != nil {
+			return Attribute{}, err
+		}
+		return newRawAttribute(atv.Oid, b)
+	case AppliedRuleBinaryComparison:
+		if isTypeSpecificRule(rule) && !isIA5StringPair(atv, atv) {
+			return atv, nil
+		}
+	}
+
+	var s string
+	if s, err = decodeAttributeValue(atv); err != nil {
+		return Attribute{}, err
+	}
+	switch applied {
+	case AppliedRuleNumericStringMatch:
+		var u string
+		if u, err = prepareNumericString(c.stringPreparer(), s); err != nil {
+			return Attribute{}, err
+		}
+		return newStringAttribute(atv.Oid, u, EncodingUTF8String)
+	case AppliedRuleCaseInsensitiveExactMatch:
+		if atv.RawValue.Tag != asn1.TagIA5String {
+			return Attribute{}, errors.New("dn: domain component should be IA5String")
+		}
+		return newStringAttribute(atv.Oid, strings.ToLower(s), EncodingIA5String)
+	case AppliedRuleCaseExactMatch, AppliedRuleCaseIgnoreMatch:
+		var u string
+		if u, err = c.stringPreparer().Prepare(s, applied == AppliedRuleCaseIgnoreMatch); err != nil {
+			return Attribute{}, err
+		}
+		//the IA5String form of unstructuredName and the values of the rules of IA5String stay IA5String, because they
+		//never match the DirectoryString form
+		if (oidEqual(atv.Oid, oidUnstructuredName) || isIA5Rule(rule)) && atv.RawValue.Tag == asn1.TagIA5String {
+			return newStringAttribute(atv.Oid, u, EncodingIA5String)
+		}
+		return newStringAttribute(atv.Oid, u, EncodingUTF8String)
+	}
+	if isIA5StringPair(atv, atv) {
+		return newStringAttribute(atv.Oid, s, EncodingIA5String)
+	}
+	return atv, nil
+}
+
+//newRawAttribute returns the attribute whose type is oid and whose value is der.
+func newRawAttribute(oid asn1.ObjectIdentifier, der []byte) (atv Attribute, err error) {
+	atv = Attribute{Oid: oid}
+	if _, err = asn1.Unmarshal(der, &atv.RawValue); err != nil {
+		return Attribute{}, err
+	}
+	return atv, nil
+}
+
+//newStringAttribute returns the attribute whose type is oid and whose value is s encoded by e.
+func newStringAttribute(oid asn1.ObjectIdentifier, s string, e Encoding) (atv Attribute, err error) {
+	var rv asn1.RawValue
+	if rv, err = encodeString(s, e); err != nil {
+		return Attribute{}, err
+	}
+	return Attribute{Oid: oid, RawValue: rv}, nil
+}
+
+//marshalDn encodes d as Distinguished Name.
+//The attributes in each RDN are sorted in the order required by DER.
+func marshalDn(d dn) ([]byte, error) {
+	//https://www.itu.int/rec/T-REC-X.690 section-11.6
+	//The encodings of the component values of a set-of value shall appear in ascending order,
+	//the encodings being compared as octet strings with the shorter components being padded
+	//at their trailing end with 0-octets.
+	//
+	//encoding/asn1 sorts the elements of the slice whose type name ends with "SET".
+	return asn1.Marshal(d)
+}
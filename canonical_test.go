@@ -0,0 +1,196 @@
+package dn
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	//C= jp (UTF8String),O= bar (UTF8String)+O= foo (UTF8String),CN= abc (UTF8String)
+	canonicalDn1 := "303d310d300b06035504060c04206a7020311c300c060355040a0c052062617220300c060355040a0c0520666f6f20310e300c06035504030c052061626320"
+	//DC=COM(IA5String),O=FOO(BMPString)
+	dcUpper, _ := hex.DecodeString("302631133011060a0992268993f22c6401191603434f4d310f300d060355040a1e060046004f004f")
+	//DC=com(IA5String),O=FOO(BMPString)
+	canonicalDcUpper := "302631133011060a0992268993f22c6401191603636f6d310f300d060355040a1e060046004f004f"
+	type args struct {
+		dnBytes []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult string
+		wantErr    bool
+	}{
+		{"Multi RDN in DER order", args{dn1b}, canonicalDn1, false},
+		{"Multi RDN not in DER order", args{dn16b}, canonicalDn1, false},
+		{"Domain component and BMPString", args{dcUpper}, canonicalDcUpper, false},
+		{"Wrong Encoding domain component", args{dn7b}, "", true},
+		{"Broken data", args{brdnb}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := Canonicalize(tt.args.dnBytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Canonicalize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if hex.EncodeToString(gotResult) != tt.wantResult {
+				t.Errorf("Canonicalize() gotResult = %x, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestCanonicalize_Compare(t *testing.T) {
+	tests := []struct {
+		name string
+		x    []byte
+		y    []byte
+	}{
+		{"Upper/Lower case characters", dn2b, dn4b},
+		{"Different Encoding(PrintableString,UTF8String)", dn2b, dn3b},
+		{"Different characters", dn2b, dn6b},
+		{"Different Encoding(PrintableString,BMPString)", dn2b, dn5b},
+		{"Multi RDN not in DER order", dn1b, dn16b},
+		{"unstructuredName, Upper/Lower case characters(IA5String)", dn23b, dn24b},
+		{"unstructuredName, Insignificant spaces(IA5String)", dn25b, dn23b},
+		{"unstructuredName, Upper/Lower case characters(UTF8String)", dn26b, dn27b},
+		{"unstructuredName, Different Encoding(IA5String,UTF8String)", dn24b, dn26b},
+		{"Constructed/Primitive PrintableString", dn36b, dnExamplePrintableb},
+		{"Nested constructed/Primitive PrintableString", dn37b, dnExamplePrintableb},
+		{"Constructed/Primitive BMPString", dn38b, dnExampleBMPb},
+		{"stateOrProvinceName and streetAddress, Upper/Lower case characters and spaces", dn39b, dn40b},
+		{"streetAddress, CR LF and space", dn42b, dn43b},
+		{"streetAddress, Different lines", dn39b, dn41b},
+		{"Full-width Latin, Half-width katakana and Ideographic space", dn70b, dn71b},
+		{"Half-width katakana with voiced sound mark", dn72b, dn73b},
+		{"Half-width katakana with and without voiced sound mark", dn70b, dn72b},
+		{"Composed and decomposed, Upper/Lower case characters", dn75b, dn76b},
+		{"Decomposed and without combining character", dn76b, dn77b},
+		{"Hangul syllable and Hangul jamo", dn78b, dn79b},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := Compare(tt.x, tt.y)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			cx, err := Canonicalize(tt.x)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cy, err := Canonicalize(tt.y)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(cx) == string(cy); got != want {
+				t.Errorf("Canonicalize() equality = %v, Compare() = %v", got, want)
+			}
+		})
+	}
+}
+
+//TestComparer_Canonicalize_Compare tries to split the distinguished names which match by Comparer.Compare into
+//different canonical forms by the encodings which the options of the Comparer accept.
+func TestComparer_Canonicalize_Compare(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		x    []byte
+		y    []byte
+		want bool
+	}{
+		{"Default, Constructed/Primitive PrintableString", nil, dn36b, dnExamplePrintableb, true},
+		{"Default, x500UniqueIdentifier with non-zero unused bits", nil, dn54b, dn55b, true},
+		{"Default, roleOccupant, Different Encoding and spaces", nil, dn45b, dn46b, true},
+		{"Default, roleOccupant, Different characters", nil, dn45b, dn47b, false},
+		{"TeletexString, TeletexString and UTF8String", []Option{WithTeletexString()}, dnTeletexb, dnTeletexUTF8b, true},
+		{"VisibleString, VisibleString and PrintableString", []Option{WithVisibleString()}, dn90b, dn3b, true},
+		{"BMPString, BMPString and UTF8String", []Option{WithBMPString()}, dn5b, dn2b, true},
+		{"BMPString, Constructed BMPString and PrintableString", []Option{WithBMPString()}, dn38b, dnExamplePrintableb, true},
+		{"Lenient PrintableString, PrintableString and UTF8String", []Option{WithLenientPrintableString()}, dnPrintableAtb, dnUTF8Atb, true},
+		{"Lenient domain component, PrintableString and IA5String", []Option{WithLenientDomainComponent()}, dn7b, dn17b, true},
+		{"Trim domain component, Inner spaces", []Option{WithTrimDCWhitespace()}, mustMarshalString(t, "DC=exa  mple,DC=com"), mustMarshalString(t, "DC=exa mple,DC=com"), true},
+		{"Ignored types, Different serialNumber", []Option{WithIgnoredTypes(oidSerialNumber)}, dn12b, dn13b, true},
+		{"Drop empty attributes", []Option{WithDropEmptyAttributes()}, mustMarshalString(t, "OU=,O=Example"), mustMarshalString(t, "O=Example"), true},
+		{"Full case folding, Capital sharp s", []Option{WithFullCaseFolding()}, mustMarshalString(t, "CN=ẞ"), mustMarshalString(t, "CN=ss"), true},
+		{"Web PKI, BMPString and UTF8String", PresetWebPKI(), dn5b, dn2b, true},
+		{"Legacy LDAP, VisibleString and PrintableString", PresetLegacyLDAP(), dn90b, dn3b, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer(tt.opts...)
+			want, err := c.Compare(tt.x, tt.y)
+			if err != nil || want != tt.want {
+				t.Fatalf("Compare() = %v, error = %v, want %v", want, err, tt.want)
+			}
+			cx, err := c.Canonicalize(tt.x)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cy, err := c.Canonicalize(tt.y)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(cx) == string(cy); got != want {
+				t.Errorf("Canonicalize() equality = %v, Compare() = %v", got, want)
+			}
+		})
+	}
+}
+
+func TestComparer_Canonicalize(t *testing.T) {
+	//C= jp (UTF8String),O= example (UTF8String)
+	canonicalDn12 := "3023310d300b06035504060c04206a702031123010060355040a0c09206578616d706c6520"
+	type args struct {
+		opts    []Option
+		dnBytes []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult string
+		wantErr    bool
+	}{
+		{"Ignored types", args{[]Option{WithIgnoredTypes(oidSerialNumber)}, dn12b}, canonicalDn12, false},
+		{"Strict with empty value", args{[]Option{WithStrict()}, dn9b}, "", true},
+		{"StrictDER with multi RDN not in DER order", args{[]Option{WithStrictDER()}, dn16b}, "", true},
+		{"Broken data", args{nil, brdnb}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(tt.args.opts...).Canonicalize(tt.args.dnBytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Canonicalize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if hex.EncodeToString(gotResult) != tt.wantResult {
+				t.Errorf("Canonicalize() gotResult = %x, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestCanonicalKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		dnBytes []byte
+		wantKey string
+		wantErr bool
+	}{
+		{"Multi RDN not in DER order", dn16b, "303d310d300b06035504060c04206a7020311c300c060355040a0c052062617220300c060355040a0c0520666f6f20310e300c06035504030c052061626320", false},
+		{"Broken data", brdnb, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKey, err := CanonicalKey(tt.dnBytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CanonicalKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if hex.EncodeToString([]byte(gotKey)) != tt.wantKey {
+				t.Errorf("CanonicalKey() gotKey = %x, want %v", gotKey, tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package dn
+
+import (
+	"crypto/x509"
+	"errors"
+)
+
+//IsSelfIssued reports whether the issuer and the subject of cert match by Compare, i.e. cert is self-issued,
+//as self-signed certificates are. Unlike the byte equality of cert.RawIssuer and cert.RawSubject, it recognizes
+//certificates which encode the same name differently in the two fields.
+//
+//IsSelfIssued returns false for certificates whose issuer or subject is an empty sequence, which names no entity.
+//It returns an error if cert is nil or the names cannot be parsed, e.g. cert is not parsed by x509.ParseCertificate.
+func IsSelfIssued(cert *x509.Certificate) (result bool, err error) {
+	//https://tools.ietf.org/html/rfc5280#section-3.3
+	//Self-issued certificates are CA certificates in which the issuer
+	//and subject are the same entity.
+	if cert == nil {
+		return false, errors.New("dn: certificate is nil")
+	}
+	var i, s dn
+	if i, err = parseDn(cert.RawIssuer); err != nil {
+		return false, err
+	}
+	if s, err = parseDn(cert.RawSubject); err != nil {
+		return false, err
+	}
+	if len(i) == 0 || len(s) == 0 {
+		return false, nil
+	}
+	return matchDistinguishedName(i, s, compareAttribute)
+}
@@ -0,0 +1,69 @@
+package dn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+)
+
+//newTestCertificateIssuedBy returns a certificate whose subject is rawSubject and issuer is rawIssuer.
+func newTestCertificateIssuedBy(t *testing.T, rawSubject []byte, rawIssuer []byte) (cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		RawSubject:   rawSubject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	parent := &x509.Certificate{RawSubject: rawIssuer}
+	raw, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = x509.ParseCertificate(raw); err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestIsSelfIssued(t *testing.T) {
+	emptyName := []byte{0x30, 0x00}
+	_, selfSigned := newTestCertificate(t, dn2b)
+	tests := []struct {
+		name       string
+		cert       *x509.Certificate
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Self-signed", selfSigned, true, false},
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", newTestCertificateIssuedBy(t, dn2b, dn3b), true, false},
+		{"Multi RDN not in DER order", newTestCertificateIssuedBy(t, dn16b, dn1b), true, false},
+		{"Different characters", newTestCertificateIssuedBy(t, dn2b, dn6b), false, false},
+		{"Same characters, Different Encoding(PrintableString,BMPString)", newTestCertificateIssuedBy(t, dn5b, dn2b), false, false},
+		{"Empty subject", newTestCertificateIssuedBy(t, emptyName, dn2b), false, false},
+		{"Empty issuer and subject", newTestCertificateIssuedBy(t, emptyName, emptyName), false, false},
+		{"Wrong Encoding domain component", newTestCertificateIssuedBy(t, dn7b, dn7b), false, true},
+		{"Not parsed", &x509.Certificate{}, false, true},
+		{"Nil", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := IsSelfIssued(tt.cert)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsSelfIssued() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("IsSelfIssued() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
@@ -0,0 +1,497 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+//Comparer compares distinguished names with configurable options.
+//The zero value compares distinguished names in the same way as Compare.
+type Comparer struct {
+	strict               bool
+	strictDER            bool
+	teletex              bool
+	visible              bool
+	bmp                  bool
+	lenientPrintable     bool
+	lenientDC            bool
+	constantTime         bool
+	rejectUnknownTag     bool
+	rejectDuplicateRDNs  bool
+	trimDCWhitespace     bool
+	dropEmptyAttributes  bool
+	ignoredTypes         []asn1.ObjectIdentifier
+	matchingRules        map[string]MatchingRule //keyed by the dotted string form of the attribute type
+	matchingFuncs        map[string]MatchingFunc //keyed by the dotted string form of the attribute type
+	auditHook            func(Event)
+	maxInputBytes        int
+	maxRawAttributeBytes int
+	maxRDNAttributes     int
+	observer             Observer
+	trace                func(TraceEvent)
+	verboseTrace         bool
+	preparer             StringPreparer
+	redactPeerDN         func(subject string) string
+	strictness           *MatchStrictness //relaxed rules recorded for CompareScored, nil for the other comparisons
+}
+
+//Option configures a Comparer.
+type Option func(*Comparer)
+
+//NewComparer returns a Comparer configured by opts.
+func NewComparer(opts ...Option) *Comparer {
+	c := &Comparer{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+//WithStrict makes the Comparer return an error for distinguished names which are accepted by the default comparison
+//but are almost always broken, such as attributes whose values are empty after the string preparation,
+//countryName and jurisdictionCountryName values which are not exactly two letters, e.g. "JP " which matches "JP"
+//by the default comparison, domainComponent values which contain whitespace, e.g. "exa mple", and values which contain
+//control characters removed by the string preparation, e.g. "AB\x00C" which matches "ABC" by the default comparison.
+//UTF8String values which contain overlong sequences, e.g. NUL encoded as 0xC0 0x80, are reported as such. The default
+//comparison also rejects them as invalid UTF-8, so that they never match the values in the shortest form.
+func WithStrict() Option {
+	return func(c *Comparer) {
+		c.strict = true
+	}
+}
+
+//WithStrictDER makes the Comparer return an error for distinguished names which violate DER,
+//such as multi-valued RDNs whose attributes are not sorted.
+//The comparison itself does not depend on the order of the attributes in RDNs.
+func WithStrictDER() Option {
+	return func(c *Comparer) {
+		c.strictDER = true
+	}
+}
+
+//WithRejectUnknownStringTags makes the Comparer return an error for attributes whose values are not encoded in
+//one of the string types which the comparison can decode: UTF8String, PrintableString, TeletexString, BMPString,
+//IA5String, NumericString, VisibleString and GeneralString. The attributes are checked before the comparison, so that the error does not
+//depend on which attributes happen to be compared. By default, such values are not checked, and the comparison
+//returns an error only when it tries to decode them.
+//Only the attribute types of DirectoryString, IA5String or NumericString syntax are checked, i.e. the types which are
+//not compared by MatchingRuleDistinguishedName, MatchingRuleBitString, MatchingRuleOctetString or MatchingFunc. The values of the other types,
+//e.g. x500UniqueIdentifier in BIT STRING or member in Name, are accepted.
+//It is a safety option for environments where values of unexpected types, such as VideotexString or
+//GraphicString, should never be accepted.
+func WithRejectUnknownStringTags() Option {
+	return func(c *Comparer) {
+		c.rejectUnknownTag = true
+	}
+}
+
+//hasStringSyntax reports whether the values of attribute type oid are strings by the matching rules of c.
+//The attribute types which are not registered are DirectoryString, as RFC 5280 section-4.1.2.6 assumes for the
+//unfamiliar attribute types.
+func (c *Comparer) hasStringSyntax(oid asn1.ObjectIdentifier) bool {
+	switch c.matchingRule(oid) {
+	case MatchingRuleDistinguishedName, MatchingRuleBitString, MatchingRuleOctetString, matchingRuleFunc:
+		return false
+	}
+	return true
+}
+
+//WithRejectDuplicateRDNs makes the Comparer return an error for distinguished names which contain the same RDN
+//more than once, e.g. two RDNs of CN=foo. RDNs are the same if they match by the matching rules of the Comparer,
+//after the other options are applied. Such distinguished names are allowed by X.501, but are suspicious of being
+//malformed or padded. By default, they are compared as they are.
+func WithRejectDuplicateRDNs() Option {
+	return func(c *Comparer) {
+		c.rejectDuplicateRDNs = true
+	}
+}
+
+//WithIgnoredTypes makes the Comparer ignore attributes whose types are one of oids.
+//RDNs which consist of only ignored attributes are ignored as well.
+func WithIgnoredTypes(oids ...asn1.ObjectIdentifier) Option {
+	return func(c *Comparer) {
+		c.ignoredTypes = append(c.ignoredTypes, oids...)
+	}
+}
+
+//CompareIgnoringSerialNumber reports whether a and b matches, ignoring serialNumber attributes.
+//It is useful for device certificates whose subjects differ only in serialNumber.
+func CompareIgnoringSerialNumber(a []byte, b []byte) (result bool, err error) {
+	return NewComparer(WithIgnoredTypes(oidSerialNumber)).Compare(a, b)
+}
+
+//Compare reports whether issuer and subject matches.
+//The numbers of RDNs and attributes and the attribute types of each RDN are compared first, and then the RDNs are
+//compared in order until one of them does not match. The values are decoded only when they are compared with the
+//values of the same type. The broken values which are never compared cause no error, unless the options of c check
+//all the values, e.g. WithStrict, or c has WithConstantTime, which compares all the RDNs. WithTrace and WithObserver
+//report the decisions as CompareExplain, which returns the same result.
+func (c *Comparer) Compare(issuer []byte, subject []byte) (result bool, err error) {
+	if c.observer != nil {
+		return c.compareObserved(issuer, subject)
+	}
+	result, _, _, err = c.compare(issuer, subject, false)
+	return result, err
+}
+
+//compare is the one code path of Compare, CompareExplain and the Observer of c.
+//If explain is true, it compares issuer and subject by explainDistinguishedName and returns the decisions.
+//If it returns an error, side is the input which caused it.
+func (c *Comparer) compare(issuer []byte, subject []byte, explain bool) (result bool, decisions []RDNDecision, side Side, err error) {
+	if err = c.checkInputBytes(issuer); err != nil {
+		return false, nil, SideIssuer, err
+	}
+	if err = c.checkInputBytes(subject); err != nil {
+		return false, nil, SideSubject, err
+	}
+	if len(issuer) == 0 {
+		//https://tools.ietf.org/html/rfc5280#section-4.1.2.4
+		//The issuer field MUST contain a non-empty distinguished name (DN)
+		return false, nil, SideIssuer, errors.New("dn: the issuer field must contain a non-empty distinguished name")
+	}
+
+	if len(subject) == 0 {
+		//issuer is not blank, but subject is blank
+		return false, nil, 0, nil
+	}
+
+	var s []rdnSET
+	var i []rdnSET
+	if i, err = c.parseDn(issuer); err != nil {
+		return false, nil, SideIssuer, err
+	}
+	if s, err = c.parseDn(subject); err != nil {
+		return false, nil, SideSubject, err
+	}
+	if i, err = c.prepare(i, SideIssuer); err != nil {
+		return false, nil, SideIssuer, err
+	}
+	if s, err = c.prepare(s, SideSubject); err != nil {
+		return false, nil, SideSubject, err
+	}
+	//CompareScored checks the values by the rules of Compare only for the attributes assigned to each other,
+	//which the decisions record
+	scored := c.strictness != nil && c.hasCustomValueRules()
+	switch {
+	case explain || scored || c.trace != nil && !c.constantTime:
+		result, decisions, err = c.explainDistinguishedName(i, s)
+	case c.constantTime:
+		result, err = matchDistinguishedNameConstantTime(i, s, c.compareAttribute)
+	default:
+		result, err = matchDistinguishedName(i, s, c.attributeMatcher(i))
+	}
+	if err != nil {
+		return false, nil, SideBoth, err
+	}
+	if result && scored {
+		c.relaxValueRules(i, s, decisions)
+	}
+	if !explain {
+		decisions = nil
+	}
+	return result, decisions, 0, nil
+}
+
+//attributeMatcher returns the attributeMatcher which compares the attributes of d with another distinguished name.
+//The attributes of a multi-valued RDN may be compared with several attributes of the other RDN, so if d has one,
+//the values are prepared once for each of the distinct strings during the comparison by withCachingPreparer.
+func (c *Comparer) attributeMatcher(d dn) attributeMatcher {
+	for _, r := range d {
+		if len(r) > 1 {
+			return c.withCachingPreparer().compareAttribute
+		}
+	}
+	return c.compareAttribute
+}
+
+//prepare applies the options of c to d, which is on side, before the comparison.
+func (c *Comparer) prepare(d dn, side Side) (result dn, err error) {
+	if c.auditHook != nil {
+		c.audit(d, side)
+	}
+	if len(c.ignoredTypes) != 0 {
+		before := d
+		d = c.removeIgnoredTypes(d)
+		c.relaxIfChanged(MatchRelaxedDroppedAttributes, before, d)
+	}
+	if c.strictDER {
+		if err = checkStrictDER(d); err != nil {
+			return nil, err
+		}
+	}
+	if c.strict {
+		if err = c.checkStrict(d); err != nil {
+			return nil, err
+		}
+	}
+	if c.rejectUnknownTag {
+		if findings := c.lintStringTags(d); len(findings) != 0 {
+			return nil, findings[0].err()
+		}
+	}
+	if c.teletex {
+		before := d
+		if d, err = transcodeTeletexStrings(d); err != nil {
+			return nil, err
+		}
+		c.relaxIfChanged(MatchRelaxedTeletexString, before, d)
+	}
+	if c.visible {
+		before := d
+		if d, err = transcodeVisibleStrings(d); err != nil {
+			return nil, err
+		}
+		c.relaxIfChanged(MatchRelaxedVisibleString, before, d)
+	}
+	if c.bmp {
+		before := d
+		if d, err = transcodeBMPStrings(d); err != nil {
+			return nil, err
+		}
+		c.relaxIfChanged(MatchRelaxedBMPString, before, d)
+	}
+	if c.lenientPrintable {
+		before := d
+		if d, err = transcodePrintableStrings(d); err != nil {
+			return nil, err
+		}
+		c.relaxIfChanged(MatchRelaxedPrintableString, before, d)
+	}
+	if c.lenientDC {
+		before := d
+		if d, err = convertDomainComponents(d); err != nil {
+			return nil, err
+		}
+		c.relaxIfChanged(MatchRelaxedDomainComponent, before, d)
+	}
+	if c.trimDCWhitespace {
+		before := d
+		if d, err = trimDomainComponents(d); err != nil {
+			return nil, err
+		}
+		c.relaxIfChanged(MatchRelaxedDomainComponent, before, d)
+	}
+	if c.dropEmptyAttributes {
+		before := d
+		if d, err = c.removeEmptyAttributes(d); err != nil {
+			return nil, err
+		}
+		c.relaxIfChanged(MatchRelaxedDroppedAttributes, before, d)
+	}
+	if c.rejectDuplicateRDNs {
+		if err = c.checkDuplicateRDNs(d); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+//CompareAttribute reports whether attribute x and attribute y matches in the same way as Compare.
+func CompareAttribute(x Attribute, y Attribute) (result bool, err error) {
+	return NewComparer().CompareAttribute(x, y)
+}
+
+//CompareAttribute reports whether attribute x and attribute y matches, applying the options of c.
+//Attributes whose types are both ignored by c matches regardless of their values.
+//If RawValue.FullBytes of the attribute is empty, the value is encoded from RawValue.Class, RawValue.Tag and RawValue.Bytes.
+func (c *Comparer) CompareAttribute(x Attribute, y Attribute) (result bool, err error) {
+	if c.isIgnoredType(x.Oid) && c.isIgnoredType(y.Oid) {
+		return true, nil
+	}
+	if x, err = completeAttribute(x); err != nil {
+		return false, err
+	}
+	if y, err = completeAttribute(y); err != nil {
+		return false, err
+	}
+	for _, atv := range []Attribute{x, y} {
+		if err = c.checkRawAttributeBytes(atv); err != nil {
+			return false, err
+		}
+	}
+	if c.strict {
+		for _, atv := range []Attribute{x, y} {
+			isEmpty := false
+			if isEmpty, err = isEmptyValue(atv); err != nil {
+				return false, err
+			}
+			if isEmpty {
+				return false, fmt.Errorf("dn: attribute %s has an empty value", atv.Oid)
+			}
+		}
+	}
+	if c.rejectUnknownTag {
+		for _, atv := range []Attribute{x, y} {
+			if c.hasStringSyntax(atv.Oid) && !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				return false, fmt.Errorf("dn: attribute %s has a value of unsupported class %d tag %d", atv.Oid, atv.RawValue.Class, atv.RawValue.Tag)
+			}
+		}
+	}
+	if c.auditHook != nil {
+		c.auditAttribute(x, SideIssuer, -1, -1)
+		c.auditAttribute(y, SideSubject, -1, -1)
+	}
+	if c.teletex {
+		if x, err = transcodeTeletexString(x); err != nil {
+			return false, err
+		}
+		if y, err = transcodeTeletexString(y); err != nil {
+			return false, err
+		}
+	}
+	if c.visible {
+		if x, err = transcodeVisibleString(x); err != nil {
+			return false, err
+		}
+		if y, err = transcodeVisibleString(y); err != nil {
+			return false, err
+		}
+	}
+	if c.bmp {
+		if x, err = transcodeBMPString(x); err != nil {
+			return false, err
+		}
+		if y, err = transcodeBMPString(y); err != nil {
+			return false, err
+		}
+	}
+	if c.lenientPrintable {
+		if x, err = transcodePrintableString(x); err != nil {
+			return false, err
+		}
+		if y, err = transcodePrintableString(y); err != nil {
+			return false, err
+		}
+	}
+	if c.lenientDC {
+		if x, err = convertDomainComponent(x); err != nil {
+			return false, err
+		}
+		if y, err = convertDomainComponent(y); err != nil {
+			return false, err
+		}
+	}
+	if c.trimDCWhitespace {
+		if x, err = trimDomainComponent(x); err != nil {
+			return false, err
+		}
+		if y, err = trimDomainComponent(y); err != nil {
+			return false, err
+		}
+	}
+	return c.compareAttribute(x, y)
+}
+
+//CompareAttributeValues reports whether value x and value y of attribute type oid matches in the same way as Compare.
+func CompareAttributeValues(oid asn1.ObjectIdentifier, x asn1.RawValue, y asn1.RawValue) (result bool, err error) {
+	return NewComparer().CompareAttributeValues(oid, x, y)
+}
+
+//CompareAttributeValues reports whether value x and value y of attribute type oid matches, applying the options of c.
+//It is the same as CompareAttribute for the attributes of type oid, and is useful for the values taken from other
+//than distinguished names, e.g. LDAP entries or certificate extensions.
+//If FullBytes of the value is empty, the value is encoded from Class, Tag and Bytes.
+func (c *Comparer) CompareAttributeValues(oid asn1.ObjectIdentifier, x asn1.RawValue, y asn1.RawValue) (result bool, err error) {
+	return c.CompareAttribute(Attribute{Oid: oid, RawValue: x}, Attribute{Oid: oid, RawValue: y})
+}
+
+//completeAttribute returns atv whose RawValue.FullBytes is filled.
+func completeAttribute(atv Attribute) (result Attribute, err error) {
+	if len(atv.RawValue.FullBytes) != 0 {
+		return atv, nil
+	}
+	if atv.RawValue.FullBytes, err = asn1.Marshal(atv.RawValue); err != nil {
+		return Attribute{}, fmt.Errorf("dn: failed to encode value of attribute %s: %w", atv.Oid, err)
+	}
+	return atv, nil
+}
+
+//checkStrict returns an error if d violates the rules enforced by WithStrict.
+func (c *Comparer) checkStrict(d dn) error {
+	//the overlong sequences are reported first, because the other checks cannot decode them
+	findings, err := lintOverlongUTF8(d)
+	if err != nil {
+		return err
+	}
+	if len(findings) != 0 {
+		return findings[0].err()
+	}
+	if findings, err = lintEmptyValues(d); err != nil {
+		return err
+	}
+	var countryFindings []Finding
+	if countryFindings, err = lintCountryNames(d); err != nil {
+		return err
+	}
+	findings = append(findings, countryFindings...)
+	var dcFindings []Finding
+	if dcFindings, err = lintDomainComponentWhitespace(d); err != nil {
+		return err
+	}
+	findings = append(findings, dcFindings...)
+	var controlFindings []Finding
+	if controlFindings, err = lintControlCharacters(d); err != nil {
+		return err
+	}
+	findings = append(findings, controlFindings...)
+	if len(findings) != 0 {
+		return findings[0].err()
+	}
+	return nil
+}
+
+//checkStrictDER returns an error if d violates the rules enforced by WithStrictDER.
+func checkStrictDER(d dn) error {
+	findings, err := lintSetOrder(d)
+	if err != nil {
+		return err
+	}
+	if len(findings) != 0 {
+		return findings[0].err()
+	}
+	return nil
+}
+
+//checkDuplicateRDNs returns an error if d contains RDNs which match each other, as enforced by WithRejectDuplicateRDNs.
+func (c *Comparer) checkDuplicateRDNs(d dn) error {
+	for i := 1; i < len(d); i++ {
+		for j := 0; j < i; j++ {
+			isMatched, err := compareRelativeDistinguishedName(d[j], d[i], c.compareAttribute)
+			if err != nil {
+				return err
+			}
+			if isMatched {
+				return fmt.Errorf("dn: RDN %d is a duplicate of RDN %d", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+//removeIgnoredTypes returns d without attributes which are ignored by WithIgnoredTypes.
+func (c *Comparer) removeIgnoredTypes(d dn) (result dn) {
+	result = make(dn, 0, len(d))
+	for _, r := range d {
+		nr := make(rdnSET, 0, len(r))
+		for _, atv := range r {
+			if !c.isIgnoredType(atv.Oid) {
+				nr = append(nr, atv)
+			}
+		}
+		if len(nr) != 0 {
+			result = append(result, nr)
+		}
+	}
+	return result
+}
+
+//isIgnoredType reports whether oid is ignored by WithIgnoredTypes.
+func (c *Comparer) isIgnoredType(oid asn1.ObjectIdentifier) bool {
+	for _, t := range c.ignoredTypes {
+		if t.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"strings"
+	"testing"
+)
+
+func TestComparer_Compare(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Default, Empty UTF8String CN", nil, args{issuer: dn9b, subject: dn9b}, true, false},
+		{"Default, PrintableString O with only spaces", nil, args{issuer: dn10b, subject: dn10b}, true, false},
+		{"Strict, Same characters", []Option{WithStrict()}, args{issuer: dn2b, subject: dn3b}, true, false},
+		{"Strict, Empty UTF8String CN in issuer", []Option{WithStrict()}, args{issuer: dn9b, subject: dn2b}, false, true},
+		{"Strict, Empty UTF8String CN in subject", []Option{WithStrict()}, args{issuer: dn2b, subject: dn9b}, false, true},
+		{"Strict, PrintableString O with only spaces", []Option{WithStrict()}, args{issuer: dn10b, subject: dn10b}, false, true},
+		{"Default, Country name with spaces", nil, args{issuer: dn20b, subject: dn2b}, true, false},
+		{"Strict, Country name with leading space", []Option{WithStrict()}, args{issuer: dn18b, subject: dn2b}, false, true},
+		{"Strict, Country name with trailing space", []Option{WithStrict()}, args{issuer: dn2b, subject: dn19b}, false, true},
+		{"Strict, Country name with inner space", []Option{WithStrict()}, args{issuer: dn22b, subject: dn2b}, false, true},
+		{"Strict, Country name of three letters", []Option{WithStrict()}, args{issuer: dn21b, subject: dn21b}, false, true},
+		{"Default, Embedded NUL", nil, args{issuer: dn85b, subject: dn2b}, true, false},
+		{"Strict, Embedded NUL in issuer", []Option{WithStrict()}, args{issuer: dn85b, subject: dn2b}, false, true},
+		{"Strict, Embedded NUL in subject", []Option{WithStrict()}, args{issuer: dn2b, subject: dn86b}, false, true},
+		{"Strict, NEXT LINE", []Option{WithStrict()}, args{issuer: dn87b, subject: dn87b}, true, false},
+		{"Default, Overlong UTF-8", nil, args{issuer: dn105b, subject: dn85b}, false, true},
+		{"Strict, Overlong UTF-8 in issuer", []Option{WithStrict()}, args{issuer: dn105b, subject: dn85b}, false, true},
+		{"Strict, Overlong UTF-8 in subject", []Option{WithStrict()}, args{issuer: dn85b, subject: dn105b}, false, true},
+		{"Default, Jurisdiction country name, Upper/Lower case characters", nil, args{issuer: dn32b, subject: dn33b}, true, false},
+		{"Default, Jurisdiction country name with leading space", nil, args{issuer: dn34b, subject: dn32b}, true, false},
+		{"Strict, Jurisdiction country name, Upper/Lower case characters", []Option{WithStrict()}, args{issuer: dn32b, subject: dn33b}, true, false},
+		{"Strict, Jurisdiction country name with leading space", []Option{WithStrict()}, args{issuer: dn34b, subject: dn32b}, false, true},
+		{"Strict, Jurisdiction country name of three letters", []Option{WithStrict()}, args{issuer: dn32b, subject: dn35b}, false, true},
+		{"Default, Multi RDN not in DER order", nil, args{issuer: dn1b, subject: dn16b}, true, false},
+		{"Strict DER, Multi RDN in DER order", []Option{WithStrictDER()}, args{issuer: dn1b, subject: dn1b}, true, false},
+		{"Strict DER, Multi RDN not in DER order", []Option{WithStrictDER()}, args{issuer: dn1b, subject: dn16b}, false, true},
+		{"Ignored types, Different serialNumber", []Option{WithIgnoredTypes(oidSerialNumber)}, args{issuer: dn12b, subject: dn13b}, true, false},
+		{"Ignored types, Different CN", []Option{WithIgnoredTypes(oidSerialNumber)}, args{issuer: dn2b, subject: dn6b}, false, false},
+		{"Ignored types, Multi RDN", []Option{WithIgnoredTypes(oidOrganization)}, args{issuer: dn1b, subject: dn2b}, true, false},
+		{"Default, VideotexString", nil, args{issuer: dn28b, subject: dn28b}, false, true},
+		{"Default, GraphicString in subject", nil, args{issuer: dn2b, subject: dn29b}, false, false},
+		{"Reject unknown string tags, Known string types", []Option{WithRejectUnknownStringTags()}, args{issuer: dn17b, subject: dn17b}, true, false},
+		{"Reject unknown string tags, NumericString", []Option{WithRejectUnknownStringTags()}, args{issuer: dn31b, subject: dn31b}, true, false},
+		{"Reject unknown string tags, VideotexString", []Option{WithRejectUnknownStringTags()}, args{issuer: dn28b, subject: dn28b}, false, true},
+		{"Reject unknown string tags, GraphicString in subject", []Option{WithRejectUnknownStringTags()}, args{issuer: dn2b, subject: dn29b}, false, true},
+		{"Reject unknown string tags, Context-specific class", []Option{WithRejectUnknownStringTags()}, args{issuer: dn30b, subject: dn30b}, false, true},
+		{"Reject unknown string tags, Ignored types", []Option{WithRejectUnknownStringTags(), WithIgnoredTypes(oidOrganization)}, args{issuer: dn28b, subject: dn29b}, true, false},
+		{"Reject unknown string tags, x500UniqueIdentifier in BIT STRING", []Option{WithRejectUnknownStringTags()}, args{issuer: dn54b, subject: dn54b}, true, false},
+		{"Reject unknown string tags, roleOccupant in Name", []Option{WithRejectUnknownStringTags()}, args{issuer: dn45b, subject: dn46b}, true, false},
+		{"Default, Duplicate RDNs", nil, args{issuer: dn51b, subject: dn51b}, true, false},
+		{"Reject duplicate RDNs, Distinct RDNs", []Option{WithRejectDuplicateRDNs()}, args{issuer: dn1b, subject: dn1b}, true, false},
+		{"Reject duplicate RDNs, Identical RDNs in issuer", []Option{WithRejectDuplicateRDNs()}, args{issuer: dn51b, subject: dn2b}, false, true},
+		{"Reject duplicate RDNs, Identical RDNs in subject", []Option{WithRejectDuplicateRDNs()}, args{issuer: dn2b, subject: dn51b}, false, true},
+		{"Reject duplicate RDNs, RDNs matched by caseIgnoreMatch", []Option{WithRejectDuplicateRDNs()}, args{issuer: dn52b, subject: dn52b}, false, true},
+		{"Reject duplicate RDNs, Multi-valued RDN containing the same attribute", []Option{WithRejectDuplicateRDNs()}, args{issuer: dn53b, subject: dn53b}, true, false},
+		{"Reject duplicate RDNs, Duplicate RDNs of ignored types", []Option{WithRejectDuplicateRDNs(), WithIgnoredTypes(asn1.ObjectIdentifier{2, 5, 4, 3})}, args{issuer: dn51b, subject: dn51b}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(tt.opts...).Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestWithStrict_OverlongUTF8(t *testing.T) {
+	_, err := NewComparer(WithStrict()).Compare(dn105b, dn85b)
+	if err == nil || !strings.Contains(err.Error(), "overlong UTF-8 sequence at 2") {
+		t.Errorf("Compare() error = %v, want overlong UTF-8 sequence", err)
+	}
+}
+
+func TestCompareIgnoringSerialNumber(t *testing.T) {
+	type args struct {
+		a []byte
+		b []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Different serialNumber", args{a: dn12b, b: dn13b}, true, false},
+		{"serialNumber in one side", args{a: dn12b, b: dn15b}, false, false},
+		{"serialNumber in Multi RDN", args{a: dn14b, b: dn15b}, true, false},
+		{"Different characters", args{a: dn2b, b: dn6b}, false, false},
+		{"Broken data", args{a: brdnb, b: dn12b}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := CompareIgnoringSerialNumber(tt.args.a, tt.args.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompareIgnoringSerialNumber() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("CompareIgnoringSerialNumber() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestComparer_CompareAttribute(t *testing.T) {
+	//O=(UTF8String)
+	emptyAtv := Attribute{Oid: oidOrganization, RawValue: asn1.RawValue{Tag: asn1.TagUTF8String, FullBytes: []byte{0x0c, 0x00}}}
+	//O=abc(BMPString) without FullBytes
+	bmpNoFullBytesAtv := Attribute{Oid: oidOrganization, RawValue: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagBMPString, Bytes: []byte{0x00, 0x61, 0x00, 0x62, 0x00, 0x63}}}
+	//O=abc(VideotexString)
+	videotexAtv := Attribute{Oid: oidOrganization, RawValue: asn1.RawValue{Class: asn1.ClassUniversal, Tag: 21, Bytes: []byte("abc"), FullBytes: []byte{0x15, 0x03, 0x61, 0x62, 0x63}}}
+	type args struct {
+		x Attribute
+		y Attribute
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Default, BMPString and UTF8String", nil, args{x: bmpAtv, y: utf8Atv}, false, false},
+		{"Default, BMPString and BMPString", nil, args{x: bmpAtv, y: bmpAtv}, true, false},
+		{"Default, PrintableString and UTF8String with different characters", nil, args{x: pAtv, y: utf8dAtv}, false, false},
+		{"Default, Different types", nil, args{x: pAtv, y: ia5Atv}, false, false},
+		{"Default, Wrong encoding domain component", nil, args{x: wrongDcAtv, y: ia5Atv}, false, true},
+		{"Default, Broken data", nil, args{x: brokenAtv, y: pAtv}, false, true},
+		{"Default, Without FullBytes", nil, args{x: bmpNoFullBytesAtv, y: bmpAtv}, true, false},
+		{"Default, Empty UTF8String", nil, args{x: emptyAtv, y: emptyAtv}, true, false},
+		{"Strict, Empty UTF8String", []Option{WithStrict()}, args{x: emptyAtv, y: emptyAtv}, false, true},
+		{"Strict, Same characters", []Option{WithStrict()}, args{x: pAtv, y: utf8Atv}, true, false},
+		{"Ignored types, Different characters", []Option{WithIgnoredTypes(oidOrganization)}, args{x: pAtv, y: pdAtv}, true, false},
+		{"Ignored types, Different types", []Option{WithIgnoredTypes(oidOrganization)}, args{x: pAtv, y: ia5Atv}, false, false},
+		{"Reject unknown string tags, Known string types", []Option{WithRejectUnknownStringTags()}, args{x: bmpAtv, y: bmpAtv}, true, false},
+		{"Reject unknown string tags, VideotexString", []Option{WithRejectUnknownStringTags()}, args{x: pAtv, y: videotexAtv}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(tt.opts...).CompareAttribute(tt.args.x, tt.args.y)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompareAttribute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("CompareAttribute() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestComparer_CompareAttributeValues(t *testing.T) {
+	//ABC(UTF8String) without FullBytes
+	upperValue := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagUTF8String, Bytes: []byte("ABC")}
+	alwaysMatch := func(x MatchingValue, y MatchingValue) (bool, error) {
+		return true, nil
+	}
+	type args struct {
+		oid asn1.ObjectIdentifier
+		x   asn1.RawValue
+		y   asn1.RawValue
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Default, PrintableString and UTF8String", nil, args{oidOrganization, pAtv.RawValue, utf8Atv.RawValue}, true, false},
+		{"Default, Upper/Lower case characters", nil, args{oidOrganization, upperValue, utf8Atv.RawValue}, true, false},
+		{"Default, BMPString and UTF8String", nil, args{oidOrganization, bmpAtv.RawValue, utf8Atv.RawValue}, false, false},
+		{"Default, Different characters", nil, args{oidOrganization, pAtv.RawValue, pdAtv.RawValue}, false, false},
+		{"Default, Domain component", nil, args{oidDomainComponent, ia5Atv.RawValue, ia5dAtv.RawValue}, false, false},
+		{"Default, Wrong encoding domain component", nil, args{oidDomainComponent, wrongDcAtv.RawValue, ia5Atv.RawValue}, false, true},
+		{"Default, Broken data", nil, args{oidOrganization, brokenAtv.RawValue, pAtv.RawValue}, false, true},
+		{"Matching rule, Upper/Lower case characters", []Option{WithMatchingRule(oidOrganization, MatchingRuleCaseExact)}, args{oidOrganization, upperValue, utf8Atv.RawValue}, false, false},
+		{"Matching function, Different characters", []Option{WithMatchingFunc(oidOrganization, alwaysMatch)}, args{oidOrganization, pAtv.RawValue, pdAtv.RawValue}, true, false},
+		{"Ignored types, Different characters", []Option{WithIgnoredTypes(oidOrganization)}, args{oidOrganization, pAtv.RawValue, pdAtv.RawValue}, true, false},
+		{"Lenient domain component, Wrong encoding domain component", []Option{WithLenientDomainComponent()}, args{oidDomainComponent, wrongDcAtv.RawValue, wrongDcAtv.RawValue}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(tt.opts...).CompareAttributeValues(tt.args.oid, tt.args.x, tt.args.y)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompareAttributeValues() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("CompareAttributeValues() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if tt.opts != nil {
+				return
+			}
+			gotResult, err = CompareAttributeValues(tt.args.oid, tt.args.x, tt.args.y)
+			if (err != nil) != tt.wantErr || gotResult != tt.wantResult {
+				t.Errorf("package CompareAttributeValues() = %v, %v, want %v", gotResult, err, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestComparer_Compare_LazyDecoding(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Broken value after mismatched RDN", nil, args{issuer: dn6b, subject: dn96b}, false, false},
+		{"Broken value compared", nil, args{issuer: dn2b, subject: dn96b}, false, true},
+		{"Broken value of different type", nil, args{issuer: dn3b, subject: dn97b}, false, false},
+		{"Broken value before different type", nil, args{issuer: dn101b, subject: dn102b}, false, false},
+		{"Trace, Broken value before different type", []Option{WithTrace(func(TraceEvent) {})}, args{issuer: dn101b, subject: dn102b}, false, false},
+		{"Multi RDN, Different types", nil, args{issuer: dn1b, subject: dn103b}, false, false},
+		{"Constant time, Broken value after mismatched RDN", []Option{WithConstantTime()}, args{issuer: dn6b, subject: dn96b}, false, true},
+		{"Strict, Broken value after mismatched RDN", []Option{WithStrict()}, args{issuer: dn6b, subject: dn96b}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(tt.opts...).Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestComparer_Compare_PreparedOnce(t *testing.T) {
+	//C=JP,O=BAR+O=FOO,CN=ABC and C=JP,O=FOO+O=BAR,CN=ABC have 4 distinct values: JP, BAR, FOO and ABC.
+	//The attributes of the multi-valued RDN are compared 3 times, which would prepare 10 values.
+	p := countDefaultStringPreparer(t)
+	gotResult, err := NewComparer().Compare(dn1b, dn16b)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if !gotResult {
+		t.Errorf("Compare() gotResult = false, want true")
+	}
+	if p.calls != 4 {
+		t.Errorf("Prepare() called %d times, want 4", p.calls)
+	}
+
+	//a StringPreparer given by WithStringPreparer is called for every comparison
+	custom := &umlautPreparer{}
+	if _, err = NewComparer(WithStringPreparer(custom)).Compare(dn1b, dn16b); err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if custom.calls != 10 {
+		t.Errorf("Prepare() of WithStringPreparer called %d times, want 10", custom.calls)
+	}
+}
+
+func BenchmarkCompare_StructureMismatch(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := Compare(dn1b, dn103b); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package dn
+
+import (
+	"crypto/subtle"
+	"encoding/asn1"
+	"errors"
+)
+
+//WithConstantTime makes the Comparer compare distinguished names so that the time does not reveal which values match,
+//e.g. to compare the distinguished names presented by clients with privileged identities.
+//
+//Under this option:
+//  1. The values are compared by crypto/subtle.ConstantTimeCompare after the conversion which the matching rule requires,
+//     i.e. the string preparation, the case folding of domain components, the decoding
+//     of bit strings or none for binary comparison.
+//  2. Every RDN and every pair of attributes in each RDN are compared, even after a mismatch is found,
+//     so that the time does not reveal how many RDNs matched.
+//
+//The following are NOT constant time:
+//  1. Parsing, and the conversion of the values, whose time depends on the encodings and the lengths of the values.
+//  2. The structure of the distinguished names: the numbers of RDNs and attributes, and the attribute types.
+//     Distinguished names which differ in them are rejected without comparing the values.
+//  3. The lengths of the converted values. ConstantTimeCompare returns immediately for values of different lengths.
+//  4. Errors, which are returned as soon as they are found.
+//  5. CompareExplain, which reports the first mismatch.
+//  6. The distinguished names nested in the values compared by MatchingRuleDistinguishedName.
+//  7. The assignment of the attributes of multi-valued RDNs after all the pairs are compared, which takes longer
+//     if an attribute matches none of the attributes which are not assigned yet.
+func WithConstantTime() Option {
+	return func(c *Comparer) {
+		c.constantTime = true
+	}
+}
+
+//matchDistinguishedNameConstantTime reports whether xd and yd matches in the same way as matchDistinguishedName,
+//comparing all the RDNs regardless of the results.
+func matchDistinguishedNameConstantTime(xd []rdnSET, yd []rdnSET, match attributeMatcher) (result bool, err error) {
+	if len(xd) != len(yd) {
+		return false, nil
+	}
+	result = true
+	for i := range xd {
+		isMatched := false
+		if isMatched, err = compareRelativeDistinguishedNameConstantTime(xd[i], yd[i], match); err != nil {
+			return false, err
+		}
+		result = result && isMatched
+	}
+	return result, nil
+}
+
+//compareRelativeDistinguishedNameConstantTime reports whether xr and yr matches in the same way as
+//compareRelativeDistinguishedName, comparing all the pairs of attributes before assigning them.
+func compareRelativeDistinguishedNameConstantTime(xr rdnSET, yr rdnSET, match attributeMatcher) (result bool, err error) {
+	if len(xr) != len(yr) {
+		return false, nil
+	}
+	matches := make([][]bool, len(xr))
+	for i, x := range xr {
+		matches[i] = make([]bool, len(yr))
+		for j, y := range yr {
+			if matches[i][j], err = match(x, y); err != nil {
+				return false, err
+			}
+		}
+	}
+	//assign the attributes in the same way as compareRelativeDistinguishedName, without comparing them again
+	a := newAttributeAssignment(len(xr), len(yr), func(i int, j int) (bool, error) {
+		return matches[i][j], nil
+	})
+	result = true
+	for i := range xr {
+		found := false
+		if found, err = a.assign(i); err != nil {
+			return false, err
+		}
+		result = result && found
+	}
+	return result, nil
+}
+
+//compareAttributeConstantTime reports whether attribute x and attribute y, which have the same type, matches in the
+//same way as compareAttributeByRule, comparing the converted values in constant time. The string values are prepared
+//by p.
+func compareAttributeConstantTime(x Attribute, y Attribute, applied AppliedRule, rule MatchingRule, p StringPreparer) (result bool, err error) {
+	switch applied {
+	case AppliedRuleBitStringMatch:
+		return compareByBitStringMatchConstantTime(x, y)
+	case AppliedRuleEmailAddressMatch:
+		return compareByEmailMatchConstantTime(x, y, rule == MatchingRuleEmailAddressCaseIgnore, p)
+	case AppliedRuleBinaryComparison:
+		if isTypeSpecificRule(rule) && !isIA5StringPair(x, y) {
+			return subtle.ConstantTimeCompare(x.RawValue.FullBytes, y.RawValue.FullBytes) == 1, nil
+		}
+	}
+	var s, t string
+	if s, err = decodeAttributeValue(x); err != nil {
+		return false, err
+	}
+	if t, err = decodeAttributeValue(y); err != nil {
+		return false, err
+	}
+
+	var kx, ky []byte
+	switch applied {
+	case AppliedRuleCaseInsensitiveExactMatch:
+		if x.RawValue.Tag != asn1.TagIA5String || y.RawValue.Tag != asn1.TagIA5String {
+			return false, errors.New("dn: domain component should be IA5String")
+		}
+		kx, ky = lowerASCII(s), lowerASCII(t)
+	case AppliedRuleNumericStringMatch:
+		var u, v string
+		if u, err = prepareNumericString(p, s); err != nil {
+			return false, err
+		}
+		if v, err = prepareNumericString(p, t); err != nil {
+			return false, err
+		}
+		kx, ky = []byte(u), []byte(v)
+	case AppliedRuleCaseExactMatch, AppliedRuleCaseIgnoreMatch:
+		caseFold := applied == AppliedRuleCaseIgnoreMatch
+		var u, v string
+		if u, err = p.Prepare(s, caseFold); err != nil {
+			return false, err
+		}
+		if v, err = p.Prepare(t, caseFold); err != nil {
+			return false, err
+		}
+		kx, ky = []byte(u), []byte(v)
+	default:
+		if isIA5StringPair(x, y) {
+			kx, ky = []byte(s), []byte(t)
+			break
+		}
+		if len(x.RawValue.FullBytes) == 0 || len(y.RawValue.FullBytes) == 0 {
+			return false, nil
+		}
+		kx, ky = x.RawValue.FullBytes, y.RawValue.FullBytes
+	}
+	return subtle.ConstantTimeCompare(kx, ky) == 1, nil
+}
+
+//lowerASCII returns s whose ASCII upper case letters are converted to lower case, without branches on the characters.
+//It is the same as strings.ToLower for IA5String, whose characters are ASCII.
+func lowerASCII(s string) []byte {
+	b := []byte(s)
+	for i, c := range b {
+		//0x20 is added if c is between 'A' and 'Z'
+		isUpper := subtle.ConstantTimeLessOrEq(int('A'), int(c)) & subtle.ConstantTimeLessOrEq(int(c), int('Z'))
+		b[i] = c | byte(isUpper<<5)
+	}
+	return b
+}
@@ -0,0 +1,82 @@
+package dn
+
+import (
+	"testing"
+)
+
+func TestWithConstantTime(t *testing.T) {
+	//all the pairs of the fixtures give the same results as Compare
+	fixtures := map[string][]byte{
+		"dn1b": dn1b, "dn2b": dn2b, "dn3b": dn3b, "dn4b": dn4b, "dn5b": dn5b, "dn6b": dn6b, "dn7b": dn7b,
+		"dn8b": dn8b, "dn9b": dn9b, "dn10b": dn10b, "dn12b": dn12b, "dn13b": dn13b, "dn14b": dn14b,
+		"dn15b": dn15b, "dn16b": dn16b, "dn17b": dn17b, "dn18b": dn18b, "dn20b": dn20b, "dn23b": dn23b,
+		"dn24b": dn24b, "dn25b": dn25b, "dn26b": dn26b, "dn27b": dn27b, "dn31b": dn31b, "dn32b": dn32b,
+		"dn33b": dn33b, "dnUpperDCb": dnUpperDCb, "dnFullWidthb": dnFullWidthb, "brdnb": brdnb,
+		"dn70b": dn70b, "dn71b": dn71b, "dn72b": dn72b, "dn73b": dn73b,
+		"dn75b": dn75b, "dn76b": dn76b, "dn77b": dn77b, "dn78b": dn78b, "dn79b": dn79b,
+	}
+	for _, opts := range [][]Option{nil, {WithMatchingRule(oidCountryName, MatchingRuleCaseExact)}} {
+		for xn, x := range fixtures {
+			for yn, y := range fixtures {
+				want, wantErr := NewComparer(opts...).Compare(x, y)
+				got, err := NewComparer(append([]Option{WithConstantTime()}, opts...)...).Compare(x, y)
+				if got != want || (err != nil) != (wantErr != nil) {
+					t.Errorf("Compare(%s, %s) = %v, %v, want %v, %v", xn, yn, got, err, want, wantErr)
+				}
+			}
+		}
+	}
+}
+
+func Test_matchDistinguishedNameConstantTime(t *testing.T) {
+	tests := []struct {
+		name       string
+		x          []byte
+		y          []byte
+		wantResult bool
+	}{
+		{"Matched", dn14b, dn14b, true},
+		{"Different characters in first RDN", dn2b, dn6b, false},
+		{"Different Encoding in last RDN", dn2b, dn5b, false},
+		{"Multi RDN not in DER order", dn1b, dn16b, true},
+		{"Different number of RDNs", dn14b, dn2b, false},
+	}
+	var c Comparer
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, _ := parseDn(tt.x)
+			y, _ := parseDn(tt.y)
+			calls := 0
+			match := func(a Attribute, b Attribute) (bool, error) {
+				calls++
+				return c.compareAttributeByRules(a, b, true, 0)
+			}
+			gotResult, err := matchDistinguishedNameConstantTime(x, y, match)
+			if err != nil {
+				t.Fatalf("matchDistinguishedNameConstantTime() error = %v", err)
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("matchDistinguishedNameConstantTime() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			//every pair of attributes in each RDN is compared
+			wantCalls := 0
+			if len(x) == len(y) {
+				for i := range x {
+					if len(x[i]) == len(y[i]) {
+						wantCalls += len(x[i]) * len(y[i])
+					}
+				}
+			}
+			if calls != wantCalls {
+				t.Errorf("matchDistinguishedNameConstantTime() compared %d pairs, want %d", calls, wantCalls)
+			}
+		})
+	}
+}
+
+func Test_lowerASCII(t *testing.T) {
+	const s = "@AZ[`az{ 09-Example.COM"
+	if got, want := string(lowerASCII(s)), "@az[`az{ 09-example.com"; got != want {
+		t.Errorf("lowerASCII() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package dn
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+)
+
+//CompareCSR reports whether the subjects of the certificate requests a and b match, comparing a.RawSubject and
+//b.RawSubject by Compare, e.g. to detect duplicate enrollment requests for the same name which encode it differently.
+//
+//As the one exception to Compare, CompareCSR returns false if either of the subjects is an empty sequence, which names
+//no entity; certificate requests may leave the subject empty and name the entity only in the subjectAltName extension,
+//and such requests are not duplicates of each other. Compare reports that two empty sequences match.
+//It returns an error if a or b is nil or the subjects cannot be parsed, e.g. the request is not parsed by
+//x509.ParseCertificateRequest.
+func CompareCSR(a *x509.CertificateRequest, b *x509.CertificateRequest) (result bool, err error) {
+	for _, csr := range []*x509.CertificateRequest{a, b} {
+		if csr == nil {
+			return false, errors.New("dn: certificate request is nil")
+		}
+		if len(csr.RawSubject) == 0 {
+			return false, errors.New("dn: certificate request has no raw subject")
+		}
+	}
+	if isEmptyName(a.RawSubject) || isEmptyName(b.RawSubject) {
+		return false, nil
+	}
+	return Compare(a.RawSubject, b.RawSubject)
+}
+
+//isEmptyName reports whether rawName is the DER encoding of an empty sequence of RDNs.
+func isEmptyName(rawName []byte) bool {
+	return bytes.Equal(rawName, []byte{0x30, 0x00})
+}
+
+//CSRSubjectDN parses the subject of the certificate request csr.
+//It returns an error if csr is nil or the subject cannot be parsed. The returned DN refers to csr.RawSubject in the
+//same way as ParseDN.
+func CSRSubjectDN(csr *x509.CertificateRequest) (*DN, error) {
+	if csr == nil {
+		return nil, errors.New("dn: certificate request is nil")
+	}
+	return ParseDN(csr.RawSubject)
+}
+
+//CSR wraps a certificate request to parse its subject by the method SubjectDN, which cannot be declared on
+//x509.CertificateRequest of another package, e.g.
+//  d, err := dn.CSR{CertificateRequest: csr}.SubjectDN()
+type CSR struct {
+	*x509.CertificateRequest
+}
+
+//SubjectDN parses the subject of csr in the same way as CSRSubjectDN.
+func (csr CSR) SubjectDN() (*DN, error) {
+	return CSRSubjectDN(csr.CertificateRequest)
+}
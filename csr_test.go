@@ -0,0 +1,92 @@
+package dn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+)
+
+//newTestCertificateRequest returns a certificate request whose subject is rawSubject.
+func newTestCertificateRequest(t *testing.T, rawSubject []byte) *x509.CertificateRequest {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{RawSubject: rawSubject}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.ParseCertificateRequest(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return csr
+}
+
+func TestCompareCSR(t *testing.T) {
+	emptyName := []byte{0x30, 0x00}
+	csr2 := newTestCertificateRequest(t, dn2b)
+	tests := []struct {
+		name       string
+		a          *x509.CertificateRequest
+		b          *x509.CertificateRequest
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Same request", csr2, csr2, true, false},
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", csr2, newTestCertificateRequest(t, dn3b), true, false},
+		{"Upper/Lower case characters", csr2, newTestCertificateRequest(t, dn4b), true, false},
+		{"Multi RDN not in DER order", newTestCertificateRequest(t, dn1b), newTestCertificateRequest(t, dn16b), true, false},
+		{"Different characters", csr2, newTestCertificateRequest(t, dn6b), false, false},
+		{"Empty subject", csr2, newTestCertificateRequest(t, emptyName), false, false},
+		{"Empty subjects", newTestCertificateRequest(t, emptyName), newTestCertificateRequest(t, emptyName), false, false},
+		{"Not parsed", csr2, &x509.CertificateRequest{}, false, true},
+		{"Nil a", nil, csr2, false, true},
+		{"Nil b", csr2, nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := CompareCSR(tt.a, tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompareCSR() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("CompareCSR() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+	//the empty subjects are the exception to Compare, which reports that they match
+	if result, err := Compare(emptyName, emptyName); err != nil || !result {
+		t.Errorf("Compare() of empty names = %v, %v, want true, nil", result, err)
+	}
+}
+
+func TestCSRSubjectDN(t *testing.T) {
+	d, err := CSRSubjectDN(newTestCertificateRequest(t, dn1b))
+	if err != nil {
+		t.Fatalf("CSRSubjectDN() error = %v", err)
+	}
+	if got, want := d.String(), "CN=ABC,O=BAR+O=FOO,C=JP"; got != want {
+		t.Errorf("CSRSubjectDN() = %v, want %v", got, want)
+	}
+	if _, err := CSRSubjectDN(nil); err == nil {
+		t.Errorf("CSRSubjectDN() of nil error = nil, want error")
+	}
+}
+
+func TestCSR_SubjectDN(t *testing.T) {
+	d, err := CSR{CertificateRequest: newTestCertificateRequest(t, dn1b)}.SubjectDN()
+	if err != nil {
+		t.Fatalf("SubjectDN() error = %v", err)
+	}
+	if got, want := d.String(), "CN=ABC,O=BAR+O=FOO,C=JP"; got != want {
+		t.Errorf("SubjectDN() = %v, want %v", got, want)
+	}
+	if _, err := (CSR{}).SubjectDN(); err == nil {
+		t.Errorf("SubjectDN() of nil error = nil, want error")
+	}
+}
@@ -0,0 +1,229 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"strings"
+)
+
+//DiffAttribute is an attribute in DNDiff.
+type DiffAttribute struct {
+	Type  asn1.ObjectIdentifier
+	Value string //decoded value, or the number sign followed by the hexadecimal encoding if it is not a string
+}
+
+//DiffRDN is an RDN which is present only in one of the distinguished names.
+type DiffRDN struct {
+	Index      int //index of the RDN in the RDNSequence
+	Attributes []DiffAttribute
+}
+
+//AttributeChange is a pair of attributes of the same type whose values do not match.
+type AttributeChange struct {
+	Type asn1.ObjectIdentifier
+	A    string //value of a, decoded in the same way as DiffAttribute
+	B    string //value of b, decoded in the same way as DiffAttribute
+}
+
+//RDNChange is a pair of RDNs which correspond positionally but do not match.
+type RDNChange struct {
+	A         int             //index of the RDN in the RDNSequence of a
+	B         int             //index of the RDN in the RDNSequence of b
+	Unchanged []DiffAttribute //attributes of a which match attributes of b
+	Changed   []AttributeChange
+	Removed   []DiffAttribute //attributes of a which have no attributes of the same type left in b
+	Added     []DiffAttribute //attributes of b which have no attributes of the same type left in a
+}
+
+//DNDiff is the report of Diff.
+type DNDiff struct {
+	Removed []DiffRDN //RDNs present only in a
+	Added   []DiffRDN //RDNs present only in b
+	Changed []RDNChange
+	lines   []string //lines of String
+}
+
+//IsEmpty reports whether a and b of d match.
+func (d *DNDiff) IsEmpty() bool {
+	return len(d.Removed) == 0 && len(d.Added) == 0 && len(d.Changed) == 0
+}
+
+//String returns d in a format similar to unified diff, one line for each RDN in the order of the RDNSequence,
+//i.e. from the root. The lines of the RDNs which match start with a space, the lines of the RDNs of a which are
+//removed or changed start with '-', and those of b start with '+'. The RDNs are in the string representation
+//described in RFC 4514, e.g.
+//
+//	--- a
+//	+++ b
+//	 C=JP
+//	-O=FOO
+//	+O=BAR
+//	 CN=ABC
+func (d *DNDiff) String() string {
+	var sb strings.Builder
+	sb.WriteString("--- a\n+++ b\n")
+	for _, line := range d.lines {
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+//Diff returns the structural difference between a and b, both of which are encoded as Distinguished Name.
+//The RDNs are compared by the same rules as Compare, so that the RDNs which differ only in their encodings, e.g.
+//"ABC" in PrintableString and "abc" in UTF8String, are not reported.
+//
+//The longest sequence of the RDNs which match in the same order is found first. Between them, the RDNs of a and b are
+//paired from the beginning as RDNChange, and the rest are reported as removed or added.
+//Diff returns an error if an RDN cannot be compared, e.g. for a prohibited character.
+func Diff(a []byte, b []byte) (diff *DNDiff, err error) {
+	var x, y dn
+	if x, err = parseDn(a); err != nil {
+		return nil, err
+	}
+	if y, err = parseDn(b); err != nil {
+		return nil, err
+	}
+	var c Comparer
+	//matched[i][j] reports whether x[i] matches y[j]
+	matched := make([][]bool, len(x))
+	for i := range x {
+		matched[i] = make([]bool, len(y))
+		for j := range y {
+			if matched[i][j], err = compareRelativeDistinguishedName(x[i], y[j], c.compareAttribute); err != nil {
+				return nil, err
+			}
+		}
+	}
+	//lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case matched[i][j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	diff = &DNDiff{}
+	var removed, added []int
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && matched[i][j]:
+			if err = diff.flush(x, y, removed, added, c.compareAttribute); err != nil {
+				return nil, err
+			}
+			removed, added = nil, nil
+			diff.lines = append(diff.lines, " "+formatRDN(x[i]))
+			i++
+			j++
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, i)
+			i++
+		default:
+			added = append(added, j)
+			j++
+		}
+	}
+	if err = diff.flush(x, y, removed, added, c.compareAttribute); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+//flush records the RDNs of x at removed and the RDNs of y at added, which are between the matched RDNs.
+func (d *DNDiff) flush(x dn, y dn, removed []int, added []int, match attributeMatcher) (err error) {
+	for k, i := range removed {
+		if k < len(added) {
+			var change RDNChange
+			if change, err = diffRDN(i, added[k], x[i], y[added[k]], match); err != nil {
+				return err
+			}
+			d.Changed = append(d.Changed, change)
+		} else {
+			d.Removed = append(d.Removed, DiffRDN{Index: i, Attributes: diffAttributes(x[i])})
+		}
+		d.lines = append(d.lines, "-"+formatRDN(x[i]))
+	}
+	for k, j := range added {
+		if k >= len(removed) {
+			d.Added = append(d.Added, DiffRDN{Index: j, Attributes: diffAttributes(y[j])})
+		}
+		d.lines = append(d.lines, "+"+formatRDN(y[j]))
+	}
+	return nil
+}
+
+//diffRDN returns the difference between xr at index a and yr at index b. The attributes which match are assigned in
+//the same way as compareRelativeDistinguishedName, and the rest of the attributes of the same type are paired in order.
+func diffRDN(a int, b int, xr rdnSET, yr rdnSET, match attributeMatcher) (change RDNChange, err error) {
+	change = RDNChange{A: a, B: b}
+	assignment := newAttributeAssignment(len(xr), len(yr), func(i int, j int) (bool, error) {
+		return match(xr[i], yr[j])
+	})
+	for i := range xr {
+		if _, err = assignment.assign(i); err != nil {
+			return RDNChange{}, err
+		}
+	}
+	paired := make([]bool, len(yr))
+	for i, x := range xr {
+		if j := assignment.assigned[i]; j != -1 {
+			paired[j] = true
+			change.Unchanged = append(change.Unchanged, diffAttribute(x))
+		}
+	}
+	for i, x := range xr {
+		if assignment.assigned[i] != -1 {
+			continue
+		}
+		isFound := false
+		for j, y := range yr {
+			if !paired[j] && oidEqual(x.Oid, y.Oid) {
+				paired[j], isFound = true, true
+				change.Changed = append(change.Changed, AttributeChange{Type: cloneOid(x.Oid), A: diffValue(x), B: diffValue(y)})
+				break
+			}
+		}
+		if !isFound {
+			change.Removed = append(change.Removed, diffAttribute(x))
+		}
+	}
+	for j, y := range yr {
+		if !paired[j] {
+			change.Added = append(change.Added, diffAttribute(y))
+		}
+	}
+	return change, nil
+}
+
+//diffAttributes returns the attributes of r for DNDiff.
+func diffAttributes(r rdnSET) []DiffAttribute {
+	result := make([]DiffAttribute, len(r))
+	for i, atv := range r {
+		result[i] = diffAttribute(atv)
+	}
+	return result
+}
+
+//diffAttribute returns atv for DNDiff.
+func diffAttribute(atv Attribute) DiffAttribute {
+	return DiffAttribute{Type: cloneOid(atv.Oid), Value: diffValue(atv)}
+}
+
+//diffValue returns the decoded value of atv, or the number sign followed by the hexadecimal encoding of the value if
+//it cannot be decoded as a string.
+func diffValue(atv Attribute) string {
+	if s, err := toString(atv.RawValue.FullBytes); err == nil {
+		return s
+	}
+	return "#" + hex.EncodeToString(atv.RawValue.FullBytes)
+}
@@ -0,0 +1,81 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oidC := asn1.ObjectIdentifier{2, 5, 4, 6}
+	oidO := asn1.ObjectIdentifier{2, 5, 4, 10}
+	oidOU := asn1.ObjectIdentifier{2, 5, 4, 11}
+	example := mustMarshalString(t, "CN=abc,O=Example,C=JP")
+	other := mustMarshalString(t, "CN=abc,O=Other,C=JP")
+	tests := []struct {
+		name        string
+		a           []byte
+		b           []byte
+		wantRemoved []DiffRDN
+		wantAdded   []DiffRDN
+		wantChanged []RDNChange
+		wantString  string
+	}{
+		{"Same", dn1b, dn1b, nil, nil, nil, "--- a\n+++ b\n C=JP\n O=BAR+O=FOO\n CN=ABC\n"},
+		{"Reordered multi-valued RDN", dn1b, dn16b, nil, nil, nil, "--- a\n+++ b\n C=JP\n O=BAR+O=FOO\n CN=ABC\n"},
+		{"Different encodings", dn2b, dn3b, nil, nil, nil, "--- a\n+++ b\n C=JP\n CN=ABC\n"},
+		{"Removed", dn1b, dn3b,
+			[]DiffRDN{{Index: 1, Attributes: []DiffAttribute{{oidO, "BAR"}, {oidO, "FOO"}}}}, nil, nil,
+			"--- a\n+++ b\n C=JP\n-O=BAR+O=FOO\n CN=ABC\n"},
+		{"Added", dn3b, dn1b,
+			nil, []DiffRDN{{Index: 1, Attributes: []DiffAttribute{{oidO, "BAR"}, {oidO, "FOO"}}}}, nil,
+			"--- a\n+++ b\n C=JP\n+O=BAR+O=FOO\n CN=ABC\n"},
+		{"Removed last", dn101b, dn3b,
+			[]DiffRDN{{Index: 2, Attributes: []DiffAttribute{{oidO, "ABC"}}}}, nil, nil,
+			"--- a\n+++ b\n C=JP\n CN=ABC\n-O=ABC\n"},
+		{"Changed value", example, other, nil, nil,
+			[]RDNChange{{A: 1, B: 1, Changed: []AttributeChange{{oidO, "Example", "Other"}}}},
+			"--- a\n+++ b\n C=JP\n-O=Example\n+O=Other\n CN=abc\n"},
+		{"Changed type in multi-valued RDN", dn103b, dn1b, nil, nil,
+			[]RDNChange{{A: 1, B: 1, Unchanged: []DiffAttribute{{oidO, "BAR"}}, Removed: []DiffAttribute{{oidOU, "FOO"}}, Added: []DiffAttribute{{oidO, "FOO"}}}},
+			"--- a\n+++ b\n C=JP\n-O=BAR+OU=FOO\n+O=BAR+O=FOO\n CN=ABC\n"},
+		{"All changed", dn3b, dn6b, nil, nil,
+			[]RDNChange{
+				{A: 0, B: 0, Changed: []AttributeChange{{oidC, "JP", "US"}}},
+				{A: 1, B: 1, Changed: []AttributeChange{{asn1.ObjectIdentifier{2, 5, 4, 3}, "ABC", "DEF"}}},
+			},
+			"--- a\n+++ b\n-C=JP\n-CN=ABC\n+C=US\n+CN=DEF\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Removed, tt.wantRemoved) {
+				t.Errorf("Diff() Removed = %v, want %v", got.Removed, tt.wantRemoved)
+			}
+			if !reflect.DeepEqual(got.Added, tt.wantAdded) {
+				t.Errorf("Diff() Added = %v, want %v", got.Added, tt.wantAdded)
+			}
+			if !reflect.DeepEqual(got.Changed, tt.wantChanged) {
+				t.Errorf("Diff() Changed = %+v, want %+v", got.Changed, tt.wantChanged)
+			}
+			if got.IsEmpty() != (tt.wantRemoved == nil && tt.wantAdded == nil && tt.wantChanged == nil) {
+				t.Errorf("IsEmpty() = %v", got.IsEmpty())
+			}
+			if s := got.String(); s != tt.wantString {
+				t.Errorf("String() = %q, want %q", s, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestDiff_Error(t *testing.T) {
+	if _, err := Diff(brdnb, dn1b); err == nil {
+		t.Errorf("Diff() of broken a error = nil, want error")
+	}
+	if _, err := Diff(dn1b, brdnb); err == nil {
+		t.Errorf("Diff() of broken b error = nil, want error")
+	}
+}
@@ -9,7 +9,8 @@ Comparison algorithm is following:
 3) Check two naming attributes are the same types and the values of the attributes are matched. The rules for value of the attribute matching are:
   3-1. If two naming attributes are domain component, then they are compared by case-insensitive exact match( RFC5280-section7.2, 7.3).
   3-2. If both two naming attributes of values are encoded in UTF8String or PrintableString, then they are compared by caseIgnoreMatch( RFC4517section-4.2.11) after processing with the string preparation algorithm( RFC4518, RFC5280-section7.1).
-  3-3. If any other cases, then two naming attributes of values are compared by binary comparison( RFC5280-section7.1).
+  3-3. If two naming attributes are unstructuredName encoded in IA5String, then they are compared by caseExactMatch( RFC4517section-4.2.4) after processing with the string preparation algorithm( RFC2985-section5.4.1).
+  3-4. If any other cases, then two naming attributes of values are compared by binary comparison( RFC5280-section7.1).
 */
 package dn
 
@@ -17,67 +18,90 @@ import (
 	"bytes"
 	"encoding/asn1"
 	"errors"
+	"fmt"
 	"github.com/tardevnull/ldapstrprep"
+	"golang.org/x/text/unicode/norm"
 	"strings"
+	"unicode/utf8"
 )
 
 //https://tools.ietf.org/html/rfc5280#appendix-A.1
 //Oid-domainComponent   AttributeType ::= { 0 9 2342 19200300 100 1 25 }
 var oidDomainComponent = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
 
+//https://tools.ietf.org/html/rfc2985#appendix-A
+//pkcs-9-at-unstructuredName OBJECT IDENTIFIER ::= { pkcs-9 2 }
+var oidUnstructuredName = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 2}
+
+//https://cabforum.org/guidelines/ EV Guidelines section 9.2.4
+//jurisdictionCountryName: 1.3.6.1.4.1.311.60.2.1.3 (ASN.1 - PrintableString (SIZE(2)))
+var oidJurisdictionCountryName = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 60, 2, 1, 3}
+
+//https://tools.ietf.org/html/rfc5280#appendix-A.1
+//id-at-serialNumber      AttributeType ::= { id-at 5 }
+var oidSerialNumber = asn1.ObjectIdentifier{2, 5, 4, 5}
+
+//https://tools.ietf.org/html/rfc5280#appendix-A.1
+//id-at-countryName       AttributeType ::= { id-at 6 }
+var oidCountryName = asn1.ObjectIdentifier{2, 5, 4, 6}
+
 type dn []rdnSET
 
-type rdnSET []attribute
+type rdnSET []Attribute
 
-type attribute struct {
+//Attribute is AttributeTypeAndValue of RFC 5280. RawValue holds the encoded value of the attribute.
+type Attribute struct {
 	Oid      asn1.ObjectIdentifier
 	RawValue asn1.RawValue
 }
 
 //Compare reports whether issuer and subject matches.
 func Compare(issuer []byte, subject []byte) (result bool, err error) {
-	var s []rdnSET
-	var i []rdnSET
-
-	if len(issuer) == 0 {
-		//https://tools.ietf.org/html/rfc5280#section-4.1.2.4
-		//The issuer field MUST contain a non-empty distinguished name (DN)
-		return false, errors.New("dn: the issuer field must contain a non-empty distinguished name")
-	}
-
-	if len(subject) == 0 {
-		//issuer is not blank, but subject is blank
-		return false, nil
-	}
-
-	if i, err = parseDn(issuer); err != nil {
-		return false, err
-	}
-	if s, err = parseDn(subject); err != nil {
-		return false, err
-	}
-	return compareDistinguishedName(i, s)
+	return NewComparer().Compare(issuer, subject)
 }
 
 //parseDn decodes dnBytes, which is encoded as Distinguished Name, to dn.
 func parseDn(dnBytes []byte) (dn dn, err error) {
-	if rest, err := asn1.Unmarshal(dnBytes, &dn); err != nil {
+	var n int
+	if dn, n, err = parseDnPrefix(dnBytes); err != nil {
 		return nil, err
-	} else if len(rest) != 0 {
+	} else if n != len(dnBytes) {
 		return nil, errors.New("dn: failed to parse distinguished name")
 	}
-	return dn, err
+	return dn, nil
 }
 
+//parseDnPrefix decodes the Distinguished Name at the beginning of dnBytes to dn.
+//n is the number of bytes of the Distinguished Name, i.e. the length of FullBytes of the outer SEQUENCE.
+func parseDnPrefix(dnBytes []byte) (dn dn, n int, err error) {
+	var rest []byte
+	if rest, err = asn1.Unmarshal(dnBytes, &dn); err != nil {
+		return nil, 0, err
+	}
+	internOids(dn)
+	return dn, len(dnBytes) - len(rest), nil
+}
+
+//attributeMatcher reports whether attribute x and attribute y matches.
+//Attributes of different types must never match, because matchDistinguishedName decides them by sameStructure.
+type attributeMatcher func(x Attribute, y Attribute) (result bool, err error)
+
 //compareDistinguishedName reports whether xd and yd matches.
 func compareDistinguishedName(xd []rdnSET, yd []rdnSET) (result bool, err error) {
-	if len(xd) != len(yd) {
+	return matchDistinguishedName(xd, yd, compareAttribute)
+}
+
+//matchDistinguishedName reports whether xd and yd matches, comparing attributes by match.
+//The structures of xd and yd are compared first, so that the distinguished names which differ in the numbers of RDNs
+//or attributes, or in the attribute types, are decided without decoding any value.
+func matchDistinguishedName(xd []rdnSET, yd []rdnSET, match attributeMatcher) (result bool, err error) {
+	if !sameStructure(xd, yd) {
 		return false, nil
 	}
 
 	for i := 0; i < len(xd); i++ {
 		isMatched := false
-		if isMatched, err = compareRelativeDistinguishedName(xd[i], yd[i]); err != nil {
+		if isMatched, err = compareRelativeDistinguishedName(xd[i], yd[i], match); err != nil {
 			return false, err
 		}
 		if isMatched == false {
@@ -88,16 +112,18 @@ func compareDistinguishedName(xd []rdnSET, yd []rdnSET) (result bool, err error)
 
 }
 
-//compareRelativeDistinguishedName reports whether xr and yr matches.
-func compareRelativeDistinguishedName(xr rdnSET, yr rdnSET) (result bool, err error) {
+//compareRelativeDistinguishedName reports whether xr and yr matches, comparing attributes by match.
+//xr and yr match if each attribute of xr is assigned to a different attribute of yr which it matches.
+func compareRelativeDistinguishedName(xr rdnSET, yr rdnSET, match attributeMatcher) (result bool, err error) {
 	if len(xr) != len(yr) {
 		return false, nil
 	}
-
-	rest := yr
+	a := newAttributeAssignment(len(xr), len(yr), func(i int, j int) (bool, error) {
+		return match(xr[i], yr[j])
+	})
 	for i := 0; i < len(xr); i++ {
 		isFound := false
-		if isFound, rest, err = findMatchedAttribute(xr[i], rest); err != nil {
+		if isFound, err = a.assign(i); err != nil {
 			return false, err
 		}
 		if isFound == false {
@@ -107,12 +133,101 @@ func compareRelativeDistinguishedName(xr rdnSET, yr rdnSET) (result bool, err er
 	return true, nil
 }
 
+//attributeAssignment assigns the attributes of an RDN to the matching attributes of another RDN,
+//so that as many attributes as possible are assigned, i.e. a maximum matching of the bipartite graph whose edges are
+//the pairs of matching attributes.
+//
+//The matching rules of Compare are equivalence relations for the attributes of the same type, for which assigning
+//each attribute to the first matching attribute which is not assigned yet is enough. The assignment tries it first,
+//and compares the attributes in the same order as before. If it fails, the assignment looks for an augmenting path,
+//reassigning the attributes assigned before, so that RDNs also match by rules which are not transitive, e.g. rules
+//which mix matching rules in the same RDN.
+type attributeAssignment struct {
+	match    func(i int, j int) (bool, error) //reports whether the i-th attribute matches the j-th attribute
+	results  [][]int8                         //memo of match, 0 if not compared, 1 if matched and -1 if not
+	assigned []int                            //index of the attribute assigned to the i-th attribute, or -1
+	owner    []int                            //index of the attribute which the j-th attribute is assigned to, or -1
+}
+
+//newAttributeAssignment returns the assignment of m attributes to n attributes which match by match.
+func newAttributeAssignment(m int, n int, match func(i int, j int) (bool, error)) *attributeAssignment {
+	a := &attributeAssignment{match: match, results: make([][]int8, m), assigned: make([]int, m), owner: make([]int, n)}
+	for i := range a.results {
+		a.results[i] = make([]int8, n)
+		a.assigned[i] = -1
+	}
+	for j := range a.owner {
+		a.owner[j] = -1
+	}
+	return a
+}
+
+//matches reports whether the i-th attribute matches the j-th attribute, comparing them at most once.
+func (a *attributeAssignment) matches(i int, j int) (result bool, err error) {
+	if a.results[i][j] == 0 {
+		if result, err = a.match(i, j); err != nil {
+			return false, err
+		}
+		a.results[i][j] = -1
+		if result {
+			a.results[i][j] = 1
+		}
+	}
+	return a.results[i][j] == 1, nil
+}
+
+//assign assigns the i-th attribute to a matching attribute, and reports whether it is assigned.
+//The attributes assigned before may be reassigned, but remain assigned.
+func (a *attributeAssignment) assign(i int) (result bool, err error) {
+	for j, o := range a.owner {
+		if o != -1 {
+			continue
+		}
+		if result, err = a.matches(i, j); err != nil {
+			return false, err
+		}
+		if result {
+			a.assigned[i], a.owner[j] = j, i
+			return true, nil
+		}
+	}
+	return a.augment(i, make([]bool, len(a.owner)))
+}
+
+//augment looks for an augmenting path from the i-th attribute, which does not pass through the visited attributes,
+//and assigns the attributes along the path if found.
+func (a *attributeAssignment) augment(i int, visited []bool) (result bool, err error) {
+	for j := range a.owner {
+		if visited[j] {
+			continue
+		}
+		if result, err = a.matches(i, j); err != nil {
+			return false, err
+		}
+		if !result {
+			continue
+		}
+		visited[j] = true
+		if a.owner[j] != -1 {
+			if result, err = a.augment(a.owner[j], visited); err != nil {
+				return false, err
+			}
+		}
+		if result {
+			a.assigned[i], a.owner[j] = j, i
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 //findMatchedAttribute finds RDN r contains attribute atv and if r contains atv, then return true and RDN which removed atv from r.
-func findMatchedAttribute(atv attribute, r rdnSET) (result bool, rest rdnSET, err error) {
+//Attributes are compared by match.
+func findMatchedAttribute(atv Attribute, r rdnSET, match attributeMatcher) (result bool, rest rdnSET, err error) {
 	isFound := false
 	rest = r
 	for i := 0; i < len(r); i++ {
-		if isFound, err = compareAttribute(atv, rest[i]); err != nil {
+		if isFound, err = match(atv, rest[i]); err != nil {
 			return false, nil, err
 		}
 		if isFound {
@@ -139,42 +254,77 @@ func removeAttribute(index int, r rdnSET) (result rdnSET, err error) {
 //compareAttribute reports whether attribute x and attribute y matches.
 //1. If both attributes are domain component, then they are compared by case-insensitive exact match.
 //2. If both of attributes of values are encoded in UTF8String or PrintableString, then they are compared by caseIgnoreMatch(RFC4517) after processing with the string preparation algorithm(RFC4518).
-//3. If any other cases, then attributes of values are compared by binary comparison.
-func compareAttribute(x attribute, y attribute) (result bool, err error) {
-	if !x.Oid.Equal(y.Oid) {
-		return false, nil
+//3. If both attributes are unstructuredName encoded in IA5String, then they are compared by caseExactMatch(RFC4517) after processing with the string preparation algorithm(RFC4518).
+//4. If any other cases, then attributes of values are compared by binary comparison. The values both encoded in
+//   IA5String are compared by their contents exactly, so that the constructed form of BER matches the primitive form.
+//5. If both attributes are member, owner, roleOccupant or seeAlso, then their values are distinguished names,
+//   which are compared recursively by distinguishedNameMatch(RFC4517).
+//6. If both attributes are x500UniqueIdentifier, then their values are BIT STRING, which are compared by bitStringMatch(RFC4517).
+//   The values not encoded in BIT STRING are compared by binary comparison.
+//The other values must be ASN.1 strings. Values of other types, e.g. streetAddress encoded as SEQUENCE OF DirectoryString
+//like postalAddress, result in an error. Multi-line values of a single string match the values whose line breaks are
+//replaced with spaces, because the string preparation maps line breaks to spaces.
+func compareAttribute(x Attribute, y Attribute) (result bool, err error) {
+	//the zero Comparer dispatches the rules in the same way as Compare
+	var c Comparer
+	return c.compareAttribute(x, y)
+}
+
+//compareAttributeByRule reports whether attribute x and attribute y, which have the same type, matches by applied,
+//which appliedRule resolves from rule. The string values are prepared by p. The distinguished names and the values
+//compared by MatchingFunc are compared by Comparer.compareAttributeByRules before.
+func compareAttributeByRule(x Attribute, y Attribute, applied AppliedRule, rule MatchingRule, p StringPreparer) (result bool, err error) {
+	switch applied {
+	case AppliedRuleBitStringMatch:
+		return compareByBitStringMatch(x, y)
+	case AppliedRuleEmailAddressMatch:
+		return compareByEmailMatch(x, y, rule == MatchingRuleEmailAddressCaseIgnore, p)
+	case AppliedRuleBinaryComparison:
+		return compareByBinaryValues(x, y, isTypeSpecificRule(rule))
 	}
 
 	var s string
-	if s, err = toString(x.RawValue.FullBytes); err != nil {
+	if s, err = decodeAttributeValue(x); err != nil {
 		return false, err
 	}
 	var t string
-	if t, err = toString(y.RawValue.FullBytes); err != nil {
+	if t, err = decodeAttributeValue(y); err != nil {
 		return false, err
 	}
-
-	//https://tools.ietf.org/html/rfc5280#section-4.1.2.4
-	//In addition, implementations of this specification MUST be prepared
-	//to receive the domainComponent attribute, as defined in [RFC4519].
-	//
-	//https://tools.ietf.org/html/rfc5280#section-7.3
-	//Conforming implementations shall perform a case-insensitive exact
-	//match when comparing domainComponent attributes in distinguished
-	//names, as described in Section 7.2.
-	//
-	//https://tools.ietf.org/html/rfc5280#section-7.2
-	//When comparing DNS names for equality, conforming implementations
-	//MUST perform a case-insensitive exact match on the entire DNS name.
-	if x.Oid.Equal(oidDomainComponent) && y.Oid.Equal(oidDomainComponent) {
+	switch applied {
+	case AppliedRuleNumericStringMatch:
+		return compareByNumericStringMatch(p, s, t)
+	case AppliedRuleCaseInsensitiveExactMatch:
+		//https://tools.ietf.org/html/rfc5280#section-4.1.2.4
+		//In addition, implementations of this specification MUST be prepared
+		//to receive the domainComponent attribute, as defined in [RFC4519].
+		//
+		//https://tools.ietf.org/html/rfc5280#section-7.3
+		//Conforming implementations shall perform a case-insensitive exact
+		//match when comparing domainComponent attributes in distinguished
+		//names, as described in Section 7.2.
+		//
+		//https://tools.ietf.org/html/rfc5280#section-7.2
+		//When comparing DNS names for equality, conforming implementations
+		//MUST perform a case-insensitive exact match on the entire DNS name.
+		//
 		//https://tools.ietf.org/html/rfc5280#appendix-A
 		//DomainComponent ::=  IA5String
 		if x.RawValue.Tag != asn1.TagIA5String || y.RawValue.Tag != asn1.TagIA5String {
 			return false, errors.New("dn: domain component should be IA5String")
 		}
 		return compareByCaseInsensitiveExactMatch(s, t), nil
+	case AppliedRuleCaseExactMatch:
+		//https://tools.ietf.org/html/rfc2985#section-5.4.1
+		//PKCS9String ::= CHOICE {
+		//  ia5String IA5String (SIZE(1..pkcs-9-ub-pkcs9String)),
+		//  directoryString DirectoryString {pkcs-9-ub-pkcs9String}
+		//}
+		//The IA5String form of unstructuredName is compared by case exact match after the string preparation.
+		//The values of the IA5String form and the DirectoryString form are compared by binary comparison,
+		//because they never match consistently with the case-insensitive DirectoryString form.
+		return compareByCaseExactMatch(p, s, t)
 	}
-
 	//https://tools.ietf.org/html/rfc5280#section-7.1
 	//Conforming implementations MUST
 	//support UTF8String and PrintableString.
@@ -184,10 +334,13 @@ func compareAttribute(x attribute, y attribute) (result bool, err error) {
 	//specification MAY use the comparison rules in Section 7.1 to process
 	//unfamiliar attribute types (i.e., for name chaining) whose attribute
 	//values use one of the encoding options from DirectoryString.
-	if isComparableDirectoryString(x.RawValue.Tag, y.RawValue.Tag) {
-		return compareByCaseIgnoreMatch(s, t) //check definition -<undefined case
-	}
+	return compareByCaseIgnoreMatch(p, s, t)
+}
 
+//compareByBinaryValues compares x and y by binary comparison, except that the values both encoded in IA5String are
+//compared by their contents exactly, so that the constructed form of BER matches the primitive form, e.g. the values of
+//challengePassword which must be compared exactly. Unless anyValue, the values must be ASN.1 strings.
+func compareByBinaryValues(x Attribute, y Attribute, anyValue bool) (result bool, err error) {
 	//https://tools.ietf.org/html/rfc5280#section-4.1.2.6
 	//Binary comparison should be used when unfamiliar attribute types include
 	//attribute values with encoding options other than those found in
@@ -201,12 +354,33 @@ func compareAttribute(x attribute, y attribute) (result bool, err error) {
 	//to case, character set, multi-character white space substring, or
 	//leading and trailing white space.  This specification relaxes these
 	//requirements, requiring support for binary comparison at a minimum.
+	if anyValue && !isIA5StringPair(x, y) {
+		return compareByBinaryComparison(x.RawValue.FullBytes, y.RawValue.FullBytes), nil
+	}
+	var s, t string
+	if s, err = decodeAttributeValue(x); err != nil {
+		return false, err
+	}
+	if t, err = decodeAttributeValue(y); err != nil {
+		return false, err
+	}
+	if isIA5StringPair(x, y) {
+		return s == t, nil
+	}
 	return compareByBinaryComparison(x.RawValue.FullBytes, y.RawValue.FullBytes), nil
 }
 
+//isIA5StringPair reports whether the values of x and y are both encoded in IA5String.
+func isIA5StringPair(x Attribute, y Attribute) bool {
+	return x.RawValue.Class == asn1.ClassUniversal && x.RawValue.Tag == asn1.TagIA5String &&
+		y.RawValue.Class == asn1.ClassUniversal && y.RawValue.Tag == asn1.TagIA5String
+}
+
 //isComparableDirectoryString reports whether tx and ty is comparable by Case Ignore Match.
 //If tx and ty are UTF8String tag or PrintableString tag ,then returns true.
 //Any other cases, returns false.
+//TeletexString, VisibleString and BMPString values take part as UTF8String, because WithTeletexString,
+//WithVisibleString and WithBMPString convert them before the comparison.
 func isComparableDirectoryString(tx int, ty int) bool {
 	//https://tools.ietf.org/html/rfc5280#section-7.1
 	//Implementations may encounter certificates and CRLs with
@@ -244,26 +418,62 @@ func compareByCaseInsensitiveExactMatch(s string, t string) bool {
 	return strings.EqualFold(s, t)
 }
 
-//compareByCaseIgnoreMatch compares s with t by CaseIgnore Match.
-func compareByCaseIgnoreMatch(s string, t string) (result bool, err error) {
-	var sr []rune
-	var tr []rune
+//compareByCaseIgnoreMatch compares s with t, which are prepared by p, by CaseIgnore Match.
+func compareByCaseIgnoreMatch(p StringPreparer, s string, t string) (result bool, err error) {
+	var sr string
+	var tr string
 
-	if sr, err = stringPrepare(s); err != nil {
+	if sr, err = p.Prepare(s, true); err != nil {
 		return false, err
 	}
 
-	if tr, err = stringPrepare(t); err != nil {
+	if tr, err = p.Prepare(t, true); err != nil {
 		return false, err
 	}
 
-	if string(sr) == string(tr) {
+	if sr == tr {
 		return true, nil
 	}
 
 	return false, nil
 }
 
+//compareByCaseExactMatch compares s with t, which are prepared by p, by CaseExact Match.
+//https://tools.ietf.org/html/rfc4517#section-4.2.4
+//The rule is identical to the caseIgnoreMatch rule except that case is not ignored.
+func compareByCaseExactMatch(p StringPreparer, s string, t string) (result bool, err error) {
+	var sr string
+	var tr string
+
+	if sr, err = p.Prepare(s, false); err != nil {
+		return false, err
+	}
+
+	if tr, err = p.Prepare(t, false); err != nil {
+		return false, err
+	}
+
+	return sr == tr, nil
+}
+
+//compareByNumericStringMatch compares s with t, which are prepared by p, by numericStringMatch.
+//https://tools.ietf.org/html/rfc4517#section-4.2.22
+//The rule is identical to the caseIgnoreMatch rule except that all space characters are skipped during comparison.
+func compareByNumericStringMatch(p StringPreparer, s string, t string) (result bool, err error) {
+	var sr string
+	var tr string
+
+	if sr, err = prepareNumericString(p, s); err != nil {
+		return false, err
+	}
+
+	if tr, err = prepareNumericString(p, t); err != nil {
+		return false, err
+	}
+
+	return sr == tr, nil
+}
+
 //compareByBinaryComparison compares x with b by Binary Comparison.
 func compareByBinaryComparison(x []byte, y []byte) bool {
 	if len(x) == 0 || len(y) == 0 {
@@ -276,32 +486,201 @@ func compareByBinaryComparison(x []byte, y []byte) bool {
 }
 
 //toString decodes src ,which is encoded as ASN.1 string, to string.
+//The tag and the length are read directly by readTLV, and the content is converted according to the tag by
+//decodeStringContent, without the reflection of encoding/asn1.
+//
+//BMPString is UCS-2, which cannot represent code points above U+FFFF. Surrogate code points(U+D800-U+DFFF) in BMPString
+//are rejected as malformed, whether they are paired or not, rather than decoded as UTF-16.
+//Decoding surrogate pairs would make a malformed BMPString match a well-formed value.
+//
+//The constructed form of string types, which BER allows, is decoded as the primitive form whose content is the
+//concatenation of the segments.
+//
+//Values whose tags are not the string types of isStringTag, e.g. implicitly tagged values, result in *TagError.
 func toString(src []byte) (s string, err error) {
-	if rest, err := asn1.Unmarshal(src, &s); err != nil {
+	class, tag, isCompound, content, rest, err := readTLV(src)
+	if err != nil {
 		return "", err
-	} else if len(rest) != 0 {
+	}
+	if !isStringTag(class, tag) {
+		return "", &TagError{Class: class, Tag: tag, IsCompound: isCompound}
+	}
+	if len(rest) != 0 {
 		return "", errors.New("dn: trailing data after ASN.1 of string")
 	}
+	if isCompound {
+		if content, err = concatenateSegments(content, 0); err != nil {
+			return "", err
+		}
+	}
+	if s, err = decodeStringContent(tag, content); err != nil {
+		return "", fmt.Errorf("dn: %w", err)
+	}
 	return s, nil
 }
 
+//decodeStringContent decodes b, which is the content of the universal string type tag of isStringTag.
+func decodeStringContent(tag int, b []byte) (s string, err error) {
+	switch tag {
+	case asn1.TagUTF8String:
+		if !utf8.Valid(b) {
+			return "", errors.New("cannot decode UTF8String: invalid UTF-8")
+		}
+		return string(b), nil
+	case asn1.TagPrintableString:
+		//'*' and '&' are accepted as encoding/asn1 does, because they are found in many certificates
+		return decodeASCIIString(b, "PrintableString", func(c byte) bool {
+			return isPrintableCharacter(c) || c == '*' || c == '&'
+		})
+	case asn1.TagIA5String:
+		return decodeASCIIString(b, "IA5String", func(c byte) bool { return c < utf8.RuneSelf })
+	case asn1.TagNumericString:
+		return decodeASCIIString(b, "NumericString", func(c byte) bool { return '0' <= c && c <= '9' || c == ' ' })
+	case asn1.TagT61String:
+		//decoded as ISO/IEC 8859-1 without the check of WithTeletexString, as encoding/asn1 does
+		var sb strings.Builder
+		sb.Grow(len(b))
+		for _, c := range b {
+			sb.WriteRune(rune(c))
+		}
+		return sb.String(), nil
+	case asn1.TagBMPString:
+		return decodeBMPString(b)
+	case tagVisibleString:
+		return decodeVisibleString(b)
+	case asn1.TagGeneralString:
+		return decodeGeneralString(b)
+	default:
+		return "", fmt.Errorf("cannot decode universal tag %d", tag)
+	}
+}
+
+//decodeASCIIString decodes b, which is the content of the string type typeName whose characters are valid.
+func decodeASCIIString(b []byte, typeName string, valid func(c byte) bool) (s string, err error) {
+	for i, c := range b {
+		if !valid(c) {
+			return "", fmt.Errorf("cannot decode %s: invalid character 0x%02x at %d", typeName, c, i)
+		}
+	}
+	return string(b), nil
+}
+
+//decodeBMPString decodes b, which is the content of BMPString.
+func decodeBMPString(b []byte) (s string, err error) {
+	if len(b)%2 != 0 {
+		return "", errors.New("cannot decode BMPString: odd length")
+	}
+	//a terminating NUL is stripped as encoding/asn1 does
+	if l := len(b); l >= 2 && b[l-1] == 0 && b[l-2] == 0 {
+		b = b[:l-2]
+	}
+	var sb strings.Builder
+	sb.Grow(len(b) / 2)
+	for i := 0; i < len(b); i += 2 {
+		r := rune(b[i])<<8 | rune(b[i+1])
+		//noncharacters(U+FFFE, U+FFFF and U+FDD0-U+FDEF) and surrogates(U+D800-U+DFFF) are rejected
+		if r == 0xfffe || r == 0xffff || (r >= 0xfdd0 && r <= 0xfdef) || (r >= 0xd800 && r <= 0xdfff) {
+			return "", fmt.Errorf("cannot decode BMPString: invalid code point U+%04X at %d", r, i)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String(), nil
+}
+
+//maxSegmentDepth is the maximum depth of the nested segments of the constructed form of strings.
+const maxSegmentDepth = 8
+
+//stringContent returns the content of rv, which is ASN.1 string in the primitive form or the constructed form.
+func stringContent(rv asn1.RawValue) (content []byte, err error) {
+	if !rv.IsCompound {
+		return rv.Bytes, nil
+	}
+	return concatenateSegments(rv.Bytes, 0)
+}
+
+//concatenateSegments returns the concatenation of the contents of the segments in b, which is the content of ASN.1 string
+//in the constructed form.
+func concatenateSegments(b []byte, depth int) (content []byte, err error) {
+	//https://www.itu.int/rec/T-REC-X.690 section-8.23.5
+	//The restricted character string types are encoded as if they had been declared
+	//[UNIVERSAL x] IMPLICIT OCTET STRING, so the segments of the constructed form are OCTET STRING.
+	if depth == maxSegmentDepth {
+		return nil, errors.New("dn: too deeply nested segments of constructed string")
+	}
+	content = []byte{}
+	for len(b) != 0 {
+		var segment asn1.RawValue
+		if b, err = asn1.Unmarshal(b, &segment); err != nil {
+			return nil, err
+		}
+		if segment.Class != asn1.ClassUniversal || segment.Tag != asn1.TagOctetString {
+			return nil, errors.New("dn: segment of constructed string must be OCTET STRING")
+		}
+		if !segment.IsCompound {
+			content = append(content, segment.Bytes...)
+			continue
+		}
+		var nested []byte
+		if nested, err = concatenateSegments(segment.Bytes, depth+1); err != nil {
+			return nil, err
+		}
+		content = append(content, nested...)
+	}
+	return content, nil
+}
+
 //stringPrepare performs the six-step string preparation algorithm described in [RFC4518] for s.
 func stringPrepare(s string) ([]rune, error) {
+	return prepareString(s, true)
+}
+
+//prepareString prepares s in the same way as stringPrepare. Case folding is applied only if caseFold is true.
+func prepareString(s string, caseFold bool) ([]rune, error) {
+	return prepareStringForm(s, caseFold, NormalizationNFKC)
+}
+
+//prepareStringForm prepares s in the same way as prepareString, except that s is normalized to form.
+func prepareStringForm(s string, caseFold bool, form NormalizationForm) ([]rune, error) {
+	return prepareStringSteps(s, caseFold, form, nil)
+}
+
+//prepareStringSteps prepares s in the same way as prepareStringForm, calling record with the result of each step
+//if record is not nil.
+func prepareStringSteps(s string, caseFold bool, form NormalizationForm, record func(step PrepStep, u []rune)) ([]rune, error) {
+	if record == nil {
+		record = func(PrepStep, []rune) {}
+	}
 	//https://tools.ietf.org/html/rfc4518#section-2
 	//TODO modify ldapstrprep
 	//1. Transcode
 	u := ldapstrprep.Transcode(s)
+	record(PrepStepTranscode, u)
 	//2. Map
-	u = ldapstrprep.MapCharacters(u, true)
+	u = ldapstrprep.MapCharacters(u, caseFold)
+	record(PrepStepMap, u)
 	//3. Normalize
-	u = ldapstrprep.Normalize(u)
+	//NFKC maps the compatibility characters to their canonical forms, e.g. full-width Latin "ＡＢＣ" to "ABC" and
+	//half-width katakana "ﾃﾞ" to "デ". The ideographic space is mapped to a space in 2. Map.
+	switch form {
+	case NormalizationNFKC:
+		u = ldapstrprep.Normalize(u)
+	case NormalizationNFC:
+		u = []rune(norm.NFC.String(string(u)))
+	default:
+		return nil, fmt.Errorf("dn: unknown normalization form %d", form)
+	}
+	record(PrepStepNormalize, u)
 	//4. Prohibit
+	//The control characters are not prohibited. NUL and the other C0 and C1 control characters are mapped to nothing
+	//in 2. Map, except those mapped to SPACE, e.g. TAB and LF, so that "AB\x00C" is prepared to " abc ".
 	if isProhibited, err := ldapstrprep.IsProhibited(u); isProhibited == true {
 		return nil, err
 	}
+	record(PrepStepProhibit, u)
 	//5. Check Bidi
 	//Do nothing.
 	//6. Insignificant Character Handling
 	u = ldapstrprep.ApplyInsignificantSpaceHandling(u)
+	record(PrepStepInsignificantCharacterHandling, u)
 	return u, nil
 }
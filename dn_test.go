@@ -3,6 +3,8 @@ package dn
 import (
 	"encoding/asn1"
 	"encoding/hex"
+	"github.com/tardevnull/ldapstrprep"
+	"golang.org/x/text/unicode/norm"
 	"reflect"
 	"testing"
 )
@@ -15,77 +17,77 @@ var (
 	b, _            = hex.DecodeString("13024A504A504A504A50") //Broken PrintableString binary
 	ia5, _          = hex.DecodeString("1603616263")           //IA5String "abc"
 	p, _            = hex.DecodeString("1303616263")           //PrintableString "abc"
-	utf8, _         = hex.DecodeString("0C03616263")           //Utf8String "abc"
+	utf8s, _        = hex.DecodeString("0C03616263")           //Utf8String "abc"
 	bmp, _          = hex.DecodeString("1E06006100620063")     //BMPString "abc"
 	ia5d, _         = hex.DecodeString("1603616264")           //IA5String "abd"
 	pd, _           = hex.DecodeString("1303616264")           //PrintableString "abd"
 	utf8d, _        = hex.DecodeString("0C03616264")           //Utf8String "abd"
 	bmpd, _         = hex.DecodeString("1E06006100620064")     //BMPString "abd"
 
-	brokenAtv = attribute{
+	brokenAtv = Attribute{
 		Oid: oidOrganization,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagPrintableString,
 			FullBytes: b,
 		},
 	}
-	wrongDcAtv = attribute{
+	wrongDcAtv = Attribute{
 		Oid: oidDomainComponent,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagPrintableString,
 			FullBytes: a,
 		},
 	}
-	ia5Atv = attribute{
+	ia5Atv = Attribute{
 		Oid: oidDomainComponent,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagIA5String,
 			FullBytes: ia5,
 		},
 	}
-	pAtv = attribute{
+	pAtv = Attribute{
 		Oid: oidOrganization,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagPrintableString,
 			FullBytes: p,
 		},
 	}
-	utf8Atv = attribute{
+	utf8Atv = Attribute{
 		Oid: oidOrganization,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagUTF8String,
-			FullBytes: utf8,
+			FullBytes: utf8s,
 		},
 	}
-	bmpAtv = attribute{
+	bmpAtv = Attribute{
 		Oid: oidOrganization,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagBMPString,
 			FullBytes: bmp,
 		},
 	}
-	ia5dAtv = attribute{
+	ia5dAtv = Attribute{
 		Oid: oidDomainComponent,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagIA5String,
 			FullBytes: ia5d,
 		},
 	}
-	pdAtv = attribute{
+	pdAtv = Attribute{
 		Oid: oidOrganization,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagPrintableString,
 			FullBytes: pd,
 		},
 	}
-	utf8dAtv = attribute{
+	utf8dAtv = Attribute{
 		Oid: oidOrganization,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagUTF8String,
 			FullBytes: utf8d,
 		},
 	}
-	bmpdAtv = attribute{
+	bmpdAtv = Attribute{
 		Oid: oidOrganization,
 		RawValue: asn1.RawValue{
 			Tag:       asn1.TagBMPString,
@@ -93,13 +95,13 @@ var (
 		},
 	}
 
-	dn1 = []rdnSET{[]attribute{pAtv}}
-	dn2 = []rdnSET{[]attribute{pAtv}, []attribute{pdAtv}}
-	dn3 = []rdnSET{[]attribute{pAtv}, []attribute{pdAtv}, []attribute{utf8Atv}}
-	dn4 = []rdnSET{[]attribute{pdAtv}}
-	dn5 = []rdnSET{[]attribute{pdAtv}, []attribute{pAtv}, []attribute{utf8Atv}}
-	dn6 = []rdnSET{[]attribute{pAtv}, []attribute{brokenAtv}}
-	dn7 = []rdnSET{[]attribute{pAtv}, []attribute{wrongDcAtv}}
+	dn1 = []rdnSET{[]Attribute{pAtv}}
+	dn2 = []rdnSET{[]Attribute{pAtv}, []Attribute{pdAtv}}
+	dn3 = []rdnSET{[]Attribute{pAtv}, []Attribute{pdAtv}, []Attribute{utf8Atv}}
+	dn4 = []rdnSET{[]Attribute{pdAtv}}
+	dn5 = []rdnSET{[]Attribute{pdAtv}, []Attribute{pAtv}, []Attribute{utf8Atv}}
+	dn6 = []rdnSET{[]Attribute{pAtv}, []Attribute{brokenAtv}}
+	dn7 = []rdnSET{[]Attribute{pAtv}, []Attribute{wrongDcAtv}}
 
 	//C=JP(PrintableString),O=BAR(UTF8String)+O=FOO(UTF8String),CN=ABC(UTF8String)
 	hdn1 = "3035310b3009060355040613024a503118300a060355040a0c03424152300a060355040a0c03464f4f310c300a06035504030c03414243"
@@ -109,9 +111,9 @@ var (
 	hatv2   = "300A060355040A0C03424152" //O=BAR(UTF8String)
 	hatv3   = "300A060355040A0C03464F4F" //O=FOO(UTF8String)
 	hatv4   = "300A06035504030C03414243" //CN=ABC(UTF8String)
-	rdn1    = []attribute{parseAtv(hatv1)}
-	rdn2    = []attribute{parseAtv(hatv2), parseAtv(hatv3)}
-	rdn3    = []attribute{parseAtv(hatv4)}
+	rdn1    = []Attribute{parseAtv(hatv1)}
+	rdn2    = []Attribute{parseAtv(hatv2), parseAtv(hatv3)}
+	rdn3    = []Attribute{parseAtv(hatv4)}
 
 	//Broken DN
 	hBrokenDn = "3035310b3009060355040613024a503118300a060355040a0c03424152300a060355040a0c03464f4f310c300a06035504030c034142431111111"
@@ -141,9 +143,388 @@ var (
 	//C=JP(PrintableString),O=FOO(BMPString),CN=ABC(PrintableString)
 	hdn8    = "302c310b3009060355040613024a50310f300d060355040a1e060046004f004f310c300a06035504030c03414243"
 	dn8b, _ = hex.DecodeString(hdn8)
+
+	//C=JP(PrintableString),CN=(empty UTF8String)
+	hdn9    = "3018310b3009060355040613024a503109300706035504030c00"
+	dn9b, _ = hex.DecodeString(hdn9)
+
+	//C=JP(PrintableString),O=   (PrintableString, only spaces)
+	hdn10    = "301b310b3009060355040613024a50310c300a060355040a1303202020"
+	dn10b, _ = hex.DecodeString(hdn10)
+
+	//C=JP(PrintableString),CN=FOO(UTF8String),CN=BAR(UTF8String)
+	hdn11    = "3029310b3009060355040613024a50310c300a06035504030c03464f4f310c300a06035504030c03424152"
+	dn11b, _ = hex.DecodeString(hdn11)
+
+	//C=JP(PrintableString),O=Example(UTF8String),serialNumber=0001(PrintableString)
+	hdn12    = "302e310b3009060355040613024a503110300e060355040a0c074578616d706c65310d300b0603550405130430303031"
+	dn12b, _ = hex.DecodeString(hdn12)
+	//C=JP(PrintableString),O=Example(UTF8String),serialNumber=0002(PrintableString)
+	hdn13    = "302e310b3009060355040613024a503110300e060355040a0c074578616d706c65310d300b0603550405130430303032"
+	dn13b, _ = hex.DecodeString(hdn13)
+	//C=JP(PrintableString),O=Example(UTF8String),CN=ABC(UTF8String)+serialNumber=0001(PrintableString)
+	hdn14    = "303a310b3009060355040613024a503110300e060355040a0c074578616d706c653119300a06035504030c03414243300b0603550405130430303031"
+	dn14b, _ = hex.DecodeString(hdn14)
+	//C=JP(PrintableString),O=Example(UTF8String),CN=ABC(UTF8String)
+	hdn15    = "302d310b3009060355040613024a503110300e060355040a0c074578616d706c65310c300a06035504030c03414243"
+	dn15b, _ = hex.DecodeString(hdn15)
+
+	//C=JP(PrintableString),O=FOO(UTF8String)+O=BAR(UTF8String),CN=ABC(UTF8String)
+	//The attributes of the multi-valued RDN are not in DER order.
+	hdn16    = "3035310b3009060355040613024a503118300a060355040a0c03464f4f300a060355040a0c03424152310c300a06035504030c03414243"
+	dn16b, _ = hex.DecodeString(hdn16)
+
+	//C=JP(PrintableString),DC=com(IA5String),DC=example(IA5String),CN=abc(UTF8String)
+	hdn17    = "3049310b3009060355040613024a5031133011060a0992268993f22c6401191603636f6d31173015060a0992268993f22c64011916076578616d706c65310c300a06035504030c03616263"
+	dn17b, _ = hex.DecodeString(hdn17)
+
+	//C= JP(PrintableString),CN=ABC(UTF8String)
+	hdn18    = "301c310c300a06035504061303204a50310c300a06035504030c03414243"
+	dn18b, _ = hex.DecodeString(hdn18)
+
+	//C=JP (PrintableString),CN=ABC(UTF8String)
+	hdn19    = "301c310c300a060355040613034a5020310c300a06035504030c03414243"
+	dn19b, _ = hex.DecodeString(hdn19)
+
+	//C= JP (PrintableString),CN=ABC(UTF8String)
+	hdn20    = "301d310d300b06035504061304204a5020310c300a06035504030c03414243"
+	dn20b, _ = hex.DecodeString(hdn20)
+
+	//C=JPN(PrintableString),CN=ABC(UTF8String)
+	hdn21    = "301c310c300a060355040613034a504e310c300a06035504030c03414243"
+	dn21b, _ = hex.DecodeString(hdn21)
+
+	//C=J P(PrintableString),CN=ABC(UTF8String)
+	hdn22    = "301c310c300a060355040613034a2050310c300a06035504030c03414243"
+	dn22b, _ = hex.DecodeString(hdn22)
+
+	//C=JP(PrintableString),unstructuredName=Router1(IA5String)
+	hdn23    = "3025310b3009060355040613024a503116301406092a864886f70d0109021607526f7574657231"
+	dn23b, _ = hex.DecodeString(hdn23)
+
+	//C=JP(PrintableString),unstructuredName=router1(IA5String)
+	hdn24    = "3025310b3009060355040613024a503116301406092a864886f70d0109021607726f7574657231"
+	dn24b, _ = hex.DecodeString(hdn24)
+
+	//C=JP(PrintableString),unstructuredName=  Router1 (IA5String)
+	hdn25    = "3028310b3009060355040613024a503119301706092a864886f70d010902160a2020526f757465723120"
+	dn25b, _ = hex.DecodeString(hdn25)
+
+	//C=JP(PrintableString),unstructuredName=router1(UTF8String)
+	hdn26    = "3025310b3009060355040613024a503116301406092a864886f70d0109020c07726f7574657231"
+	dn26b, _ = hex.DecodeString(hdn26)
+
+	//C=JP(PrintableString),unstructuredName=ROUTER1(UTF8String)
+	hdn27    = "3025310b3009060355040613024a503116301406092a864886f70d0109020c07524f5554455231"
+	dn27b, _ = hex.DecodeString(hdn27)
+
+	//C=JP(PrintableString),O=Example(VideotexString)
+	hdn28    = "301f310b3009060355040613024a503110300e060355040a15074578616d706c65"
+	dn28b, _ = hex.DecodeString(hdn28)
+
+	//C=JP(PrintableString),O=Example(GraphicString)
+	hdn29    = "301f310b3009060355040613024a503110300e060355040a19074578616d706c65"
+	dn29b, _ = hex.DecodeString(hdn29)
+
+	//C=JP(PrintableString),O=Example([0] IMPLICIT)
+	hdn30    = "301f310b3009060355040613024a503110300e060355040a80074578616d706c65"
+	dn30b, _ = hex.DecodeString(hdn30)
+
+	//C=JP(PrintableString),O=12345(NumericString)
+	hdn31    = "301d310b3009060355040613024a50310e300c060355040a12053132333435"
+	dn31b, _ = hex.DecodeString(hdn31)
+
+	//C=JP(PrintableString),jurisdictionC=JP(PrintableString),O=Example(UTF8String)
+	hdn32    = "3034310b3009060355040613024a5031133011060b2b0601040182373c02010313024a503110300e060355040a0c074578616d706c65"
+	dn32b, _ = hex.DecodeString(hdn32)
+
+	//C=JP(PrintableString),jurisdictionC=jp(PrintableString),O=Example(UTF8String)
+	hdn33    = "3034310b3009060355040613024a5031133011060b2b0601040182373c02010313026a703110300e060355040a0c074578616d706c65"
+	dn33b, _ = hex.DecodeString(hdn33)
+
+	//C=JP(PrintableString),jurisdictionC= JP(PrintableString),O=Example(UTF8String)
+	hdn34    = "3035310b3009060355040613024a5031143012060b2b0601040182373c0201031303204a503110300e060355040a0c074578616d706c65"
+	dn34b, _ = hex.DecodeString(hdn34)
+
+	//C=JP(PrintableString),jurisdictionC=JPN(PrintableString),O=Example(UTF8String)
+	hdn35    = "3035310b3009060355040613024a5031143012060b2b0601040182373c02010313034a504e3110300e060355040a0c074578616d706c65"
+	dn35b, _ = hex.DecodeString(hdn35)
+
+	//C=JP(PrintableString),O=Exa+mple(PrintableString in the constructed form of BER)
+	hdn36    = "3023310b3009060355040613024a5031143012060355040a330b040345786104046d706c65"
+	dn36b, _ = hex.DecodeString(hdn36)
+
+	//C=JP(PrintableString),O=(Ex+a)+mple(PrintableString in the nested constructed form of BER)
+	hdn37    = "3027310b3009060355040613024a5031183016060355040a330f24070402457804016104046d706c65"
+	dn37b, _ = hex.DecodeString(hdn37)
+
+	//C=JP(PrintableString),O=Example(BMPString in the constructed form of BER)
+	hdn38    = "3028310b3009060355040613024a5031193017060355040a3e10040e004500780061006d0070006c0065"
+	dn38b, _ = hex.DecodeString(hdn38)
+
+	//C=JP(PrintableString),ST=Tokyo(UTF8String),STREET=1-2-3 Chiyoda(PrintableString)
+	hdn39    = "3035310b3009060355040613024a50310e300c06035504080c05546f6b796f311630140603550409130d312d322d3320436869796f6461"
+	dn39b, _ = hex.DecodeString(hdn39)
+
+	//C=JP(PrintableString),ST=TOKYO(PrintableString),STREET=1-2-3  CHIYODA (UTF8String)
+	hdn40    = "3037310b3009060355040613024a50310e300c06035504081305544f4b594f3118301606035504090c0f312d322d332020434849594f444120"
+	dn40b, _ = hex.DecodeString(hdn40)
+
+	//C=JP(PrintableString),ST=Tokyo(UTF8String),STREET=1-2-3 Chiyoda LF Suite 100(UTF8String)
+	hdn41    = "303f310b3009060355040613024a50310e300c06035504080c05546f6b796f3120301e06035504090c17312d322d3320436869796f64610a537569746520313030"
+	dn41b, _ = hex.DecodeString(hdn41)
+
+	//C=JP(PrintableString),ST=Tokyo(UTF8String),STREET=1-2-3 Chiyoda CR LF Suite 100(UTF8String)
+	hdn42    = "3040310b3009060355040613024a50310e300c06035504080c05546f6b796f3121301f06035504090c18312d322d3320436869796f64610d0a537569746520313030"
+	dn42b, _ = hex.DecodeString(hdn42)
+
+	//C=JP(PrintableString),ST=Tokyo(UTF8String),STREET=1-2-3 chiyoda suite 100(UTF8String)
+	hdn43    = "303f310b3009060355040613024a50310e300c06035504080c05546f6b796f3120301e06035504090c17312d322d3320636869796f646120737569746520313030"
+	dn43b, _ = hex.DecodeString(hdn43)
+
+	//C=JP(PrintableString),ST=Tokyo(UTF8String),STREET=SEQUENCE{1-2-3 Chiyoda, Suite 100}(UTF8String)
+	hdn44    = "3042310b3009060355040613024a50310e300c06035504080c05546f6b796f312330210603550409301a0c0d312d322d3320436869796f64610c09537569746520313030"
+	dn44b, _ = hex.DecodeString(hdn44)
+
+	//C=JP(PrintableString),O=Example(UTF8String),roleOccupant=[C=JP(PrintableString),CN=Alice(UTF8String)]
+	hdn45    = "3047310b3009060355040613024a503110300e060355040a0c074578616d706c65312630240603550421301d310b3009060355040613024a50310e300c06035504030c05416c696365"
+	dn45b, _ = hex.DecodeString(hdn45)
+
+	//C=JP(PrintableString),O=Example(UTF8String),roleOccupant=[C=jp(UTF8String),CN=ALICE (PrintableString)]
+	hdn46    = "3048310b3009060355040613024a503110300e060355040a0c074578616d706c65312730250603550421301e310b300906035504060c026a70310f300d06035504031306414c49434520"
+	dn46b, _ = hex.DecodeString(hdn46)
+
+	//C=JP(PrintableString),O=Example(UTF8String),roleOccupant=[C=JP(PrintableString),CN=Bob(UTF8String)]
+	hdn47    = "3045310b3009060355040613024a503110300e060355040a0c074578616d706c65312430220603550421301b310b3009060355040613024a50310c300a06035504030c03426f62"
+	dn47b, _ = hex.DecodeString(hdn47)
+
+	//C=JP(PrintableString),O=Example(UTF8String),roleOccupant=CN=Alice,C=JP(UTF8String)
+	hdn48    = "3037310b3009060355040613024a503110300e060355040a0c074578616d706c653116301406035504210c0d434e3d416c6963652c433d4a50"
+	dn48b, _ = hex.DecodeString(hdn48)
+
+	//C=JP(PrintableString),O=Example(UTF8String),roleOccupant nested 5 levels
+	hdn49    = "3073310b3009060355040613024a503110300e060355040a0c074578616d706c653152305006035504213049314730450603550421303e313c303a060355042130333131302f06035504213028312630240603550421301d310b3009060355040613024a50310e300c06035504030c05416c696365"
+	dn49b, _ = hex.DecodeString(hdn49)
+
+	//C=JP(PrintableString),O=Example(UTF8String),roleOccupant nested 4 levels
+	hdn50    = "3068310b3009060355040613024a503110300e060355040a0c074578616d706c65314730450603550421303e313c303a060355042130333131302f06035504213028312630240603550421301d310b3009060355040613024a50310e300c06035504030c05416c696365"
+	dn50b, _ = hex.DecodeString(hdn50)
+
+	//C=JP(PrintableString),CN=foo(UTF8String),CN=foo(UTF8String)
+	hdn51    = "3029310b3009060355040613024a50310c300a06035504030c03666f6f310c300a06035504030c03666f6f"
+	dn51b, _ = hex.DecodeString(hdn51)
+
+	//C=JP(PrintableString),CN=foo(UTF8String),CN= FOO(PrintableString)
+	hdn52    = "302a310b3009060355040613024a50310c300a06035504030c03666f6f310d300b0603550403130420464f4f"
+	dn52b, _ = hex.DecodeString(hdn52)
+
+	//C=JP(PrintableString),CN=foo(UTF8String)+O=foo(UTF8String),CN=foo(UTF8String)
+	hdn53    = "3035310b3009060355040613024a503118300a06035504030c03666f6f300a060355040a0c03666f6f310c300a06035504030c03666f6f"
+	dn53b, _ = hex.DecodeString(hdn53)
+
+	//C=JP(PrintableString),CN=Alice(UTF8String),x500UniqueIdentifier=12345(20 bits)
+	hdn54    = "302c310b3009060355040613024a50310e300c06035504030c05416c696365310d300b060355042d030404123450"
+	dn54b, _ = hex.DecodeString(hdn54)
+
+	//C=JP(PrintableString),CN=Alice(UTF8String),x500UniqueIdentifier=12345(20 bits, non-zero unused bits)
+	hdn55    = "302c310b3009060355040613024a50310e300c06035504030c05416c696365310d300b060355042d03040412345f"
+	dn55b, _ = hex.DecodeString(hdn55)
+
+	//C=JP(PrintableString),CN=Alice(UTF8String),x500UniqueIdentifier=12345(20 bits in the constructed form of BER)
+	hdn56    = "3031310b3009060355040613024a50310e300c06035504030c05416c69636531123010060355042d2309030300123403020450"
+	dn56b, _ = hex.DecodeString(hdn56)
+
+	//C=JP(PrintableString),CN=Alice(UTF8String),x500UniqueIdentifier=123450(24 bits)
+	hdn57    = "302c310b3009060355040613024a50310e300c06035504030c05416c696365310d300b060355042d030400123450"
+	dn57b, _ = hex.DecodeString(hdn57)
+
+	//C=JP(PrintableString),CN=Alice(UTF8String),x500UniqueIdentifier=12346(20 bits)
+	hdn58    = "302c310b3009060355040613024a50310e300c06035504030c05416c696365310d300b060355042d030404123460"
+	dn58b, _ = hex.DecodeString(hdn58)
+
+	//C=JP(PrintableString),CN=Alice(UTF8String),x500UniqueIdentifier=12345(UTF8String)
+	hdn59    = "302d310b3009060355040613024a50310e300c06035504030c05416c696365310e300c060355042d0c053132333435"
+	dn59b, _ = hex.DecodeString(hdn59)
+
+	//C=JP(PrintableString),O=foo(UTF8String)+O=FOO(PrintableString)+O=foo(BMPString)
+	hdn60    = "3036310b3009060355040613024a503127300a060355040a0c03666f6f300a060355040a1303464f4f300d060355040a1e060066006f006f"
+	dn60b, _ = hex.DecodeString(hdn60)
+
+	//C=JP(PrintableString),O=foo(BMPString)+O= Foo(UTF8String)+O=foo(PrintableString)
+	hdn61    = "3037310b3009060355040613024a503128300d060355040a1e060066006f006f300b060355040a0c0420466f6f300a060355040a1303666f6f"
+	dn61b, _ = hex.DecodeString(hdn61)
+
+	//C=JP(PrintableString),O=FOO(PrintableString)+O=foo(UTF8String)+O=foo(BMPString)
+	hdn62    = "3036310b3009060355040613024a503127300a060355040a1303464f4f300a060355040a0c03666f6f300d060355040a1e060066006f006f"
+	dn62b, _ = hex.DecodeString(hdn62)
+
+	//DC=com(IA5String),DC=exa mple(IA5String)
+	hdn63    = "302f31133011060a0992268993f22c6401191603636f6d31183016060a0992268993f22c6401191608657861206d706c65"
+	dn63b, _ = hex.DecodeString(hdn63)
+
+	//DC=com(IA5String),DC= EXA  mple (IA5String)
+	hdn64    = "303231133011060a0992268993f22c6401191603636f6d311b3019060a0992268993f22c640119160b2045584120206d706c6520"
+	dn64b, _ = hex.DecodeString(hdn64)
+
+	//DC=com(IA5String),DC=example(IA5String)
+	hdn65    = "302e31133011060a0992268993f22c6401191603636f6d31173015060a0992268993f22c64011916076578616d706c65"
+	dn65b, _ = hex.DecodeString(hdn65)
+
+	//DC=com(IA5String),DC=exa mple(PrintableString)
+	hdn66    = "302f31133011060a0992268993f22c6401191603636f6d31183016060a0992268993f22c6401191308657861206d706c65"
+	dn66b, _ = hex.DecodeString(hdn66)
+
+	//C=JP(PrintableString),emailAddress=用户@例子.广告(UTF8String)
+	hdn67    = "3032310b3009060355040613024a503123302106092a864886f70d0109010c14e794a8e688b740e4be8be5ad902ee5b9bfe5918a"
+	dn67b, _ = hex.DecodeString(hdn67)
+
+	//C=JP(PrintableString),emailAddress=用户@XN--FSQU00A.xn--4rr70v(UTF8String)
+	hdn68    = "303b310b3009060355040613024a50312c302a06092a864886f70d0109010c1de794a8e688b740584e2d2d465351553030412e786e2d2d347272373076"
+	dn68b, _ = hex.DecodeString(hdn68)
+
+	//C=JP(PrintableString),emailAddress=用户@例子.com(UTF8String)
+	hdn69    = "302f310b3009060355040613024a503120301e06092a864886f70d0109010c11e794a8e688b740e4be8be5ad902e636f6d"
+	dn69b, _ = hex.DecodeString(hdn69)
+
+	//C=JP(PrintableString),O=ﾃｽﾄ(UTF8String, half-width katakana),CN=ＡＢＣ　ｄｅｆ(UTF8String, full-width Latin and ideographic space)
+	hdn70    = "3041310b3009060355040613024a5031123010060355040a0c09efbe83efbdbdefbe84311e301c06035504030c15efbca1efbca2efbca3e38080efbd84efbd85efbd86"
+	dn70b, _ = hex.DecodeString(hdn70)
+
+	//C=JP(PrintableString),O=テスト(UTF8String),CN=ABC DEF(PrintableString)
+	hdn71    = "3033310b3009060355040613024a5031123010060355040a0c09e38386e382b9e383883110300e0603550403130741424320444546"
+	dn71b, _ = hex.DecodeString(hdn71)
+
+	//C=JP(PrintableString),O=ﾃﾞｽﾄ(UTF8String, half-width katakana with voiced sound mark),CN=ABC DEF(PrintableString)
+	hdn72    = "3036310b3009060355040613024a5031153013060355040a0c0cefbe83efbe9eefbdbdefbe843110300e0603550403130741424320444546"
+	dn72b, _ = hex.DecodeString(hdn72)
+
+	//C=JP(PrintableString),O=デスト(UTF8String),CN=ABC DEF(PrintableString)
+	hdn73    = "3033310b3009060355040613024a5031123010060355040a0c09e38387e382b9e383883110300e0603550403130741424320444546"
+	dn73b, _ = hex.DecodeString(hdn73)
+
+	//C=JP(PrintableString),CN=ABC(UTF8String) whose OID 2.5.4.3 is not minimally encoded(55 80 04 03)
+	hdn74    = "301c310b3009060355040613024a50310d300b0604558004030c03414243"
+	dn74b, _ = hex.DecodeString(hdn74)
+
+	//C=JP(PrintableString),CN=José(UTF8String, U+00E9)
+	hdn75    = "301d310b3009060355040613024a50310e300c06035504030c054a6f73c3a9"
+	dn75b, _ = hex.DecodeString(hdn75)
+
+	//C=JP(PrintableString),CN=JOSÉ(UTF8String, E U+0301)
+	hdn76    = "301e310b3009060355040613024a50310f300d06035504030c064a4f5345cc81"
+	dn76b, _ = hex.DecodeString(hdn76)
+
+	//C=JP(PrintableString),CN=Jose(PrintableString)
+	hdn77    = "301c310b3009060355040613024a50310d300b060355040313044a6f7365"
+	dn77b, _ = hex.DecodeString(hdn77)
+
+	//C=JP(PrintableString),O=각(UTF8String, U+AC01)
+	hdn78    = "301b310b3009060355040613024a50310c300a060355040a0c03eab081"
+	dn78b, _ = hex.DecodeString(hdn78)
+
+	//C=JP(PrintableString),O=각(UTF8String, U+1100 U+1161 U+11A8)
+	hdn79    = "3021310b3009060355040613024a5031123010060355040a0c09e18480e185a1e186a8"
+	dn79b, _ = hex.DecodeString(hdn79)
+
+	//C=JP(PrintableString),O=123 45(NumericString)
+	hdn80    = "301e310b3009060355040613024a50310f300d060355040a1206313233203435"
+	dn80b, _ = hex.DecodeString(hdn80)
+
+	//C=JP(PrintableString),O=1 2 3 4 5(UTF8String)
+	hdn81    = "3021310b3009060355040613024a5031123010060355040a0c09312032203320342035"
+	dn81b, _ = hex.DecodeString(hdn81)
+
+	//C=JP(PrintableString),O=12346(NumericString)
+	hdn82    = "301d310b3009060355040613024a50310e300c060355040a12053132333436"
+	dn82b, _ = hex.DecodeString(hdn82)
+
+	//C=JP(PrintableString),O=Müller(UTF8String)
+	hdn83    = "301f310b3009060355040613024a503110300e060355040a0c074dc3bc6c6c6572"
+	dn83b, _ = hex.DecodeString(hdn83)
+
+	//C=JP(PrintableString),O=Mueller(PrintableString)
+	hdn84    = "301f310b3009060355040613024a503110300e060355040a13074d75656c6c6572"
+	dn84b, _ = hex.DecodeString(hdn84)
+
+	//C=JP(PrintableString),CN=AB\x00C(UTF8String, embedded NUL)
+	hdn85    = "301c310b3009060355040613024a50310d300b06035504030c0441420043"
+	dn85b, _ = hex.DecodeString(hdn85)
+
+	//C=JP(PrintableString),CN=ABC\x00.evil(UTF8String, NUL followed by a suffix)
+	hdn86    = "3021310b3009060355040613024a503112301006035504030c09414243002e6576696c"
+	dn86b, _ = hex.DecodeString(hdn86)
+
+	//C=JP(PrintableString),CN=AB\u0085C(UTF8String, NEXT LINE which is mapped to SPACE)
+	hdn87    = "301d310b3009060355040613024a50310e300c06035504030c054142c28543"
+	dn87b, _ = hex.DecodeString(hdn87)
+
+	//C=JP(PrintableString),CN=\ufb01le(UTF8String, ligature fi)
+	hdn88    = "301d310b3009060355040613024a50310e300c06035504030c05efac816c65"
+	dn88b, _ = hex.DecodeString(hdn88)
+
+	//C=JP(PrintableString),CN=file(PrintableString)
+	hdn89    = "301c310b3009060355040613024a50310d300b0603550403130466696c65"
+	dn89b, _ = hex.DecodeString(hdn89)
+	//C=JP(PrintableString),CN=ABC(VisibleString)
+	hdn90    = "301b310b3009060355040613024a50310c300a06035504031a03414243"
+	dn90b, _ = hex.DecodeString(hdn90)
+	//C=JP(PrintableString),CN=a@b_c(VisibleString)
+	hdn91    = "301d310b3009060355040613024a50310e300c06035504031a056140625f63"
+	dn91b, _ = hex.DecodeString(hdn91)
+	//C=JP(PrintableString),CN=Caf\xe9(VisibleString)
+	hdn92    = "301c310b3009060355040613024a50310d300b06035504031a04436166e9"
+	dn92b, _ = hex.DecodeString(hdn92)
+	//C=JP(PrintableString),CN=Caf\xe9(GeneralString)
+	hdn93    = "301c310b3009060355040613024a50310d300b06035504031b04436166e9"
+	dn93b, _ = hex.DecodeString(hdn93)
+	//C=JP(PrintableString),CN=\x1b(BABC(GeneralString)
+	hdn94    = "301e310b3009060355040613024a50310f300d06035504031b061b2842414243"
+	dn94b, _ = hex.DecodeString(hdn94)
+	//C=JP(PrintableString),CN=ABC(VisibleString in the constructed form)
+	hdn95    = "301f310b3009060355040613024a503110300e06035504033a0704014104024243"
+	dn95b, _ = hex.DecodeString(hdn95)
+	//C=JP(PrintableString),CN=A@B(PrintableString, broken)
+	hdn96    = "301b310b3009060355040613024a50310c300a06035504031303414042"
+	dn96b, _ = hex.DecodeString(hdn96)
+	//C=JP(PrintableString),O=A@B(PrintableString, broken)
+	hdn97    = "301b310b3009060355040613024a50310c300a060355040a1303414042"
+	dn97b, _ = hex.DecodeString(hdn97)
+	//C=JP(PrintableString),emailAddress=Secret(IA5String)
+	hdn98    = "3024310b3009060355040613024a503115301306092a864886f70d0109011606536563726574"
+	dn98b, _ = hex.DecodeString(hdn98)
+	//C=JP(PrintableString),emailAddress=Secret(IA5String in the constructed form)
+	hdn99    = "3028310b3009060355040613024a503119301706092a864886f70d010901360a04035365630403726574"
+	dn99b, _ = hex.DecodeString(hdn99)
+	//C=JP(PrintableString),emailAddress=secret(IA5String)
+	hdn100    = "3024310b3009060355040613024a503115301306092a864886f70d0109011606736563726574"
+	dn100b, _ = hex.DecodeString(hdn100)
+	//C=JP(PrintableString),CN=ABC(UTF8String),O=ABC(UTF8String)
+	hdn101    = "3029310b3009060355040613024a50310c300a06035504030c03414243310c300a060355040a0c03414243"
+	dn101b, _ = hex.DecodeString(hdn101)
+	//C=JP(PrintableString),CN=A@B(PrintableString, broken),OU=ABC(UTF8String)
+	hdn102    = "3029310b3009060355040613024a50310c300a06035504031303414042310c300a060355040b0c03414243"
+	dn102b, _ = hex.DecodeString(hdn102)
+	//C=JP(PrintableString),O=BAR(UTF8String)+OU=FOO(UTF8String),CN=ABC(UTF8String)
+	hdn103    = "3035310b3009060355040613024a503118300a060355040a0c03424152300a060355040b0c03464f4f310c300a06035504030c03414243"
+	dn103b, _ = hex.DecodeString(hdn103)
+	//C=JP(PrintableString),CN=ABC([0] IMPLICIT)
+	hdn104    = "301b310b3009060355040613024a50310c300a06035504038003414243"
+	dn104b, _ = hex.DecodeString(hdn104)
+	//C=JP(PrintableString),CN=AB\x00C(UTF8String, NUL in the overlong sequence 0xC0 0x80)
+	hdn105    = "301d310b3009060355040613024a50310e300c06035504030c054142c08043"
+	dn105b, _ = hex.DecodeString(hdn105)
+	//C=DE(PrintableString),O=Example GmbH(UTF8String),serialNumber=pnode-12345(PrintableString)+initials=a.m.(PrintableString)+surname=SCHMIDT(UTF8String)+givenName=anna  maria(UTF8String)
+	hdn106    = "306b310b300906035504061302444531153013060355040a0c0c4578616d706c6520476d6248314530120603550405130b706e6f64652d3132333435300b060355042b1304612e6d2e300e06035504040c075343484d4944543012060355042a0c0b616e6e6120206d61726961"
+	dn106b, _ = hex.DecodeString(hdn106)
+	//businessCategory=Private Organization(PrintableString),jurisdictionC=US(PrintableString),jurisdictionST=Delaware(UTF8String),serialNumber=5157550(PrintableString),C=US(PrintableString),ST=California(UTF8String),L=San Francisco(UTF8String),O=Example, Inc.(UTF8String),CN=www.example.com(UTF8String)
+	hdn107    = "3081cd311d301b060355040f131450726976617465204f7267616e697a6174696f6e31133011060b2b0601040182373c0201031302555331193017060b2b0601040182373c0201020c0844656c61776172653110300e0603550405130735313537353530310b30090603550406130255533113301106035504080c0a43616c69666f726e69613116301406035504070c0d53616e204672616e636973636f31163014060355040a0c0d4578616d706c652c20496e632e3118301606035504030c0f7777772e6578616d706c652e636f6d"
+	dn107b, _ = hex.DecodeString(hdn107)
+	//the same as hdn107 except jurisdictionC=US(UTF8String)
+	hdn108    = "3081cd311d301b060355040f131450726976617465204f7267616e697a6174696f6e31133011060b2b0601040182373c0201030c02555331193017060b2b0601040182373c0201020c0844656c61776172653110300e0603550405130735313537353530310b30090603550406130255533113301106035504080c0a43616c69666f726e69613116301406035504070c0d53616e204672616e636973636f31163014060355040a0c0d4578616d706c652c20496e632e3118301606035504030c0f7777772e6578616d706c652e636f6d"
+	dn108b, _ = hex.DecodeString(hdn108)
 )
 
-func parseAtv(h string) (atv attribute) {
+func parseAtv(h string) (atv Attribute) {
 	bytes, _ := hex.DecodeString(h)
 	if r, err := asn1.Unmarshal(bytes, &atv); err != nil || len(r) != 0 {
 		panic("")
@@ -167,9 +548,42 @@ func TestCompare(t *testing.T) {
 		{"Upper/Lower case characters, Same Encoding", args{issuer: dn2b, subject: dn3b}, true, false},
 		{"Same characters, Different Encoding(PrintableString,UTF8String)", args{issuer: dn2b, subject: dn3b}, true, false},
 		{"Same characters, Different Encoding(PrintableString,BMPString)", args{issuer: dn2b, subject: dn5b}, false, false},
+		{"Full-width Latin, Half-width katakana and Ideographic space", args{issuer: dn70b, subject: dn71b}, true, false},
+		{"Half-width katakana with voiced sound mark", args{issuer: dn72b, subject: dn73b}, true, false},
+		{"Half-width katakana with and without voiced sound mark", args{issuer: dn70b, subject: dn72b}, false, false},
+		{"Composed and decomposed, Upper/Lower case characters", args{issuer: dn75b, subject: dn76b}, true, false},
+		{"Composed and without combining character, Different Encoding(UTF8String,PrintableString)", args{issuer: dn75b, subject: dn77b}, false, false},
+		{"Decomposed and without combining character, Different Encoding(UTF8String,PrintableString)", args{issuer: dn76b, subject: dn77b}, false, false},
+		{"Hangul syllable and Hangul jamo", args{issuer: dn78b, subject: dn79b}, true, false},
+		{"Embedded NUL is removed by the string preparation", args{issuer: dn85b, subject: dn2b}, true, false},
+		{"NUL does not truncate the value", args{issuer: dn86b, subject: dn2b}, false, false},
+		{"NEXT LINE is mapped to SPACE", args{issuer: dn87b, subject: dn2b}, false, false},
+		{"Multi-valued RDN of the same type, Different Encoding(UTF8String,PrintableString,BMPString)", args{issuer: dn60b, subject: dn61b}, true, false},
+		{"Multi-valued RDN of the same type, Different order", args{issuer: dn61b, subject: dn62b}, true, false},
 		{"Same characters, Multi RDN", args{issuer: dn1b, subject: dn1b}, true, false},
 		{"Different characters, Same Encoding", args{issuer: dn2b, subject: dn6b}, false, false},
 		{"Wrong Encoding domain component", args{issuer: dn7b, subject: dn7b}, false, true},
+		{"Leading space in country name", args{issuer: dn18b, subject: dn2b}, true, false},
+		{"Trailing space in country name", args{issuer: dn2b, subject: dn19b}, true, false},
+		{"Leading and trailing spaces in country name", args{issuer: dn20b, subject: dn18b}, true, false},
+		{"Inner space in country name", args{issuer: dn22b, subject: dn2b}, false, false},
+		{"unstructuredName, Same characters(IA5String)", args{issuer: dn23b, subject: dn23b}, true, false},
+		{"unstructuredName, Upper/Lower case characters(IA5String)", args{issuer: dn23b, subject: dn24b}, false, false},
+		{"unstructuredName, Insignificant spaces(IA5String)", args{issuer: dn25b, subject: dn23b}, true, false},
+		{"unstructuredName, Upper/Lower case characters(UTF8String)", args{issuer: dn26b, subject: dn27b}, true, false},
+		{"unstructuredName, Different Encoding(IA5String,UTF8String)", args{issuer: dn24b, subject: dn26b}, false, false},
+		{"Constructed form and primitive form(PrintableString)", args{issuer: dn36b, subject: dnExamplePrintableb}, true, false},
+		{"Nested constructed form and primitive form(PrintableString)", args{issuer: dnExamplePrintableb, subject: dn37b}, true, false},
+		{"Nested constructed form and constructed form(PrintableString)", args{issuer: dn37b, subject: dn36b}, true, false},
+		{"Constructed form and primitive form(BMPString)", args{issuer: dn38b, subject: dnExampleBMPb}, false, false},
+		{"stateOrProvinceName and streetAddress, Same characters", args{issuer: dn39b, subject: dn39b}, true, false},
+		{"stateOrProvinceName and streetAddress, Upper/Lower case characters and spaces", args{issuer: dn39b, subject: dn40b}, true, false},
+		{"streetAddress, LF and space", args{issuer: dn41b, subject: dn43b}, true, false},
+		{"streetAddress, CR LF and space", args{issuer: dn43b, subject: dn42b}, true, false},
+		{"streetAddress, CR LF and LF", args{issuer: dn42b, subject: dn41b}, true, false},
+		{"streetAddress, Different lines", args{issuer: dn39b, subject: dn41b}, false, false},
+		{"streetAddress, List and single string", args{issuer: dn44b, subject: dn41b}, false, true},
+		{"streetAddress, Same list", args{issuer: dn44b, subject: dn44b}, false, true},
 		{"Broken data", args{issuer: brdnb, subject: brdnb}, false, true},
 		{"Issuer is blank", args{issuer: []byte{}, subject: brdnb}, false, true},
 		{"Subject is blank", args{issuer: brdnb, subject: []byte{}}, false, false},
@@ -260,19 +674,19 @@ func Test_compareRelativeDistinguishedName(t *testing.T) {
 		wantResult bool
 		wantErr    bool
 	}{
-		{"RDNs are same, have 1 element", args{xr: []attribute{pAtv}, yr: []attribute{pAtv}}, true, false},
-		{"RDNs are same, have 2 elements", args{xr: []attribute{pAtv, pdAtv}, yr: []attribute{pAtv, pdAtv}}, true, false},
-		{"RDNs are same, have 2 elements", args{xr: []attribute{bmpAtv, pAtv}, yr: []attribute{pAtv, bmpAtv}}, true, false},
-		{"RDNs are same, have 3 elements", args{xr: []attribute{pAtv, ia5Atv, bmpAtv}, yr: []attribute{ia5Atv, pAtv, bmpAtv}}, true, false},
-		{"RDNs are not same, have 1 element", args{xr: []attribute{pAtv}, yr: []attribute{ia5dAtv}}, false, false},
-		{"RDNs are not same, have 3 elements", args{xr: []attribute{pAtv, ia5Atv, bmpAtv}, yr: []attribute{ia5Atv, pdAtv, bmpAtv}}, false, false},
-		{"RDNs are not same, have different number of elements", args{xr: []attribute{pAtv, pdAtv}, yr: []attribute{pAtv}}, false, false},
-		{"RDNs are same, have 2 elements and have broken element", args{xr: []attribute{pAtv, brokenAtv}, yr: []attribute{pAtv, brokenAtv}}, false, true}, // Unknown
+		{"RDNs are same, have 1 element", args{xr: []Attribute{pAtv}, yr: []Attribute{pAtv}}, true, false},
+		{"RDNs are same, have 2 elements", args{xr: []Attribute{pAtv, pdAtv}, yr: []Attribute{pAtv, pdAtv}}, true, false},
+		{"RDNs are same, have 2 elements", args{xr: []Attribute{bmpAtv, pAtv}, yr: []Attribute{pAtv, bmpAtv}}, true, false},
+		{"RDNs are same, have 3 elements", args{xr: []Attribute{pAtv, ia5Atv, bmpAtv}, yr: []Attribute{ia5Atv, pAtv, bmpAtv}}, true, false},
+		{"RDNs are not same, have 1 element", args{xr: []Attribute{pAtv}, yr: []Attribute{ia5dAtv}}, false, false},
+		{"RDNs are not same, have 3 elements", args{xr: []Attribute{pAtv, ia5Atv, bmpAtv}, yr: []Attribute{ia5Atv, pdAtv, bmpAtv}}, false, false},
+		{"RDNs are not same, have different number of elements", args{xr: []Attribute{pAtv, pdAtv}, yr: []Attribute{pAtv}}, false, false},
+		{"RDNs are same, have 2 elements and have broken element", args{xr: []Attribute{pAtv, brokenAtv}, yr: []Attribute{pAtv, brokenAtv}}, false, true}, // Unknown
 
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotResult, err := compareRelativeDistinguishedName(tt.args.xr, tt.args.yr)
+			gotResult, err := compareRelativeDistinguishedName(tt.args.xr, tt.args.yr, compareAttribute)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("compareRelativeDistinguishedName() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -284,9 +698,61 @@ func Test_compareRelativeDistinguishedName(t *testing.T) {
 	}
 }
 
+func Test_compareRelativeDistinguishedName_NotTransitive(t *testing.T) {
+	//edges[i] are the indexes of the attributes of yr which the i-th attribute of xr matches.
+	//The cases break the assignment of each attribute to the first matching attribute which is not assigned yet.
+	tests := []struct {
+		name       string
+		edges      [][]int
+		wantResult bool
+	}{
+		{"Reassign an attribute", [][]int{{0, 1}, {0}}, true},
+		{"Reassign attributes along a path", [][]int{{0, 1}, {1, 2}, {0}}, true},
+		{"Reassign attributes along a long path", [][]int{{0, 1}, {1, 2}, {2, 3}, {0}}, true},
+		{"Two attributes match only the same attribute", [][]int{{0}, {0}, {1, 2}}, false},
+		{"An attribute matches nothing", [][]int{{0, 1}, {0, 1}, {}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xr := make(rdnSET, len(tt.edges))
+			yr := make(rdnSET, len(tt.edges))
+			for i := range tt.edges {
+				xr[i] = Attribute{Oid: asn1.ObjectIdentifier{1, 2, i}}
+				yr[i] = Attribute{Oid: asn1.ObjectIdentifier{3, 4, i}}
+			}
+			compared := make(map[[2]int]int)
+			match := func(x Attribute, y Attribute) (bool, error) {
+				i, j := x.Oid[2], y.Oid[2]
+				compared[[2]int{i, j}]++
+				for _, e := range tt.edges[i] {
+					if e == j {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+			gotResult, err := compareRelativeDistinguishedName(xr, yr, match)
+			if err != nil {
+				t.Fatalf("compareRelativeDistinguishedName() error = %v", err)
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("compareRelativeDistinguishedName() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			for pair, n := range compared {
+				if n != 1 {
+					t.Errorf("compareRelativeDistinguishedName() compared %v %d times, want once", pair, n)
+				}
+			}
+			if gotResult, err = compareRelativeDistinguishedNameConstantTime(xr, yr, match); err != nil || gotResult != tt.wantResult {
+				t.Errorf("compareRelativeDistinguishedNameConstantTime() = %v, %v, want %v", gotResult, err, tt.wantResult)
+			}
+		})
+	}
+}
+
 func Test_findMatchedAttribute(t *testing.T) {
 	type args struct {
-		atv attribute
+		atv Attribute
 		r   rdnSET
 	}
 	tests := []struct {
@@ -296,17 +762,17 @@ func Test_findMatchedAttribute(t *testing.T) {
 		wantRest   rdnSET
 		wantErr    bool
 	}{
-		{"RDN has 1 elements and 1 match", args{atv: pAtv, r: []attribute{pAtv}}, true, []attribute{}, false},
-		{"RDN has 1 elements and No match", args{atv: pAtv, r: []attribute{bmpAtv}}, false, []attribute{bmpAtv}, false},
-		{"RDN has 2 elements and 1 match", args{atv: pAtv, r: []attribute{pAtv, pAtv}}, true, []attribute{pAtv}, false},
-		{"RDN has 2 elements and 1 match", args{atv: pAtv, r: []attribute{utf8Atv, pAtv}}, true, []attribute{pAtv}, false},
-		{"RDN has 3 elements and 1 match", args{atv: pAtv, r: []attribute{utf8Atv, pAtv, pAtv}}, true, []attribute{pAtv, pAtv}, false},
-		{"RDN has 2 elements and No match", args{atv: ia5Atv, r: []attribute{pAtv, pAtv}}, false, []attribute{pAtv, pAtv}, false},
-		{"RDN has 2 elements and 1 is broken", args{atv: pAtv, r: []attribute{ia5Atv, brokenAtv}}, false, nil, true},
+		{"RDN has 1 elements and 1 match", args{atv: pAtv, r: []Attribute{pAtv}}, true, []Attribute{}, false},
+		{"RDN has 1 elements and No match", args{atv: pAtv, r: []Attribute{bmpAtv}}, false, []Attribute{bmpAtv}, false},
+		{"RDN has 2 elements and 1 match", args{atv: pAtv, r: []Attribute{pAtv, pAtv}}, true, []Attribute{pAtv}, false},
+		{"RDN has 2 elements and 1 match", args{atv: pAtv, r: []Attribute{utf8Atv, pAtv}}, true, []Attribute{pAtv}, false},
+		{"RDN has 3 elements and 1 match", args{atv: pAtv, r: []Attribute{utf8Atv, pAtv, pAtv}}, true, []Attribute{pAtv, pAtv}, false},
+		{"RDN has 2 elements and No match", args{atv: ia5Atv, r: []Attribute{pAtv, pAtv}}, false, []Attribute{pAtv, pAtv}, false},
+		{"RDN has 2 elements and 1 is broken", args{atv: pAtv, r: []Attribute{ia5Atv, brokenAtv}}, false, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotResult, gotRest, err := findMatchedAttribute(tt.args.atv, tt.args.r)
+			gotResult, gotRest, err := findMatchedAttribute(tt.args.atv, tt.args.r, compareAttribute)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("findMatchedAttribute() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -332,12 +798,12 @@ func Test_removeAttribute1(t *testing.T) {
 		wantResult rdnSET
 		wantErr    bool
 	}{
-		{"Remove element[0] from 2 elements", args{index: 0, r: []attribute{utf8Atv, pAtv}}, []attribute{pAtv}, false},
-		{"Remove element[0] from 1 element", args{index: 0, r: []attribute{utf8Atv}}, []attribute{}, false},
-		{"Remove element[1] from 1 element", args{index: 1, r: []attribute{utf8Atv}}, nil, true},
-		{"Remove element[-1] from 1 element", args{index: -1, r: []attribute{utf8Atv}}, nil, true},
-		{"Remove element[1] from 2 elements", args{index: 1, r: []attribute{utf8Atv, pAtv}}, []attribute{utf8Atv}, false},
-		{"Remove element[1] from 3 elements", args{index: 1, r: []attribute{utf8Atv, pAtv, bmpAtv}}, []attribute{utf8Atv, bmpAtv}, false},
+		{"Remove element[0] from 2 elements", args{index: 0, r: []Attribute{utf8Atv, pAtv}}, []Attribute{pAtv}, false},
+		{"Remove element[0] from 1 element", args{index: 0, r: []Attribute{utf8Atv}}, []Attribute{}, false},
+		{"Remove element[1] from 1 element", args{index: 1, r: []Attribute{utf8Atv}}, nil, true},
+		{"Remove element[-1] from 1 element", args{index: -1, r: []Attribute{utf8Atv}}, nil, true},
+		{"Remove element[1] from 2 elements", args{index: 1, r: []Attribute{utf8Atv, pAtv}}, []Attribute{utf8Atv}, false},
+		{"Remove element[1] from 3 elements", args{index: 1, r: []Attribute{utf8Atv, pAtv, bmpAtv}}, []Attribute{utf8Atv, bmpAtv}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -353,11 +819,101 @@ func Test_removeAttribute1(t *testing.T) {
 	}
 }
 
+func TestCompare_IA5StringContent(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+	}{
+		{"Same", args{issuer: dn98b, subject: dn98b}, true},
+		{"Primitive and constructed form", args{issuer: dn98b, subject: dn99b}, true},
+		{"Constructed and primitive form", args{issuer: dn99b, subject: dn98b}, true},
+		{"Upper/Lower case characters", args{issuer: dn98b, subject: dn100b}, false},
+		{"Constructed form, Upper/Lower case characters", args{issuer: dn99b, subject: dn100b}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []*Comparer{NewComparer(), NewComparer(WithConstantTime())} {
+				gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+				if err != nil {
+					t.Fatalf("Compare() error = %v", err)
+				}
+				if gotResult != tt.wantResult {
+					t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+				}
+			}
+
+			ci, err := Canonicalize(tt.args.issuer)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cs, err := Canonicalize(tt.args.subject)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(ci) == string(cs); got != tt.wantResult {
+				t.Errorf("Canonicalize() equality = %v, want %v", got, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestCompare_PersonName(t *testing.T) {
+	person := mustMarshalString(t, "givenName=Anna Maria+SN=Schmidt+initials=A.M.+SERIALNUMBER=PNODE-12345,O=Example GmbH,C=DE")
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+	}{
+		{"Same", args{issuer: person, subject: person}, true},
+		{"Other encodings, case, spaces and order", args{issuer: person, subject: dn106b}, true},
+		{"Reverse", args{issuer: dn106b, subject: person}, true},
+		{"Different initials", args{issuer: person, subject: mustMarshalString(t, "givenName=Anna Maria+SN=Schmidt+initials=A.K.+SERIALNUMBER=PNODE-12345,O=Example GmbH,C=DE")}, false},
+		{"Different serialNumber", args{issuer: person, subject: mustMarshalString(t, "givenName=Anna Maria+SN=Schmidt+initials=A.M.+SERIALNUMBER=PNODE-12346,O=Example GmbH,C=DE")}, false},
+		{"Missing initials", args{issuer: person, subject: mustMarshalString(t, "givenName=Anna Maria+SN=Schmidt+SERIALNUMBER=PNODE-12345,O=Example GmbH,C=DE")}, false},
+		{"Separate RDNs", args{issuer: person, subject: mustMarshalString(t, "givenName=Anna Maria+SN=Schmidt,initials=A.M.+SERIALNUMBER=PNODE-12345,O=Example GmbH,C=DE")}, false},
+		{"Initials in another type", args{issuer: person, subject: mustMarshalString(t, "givenName=Anna Maria+SN=Schmidt+CN=A.M.+SERIALNUMBER=PNODE-12345,O=Example GmbH,C=DE")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []*Comparer{NewComparer(), NewComparer(WithConstantTime())} {
+				gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+				if err != nil {
+					t.Fatalf("Compare() error = %v", err)
+				}
+				if gotResult != tt.wantResult {
+					t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+				}
+			}
+
+			ci, err := Canonicalize(tt.args.issuer)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cs, err := Canonicalize(tt.args.subject)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(ci) == string(cs); got != tt.wantResult {
+				t.Errorf("Canonicalize() equality = %v, want %v", got, tt.wantResult)
+			}
+		})
+	}
+}
+
 func Test_compareAttribute(t *testing.T) {
 
 	type args struct {
-		x attribute
-		y attribute
+		x Attribute
+		y Attribute
 	}
 	tests := []struct {
 		name       string
@@ -366,9 +922,9 @@ func Test_compareAttribute(t *testing.T) {
 		wantErr    bool
 	}{
 		//Add isProhibit Error case
-		{"Different OID", args{x: attribute{Oid: oidCountry}, y: attribute{Oid: oidLocality}}, false, false},
-		{"Broken String x", args{x: brokenAtv, y: attribute{Oid: oidOrganization}}, false, true},
-		{"Broken String y", args{x: attribute{Oid: oidOrganization}, y: brokenAtv}, false, true},
+		{"Different OID", args{x: Attribute{Oid: oidCountry}, y: Attribute{Oid: oidLocality}}, false, false},
+		{"Broken String x", args{x: brokenAtv, y: Attribute{Oid: oidOrganization}}, false, true},
+		{"Broken String y", args{x: Attribute{Oid: oidOrganization}, y: brokenAtv}, false, true},
 		{"Wrong Encode domainComponent x", args{x: wrongDcAtv, y: ia5Atv}, false, true},
 		{"Wrong Encode domainComponent y", args{x: ia5Atv, y: wrongDcAtv}, false, true},
 		{"Compare domainComponent", args{x: ia5Atv, y: ia5Atv}, true, false},
@@ -463,10 +1019,43 @@ func Test_compareByCaseIgnoreMatch(t *testing.T) {
 		{" foo ,foo", args{" foo ", "foo"}, true, false},
 		{"foo bar, Foo  bar ", args{"foo bar", "Foo  bar "}, true, false},
 		{"漢字, 漢字　　", args{"漢字", " 漢字　　"}, true, false},
+		{"Full-width Latin, ASCII", args{"ＡＢＣ", "ABC"}, true, false},
+		{"Full-width lower case Latin, ASCII upper case", args{"ａｂｃ", "ABC"}, true, false},
+		{"Full-width digits, ASCII digits", args{"１２３", "123"}, true, false},
+		{"Full-width Latin, Different ASCII", args{"ＡＢＣ", "ABD"}, false, false},
+		{"Ideographic space, Space", args{"ABC　DEF", "ABC DEF"}, true, false},
+		{"Ideographic spaces, Spaces", args{"　ABC　　DEF　", "ABC  DEF"}, true, false},
+		{"Half-width katakana, Full-width katakana", args{"ﾃｽﾄ", "テスト"}, true, false},
+		{"Half-width katakana with voiced sound mark, Full-width katakana", args{"ｶﾞｷﾞﾊﾟ", "ガギパ"}, true, false},
+		{"Half-width katakana with voiced sound mark, Unvoiced full-width katakana", args{"ｶﾞ", "カ"}, false, false},
+		{"Half-width katakana, Hiragana", args{"ﾃｽﾄ", "てすと"}, false, false},
+		{"Half-width katakana, Kanji", args{"ｶﾌﾞｼｷｶﾞｲｼｬ", "株式会社"}, false, false},
+		{"Half-width small katakana and prolonged sound mark, Full-width katakana", args{"ｷｯﾁｮｰ", "キッチョー"}, true, false},
+		{"Half-width katakana with semi-voiced sound mark, Voiced full-width katakana", args{"ﾊﾟ", "バ"}, false, false},
+		{"Half-width katakana, Full-width katakana in mixed script", args{"ｱｲｳ商事ＡＢＣ", "アイウ商事abc"}, true, false},
+		{"Half-width katakana middle dot and brackets, Full-width", args{"｢ｱ･ｲ｣", "「ア・イ」"}, true, false},
+		{"Half-width ideographic full stop, Ideographic full stop", args{"ｶﾌﾞ｡", "カブ。"}, true, false},
+		{"Full-width katakana, Hiragana", args{"テスト", "てすと"}, false, false},
+		{"Full-width Latin mixed case, ASCII", args{"Ｔｏｋｙｏ", "TOKYO"}, true, false},
+		{"Full-width punctuations, ASCII", args{"Ａ－Ｂ（Ｃ）＆！", "a-b(c)&!"}, true, false},
+		{"Full-width Latin, Half-width katakana", args{"ＡＢＣ", "ｱﾌﾞｸ"}, false, false},
+		{"CJK compatibility ideograph, Unified ideograph", args{"\uf900", "\u8c48"}, true, false},
+		{"Half-width Hangul, Hangul compatibility jamo", args{"\uffa1", "\u3131"}, true, false},
+		{"Composed, Decomposed", args{"Jos\u00e9", "Jose\u0301"}, true, false},
+		{"Composed upper case, Decomposed lower case", args{"JOS\u00c9", "jose\u0301"}, true, false},
+		{"Decomposed, Without combining character", args{"Jose\u0301", "Jose"}, false, false},
+		{"Hangul syllable, Hangul jamo", args{"\uac01", "\u1100\u1161\u11a8"}, true, false},
+		{"Hangul syllable, Hangul jamo LV and T", args{"\uac01", "\uac00\u11a8"}, true, false},
+		{"Hangul syllable, Hangul jamo without T", args{"\uac01", "\u1100\u1161"}, false, false},
+		{"Three code points, Composed", args{"e\u0302\u0323", "\u1ec7"}, true, false},
+		{"Three code points, Combining characters in the other order", args{"e\u0323\u0302", "\u1ec7"}, true, false},
+		{"Three code points, Partially composed", args{"\u00ea\u0323", "\u1ec7"}, true, false},
+		{"Two combining characters followed by ASCII, Composed", args{"u\u0308\u0304x", "\u01d6x"}, true, false},
+		{"Composed, Different combining character", args{"\u1ec7", "\u1ec5"}, false, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotResult, err := compareByCaseIgnoreMatch(tt.args.s, tt.args.t)
+			gotResult, err := compareByCaseIgnoreMatch(DefaultStringPreparer, tt.args.s, tt.args.t)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("compareByCaseIgnoreMatch() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -505,6 +1094,34 @@ func Test_toString(t *testing.T) {
 	case2, _ := hex.DecodeString("0C0141")
 	case3, _ := hex.DecodeString("160141")
 	case4, _ := hex.DecodeString("16014141")
+	case5, _ := hex.DecodeString("1E06006100620063") //BMPString "abc"
+	case6, _ := hex.DecodeString("1E04D83DDE00")     //BMPString surrogate pair(U+1F600)
+	case7, _ := hex.DecodeString("1E04D83D0061")     //BMPString lone high surrogate
+	case8, _ := hex.DecodeString("1E040061DE00")     //BMPString lone low surrogate
+
+	case9, _ := hex.DecodeString("330b040345786104046d706c65")                    //PrintableString in the constructed form
+	case10, _ := hex.DecodeString("330f24070402457804016104046d706c65")           //PrintableString in the nested constructed form
+	case11, _ := hex.DecodeString("3300")                                         //empty PrintableString in the constructed form
+	case12, _ := hex.DecodeString("330b130345786104046d706c65")                   //PrintableString segment in the constructed form
+	case13, _ := hex.DecodeString("330a0403457861040a6d706c65")                   //broken segment
+	case14, _ := hex.DecodeString("331424122410240e240c240a24082406240424020400") //segments nested 9 levels
+	case15, _ := hex.DecodeString("30050c03414243")                               //SEQUENCE of UTF8String
+	case16, _ := hex.DecodeString("1a03414062")                                   //VisibleString "A@b"
+	case17, _ := hex.DecodeString("1a0241e9")                                     //VisibleString with non-ASCII byte
+	case18, _ := hex.DecodeString("1a02410a")                                     //VisibleString with LF
+	case19, _ := hex.DecodeString("1b04436166e9")                                 //GeneralString "Café" in ISO/IEC 8859-1
+	case20, _ := hex.DecodeString("1b041b284241")                                 //GeneralString with escape sequence
+	case21, _ := hex.DecodeString("3a0704014104024062")                           //VisibleString in the constructed form
+	case22, _ := hex.DecodeString("13032a2641")                                   //PrintableString "*&A"
+	case23, _ := hex.DecodeString("130141" + "00")                                //trailing data
+	case24, _ := hex.DecodeString("16024180")                                     //IA5String with non-ASCII byte
+	case25, _ := hex.DecodeString("12023141")                                     //NumericString with letter
+	case26, _ := hex.DecodeString("0c02c328")                                     //UTF8String with invalid UTF-8
+	case27, _ := hex.DecodeString("1404436166e9")                                 //TeletexString "Café"
+	case28, _ := hex.DecodeString("1e03006100")                                   //BMPString of odd length
+	case29, _ := hex.DecodeString("1e0400610000")                                 //BMPString with terminator
+	case30, _ := hex.DecodeString("1e02fffe")                                     //BMPString noncharacter
+	case31, _ := hex.DecodeString("13810141")                                     //non-minimal length
 	type args struct {
 		src []byte
 	}
@@ -518,6 +1135,33 @@ func Test_toString(t *testing.T) {
 		{"UTF8String", args{case2}, "A", false},
 		{"IA5String", args{case3}, "A", false},
 		{"Broken Data", args{case4}, "", true},
+		{"BMPString", args{case5}, "abc", false},
+		{"BMPString surrogate pair", args{case6}, "", true},
+		{"BMPString lone high surrogate", args{case7}, "", true},
+		{"BMPString lone low surrogate", args{case8}, "", true},
+		{"Constructed form", args{case9}, "Example", false},
+		{"Nested constructed form", args{case10}, "Example", false},
+		{"Empty constructed form", args{case11}, "", false},
+		{"Constructed form with PrintableString segment", args{case12}, "", true},
+		{"Constructed form with broken segment", args{case13}, "", true},
+		{"Constructed form with too deeply nested segments", args{case14}, "", true},
+		{"SEQUENCE", args{case15}, "", true},
+		{"VisibleString", args{case16}, "A@b", false},
+		{"VisibleString non-ASCII", args{case17}, "", true},
+		{"VisibleString control character", args{case18}, "", true},
+		{"GeneralString", args{case19}, "Café", false},
+		{"GeneralString escape sequence", args{case20}, "", true},
+		{"VisibleString in the constructed form", args{case21}, "A@b", false},
+		{"PrintableString asterisk and ampersand", args{case22}, "*&A", false},
+		{"Trailing data", args{case23}, "", true},
+		{"IA5String non-ASCII", args{case24}, "", true},
+		{"NumericString letter", args{case25}, "", true},
+		{"UTF8String invalid UTF-8", args{case26}, "", true},
+		{"TeletexString", args{case27}, "Café", false},
+		{"BMPString odd length", args{case28}, "", true},
+		{"BMPString terminator", args{case29}, "a", false},
+		{"BMPString noncharacter", args{case30}, "", true},
+		{"Non-minimal length", args{case31}, "", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -533,6 +1177,22 @@ func Test_toString(t *testing.T) {
 	}
 }
 
+func Benchmark_toString(b *testing.B) {
+	values := make([][]byte, 0, 6)
+	for _, v := range []string{"130141", "0c0141", "160141", "1E06006100620063", "330b040345786104046d706c65", "1a03414062"} {
+		src, _ := hex.DecodeString(v)
+		values = append(values, src)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, src := range values {
+			if _, err := toString(src); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
 func Test_stringPrepare(t *testing.T) {
 	type args struct {
 		s string
@@ -549,6 +1209,11 @@ func Test_stringPrepare(t *testing.T) {
 		{"    foo bar   ", args{"foo bar"}, []rune(" foo  bar "), false},
 		{" foo            bar ", args{"foo bar"}, []rune(" foo  bar "), false},
 		{"パパ", args{"パパ"}, []rune(" パパ "), false},
+		{"Embedded NUL", args{"AB\x00C"}, []rune(" abc "), false},
+		{"Trailing NUL", args{"ABC\x00"}, []rune(" abc "), false},
+		{"C0 and C1 control characters", args{"A\x01B\x7fC\u0084D\u009f"}, []rune(" abcd "), false},
+		{"NEXT LINE", args{"AB\u0085C"}, []rune(" ab  c "), false},
+		{"Only NUL", args{"\x00"}, []rune("  "), false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -563,3 +1228,34 @@ func Test_stringPrepare(t *testing.T) {
 		})
 	}
 }
+
+//Test_ldapstrprepNormalize_WidthVariants confirms that the normalization step of the string preparation is NFKC,
+//which folds the width variants of CJK and Latin characters.
+func Test_ldapstrprepNormalize_WidthVariants(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"Half-width katakana", "ﾃｽﾄ", "テスト"},
+		{"Half-width katakana with voiced sound marks", "ｶﾞﾊﾟ", "ガパ"},
+		{"Half-width small katakana and prolonged sound mark", "ｯｰ", "ッー"},
+		{"Half-width CJK punctuations", "｢･｣｡", "「・」。"},
+		{"Full-width Latin", "ＡＢＣａｂｃ", "ABCabc"},
+		{"Full-width digits and punctuations", "１２３－（）", "123-()"},
+		{"CJK compatibility ideograph", "\uf900", "\u8c48"},
+		{"Half-width Hangul", "\uffa1", "\u1100"},
+		{"Kanji and full-width katakana are not changed", "漢字カナ", "漢字カナ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(ldapstrprep.Normalize([]rune(tt.s)))
+			if got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+			if nfkc := norm.NFKC.String(tt.s); got != nfkc {
+				t.Errorf("Normalize() = %q, NFKC = %q", got, nfkc)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+//Package dntest generates distinguished names for tests of packages which use dn, including property-based tests
+//and fuzz tests, and provides assertions which report the decisions of dn.CompareExplain on failure.
+package dntest
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"github.com/tardevnull/dn"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+//Attribute types generated by default.
+var (
+	OidCountryName         = asn1.ObjectIdentifier{2, 5, 4, 6}
+	OidStateOrProvinceName = asn1.ObjectIdentifier{2, 5, 4, 8}
+	OidLocalityName        = asn1.ObjectIdentifier{2, 5, 4, 7}
+	OidOrganizationName    = asn1.ObjectIdentifier{2, 5, 4, 10}
+	OidOrganizationalUnit  = asn1.ObjectIdentifier{2, 5, 4, 11}
+	OidCommonName          = asn1.ObjectIdentifier{2, 5, 4, 3}
+	OidDomainComponent     = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
+)
+
+//DefaultTypes are the attribute types which GenerateDN uses if Config.Types is empty.
+var DefaultTypes = []asn1.ObjectIdentifier{
+	OidCountryName,
+	OidStateOrProvinceName,
+	OidLocalityName,
+	OidOrganizationName,
+	OidOrganizationalUnit,
+	OidCommonName,
+}
+
+//Config configures the distinguished names generated by GenerateDN and GenerateEquivalentPair.
+//The zero value generates 1 to 5 single-valued RDNs of DefaultTypes, whose values are encoded in
+//PrintableString or UTF8String.
+type Config struct {
+	MinRDNs             int                     //minimum number of RDNs, 1 if 0
+	MaxRDNs             int                     //maximum number of RDNs, MinRDNs + 4 if less than MinRDNs
+	MaxAttributesPerRDN int                     //maximum number of attributes in each RDN, 1 if 0
+	MaxValueLength      int                     //maximum number of characters of each value, 16 if 0
+	Types               []asn1.ObjectIdentifier //attribute types chosen for the attributes, DefaultTypes if empty
+	//Encodings are the encodings chosen for the values, PrintableString and UTF8String if empty.
+	//The values of countryName are always encoded in PrintableString, and the values of domainComponent in IA5String.
+	Encodings []dn.Encoding
+	//NonASCII makes the values encoded in UTF8String or BMPString contain non-ASCII characters.
+	NonASCII bool
+}
+
+//printableCharacters are the characters of the generated values, which PrintableString can encode.
+const printableCharacters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789 '()+,-./:=?"
+
+//nonASCIICharacters are the characters added to the values if Config.NonASCII is true. They are in the BMP,
+//and the string preparation does not change them.
+const nonASCIICharacters = "éñøΩЖあ日本語"
+
+//rdnSET is the RDN generated, which encoding/asn1 encodes as SET OF in DER order.
+type rdnSET []dn.Attribute
+
+//GenerateDN returns a distinguished name generated by r according to cfg, encoded in DER.
+//The same r and cfg generate the same distinguished name. GenerateDN panics if cfg has an unsupported encoding.
+func GenerateDN(r *rand.Rand, cfg Config) []byte {
+	return marshal(generate(r, cfg))
+}
+
+//GenerateEquivalentPair returns a distinguished name generated by r according to cfg and another encoding of it,
+//both encoded in DER, which match by dn.Compare with the default options.
+//The second differs from the first in the encodings, the case and the insignificant spaces of the values
+//where the matching rules allow. They are often, but not always, different in bytes.
+func GenerateEquivalentPair(r *rand.Rand, cfg Config) (a []byte, b []byte) {
+	d := generate(r, cfg)
+	e := make([]rdnSET, len(d))
+	for i, rdn := range d {
+		e[i] = make(rdnSET, len(rdn))
+		for j, atv := range rdn {
+			e[i][j] = equivalentAttribute(r, atv)
+		}
+	}
+	return marshal(d), marshal(e)
+}
+
+//generate returns the RDNs generated by r according to cfg.
+func generate(r *rand.Rand, cfg Config) []rdnSET {
+	cfg = normalize(cfg)
+	n := cfg.MinRDNs + r.Intn(cfg.MaxRDNs-cfg.MinRDNs+1)
+	d := make([]rdnSET, n)
+	for i := range d {
+		d[i] = make(rdnSET, 1+r.Intn(cfg.MaxAttributesPerRDN))
+		for j := range d[i] {
+			d[i][j] = generateAttribute(r, cfg)
+		}
+	}
+	return d
+}
+
+//normalize returns cfg whose zero fields are replaced with the defaults.
+func normalize(cfg Config) Config {
+	if cfg.MinRDNs <= 0 {
+		cfg.MinRDNs = 1
+	}
+	if cfg.MaxRDNs < cfg.MinRDNs {
+		cfg.MaxRDNs = cfg.MinRDNs + 4
+	}
+	if cfg.MaxAttributesPerRDN <= 0 {
+		cfg.MaxAttributesPerRDN = 1
+	}
+	if cfg.MaxValueLength <= 0 {
+		cfg.MaxValueLength = 16
+	}
+	if len(cfg.Types) == 0 {
+		cfg.Types = DefaultTypes
+	}
+	if len(cfg.Encodings) == 0 {
+		cfg.Encodings = []dn.Encoding{dn.EncodingPrintableString, dn.EncodingUTF8String}
+	}
+	return cfg
+}
+
+//generateAttribute returns an attribute generated by r according to cfg.
+func generateAttribute(r *rand.Rand, cfg Config) dn.Attribute {
+	oid := cfg.Types[r.Intn(len(cfg.Types))]
+	switch {
+	case oid.Equal(OidCountryName):
+		return encode(oid, randomString(r, "ABCDEFGHIJKLMNOPQRSTUVWXYZ", 2), dn.EncodingPrintableString)
+	case oid.Equal(OidDomainComponent):
+		return encode(oid, randomString(r, "abcdefghijklmnopqrstuvwxyz0123456789", 1+r.Intn(cfg.MaxValueLength)), dn.EncodingIA5String)
+	}
+	e := cfg.Encodings[r.Intn(len(cfg.Encodings))]
+	characters := printableCharacters
+	if cfg.NonASCII && (e == dn.EncodingUTF8String || e == dn.EncodingBMPString) {
+		characters += nonASCIICharacters
+	}
+	//the first character is a letter, so that the value is not empty after the string preparation
+	s := randomString(r, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", 1) + randomString(r, characters, r.Intn(cfg.MaxValueLength))
+	return encode(oid, s, e)
+}
+
+//equivalentAttribute returns atv whose value is changed by r so that it matches atv by the default options.
+func equivalentAttribute(r *rand.Rand, atv dn.Attribute) dn.Attribute {
+	e := dn.Encoding(atv.RawValue.Tag)
+	isDC := atv.Oid.Equal(OidDomainComponent)
+	if !isDC && e != dn.EncodingPrintableString && e != dn.EncodingUTF8String {
+		//the values in the other encodings are compared by binary comparison, or by case exact match
+		return atv
+	}
+	var s string
+	if _, err := asn1.Unmarshal(atv.RawValue.FullBytes, &s); err != nil {
+		panic(fmt.Sprintf("dntest: cannot decode generated value: %v", err))
+	}
+	if isDC {
+		//domainComponent values are compared by case-insensitive exact match
+		return encode(atv.Oid, flipCase(r, s), dn.EncodingIA5String)
+	}
+	//PrintableString and UTF8String values are compared by caseIgnoreMatch
+	if r.Intn(2) == 0 {
+		if e == dn.EncodingUTF8String && isPrintable(s) {
+			e = dn.EncodingPrintableString
+		} else {
+			e = dn.EncodingUTF8String
+		}
+	}
+	s = flipCase(r, s)
+	if r.Intn(2) == 0 {
+		s = " " + s
+	}
+	if r.Intn(2) == 0 {
+		s += " "
+	}
+	if r.Intn(2) == 0 {
+		s = strings.Replace(s, " ", "  ", 1)
+	}
+	return encode(atv.Oid, s, e)
+}
+
+//encode returns the attribute of type oid whose value is s encoded in e.
+func encode(oid asn1.ObjectIdentifier, s string, e dn.Encoding) dn.Attribute {
+	rv, err := dn.EncodingPolicy{oid.String(): e}.Encode(oid, s)
+	if err != nil {
+		panic(fmt.Sprintf("dntest: %v", err))
+	}
+	return dn.Attribute{Oid: oid, RawValue: rv}
+}
+
+//marshal encodes d as Distinguished Name.
+func marshal(d []rdnSET) []byte {
+	b, err := asn1.Marshal(d)
+	if err != nil {
+		panic(fmt.Sprintf("dntest: %v", err))
+	}
+	return b
+}
+
+//randomString returns a string of n characters chosen by r from characters.
+func randomString(r *rand.Rand, characters string, n int) string {
+	runes := []rune(characters)
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteRune(runes[r.Intn(len(runes))])
+	}
+	return sb.String()
+}
+
+//flipCase returns s whose ASCII letters are converted to the other case at random.
+func flipCase(r *rand.Rand, s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if ('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z') && r.Intn(2) == 0 {
+			b[i] = c ^ 0x20
+		}
+	}
+	return string(b)
+}
+
+//isPrintable reports whether PrintableString can encode s.
+func isPrintable(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune(printableCharacters, c) {
+			return false
+		}
+	}
+	return true
+}
+
+//AssertEqual reports an error to t unless a and b match by dn.Compare, with the decisions of dn.CompareExplain.
+func AssertEqual(t testing.TB, a []byte, b []byte) {
+	t.Helper()
+	result, err := dn.Compare(a, b)
+	if err != nil {
+		t.Errorf("dn.Compare(%x, %x) error = %v", a, b, err)
+		return
+	}
+	if !result {
+		t.Errorf("dn.Compare(%x, %x) = false, want true\n%s", a, b, explain(a, b))
+	}
+}
+
+//AssertNotEqual reports an error to t unless a and b do not match by dn.Compare, with the decisions of
+//dn.CompareExplain.
+func AssertNotEqual(t testing.TB, a []byte, b []byte) {
+	t.Helper()
+	result, err := dn.Compare(a, b)
+	if err != nil {
+		t.Errorf("dn.Compare(%x, %x) error = %v", a, b, err)
+		return
+	}
+	if result {
+		t.Errorf("dn.Compare(%x, %x) = true, want false\n%s", a, b, explain(a, b))
+	}
+}
+
+//explain returns the decisions of dn.CompareExplain for a and b, one RDN per line.
+func explain(a []byte, b []byte) string {
+	_, decisions, err := dn.CompareExplain(a, b)
+	if err != nil {
+		return fmt.Sprintf("CompareExplain error: %v", err)
+	}
+	var sb strings.Builder
+	for _, d := range decisions {
+		fmt.Fprintf(&sb, "RDN %d: matched %v", d.RDN, d.Matched)
+		if d.Reason != "" {
+			fmt.Fprintf(&sb, " (%s)", d.Reason)
+		}
+		for _, ad := range d.Attributes {
+			fmt.Fprintf(&sb, "\n  %s: issuer %d, subject %d, %s, matched %v", ad.Type, ad.Issuer, ad.Subject, ad.Rule, ad.Matched)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
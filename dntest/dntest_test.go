@@ -0,0 +1,111 @@
+package dntest
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"github.com/tardevnull/dn"
+	"math/rand"
+	"testing"
+)
+
+func TestGenerateDN(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		minRDNs  int
+		maxRDNs  int
+		maxAtvs  int
+		wantTags []int
+	}{
+		{"Zero value", Config{}, 1, 5, 1, []int{asn1.TagPrintableString, asn1.TagUTF8String}},
+		{"Fixed number of RDNs", Config{MinRDNs: 3, MaxRDNs: 3}, 3, 3, 1, []int{asn1.TagPrintableString, asn1.TagUTF8String}},
+		{"Multi-valued RDNs", Config{MaxAttributesPerRDN: 3}, 1, 5, 3, []int{asn1.TagPrintableString, asn1.TagUTF8String}},
+		{"BMPString", Config{Types: []asn1.ObjectIdentifier{OidOrganizationName}, Encodings: []dn.Encoding{dn.EncodingBMPString}, NonASCII: true}, 1, 5, 1, []int{asn1.TagBMPString}},
+		{"Domain components", Config{Types: []asn1.ObjectIdentifier{OidDomainComponent}, Encodings: []dn.Encoding{dn.EncodingUTF8String}}, 1, 5, 1, []int{asn1.TagIA5String}},
+		{"Country names", Config{Types: []asn1.ObjectIdentifier{OidCountryName}, Encodings: []dn.Encoding{dn.EncodingUTF8String}}, 1, 5, 1, []int{asn1.TagPrintableString}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < 100; i++ {
+				b := GenerateDN(r, tt.cfg)
+				var d []rdnSET
+				if _, err := asn1.Unmarshal(b, &d); err != nil {
+					t.Fatalf("GenerateDN() = %x, error = %v", b, err)
+				}
+				if len(d) < tt.minRDNs || len(d) > tt.maxRDNs {
+					t.Fatalf("GenerateDN() = %x, %d RDNs, want %d to %d", b, len(d), tt.minRDNs, tt.maxRDNs)
+				}
+				for _, rdn := range d {
+					if len(rdn) > tt.maxAtvs {
+						t.Fatalf("GenerateDN() = %x, %d attributes in RDN, want at most %d", b, len(rdn), tt.maxAtvs)
+					}
+					for _, atv := range rdn {
+						if !containsTag(tt.wantTags, atv.RawValue.Tag) {
+							t.Fatalf("GenerateDN() = %x, tag %d, want one of %v", b, atv.RawValue.Tag, tt.wantTags)
+						}
+					}
+				}
+				AssertEqual(t, b, b)
+			}
+		})
+	}
+}
+
+func TestGenerateDN_Deterministic(t *testing.T) {
+	cfg := Config{MaxAttributesPerRDN: 2, NonASCII: true}
+	a := GenerateDN(rand.New(rand.NewSource(7)), cfg)
+	b := GenerateDN(rand.New(rand.NewSource(7)), cfg)
+	if !bytes.Equal(a, b) {
+		t.Errorf("GenerateDN() = %x and %x, want the same for the same seed", a, b)
+	}
+}
+
+func TestGenerateEquivalentPair(t *testing.T) {
+	configs := []Config{
+		{},
+		{MaxAttributesPerRDN: 3},
+		{NonASCII: true},
+		{Types: append([]asn1.ObjectIdentifier{OidDomainComponent}, DefaultTypes...)},
+		{Encodings: []dn.Encoding{dn.EncodingPrintableString, dn.EncodingUTF8String, dn.EncodingBMPString, dn.EncodingIA5String}, NonASCII: true},
+	}
+	for i, cfg := range configs {
+		r := rand.New(rand.NewSource(int64(i)))
+		different := 0
+		for j := 0; j < 200; j++ {
+			a, b := GenerateEquivalentPair(r, cfg)
+			AssertEqual(t, a, b)
+			AssertEqual(t, b, a)
+			if !bytes.Equal(a, b) {
+				different++
+			}
+		}
+		if different == 0 {
+			t.Errorf("GenerateEquivalentPair() with config %d generated no pairs different in bytes", i)
+		}
+	}
+}
+
+func TestAssertNotEqual(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := GenerateDN(r, Config{MinRDNs: 2, MaxRDNs: 2})
+	b := GenerateDN(r, Config{MinRDNs: 3, MaxRDNs: 3})
+	AssertNotEqual(t, a, b)
+}
+
+func Test_explain(t *testing.T) {
+	a := GenerateDN(rand.New(rand.NewSource(1)), Config{MinRDNs: 2, MaxRDNs: 2})
+	b := GenerateDN(rand.New(rand.NewSource(2)), Config{MinRDNs: 2, MaxRDNs: 2})
+	if got := explain(a, b); got == "" {
+		t.Errorf("explain() = %q, want decisions", got)
+	}
+}
+
+func containsTag(tags []int, tag int) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
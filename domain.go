@@ -0,0 +1,207 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//DCsFromDomain returns the domainComponent attributes which represent domain, e.g. "example.com" becomes
+//DC=com and DC=example. The attributes are in the order of RDNSequence, which starts with the root,
+//so each of them becomes an RDN in the order of the slice. A trailing dot of domain is ignored.
+//
+//The labels are converted to lower case, because domainComponent values are compared case-insensitively.
+//DCsFromDomain returns nil if domain is empty, has an empty label or has characters which IA5String cannot encode.
+//FromPkixName converts its domain in the same way.
+func DCsFromDomain(domain string) []Attribute {
+	labels, err := domainComponents(domain)
+	if err != nil || len(labels) == 0 {
+		return nil
+	}
+	result := make([]Attribute, len(labels))
+	for i, label := range labels {
+		if result[i], err = newStringAttribute(cloneOid(oidDomainComponent), label, EncodingIA5String); err != nil {
+			return nil
+		}
+	}
+	return result
+}
+
+//domainComponents splits domain into the values of domainComponent ordered from the top-level label, e.g.
+//"Example.com" to ["com", "example"]. A trailing dot of domain is ignored, and the labels are converted to lower case.
+//It returns nil if domain is empty, or an error if domain has an empty label or a label which IA5String cannot encode.
+func domainComponents(domain string) (labels []string, err error) {
+	//https://tools.ietf.org/html/rfc2247#section-4
+	//The domain name is converted to a distinguished name by creating a domainComponent RDN for each label,
+	//the most significant label first in RDNSequence.
+	if domain == "" {
+		return nil, nil
+	}
+	parts := strings.Split(strings.TrimSuffix(domain, "."), ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] == "" {
+			return nil, fmt.Errorf("dn: domain %q has an empty label", domain)
+		}
+		if !isLabel(parts[i]) {
+			return nil, fmt.Errorf("dn: domain %q has a label which IA5String cannot encode", domain)
+		}
+		labels = append(labels, strings.ToLower(parts[i]))
+	}
+	return labels, nil
+}
+
+//DomainFromDCs returns the domain name which the domainComponent RDNs of d represent, in lower case,
+//e.g. "example.com" for "CN=abc,DC=Example,DC=com". ok is false if d has no domainComponent RDNs, or
+//they cannot be a domain name: they are not contiguous, some of them are multi-valued, or the values are not
+//labels encoded in IA5String.
+func DomainFromDCs(d *DN) (domain string, ok bool) {
+	if d == nil {
+		return "", false
+	}
+	var labels []string
+	end := -1 //index next to the last domainComponent RDN
+	for i, r := range d.rdns {
+		hasDC := false
+		for _, atv := range r {
+			if isDomainComponent(atv.Oid) {
+				hasDC = true
+			}
+		}
+		if !hasDC {
+			continue
+		}
+		if len(r) != 1 || (end >= 0 && end != i) {
+			return "", false
+		}
+		v := r[0].RawValue
+		if v.Class != asn1.ClassUniversal || v.Tag != asn1.TagIA5String {
+			return "", false
+		}
+		s, err := toString(v.FullBytes)
+		if err != nil || !isLabel(s) {
+			return "", false
+		}
+		labels = append(labels, strings.ToLower(s))
+		end = i + 1
+	}
+	if len(labels) == 0 {
+		return "", false
+	}
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	return strings.Join(labels, "."), true
+}
+
+//isLabel reports whether s can be a label of a domain name: a non-empty IA5String without dots.
+func isLabel(s string) bool {
+	return s != "" && !strings.Contains(s, ".") && isIA5String(s)
+}
+
+//WithLenientDomainComponent makes the Comparer accept domainComponent values which are encoded in other string types
+//than IA5String, such as PrintableString or UTF8String, if they consist of only IA5 characters.
+//The values are converted to IA5String before the comparison. By default, the comparison returns an error for them.
+//Whitespace in the converted values is compared literally, unless WithTrimDCWhitespace is also given.
+func WithLenientDomainComponent() Option {
+	return func(c *Comparer) {
+		c.lenientDC = true
+	}
+}
+
+//convertDomainComponents returns d whose domainComponent values are converted to IA5String.
+func convertDomainComponents(d dn) (result dn, err error) {
+	result = make(dn, len(d))
+	for i, r := range d {
+		result[i] = make(rdnSET, len(r))
+		for j, atv := range r {
+			if result[i][j], err = convertDomainComponent(atv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+//convertDomainComponent returns atv whose value is converted to IA5String if atv is domainComponent
+//encoded in another string type.
+func convertDomainComponent(atv Attribute) (result Attribute, err error) {
+	if !isDomainComponent(atv.Oid) || atv.RawValue.Tag == asn1.TagIA5String || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+		return atv, nil
+	}
+	var s string
+	if s, err = toString(atv.RawValue.FullBytes); err != nil {
+		return Attribute{}, err
+	}
+	if !isIA5String(s) {
+		return Attribute{}, fmt.Errorf("dn: domain component %q cannot be encoded as IA5String", s)
+	}
+	return newStringAttribute(atv.Oid, s, EncodingIA5String)
+}
+
+//WithTrimDCWhitespace makes the Comparer remove the leading and trailing whitespace of domainComponent values and
+//collapse the inner runs of whitespace into a single space before the case-insensitive exact match, e.g. " EXA  mple "
+//matches "exa mple" but not "example". By default, whitespace in domainComponent values is compared literally.
+//
+//domainComponent values are labels of DNS names, which never contain whitespace, but malformed certificates sometimes
+//have it. WithStrict rejects such values regardless of this option.
+func WithTrimDCWhitespace() Option {
+	return func(c *Comparer) {
+		c.trimDCWhitespace = true
+	}
+}
+
+//trimDomainComponents returns d whose domainComponent values are converted by trimDomainComponent.
+func trimDomainComponents(d dn) (result dn, err error) {
+	result = make(dn, len(d))
+	for i, r := range d {
+		result[i] = make(rdnSET, len(r))
+		for j, atv := range r {
+			if result[i][j], err = trimDomainComponent(atv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+//trimDomainComponent returns atv whose whitespace is trimmed and collapsed if atv is domainComponent encoded in IA5String.
+//The other values are returned as they are, so that the comparison handles them.
+func trimDomainComponent(atv Attribute) (result Attribute, err error) {
+	if !isDomainComponent(atv.Oid) || atv.RawValue.Class != asn1.ClassUniversal || atv.RawValue.Tag != asn1.TagIA5String {
+		return atv, nil
+	}
+	var s string
+	if s, err = toString(atv.RawValue.FullBytes); err != nil {
+		return Attribute{}, err
+	}
+	t := strings.Join(strings.Fields(s), " ")
+	if t == s {
+		return atv, nil
+	}
+	return newStringAttribute(atv.Oid, t, EncodingIA5String)
+}
+
+//lintDomainComponentWhitespace returns the findings for domainComponent values which contain whitespace.
+func lintDomainComponentWhitespace(d dn) (findings []Finding, err error) {
+	for i, r := range d {
+		for j, atv := range r {
+			if !isDomainComponent(atv.Oid) || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				return nil, err
+			}
+			if strings.IndexFunc(s, unicode.IsSpace) >= 0 {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("domain component contains whitespace: %q", s),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,261 @@
+package dn
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+var (
+	//DC=COM(IA5String),DC=Example(IA5String),CN=abc(UTF8String)
+	dnMixedCaseDCb, _ = hex.DecodeString("303c31133011060a0992268993f22c6401191603434f4d31173015060a0992268993f22c64011916074578616d706c65310c300a06035504030c03616263")
+	//DC=com(IA5String),O=x(UTF8String),DC=example(IA5String)
+	dnSeparatedDCb, _ = hex.DecodeString("303a31133011060a0992268993f22c6401191603636f6d310a3008060355040a0c017831173015060a0992268993f22c64011916076578616d706c65")
+	//DC=com(IA5String),DC=example(IA5String)+O=x(UTF8String)
+	dnMultiValuedDCb, _ = hex.DecodeString("303831133011060a0992268993f22c6401191603636f6d31213015060a0992268993f22c64011916076578616d706c653008060355040a0c0178")
+	//DC=com(IA5String),DC=a.b(IA5String)
+	dnDottedDCb, _ = hex.DecodeString("302a31133011060a0992268993f22c6401191603636f6d31133011060a0992268993f22c6401191603612e62")
+)
+
+func TestDCsFromDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		want   []string
+	}{
+		{"Two labels", "example.com", []string{"com", "example"}},
+		{"Upper case", "Example.COM", []string{"com", "example"}},
+		{"Trailing dot", "www.example.com.", []string{"com", "example", "www"}},
+		{"One label", "localhost", []string{"localhost"}},
+		{"Empty", "", nil},
+		{"Only dot", ".", nil},
+		{"Empty label", "example..com", nil},
+		{"Leading dot", ".example.com", nil},
+		{"Non-ASCII", "例え.jp", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DCsFromDomain(tt.domain)
+			var values []string
+			for _, atv := range got {
+				if !atv.Oid.Equal(oidDomainComponent) || atv.RawValue.Tag != asn1.TagIA5String {
+					t.Errorf("DCsFromDomain() attribute = %v, want domainComponent in IA5String", atv)
+				}
+				values = append(values, string(atv.RawValue.Bytes))
+			}
+			if !reflect.DeepEqual(values, tt.want) {
+				t.Errorf("DCsFromDomain() = %q, want %q", values, tt.want)
+			}
+		})
+	}
+}
+
+func Test_domainComponents(t *testing.T) {
+	type args struct {
+		domain string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantLabels []string
+		wantErr    bool
+	}{
+		{"example.com", args{"example.com"}, []string{"com", "example"}, false},
+		{"www.example.com.", args{"www.example.com."}, []string{"com", "example", "www"}, false},
+		{"Empty", args{""}, nil, false},
+		{"Empty label", args{"example..com"}, nil, true},
+		{"Upper case", args{"Example.COM"}, []string{"com", "example"}, false},
+		{"Only dot", args{"."}, nil, true},
+		{"Not IA5", args{"例.jp"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLabels, err := domainComponents(tt.args.domain)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("domainComponents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotLabels, tt.wantLabels) {
+				t.Errorf("domainComponents() gotLabels = %v, want %v", gotLabels, tt.wantLabels)
+			}
+		})
+	}
+}
+
+func TestDCsFromDomain_FromPkixName(t *testing.T) {
+	for _, domain := range []string{"Example.COM", "www.example.com.", "localhost"} {
+		t.Run(domain, func(t *testing.T) {
+			var d dn
+			for _, atv := range DCsFromDomain(domain) {
+				d = append(d, rdnSET{atv})
+			}
+			want, err := marshalDn(d)
+			if err != nil {
+				t.Fatalf("marshalDn() error = %v", err)
+			}
+			got, err := FromPkixName(pkix.Name{}, domain)
+			if err != nil {
+				t.Fatalf("FromPkixName() error = %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("FromPkixName() = %x, want %x by DCsFromDomain", got, want)
+			}
+		})
+	}
+}
+
+func TestDCsFromDomain_Compare(t *testing.T) {
+	//C=JP,DC=com,DC=example,CN=abc
+	d := dn{{parseAtv(hatv1)}}
+	for _, atv := range DCsFromDomain("EXAMPLE.com") {
+		d = append(d, rdnSET{atv})
+	}
+	d = append(d, rdnSET{parseAtv(hatv4)})
+	der, err := marshalDn(d)
+	if err != nil {
+		t.Fatalf("marshalDn() error = %v", err)
+	}
+	if result, err := Compare(dn17b, der); err != nil || !result {
+		t.Errorf("Compare() = %v, %v, want true", result, err)
+	}
+}
+
+func TestDomainFromDCs(t *testing.T) {
+	tests := []struct {
+		name       string
+		der        []byte
+		wantDomain string
+		wantOk     bool
+	}{
+		{"Domain components", dn17b, "example.com", true},
+		{"Upper/Lower case characters", dnMixedCaseDCb, "example.com", true},
+		{"No domain components", dn2b, "", false},
+		{"Not contiguous", dnSeparatedDCb, "", false},
+		{"Multi-valued RDN", dnMultiValuedDCb, "", false},
+		{"Label with dot", dnDottedDCb, "", false},
+		{"Wrong Encoding domain component", dn7b, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDN(tt.der)
+			if err != nil {
+				t.Fatalf("ParseDN() error = %v", err)
+			}
+			gotDomain, gotOk := DomainFromDCs(d)
+			if gotDomain != tt.wantDomain || gotOk != tt.wantOk {
+				t.Errorf("DomainFromDCs() = %q, %v, want %q, %v", gotDomain, gotOk, tt.wantDomain, tt.wantOk)
+			}
+		})
+	}
+	if _, ok := DomainFromDCs(nil); ok {
+		t.Errorf("DomainFromDCs(nil) ok = true, want false")
+	}
+}
+
+func TestWithLenientDomainComponent(t *testing.T) {
+	//C=JP(PrintableString),DC=例え(UTF8String)
+	dnNonIA5DCb, _ := hex.DecodeString("3025310b3009060355040613024a5031163014060a0992268993f22c6401190c06e4be8be38188")
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Wrong Encoding domain component", args{dn7b, dn7b}, true, false},
+		{"Wrong Encoding domain component, IA5String", args{dn7b, dn17b}, true, false},
+		{"Wrong Encoding domain component, Upper/Lower case characters", args{dn7b, dnUpperDCb}, true, false},
+		{"Wrong Encoding domain component, Different DN", args{dn7b, dn2b}, false, false},
+		{"Non-IA5 characters", args{dnNonIA5DCb, dnNonIA5DCb}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(WithLenientDomainComponent()).Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestWithTrimDCWhitespace(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Default, Same value with inner space", nil, args{dn63b, dn63b}, true, false},
+		{"Default, Trimmed and collapsed value", nil, args{dn63b, dn64b}, false, false},
+		{"Default, Value without space", nil, args{dn63b, dn65b}, false, false},
+		{"Lenient DC, PrintableString value with inner space", []Option{WithLenientDomainComponent()}, args{dn66b, dn63b}, true, false},
+		{"Lenient DC, Trimmed and collapsed value", []Option{WithLenientDomainComponent()}, args{dn66b, dn64b}, false, false},
+		{"Trim DC whitespace, Trimmed and collapsed value", []Option{WithTrimDCWhitespace()}, args{dn63b, dn64b}, true, false},
+		{"Trim DC whitespace, Value without space", []Option{WithTrimDCWhitespace()}, args{dn64b, dn65b}, false, false},
+		{"Trim DC whitespace, PrintableString value", []Option{WithTrimDCWhitespace()}, args{dn66b, dn64b}, false, true},
+		{"Trim DC whitespace and lenient DC, PrintableString value", []Option{WithTrimDCWhitespace(), WithLenientDomainComponent()}, args{dn66b, dn64b}, true, false},
+		{"Strict, Value with inner space", []Option{WithStrict()}, args{dn63b, dn63b}, false, true},
+		{"Strict, Value without space", []Option{WithStrict()}, args{dn65b, dn65b}, true, false},
+		{"Strict and trim DC whitespace, Value with inner space", []Option{WithStrict(), WithTrimDCWhitespace()}, args{dn65b, dn64b}, false, true},
+		{"Strict and lenient DC, PrintableString value with inner space", []Option{WithStrict(), WithLenientDomainComponent()}, args{dn66b, dn66b}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer(tt.opts...)
+			gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if tt.wantErr {
+				return
+			}
+			ci, err := c.Canonicalize(tt.args.issuer)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cs, err := c.Canonicalize(tt.args.subject)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(ci) == string(cs); got != tt.wantResult {
+				t.Errorf("Canonicalize() equality = %v, want %v", got, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestComparer_CompareAttribute_TrimDCWhitespace(t *testing.T) {
+	x, err := newStringAttribute(oidDomainComponent, "exa mple", EncodingIA5String)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := newStringAttribute(oidDomainComponent, " EXA\tmple ", EncodingIA5String)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := NewComparer().CompareAttribute(x, y); err != nil || got {
+		t.Errorf("CompareAttribute() = %v, %v, want false", got, err)
+	}
+	if got, err := NewComparer(WithTrimDCWhitespace()).CompareAttribute(x, y); err != nil || !got {
+		t.Errorf("CompareAttribute() with WithTrimDCWhitespace = %v, %v, want true", got, err)
+	}
+}
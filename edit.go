@@ -0,0 +1,131 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+//WithAttribute returns a copy of d to which the attribute of type oid whose value is value encoded in encoding is
+//added as a single-valued RDN at the end of the RDNSequence, i.e. at the beginning of the string representation.
+//If encoding is EncodingDirectoryString, value is encoded in PrintableString or UTF8String as EncodeDirectoryString
+//does. It returns an error if value cannot be encoded in encoding.
+//
+//d is not modified. The copy shares the other attributes with d, so that it refers to the buffer which d was parsed
+//from in the same way as d. Marshal encodes them as they are, so that its result differs from that of d only in the
+//added RDN and the length of the outer SEQUENCE.
+func (d *DN) WithAttribute(oid asn1.ObjectIdentifier, value string, encoding Encoding) (*DN, error) {
+	atv, err := newStringAttribute(oid, value, encoding)
+	if err != nil {
+		return nil, err
+	}
+	return d.AppendRDN(atv)
+}
+
+//AppendRDN returns a copy of d to which the RDN consisting of attrs is added at the end of the RDNSequence, i.e. at the
+//beginning of the string representation, e.g. CN=Intermediate 3 to the name of the issuer. The values are usually
+//encoded by EncodingPolicy.Encode. If RawValue.FullBytes of an attribute is empty, the value is encoded from
+//RawValue.Class, RawValue.Tag and RawValue.Bytes.
+//It returns an error if attrs is empty, or an attribute has an invalid type or its value is not a single ASN.1 value.
+//
+//The attributes are copied, and the other attributes are shared in the same way as WithAttribute.
+func (d *DN) AppendRDN(attrs ...Attribute) (*DN, error) {
+	return d.InsertRDN(len(d.rdns), attrs...)
+}
+
+//InsertRDN returns a copy of d into which the RDN consisting of attrs is inserted at index of the RDNSequence, so that
+//the RDN at index and the following RDNs are moved back. index must be between 0 and d.Len(); InsertRDN(d.Len(), ...)
+//is the same as AppendRDN. The attributes are checked and copied in the same way as AppendRDN.
+func (d *DN) InsertRDN(index int, attrs ...Attribute) (*DN, error) {
+	if index < 0 || index > len(d.rdns) {
+		return nil, fmt.Errorf("dn: index %d is out of range of %d RDNs", index, len(d.rdns))
+	}
+	if len(attrs) == 0 {
+		return nil, errors.New("dn: RDN must have at least one attribute")
+	}
+	r := make(rdnSET, len(attrs))
+	for i, atv := range attrs {
+		var err error
+		if r[i], err = newRDNAttribute(atv); err != nil {
+			return nil, err
+		}
+	}
+	if err := sortRDN(r); err != nil {
+		return nil, err
+	}
+	result := d.copyRDNs()
+	result.rdns = append(result.rdns[:index], append(dn{r}, result.rdns[index:]...)...)
+	return result, nil
+}
+
+//newRDNAttribute returns a copy of atv, which is added to a DN, whose value is encoded.
+func newRDNAttribute(atv Attribute) (result Attribute, err error) {
+	if _, err = asn1.Marshal(atv.Oid); err != nil {
+		return Attribute{}, fmt.Errorf("dn: invalid attribute type %s: %w", atv.Oid, err)
+	}
+	if atv, err = completeAttribute(atv); err != nil {
+		return Attribute{}, err
+	}
+	//the value is parsed again, so that Class, Tag and Bytes agree with FullBytes
+	var rv asn1.RawValue
+	if rest, err := asn1.Unmarshal(atv.RawValue.FullBytes, &rv); err != nil {
+		return Attribute{}, fmt.Errorf("dn: value of attribute %s: %w", atv.Oid, err)
+	} else if len(rest) != 0 {
+		return Attribute{}, fmt.Errorf("dn: trailing data after value of attribute %s", atv.Oid)
+	}
+	return cloneAttribute(Attribute{Oid: atv.Oid, RawValue: rv}), nil
+}
+
+//RemoveAttributes returns a copy of d from which the attributes of type oid are removed.
+//The RDNs which have no attributes left are removed. The other attributes are shared in the same way as WithAttribute.
+func (d *DN) RemoveAttributes(oid asn1.ObjectIdentifier) *DN {
+	result := &DN{rdns: make(dn, 0, len(d.rdns))}
+	for _, r := range d.rdns {
+		nr := make(rdnSET, 0, len(r))
+		for _, atv := range r {
+			if !oidEqual(atv.Oid, oid) {
+				nr = append(nr, atv)
+			}
+		}
+		if len(nr) != 0 {
+			result.rdns = append(result.rdns, nr)
+		}
+	}
+	return result
+}
+
+//ReplaceValue returns a copy of d in which the value of the index-th attribute of type oid is replaced by value
+//encoded in encoding. The attributes are counted from 0 in the order of the RDNSequence and of the attributes in
+//each RDN. It returns an error if d does not have the attribute, or value cannot be encoded in encoding.
+//The other attributes are shared in the same way as WithAttribute.
+func (d *DN) ReplaceValue(oid asn1.ObjectIdentifier, index int, value string, encoding Encoding) (*DN, error) {
+	n := 0
+	for i, r := range d.rdns {
+		for j, atv := range r {
+			if !oidEqual(atv.Oid, oid) {
+				continue
+			}
+			if n != index {
+				n++
+				continue
+			}
+			replaced, err := newStringAttribute(atv.Oid, value, encoding)
+			if err != nil {
+				return nil, err
+			}
+			result := d.copyRDNs()
+			result.rdns[i][j] = replaced
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("dn: index %d is out of range of %d attributes of type %s", index, n, oid)
+}
+
+//copyRDNs returns a copy of d whose RDNs can be modified without modifying d. The attributes are shared.
+func (d *DN) copyRDNs() *DN {
+	result := &DN{rdns: make(dn, len(d.rdns))}
+	for i, r := range d.rdns {
+		result.rdns[i] = append(rdnSET(nil), r...)
+	}
+	return result
+}
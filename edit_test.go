@@ -0,0 +1,240 @@
+package dn
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+const (
+	//The expected encodings are the subjects of the certificate requests generated by OpenSSL, e.g.
+	//openssl req -new -key key.pem -subj "/C=JP/O=Example/CN=abc/OU=Unit" -utf8 -outform DER
+
+	//C=JP,O=Example,CN=abc
+	hopensslBase = "302d310b3009060355040613024a503110300e060355040a0c074578616d706c65310c300a06035504030c03616263"
+	//C=JP,O=Example,CN=abc,OU=Unit
+	hopensslOU = "303c310b3009060355040613024a503110300e060355040a0c074578616d706c65310c300a06035504030c03616263310d300b060355040b0c04556e6974"
+	//C=JP,O=Example,CN=abc,emailAddress=a@example.com
+	hopensslEmail = "304b310b3009060355040613024a503110300e060355040a0c074578616d706c65310c300a06035504030c03616263311c301a06092a864886f70d010901160d61406578616d706c652e636f6d"
+	//C=JP,O=Example,CN=abc,emailAddress=a@example.com,OU=a...a(60 characters)
+	hopensslLongOU = "308192310b3009060355040613024a503110300e060355040a0c074578616d706c65310c300a06035504030c03616263311c301a06092a864886f70d010901160d61406578616d706c652e636f6d31453043060355040b0c3c616161616161616161616161616161616161616161616161616161616161616161616161616161616161616161616161616161616161616161616161"
+	//C=JP,O=Example,CN=xyz
+	hopensslCN = "302d310b3009060355040613024a503110300e060355040a0c074578616d706c65310c300a06035504030c0378797a"
+	//C=JP,O=Example,OU=Unit+CN=abc
+	hopensslMulti = "303a310b3009060355040613024a503110300e060355040a0c074578616d706c653119300a06035504030c03616263300b060355040b0c04556e6974"
+	//C=JP,O=Example,OU=Unit+CN=xyz
+	hopensslMultiCN = "303a310b3009060355040613024a503110300e060355040a0c074578616d706c653119300a06035504030c0378797a300b060355040b0c04556e6974"
+	//C=JP,O=BAR+O=BAZ,CN=ABC
+	hopensslMultiO = "3035310b3009060355040613024a503118300a060355040a0c03424152300a060355040a0c0342415a310c300a06035504030c03414243"
+)
+
+func TestDN_Edit(t *testing.T) {
+	oidOU := asn1.ObjectIdentifier{2, 5, 4, 11}
+	oidO := asn1.ObjectIdentifier{2, 5, 4, 10}
+	tests := []struct {
+		name string
+		der  string
+		edit func(d *DN) (*DN, error)
+		want string
+	}{
+		{"Add OU", hopensslBase, func(d *DN) (*DN, error) {
+			return d.WithAttribute(oidOU, "Unit", EncodingUTF8String)
+		}, hopensslOU},
+		{"Add OU as DirectoryString", hopensslBase, func(d *DN) (*DN, error) {
+			return d.WithAttribute(oidOU, "Unit", EncodingDirectoryString)
+		}, strings.Replace(hopensslOU, "0c04556e6974", "1304556e6974", 1)},
+		{"Add OU with long form length", hopensslEmail, func(d *DN) (*DN, error) {
+			return d.WithAttribute(oidOU, strings.Repeat("a", 60), EncodingUTF8String)
+		}, hopensslLongOU},
+		{"Remove emailAddress", hopensslEmail, func(d *DN) (*DN, error) {
+			return d.RemoveAttributes(oidEmailAddress), nil
+		}, hopensslBase},
+		{"Remove emailAddress from long form length", hopensslLongOU, func(d *DN) (*DN, error) {
+			return d.RemoveAttributes(oidEmailAddress).RemoveAttributes(oidOU), nil
+		}, hopensslBase},
+		{"Remove attribute of multi-valued RDN", hopensslMulti, func(d *DN) (*DN, error) {
+			return d.RemoveAttributes(oidOU), nil
+		}, hopensslBase},
+		{"Remove absent attribute", hopensslBase, func(d *DN) (*DN, error) {
+			return d.RemoveAttributes(oidOU), nil
+		}, hopensslBase},
+		{"Replace CN", hopensslBase, func(d *DN) (*DN, error) {
+			return d.ReplaceValue(oidCommonName, 0, "xyz", EncodingUTF8String)
+		}, hopensslCN},
+		{"Replace CN in multi-valued RDN", hopensslMulti, func(d *DN) (*DN, error) {
+			return d.ReplaceValue(oidCommonName, 0, "xyz", EncodingUTF8String)
+		}, hopensslMultiCN},
+		{"Replace second O", hdn1, func(d *DN) (*DN, error) {
+			return d.ReplaceValue(oidO, 1, "BAZ", EncodingUTF8String)
+		}, hopensslMultiO},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			der, _ := hex.DecodeString(tt.der)
+			d, err := ParseDN(der)
+			if err != nil {
+				t.Fatalf("ParseDN() error = %v", err)
+			}
+			e, err := tt.edit(d)
+			if err != nil {
+				t.Fatalf("edit error = %v", err)
+			}
+			got, err := e.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("Marshal() = %x, want %v", got, tt.want)
+			}
+			if original, err := d.Marshal(); err != nil || !bytes.Equal(original, der) {
+				t.Errorf("Marshal() of the original = %x, %v, want %x", original, err, der)
+			}
+		})
+	}
+}
+
+func TestDN_Edit_KeepsEncodings(t *testing.T) {
+	//the BMPString value is encoded as it is, although UTF8String is the default encoding
+	d, err := ParseDN(dn5b)
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	e, err := d.WithAttribute(oidOrganizationName, "Example", EncodingPrintableString)
+	if err != nil {
+		t.Fatalf("WithAttribute() error = %v", err)
+	}
+	got, err := e.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	//the RDNs of dn5b follow the new length of the outer SEQUENCE
+	if !bytes.Equal(got[2:len(dn5b)], dn5b[2:]) {
+		t.Errorf("Marshal() = %x, want the RDNs of %x", got, dn5b)
+	}
+}
+
+func TestDN_Edit_Error(t *testing.T) {
+	d, err := ParseDN(dn1b)
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	if _, err := d.WithAttribute(oidCountryName, "日本", EncodingPrintableString); err == nil {
+		t.Errorf("WithAttribute() of value which cannot be encoded error = nil, want error")
+	}
+	if _, err := d.ReplaceValue(oidCommonName, 1, "xyz", EncodingUTF8String); err == nil {
+		t.Errorf("ReplaceValue() out of range error = nil, want error")
+	}
+	if _, err := d.ReplaceValue(oidCommonName, -1, "xyz", EncodingUTF8String); err == nil {
+		t.Errorf("ReplaceValue() of negative index error = nil, want error")
+	}
+	if _, err := d.ReplaceValue(oidCommonName, 0, "\xff", EncodingUTF8String); err == nil {
+		t.Errorf("ReplaceValue() of invalid UTF-8 error = nil, want error")
+	}
+}
+
+func TestDN_InsertRDN(t *testing.T) {
+	//OpenSSL, which generated the expected values, encodes CN and OU in UTF8String
+	policy := DefaultEncodingPolicy()
+	policy["2.5.4.3"], policy["2.5.4.11"] = EncodingUTF8String, EncodingUTF8String
+	attribute := func(oid asn1.ObjectIdentifier, value string) Attribute {
+		rv, err := policy.Encode(oid, value)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		return Attribute{Oid: oid, RawValue: rv}
+	}
+	oidOU := asn1.ObjectIdentifier{2, 5, 4, 11}
+	unit := attribute(oidOU, "Unit")
+	tests := []struct {
+		name  string
+		der   string
+		index int
+		attrs []Attribute
+		want  string
+	}{
+		{"Append", hopensslBase, 3, []Attribute{unit}, hopensslOU},
+		{"Append emailAddress", hopensslBase, 3, []Attribute{attribute(oidEmailAddress, "a@example.com")}, hopensslEmail},
+		{"Insert multi-valued RDN", hopensslBase, 2, []Attribute{unit, attribute(oidCommonName, "abc")},
+			"3048310b3009060355040613024a503110300e060355040a0c074578616d706c653119300a06035504030c03616263300b060355040b0c04556e6974310c300a06035504030c03616263"},
+		{"Insert at the beginning", hopensslBase, 0, []Attribute{unit},
+			"303c310d300b060355040b0c04556e6974310b3009060355040613024a503110300e060355040a0c074578616d706c65310c300a06035504030c03616263"},
+		{"Value from Class, Tag and Bytes", hopensslBase, 3, []Attribute{{Oid: oidOU, RawValue: asn1.RawValue{Tag: asn1.TagUTF8String, Bytes: []byte("Unit")}}}, hopensslOU},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			der, _ := hex.DecodeString(tt.der)
+			d, err := ParseDN(der)
+			if err != nil {
+				t.Fatalf("ParseDN() error = %v", err)
+			}
+			e, err := d.InsertRDN(tt.index, tt.attrs...)
+			if err != nil {
+				t.Fatalf("InsertRDN() error = %v", err)
+			}
+			got, err := e.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("Marshal() = %x, want %v", got, tt.want)
+			}
+			if original, err := d.Marshal(); err != nil || !bytes.Equal(original, der) {
+				t.Errorf("Marshal() of the original = %x, %v, want %x", original, err, der)
+			}
+		})
+	}
+}
+
+func TestDN_AppendRDN(t *testing.T) {
+	d, err := ParseDN(dn1b)
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	value := []byte{0x0c, 0x0e, 'I', 'n', 't', 'e', 'r', 'm', 'e', 'd', 'i', 'a', 't', 'e', ' ', '3'}
+	e, err := d.AppendRDN(Attribute{Oid: oidCommonName, RawValue: asn1.RawValue{FullBytes: value}})
+	if err != nil {
+		t.Fatalf("AppendRDN() error = %v", err)
+	}
+	//the attribute is copied
+	value[2] = 'X'
+	if got, want := e.String(), "CN=Intermediate 3,CN=ABC,O=BAR+O=FOO,C=JP"; got != want {
+		t.Errorf("AppendRDN() = %v, want %v", got, want)
+	}
+	got, err := e.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	//the RDNs of dn1b are not disturbed
+	if !bytes.Equal(got[2:len(dn1b)], dn1b[2:]) {
+		t.Errorf("Marshal() = %x, want the RDNs of %x", got, dn1b)
+	}
+}
+
+func TestDN_InsertRDN_Error(t *testing.T) {
+	d, err := ParseDN(dn1b)
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	cn := Attribute{Oid: oidCommonName, RawValue: asn1.RawValue{Tag: asn1.TagUTF8String, Bytes: []byte("abc")}}
+	tests := []struct {
+		name  string
+		index int
+		attrs []Attribute
+	}{
+		{"Negative index", -1, []Attribute{cn}},
+		{"Index after the end", 4, []Attribute{cn}},
+		{"Empty RDN", 0, nil},
+		{"Empty type", 0, []Attribute{{RawValue: cn.RawValue}}},
+		{"Trailing data", 0, []Attribute{{Oid: oidCommonName, RawValue: asn1.RawValue{FullBytes: []byte{0x0c, 0x01, 'a', 0x00}}}}},
+		{"Broken value", 0, []Attribute{{Oid: oidCommonName, RawValue: asn1.RawValue{FullBytes: []byte{0x0c, 0x02, 'a'}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := d.InsertRDN(tt.index, tt.attrs...); err == nil {
+				t.Errorf("InsertRDN() error = nil, want error")
+			}
+		})
+	}
+}
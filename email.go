@@ -0,0 +1,216 @@
+package dn
+
+import (
+	"crypto/subtle"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"github.com/tardevnull/ldapstrprep"
+	"strings"
+	"unicode/utf8"
+)
+
+//oidEmailAddress is emailAddress of PKCS #9, which is encoded in IA5String, or in UTF8String for internationalized
+//addresses.
+//https://tools.ietf.org/html/rfc2985#section-5.2.1
+var oidEmailAddress = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+
+//WithInternationalizedEmail makes the Comparer compare emailAddress values encoded in IA5String or UTF8String as
+//internationalized email addresses(RFC6530). The values are split at the last '@' into the local part and the domain.
+//The domains are compared case-insensitively after converting their labels to A-labels, so that "例子.广告" matches
+//"xn--fsqu00a.xn--4rr70v". The local parts are compared exactly, or case-insensitively if caseIgnoreLocalPart is true.
+//Values without '@' are compared by caseIgnoreIA5Match(RFC4517 section-4.2.7).
+//
+//By default, emailAddress values encoded in IA5String are compared by binary comparison, as RFC 5280 requires
+//for values which are not DirectoryString. It is the same as WithMatchingRule(oid, MatchingRuleEmailAddress) or
+//WithMatchingRule(oid, MatchingRuleEmailAddressCaseIgnore) for emailAddress.
+func WithInternationalizedEmail(caseIgnoreLocalPart bool) Option {
+	rule := MatchingRuleEmailAddress
+	if caseIgnoreLocalPart {
+		rule = MatchingRuleEmailAddressCaseIgnore
+	}
+	return WithMatchingRule(oidEmailAddress, rule)
+}
+
+//compareByEmailMatch reports whether the values of x and y, which are email addresses, matches.
+//The local parts are compared case-insensitively if caseIgnoreLocalPart is true. The values without '@' are prepared by p.
+func compareByEmailMatch(x Attribute, y Attribute, caseIgnoreLocalPart bool, p StringPreparer) (result bool, err error) {
+	var kx, ky string
+	if kx, err = emailKey(x, caseIgnoreLocalPart, p); err != nil {
+		return false, err
+	}
+	if ky, err = emailKey(y, caseIgnoreLocalPart, p); err != nil {
+		return false, err
+	}
+	return kx == ky, nil
+}
+
+//compareByEmailMatchConstantTime reports whether x and y matches in the same way as compareByEmailMatch,
+//comparing the converted values in constant time.
+func compareByEmailMatchConstantTime(x Attribute, y Attribute, caseIgnoreLocalPart bool, p StringPreparer) (result bool, err error) {
+	var kx, ky string
+	if kx, err = emailKey(x, caseIgnoreLocalPart, p); err != nil {
+		return false, err
+	}
+	if ky, err = emailKey(y, caseIgnoreLocalPart, p); err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(kx), []byte(ky)) == 1, nil
+}
+
+//emailKey returns the value of atv, which is an email address, converted so that the values which match by
+//compareByEmailMatch have the same key. The values without '@' are prepared by p.
+func emailKey(atv Attribute, caseIgnoreLocalPart bool, p StringPreparer) (key string, err error) {
+	if atv.RawValue.Class != asn1.ClassUniversal || (atv.RawValue.Tag != asn1.TagIA5String && atv.RawValue.Tag != asn1.TagUTF8String) {
+		return "", fmt.Errorf("dn: value of attribute %s is not an email address encoded in IA5String or UTF8String", atv.Oid)
+	}
+	var s string
+	if s, err = decodeAttributeValue(atv); err != nil {
+		return "", err
+	}
+	at := strings.LastIndexByte(s, '@')
+	if at < 0 {
+		//https://tools.ietf.org/html/rfc4517#section-4.2.7
+		//caseIgnoreIA5Match is the same as caseIgnoreMatch except that the values are IA5String.
+		//The key has no '@', so that it never matches the keys of addresses.
+		return p.Prepare(s, true)
+	}
+	local := s[:at]
+	if caseIgnoreLocalPart {
+		local = string(ldapstrprep.MapCharacters([]rune(local), true))
+	}
+	var domain string
+	if domain, err = toASCIIDomain(s[at+1:]); err != nil {
+		return "", err
+	}
+	return local + "@" + domain, nil
+}
+
+//toASCIIDomain converts the labels of domain to A-labels in lower case.
+//U-labels are case-folded and normalized to NFKC before they are encoded by Punycode, which approximates the mapping
+//of IDNA(UTS #46) for the labels of email addresses.
+func toASCIIDomain(domain string) (result string, err error) {
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		if isASCII(label) {
+			labels[i] = strings.ToLower(label)
+			continue
+		}
+		u := ldapstrprep.Normalize(ldapstrprep.MapCharacters([]rune(label), true))
+		var p string
+		if p, err = punycodeEncode(u); err != nil {
+			return "", err
+		}
+		labels[i] = "xn--" + p
+	}
+	return strings.Join(labels, "."), nil
+}
+
+//isASCII reports whether s consists of only ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+//Parameters of Punycode.
+//https://tools.ietf.org/html/rfc3492#section-5
+const (
+	punycodeBase        = 36
+	punycodeTMin        = 1
+	punycodeTMax        = 26
+	punycodeSkew        = 38
+	punycodeDamp        = 700
+	punycodeInitialBias = 72
+	punycodeInitialN    = 128
+)
+
+//punycodeEncode encodes label by Punycode(RFC3492), without the ACE prefix "xn--".
+func punycodeEncode(label []rune) (result string, err error) {
+	//https://tools.ietf.org/html/rfc3492#section-6.3
+	var sb strings.Builder
+	for _, r := range label {
+		if r < utf8.RuneSelf {
+			sb.WriteRune(r)
+		}
+	}
+	b := sb.Len()
+	h := b
+	if b > 0 {
+		sb.WriteByte('-')
+	}
+	n, delta, bias := rune(punycodeInitialN), 0, punycodeInitialBias
+	for h < len(label) {
+		m := rune(utf8.MaxRune + 1)
+		for _, r := range label {
+			if r >= n && r < m {
+				m = r
+			}
+		}
+		if int(m-n) > (maxPunycodeDelta-delta)/(h+1) {
+			return "", errors.New("dn: overflow in Punycode encoding")
+		}
+		delta += int(m-n) * (h + 1)
+		n = m
+		for _, r := range label {
+			if r < n {
+				delta++
+			}
+			if r != n {
+				continue
+			}
+			q := delta
+			for k := punycodeBase; ; k += punycodeBase {
+				t := k - bias
+				if t < punycodeTMin {
+					t = punycodeTMin
+				} else if t > punycodeTMax {
+					t = punycodeTMax
+				}
+				if q < t {
+					break
+				}
+				sb.WriteByte(punycodeDigit(t + (q-t)%(punycodeBase-t)))
+				q = (q - t) / (punycodeBase - t)
+			}
+			sb.WriteByte(punycodeDigit(q))
+			bias = punycodeAdapt(delta, h+1, h == b)
+			delta = 0
+			h++
+		}
+		delta++
+		n++
+	}
+	return sb.String(), nil
+}
+
+//maxPunycodeDelta is the maximum delta of Punycode, which is enough for the labels of domain names.
+const maxPunycodeDelta = 1<<31 - 1
+
+//punycodeDigit returns the basic code point of digit d.
+func punycodeDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+//punycodeAdapt returns the bias adapted for delta.
+func punycodeAdapt(delta int, numPoints int, firstTime bool) int {
+	//https://tools.ietf.org/html/rfc3492#section-6.1
+	if firstTime {
+		delta /= punycodeDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punycodeBase-punycodeTMin)*punycodeTMax)/2 {
+		delta /= punycodeBase - punycodeTMin
+		k += punycodeBase
+	}
+	return k + (punycodeBase-punycodeTMin+1)*delta/(delta+punycodeSkew)
+}
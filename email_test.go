@@ -0,0 +1,151 @@
+package dn
+
+import (
+	"testing"
+)
+
+func TestWithInternationalizedEmail(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Default, U-label and A-label", nil, args{issuer: dn67b, subject: dn68b}, false, false},
+		{"Default, Same", nil, args{issuer: dn67b, subject: dn67b}, true, false},
+		{"Internationalized email, U-label and A-label", []Option{WithInternationalizedEmail(false)}, args{issuer: dn67b, subject: dn68b}, true, false},
+		{"Internationalized email, Different domain", []Option{WithInternationalizedEmail(false)}, args{issuer: dn67b, subject: dn69b}, false, false},
+		{"Internationalized email, Constant time, U-label and A-label", []Option{WithInternationalizedEmail(false), WithConstantTime()}, args{issuer: dn68b, subject: dn67b}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer(tt.opts...)
+			gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			ci, err := c.Canonicalize(tt.args.issuer)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cs, err := c.Canonicalize(tt.args.subject)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(ci) == string(cs); got != tt.wantResult {
+				t.Errorf("Canonicalize() equality = %v, want %v", got, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestComparer_CompareAttribute_InternationalizedEmail(t *testing.T) {
+	type args struct {
+		x string
+		y string
+		e Encoding
+	}
+	tests := []struct {
+		name                string
+		caseIgnoreLocalPart bool
+		args                args
+		wantResult          bool
+		wantErr             bool
+	}{
+		{"Unicode local part, U-label and A-label", false, args{"用户@例子.广告", "用户@xn--fsqu00a.xn--4rr70v", EncodingUTF8String}, true, false},
+		{"Unicode local part, Different local part", false, args{"用户@例子.广告", "用戶@例子.广告", EncodingUTF8String}, false, false},
+		{"Upper/Lower case U-label and A-label", false, args{"info@MÜNCHEN.de", "info@xn--mnchen-3ya.DE", EncodingUTF8String}, true, false},
+		{"Upper/Lower case local part", false, args{"Info@example.com", "info@EXAMPLE.com", EncodingIA5String}, false, false},
+		{"Upper/Lower case local part, Case ignore", true, args{"Info@example.com", "info@EXAMPLE.com", EncodingIA5String}, true, false},
+		{"Upper/Lower case Unicode local part, Case ignore", true, args{"Élodie@example.com", "élodie@example.com", EncodingUTF8String}, true, false},
+		{"Split at the last '@'", false, args{`"a@b"@example.com`, `"a@b"@EXAMPLE.COM`, EncodingIA5String}, true, false},
+		{"Without '@', Upper/Lower case characters and spaces", false, args{"Postmaster", " postmaster ", EncodingIA5String}, true, false},
+		{"Without '@' and address", false, args{"example.com", "@example.com", EncodingIA5String}, false, false},
+		{"BMPString", false, args{"info@example.com", "info@example.com", EncodingBMPString}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, err := newStringAttribute(oidEmailAddress, tt.args.x, tt.args.e)
+			if err != nil {
+				t.Fatalf("cannot encode %q: %v", tt.args.x, err)
+			}
+			y, err := newStringAttribute(oidEmailAddress, tt.args.y, tt.args.e)
+			if err != nil {
+				t.Fatalf("cannot encode %q: %v", tt.args.y, err)
+			}
+			gotResult, err := NewComparer(WithInternationalizedEmail(tt.caseIgnoreLocalPart)).CompareAttribute(x, y)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompareAttribute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("CompareAttribute() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_punycodeEncode(t *testing.T) {
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"例子", "fsqu00a"},
+		{"广告", "4rr70v"},
+		{"münchen", "mnchen-3ya"},
+		{"bücher", "bcher-kva"},
+		//https://tools.ietf.org/html/rfc3492#section-7.1
+		//(A) Arabic (Egyptian)
+		{"ليهمابتكلموشعربي؟", "egbpdaj6bu4bxfgehfvwxn"},
+		//(B) Chinese (simplified)
+		{"他们为什么不说中文", "ihqwcrb4cv8a8dqg056pqjye"},
+		//(C) Chinese (traditional)
+		{"他們爲什麽不說中文", "ihqwctvzc91f659drss3x8bo0yb"},
+		//(D) Czech
+		{"Pročprostěnemluvíčesky", "Proprostnemluvesky-uyb24dma41a"},
+		//(E) Hebrew
+		{"למההםפשוטלאמדבריםעברית", "4dbcagdahymbxekheh6e0a7fei0b"},
+		//(F) Hindi (Devanagari)
+		{"यहलोगहिन्दीक्योंनहींबोलसकतेहैं", "i1baa7eci9glrd9b2ae1bj0hfcgg6iyaf8o0a1dig0cd"},
+		//(G) Japanese (kanji and hiragana)
+		{"なぜみんな日本語を話してくれないのか", "n8jok5ay5dzabd5bym9f0cm5685rrjetr6pdxa"},
+		//(H) Korean (Hangul syllables)
+		{"세계의모든사람들이한국어를이해한다면얼마나좋을까", "989aomsvi5e83db1d2a355cv1e0vak1dwrv93d5xbh15a0dt30a5jpsd879ccm6fea98c"},
+		//(I) Russian (Cyrillic), whose "D" of the sample is the mixed-case annotation, which punycodeEncode does not output
+		{"почемужеонинеговорятпорусски", "b1abfaaepdrnnbgefbadotcwatmq2g4l"},
+		//(J) Spanish
+		{"PorquénopuedensimplementehablarenEspañol", "PorqunopuedensimplementehablarenEspaol-fmd56a"},
+		//(K) Vietnamese
+		{"TạisaohọkhôngthểchỉnóitiếngViệt", "TisaohkhngthchnitingVit-kjcr8268qyxafd2f1b9g"},
+		//(L) to (R) Japanese
+		{"3年B組金八先生", "3B-ww4c5e180e575a65lsy2b"},
+		{"安室奈美恵-with-SUPER-MONKEYS", "-with-SUPER-MONKEYS-pc58ag80a8qai00g7n9n"},
+		{"Hello-Another-Way-それぞれの場所", "Hello-Another-Way--fc4qua05auwb3674vfr0b"},
+		{"ひとつ屋根の下2", "2-u9tlzr9756bt3uc0v"},
+		{"MajiでKoiする5秒前", "MajiKoi5-783gue6qz075azm5e"},
+		{"パフィーdeルンバ", "de-jg4avhby1noc0d"},
+		{"そのスピードで", "d9juau41awczczp"},
+		//(S) only basic code points
+		{"-> $1.00 <-", "-> $1.00 <--"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			got, err := punycodeEncode([]rune(tt.label))
+			if err != nil {
+				t.Fatalf("punycodeEncode() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("punycodeEncode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package dn
+
+import (
+	"strings"
+)
+
+//WithDropEmptyAttributes makes the Comparer treat attributes whose values are empty as absent, e.g. "OU=,O=Example"
+//matches "O=Example". A value is empty if it is a string which is empty after the string preparation of the Comparer,
+//e.g. "" and "  ". Values which are not encoded as string are never empty. By default, an empty value matches only
+//another empty value of the same type.
+//
+//The empty attributes are removed before the comparison, and so are the RDNs which consist of only empty attributes,
+//so that the number of RDNs compared is the number of the RDNs left, e.g. "CN=abc,OU=,O=Example" has two RDNs.
+//A multi-valued RDN which has other attributes is kept without the empty ones, e.g. "OU=+CN=abc" matches "CN=abc".
+//
+//WithStrict still returns an error for empty values, because it checks the distinguished names before they are
+//removed. CompareAttribute, which compares a pair of attributes, is not affected.
+func WithDropEmptyAttributes() Option {
+	return func(c *Comparer) {
+		c.dropEmptyAttributes = true
+	}
+}
+
+//removeEmptyAttributes returns d without the attributes whose values are empty as defined by WithDropEmptyAttributes.
+func (c *Comparer) removeEmptyAttributes(d dn) (result dn, err error) {
+	p := c.stringPreparer()
+	result = make(dn, 0, len(d))
+	for _, r := range d {
+		nr := make(rdnSET, 0, len(r))
+		for _, atv := range r {
+			if !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				nr = append(nr, atv)
+				continue
+			}
+			var s string
+			if s, err = decodeAttributeValue(atv); err != nil {
+				return nil, err
+			}
+			if s, err = p.Prepare(s, true); err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(s) != "" {
+				nr = append(nr, atv)
+			}
+		}
+		if len(nr) != 0 {
+			result = append(result, nr)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package dn
+
+import (
+	"testing"
+)
+
+func TestWithDropEmptyAttributes(t *testing.T) {
+	tests := []struct {
+		name        string
+		x           string
+		y           string
+		wantDefault bool
+		wantResult  bool
+	}{
+		{"Empty and absent", "OU=,O=Example,C=JP", "O=Example,C=JP", false, true},
+		{"Absent and empty", "O=Example,C=JP", "OU=,O=Example,C=JP", false, true},
+		{"Only spaces and absent", "CN=abc,OU=  ,O=Example", "CN=abc,O=Example", false, true},
+		{"Empty in multi-valued RDN", "CN=abc+OU=,O=Example", "CN=abc,O=Example", false, true},
+		{"Empty on both sides", "OU=,O=Example", "OU=,O=Example", true, true},
+		{"Empty at different positions", "OU=,O=Example,C=JP", "O=Example,OU=,C=JP", false, true},
+		{"Only empty attributes", "OU=,CN=", "OU=", false, true},
+		{"Non-empty and absent", "OU=Unit,O=Example", "O=Example", false, false},
+		{"Empty and non-empty", "OU=,O=Example", "OU=Unit,O=Example", false, false},
+		{"Empty OCTET STRING and absent", "OU=#0400,O=Example", "O=Example", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := mustMarshalString(t, tt.x)
+			y := mustMarshalString(t, tt.y)
+			gotDefault, err := Compare(x, y)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if gotDefault != tt.wantDefault {
+				t.Errorf("Compare() gotResult = %v, want %v", gotDefault, tt.wantDefault)
+			}
+			for _, opts := range [][]Option{{WithDropEmptyAttributes()}, {WithDropEmptyAttributes(), WithConstantTime()}} {
+				gotResult, err := NewComparer(opts...).Compare(x, y)
+				if err != nil {
+					t.Fatalf("Comparer.Compare() error = %v", err)
+				}
+				if gotResult != tt.wantResult {
+					t.Errorf("Comparer.Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+				}
+			}
+		})
+	}
+}
+
+func TestWithDropEmptyAttributes_Strict(t *testing.T) {
+	x := mustMarshalString(t, "OU=,O=Example")
+	y := mustMarshalString(t, "O=Example")
+	if _, err := NewComparer(WithDropEmptyAttributes(), WithStrict()).Compare(x, y); err == nil {
+		t.Errorf("Compare() error = nil, want error for the empty value")
+	}
+}
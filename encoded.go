@@ -0,0 +1,43 @@
+package dn
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+//CompareHex reports whether issuer and subject, which are hex encoded Distinguished Names, matches.
+//It returns an error if issuerHex or subjectHex is not valid hex string.
+func CompareHex(issuerHex string, subjectHex string) (result bool, err error) {
+	var issuer, subject []byte
+	if issuer, err = hex.DecodeString(issuerHex); err != nil {
+		return false, fmt.Errorf("dn: failed to decode hex of issuer: %w", err)
+	}
+	if subject, err = hex.DecodeString(subjectHex); err != nil {
+		return false, fmt.Errorf("dn: failed to decode hex of subject: %w", err)
+	}
+	return Compare(issuer, subject)
+}
+
+//CompareBase64 reports whether issuer and subject, which are base64 encoded Distinguished Names, matches.
+//The inputs are decoded with the standard encoding(RFC 4648 section-4). The padding is optional.
+//It returns an error if issuerB64 or subjectB64 is not valid base64 string.
+func CompareBase64(issuerB64 string, subjectB64 string) (result bool, err error) {
+	var issuer, subject []byte
+	if issuer, err = decodeBase64(issuerB64); err != nil {
+		return false, fmt.Errorf("dn: failed to decode base64 of issuer: %w", err)
+	}
+	if subject, err = decodeBase64(subjectB64); err != nil {
+		return false, fmt.Errorf("dn: failed to decode base64 of subject: %w", err)
+	}
+	return Compare(issuer, subject)
+}
+
+//decodeBase64 decodes s with the standard encoding, with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	if strings.HasSuffix(s, "=") || len(s)%4 == 0 {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
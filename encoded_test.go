@@ -0,0 +1,76 @@
+package dn
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestCompareHex(t *testing.T) {
+	type args struct {
+		issuerHex  string
+		subjectHex string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    error
+	}{
+		{"Same characters, Multi RDN", args{hdn1, hdn1}, true, nil},
+		{"Upper/Lower case characters", args{hdn2, hdn4}, true, nil},
+		{"Different characters", args{hdn2, hdn6}, false, nil},
+		{"Invalid hex issuer", args{"30zz", hdn2}, false, hex.InvalidByteError('z')},
+		{"Odd length subject", args{hdn2, hdn2[1:]}, false, hex.ErrLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := CompareHex(tt.args.issuerHex, tt.args.subjectHex)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CompareHex() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("CompareHex() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestCompareBase64(t *testing.T) {
+	//C=JP(PrintableString),CN=ABC(UTF8String), padded
+	b64dn2 := base64.StdEncoding.EncodeToString(dn2b)
+	//C=JP(PrintableString),CN=abc(UTF8String), unpadded
+	b64dn4 := base64.RawStdEncoding.EncodeToString(dn4b)
+	//C=US(PrintableString),CN=DEF(UTF8String)
+	b64dn6 := base64.StdEncoding.EncodeToString(dn6b)
+	type args struct {
+		issuerB64  string
+		subjectB64 string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Padded and unpadded", args{b64dn2, b64dn4}, true, false},
+		{"Different characters", args{b64dn2, b64dn6}, false, false},
+		{"Invalid base64 issuer", args{"MB!x", b64dn2}, false, true},
+		{"Invalid base64 subject", args{b64dn2, "MBsx="}, false, true},
+		{"URL encoding", args{b64dn2, "-_-_"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := CompareBase64(tt.args.issuerB64, tt.args.subjectB64)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompareBase64() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("CompareBase64() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
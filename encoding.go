@@ -0,0 +1,209 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+//Encoding is an ASN.1 string type which is used to encode attribute values.
+type Encoding int
+
+//Encodings of attribute values.
+//EncodingDirectoryString selects PrintableString or UTF8String for each value as EncodeDirectoryString does.
+const (
+	EncodingUTF8String      Encoding = asn1.TagUTF8String
+	EncodingPrintableString Encoding = asn1.TagPrintableString
+	EncodingIA5String       Encoding = asn1.TagIA5String
+	EncodingBMPString       Encoding = asn1.TagBMPString
+	EncodingDirectoryString Encoding = 0
+)
+
+//EncodingPolicy maps attribute types to the encodings of their values.
+//The key is the dotted string form of the attribute type, e.g. "2.5.4.6".
+//Attribute types which are not in the policy are encoded as DirectoryString by EncodingDirectoryString, i.e.
+//PrintableString if the value consists of only the characters of PrintableString, or UTF8String otherwise.
+type EncodingPolicy map[string]Encoding
+
+//DefaultEncodingPolicy returns the EncodingPolicy which follows RFC 5280.
+func DefaultEncodingPolicy() EncodingPolicy {
+	return EncodingPolicy{
+		//https://tools.ietf.org/html/rfc5280#appendix-A.1
+		//X520countryName ::=     PrintableString (SIZE (2))
+		"2.5.4.6": EncodingPrintableString,
+		//X520SerialNumber ::=    PrintableString (SIZE (1..ub-serial-number))
+		"2.5.4.5": EncodingPrintableString,
+		//X520dnQualifier ::=     PrintableString
+		"2.5.4.46": EncodingPrintableString,
+		//https://cabforum.org/guidelines/ EV Guidelines section 9.2.4
+		//jurisdictionCountryName: ASN.1 - PrintableString (SIZE(2))
+		"1.3.6.1.4.1.311.60.2.1.3": EncodingPrintableString,
+		//DomainComponent ::=  IA5String
+		"0.9.2342.19200300.100.1.25": EncodingIA5String,
+		//EmailAddress ::=	 IA5String (SIZE (1..ub-emailaddress-length))
+		"1.2.840.113549.1.9.1": EncodingIA5String,
+	}
+}
+
+//Encoding returns the encoding of the value of attribute type oid.
+func (p EncodingPolicy) Encoding(oid asn1.ObjectIdentifier) Encoding {
+	if e, ok := p[oid.String()]; ok {
+		return e
+	}
+	//https://tools.ietf.org/html/rfc5280#section-4.1.2.4
+	//CAs conforming to this profile MUST use either the
+	//PrintableString or UTF8String encoding of DirectoryString
+	return EncodingDirectoryString
+}
+
+//Encode encodes value as the value of attribute type oid according to p.
+func (p EncodingPolicy) Encode(oid asn1.ObjectIdentifier, value string) (rv asn1.RawValue, err error) {
+	return encodeString(value, p.Encoding(oid))
+}
+
+//EncodeDirectoryString encodes value as DirectoryString in the minimal encoding, which is PrintableString if value
+//consists of only the characters of PrintableString, e.g. "(" but not "@" or "*", or UTF8String otherwise.
+//If force is not EncodingDirectoryString, value is encoded in force instead, and an error is returned if value cannot
+//be encoded in it.
+func EncodeDirectoryString(value string, force Encoding) (asn1.RawValue, error) {
+	return encodeString(value, force)
+}
+
+//encodeString encodes s as ASN.1 string specified by e.
+func encodeString(s string, e Encoding) (rv asn1.RawValue, err error) {
+	var b []byte
+	if e == EncodingDirectoryString {
+		//https://tools.ietf.org/html/rfc5280#section-4.1.2.4
+		//CAs conforming to this profile MUST use either the
+		//PrintableString or UTF8String encoding of DirectoryString
+		e = EncodingUTF8String
+		if isPrintableString(s) {
+			e = EncodingPrintableString
+		}
+	}
+	switch e {
+	case EncodingUTF8String:
+		if !utf8.ValidString(s) {
+			return asn1.RawValue{}, errors.New("dn: invalid UTF-8 string")
+		}
+		b = []byte(s)
+	case EncodingPrintableString:
+		if !isPrintableString(s) {
+			return asn1.RawValue{}, fmt.Errorf("dn: %q cannot be encoded as PrintableString", s)
+		}
+		b = []byte(s)
+	case EncodingIA5String:
+		if !isIA5String(s) {
+			return asn1.RawValue{}, fmt.Errorf("dn: %q cannot be encoded as IA5String", s)
+		}
+		b = []byte(s)
+	case EncodingBMPString:
+		for _, c := range s {
+			if c > 0xFFFF {
+				return asn1.RawValue{}, fmt.Errorf("dn: %q cannot be encoded as BMPString", s)
+			}
+		}
+		for _, u := range utf16.Encode([]rune(s)) {
+			b = append(b, byte(u>>8), byte(u))
+		}
+	default:
+		return asn1.RawValue{}, fmt.Errorf("dn: unsupported encoding %d", e)
+	}
+	rv = asn1.RawValue{Class: asn1.ClassUniversal, Tag: int(e), Bytes: b}
+	if rv.FullBytes, err = asn1.Marshal(rv); err != nil {
+		return asn1.RawValue{}, err
+	}
+	return rv, nil
+}
+
+//isPrintableString reports whether s consists of only the characters of PrintableString.
+func isPrintableString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isPrintableCharacter(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+//isPrintableCharacter reports whether c is the character of PrintableString.
+func isPrintableCharacter(c byte) bool {
+	//https://www.itu.int/rec/T-REC-X.680
+	//PrintableString: A-Z a-z 0-9 space ' ( ) + , - . / : = ?
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	switch c {
+	case ' ', '\'', '(', ')', '+', ',', '-', '.', '/', ':', '=', '?':
+		return true
+	}
+	return false
+}
+
+//isIA5String reports whether s consists of only the characters of IA5String.
+func isIA5String(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > 0x7F {
+			return false
+		}
+	}
+	return true
+}
+
+//EncodingIssue describes a value whose encoding does not conform to the profile.
+type EncodingIssue struct {
+	RDN       int                   //index of the RDN which contains the Attribute
+	Attribute int                   //index of the attribute in the RDN
+	Type      asn1.ObjectIdentifier //attribute type
+	Violation bool                  //true if the encoding violates ASN.1, false if it is only advisory
+	Message   string
+}
+
+//utf8PreferredTypes are the attribute types whose values the modern profiles encode in UTF8String, which
+//NonConformantEncodings reports if they are encoded in PrintableString.
+//https://tools.ietf.org/html/rfc3280#section-4.1.2.4
+//all certificates issued after December 31, 2003 MUST use the UTF8String encoding of DirectoryString
+var utf8PreferredTypes = map[string]bool{
+	"2.5.4.3":  true, //commonName
+	"2.5.4.10": true, //organizationName
+	"2.5.4.11": true, //organizationalUnitName
+	"2.5.4.7":  true, //localityName
+	"2.5.4.8":  true, //stateOrProvinceName
+	"2.5.4.9":  true, //streetAddress
+}
+
+//NonConformantEncodings reports the values encoded in PrintableString which do not conform to the profile:
+//  1. PrintableString which contains characters out of the character set of PrintableString, including non-ASCII characters.
+//     This is a violation of ASN.1.
+//  2. PrintableString for the attribute types whose values the modern profiles encode in UTF8String, i.e.
+//     commonName, organizationName, organizationalUnitName, localityName, stateOrProvinceName and streetAddress.
+//     This is advisory, since RFC 5280 allows PrintableString for DirectoryString, and DefaultEncodingPolicy
+//     encodes their values in PrintableString if possible.
+func (d *DN) NonConformantEncodings() (issues []EncodingIssue, err error) {
+	for i, r := range d.rdns {
+		for j, atv := range r {
+			if atv.RawValue.Class != asn1.ClassUniversal || atv.RawValue.Tag != asn1.TagPrintableString {
+				continue
+			}
+			issue := EncodingIssue{RDN: i, Attribute: j, Type: cloneOid(atv.Oid)}
+			if s, decodeErr := toString(atv.RawValue.FullBytes); decodeErr != nil || !isPrintableString(s) {
+				issue.Violation = true
+				if isIA5String(string(atv.RawValue.Bytes)) {
+					issue.Message = fmt.Sprintf("attribute %s contains characters not allowed in PrintableString", atv.Oid)
+				} else {
+					issue.Message = fmt.Sprintf("attribute %s contains non-ASCII characters in PrintableString", atv.Oid)
+				}
+				issues = append(issues, issue)
+				continue
+			}
+			if utf8PreferredTypes[atv.Oid.String()] {
+				issue.Message = fmt.Sprintf("attribute %s is encoded in PrintableString instead of UTF8String", atv.Oid)
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues, nil
+}
@@ -0,0 +1,172 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodingPolicy_Encoding(t *testing.T) {
+	type args struct {
+		oid asn1.ObjectIdentifier
+	}
+	tests := []struct {
+		name string
+		p    EncodingPolicy
+		args args
+		want Encoding
+	}{
+		{"Default, C", DefaultEncodingPolicy(), args{oidCountry}, EncodingPrintableString},
+		{"Default, DC", DefaultEncodingPolicy(), args{oidDomainComponent}, EncodingIA5String},
+		{"Default, O", DefaultEncodingPolicy(), args{oidOrganization}, EncodingDirectoryString},
+		{"Override, O", EncodingPolicy{"2.5.4.10": EncodingPrintableString}, args{oidOrganization}, EncodingPrintableString},
+		{"Empty, C", EncodingPolicy{}, args{oidCountry}, EncodingDirectoryString},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.Encoding(tt.args.oid); got != tt.want {
+				t.Errorf("Encoding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodingPolicy_Encode(t *testing.T) {
+	type args struct {
+		oid   asn1.ObjectIdentifier
+		value string
+	}
+	tests := []struct {
+		name    string
+		p       EncodingPolicy
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{"Default, C", DefaultEncodingPolicy(), args{oidCountry, "JP"}, "13024A50", false},
+		{"Default, DC", DefaultEncodingPolicy(), args{oidDomainComponent, "abc"}, "1603616263", false},
+		{"Default, O", DefaultEncodingPolicy(), args{oidOrganization, "abc"}, "1303616263", false},
+		{"Default, O is not printable", DefaultEncodingPolicy(), args{oidOrganization, "a@c"}, "0C03614063", false},
+		{"Override, O as UTF8String", EncodingPolicy{"2.5.4.10": EncodingUTF8String}, args{oidOrganization, "abc"}, "0C03616263", false},
+		{"Override, O as BMPString", EncodingPolicy{"2.5.4.10": EncodingBMPString}, args{oidOrganization, "abc"}, "1E06006100620063", false},
+		{"Default, C is not printable", DefaultEncodingPolicy(), args{oidCountry, "J@"}, "", true},
+		{"Default, DC is not IA5", DefaultEncodingPolicy(), args{oidDomainComponent, "例"}, "", true},
+		{"Override, O as BMPString is out of BMP", EncodingPolicy{"2.5.4.10": EncodingBMPString}, args{oidOrganization, "😀"}, "", true},
+		{"Unsupported encoding", EncodingPolicy{"2.5.4.10": Encoding(asn1.TagInteger)}, args{oidOrganization, "abc"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.p.Encode(tt.args.oid, tt.args.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Encode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if h := strings.ToUpper(hex.EncodeToString(got.FullBytes)); h != tt.want {
+				t.Errorf("Encode() got = %v, want %v", h, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeDirectoryString(t *testing.T) {
+	type args struct {
+		value string
+		force Encoding
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{"Empty", args{"", EncodingDirectoryString}, "1300", false},
+		{"Letters and digits", args{"Az09", EncodingDirectoryString}, "1304417A3039", false},
+		{"All punctuations of PrintableString", args{" '()+,-./:=?", EncodingDirectoryString}, "130C202728292B2C2D2E2F3A3D3F", false},
+		{"Left parenthesis", args{"(", EncodingDirectoryString}, "130128", false},
+		{"Commercial at", args{"@", EncodingDirectoryString}, "0C0140", false},
+		{"Asterisk", args{"*", EncodingDirectoryString}, "0C012A", false},
+		{"Ampersand", args{"&", EncodingDirectoryString}, "0C0126", false},
+		{"Underscore", args{"a_b", EncodingDirectoryString}, "0C03615F62", false},
+		{"Control character", args{"a\tb", EncodingDirectoryString}, "0C03610962", false},
+		{"Latin-1", args{"Café", EncodingDirectoryString}, "0C05436166C3A9", false},
+		{"Japanese", args{"日本", EncodingDirectoryString}, "0C06E697A5E69CAC", false},
+		{"Force UTF8String", args{"abc", EncodingUTF8String}, "0C03616263", false},
+		{"Force PrintableString", args{"abc", EncodingPrintableString}, "1303616263", false},
+		{"Force IA5String", args{"a@c", EncodingIA5String}, "1603614063", false},
+		{"Force BMPString", args{"(", EncodingBMPString}, "1E020028", false},
+		{"Force PrintableString, Commercial at", args{"a@c", EncodingPrintableString}, "", true},
+		{"Force PrintableString, Asterisk", args{"*", EncodingPrintableString}, "", true},
+		{"Invalid UTF-8", args{"\xff", EncodingDirectoryString}, "", true},
+		{"Unsupported encoding", args{"abc", Encoding(asn1.TagInteger)}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeDirectoryString(tt.args.value, tt.args.force)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EncodeDirectoryString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if h := strings.ToUpper(hex.EncodeToString(got.FullBytes)); h != tt.want {
+				t.Errorf("EncodeDirectoryString() got = %v, want %v", h, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeDirectoryString_PrintableBoundary(t *testing.T) {
+	for c := 0; c < 0x80; c++ {
+		got, err := EncodeDirectoryString(string(rune(c)), EncodingDirectoryString)
+		if err != nil {
+			t.Fatalf("EncodeDirectoryString(%q) error = %v", c, err)
+		}
+		want := asn1.TagUTF8String
+		if strings.ContainsRune("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789 '()+,-./:=?", rune(c)) {
+			want = asn1.TagPrintableString
+		}
+		if got.Tag != want {
+			t.Errorf("EncodeDirectoryString(%q) tag = %d, want %d", c, got.Tag, want)
+		}
+	}
+}
+
+func TestDN_NonConformantEncodings(t *testing.T) {
+	//C=JP(PrintableString),O=caf\xe9(PrintableString),OU=a@b(PrintableString),CN=abc(UTF8String)
+	invalid, _ := hex.DecodeString("3038310b3009060355040613024a50310d300b060355040a1304636166e9310c300a060355040b1303614062310c300a06035504030c03616263")
+	tests := []struct {
+		name       string
+		der        []byte
+		wantIssues []EncodingIssue
+	}{
+		{"Conformant", dn2b, nil},
+		{"PrintableString for CN", dn3b, []EncodingIssue{{RDN: 1, Attribute: 0, Type: asn1.ObjectIdentifier{2, 5, 4, 3}, Violation: false, Message: "attribute 2.5.4.3 is encoded in PrintableString instead of UTF8String"}}},
+		{"PrintableString for C", mustMarshalString(t, "C=JP"), nil},
+		{"Invalid characters in PrintableString", invalid, []EncodingIssue{
+			{RDN: 1, Attribute: 0, Type: asn1.ObjectIdentifier{2, 5, 4, 10}, Violation: true, Message: "attribute 2.5.4.10 contains non-ASCII characters in PrintableString"},
+			{RDN: 2, Attribute: 0, Type: asn1.ObjectIdentifier{2, 5, 4, 11}, Violation: true, Message: "attribute 2.5.4.11 contains characters not allowed in PrintableString"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDN(tt.der)
+			if err != nil {
+				t.Fatalf("ParseDN() error = %v", err)
+			}
+			gotIssues, err := d.NonConformantEncodings()
+			if err != nil {
+				t.Errorf("NonConformantEncodings() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(gotIssues, tt.wantIssues) {
+				t.Errorf("NonConformantEncodings() gotIssues = %v, want %v", gotIssues, tt.wantIssues)
+			}
+		})
+	}
+}
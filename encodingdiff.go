@@ -0,0 +1,182 @@
+package dn
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"github.com/tardevnull/ldapstrprep"
+	"strings"
+)
+
+//EncodingDifference is a set of reasons why the encodings of two matched values differ.
+type EncodingDifference int
+
+//Reasons of EncodingDifference.
+const (
+	//EncodingDifferenceTag means the values are encoded in different string types, which the comparison unified.
+	EncodingDifferenceTag EncodingDifference = 1 << iota
+	//EncodingDifferenceCase means the values matched by case folding.
+	EncodingDifferenceCase
+	//EncodingDifferenceSpace means the values matched by the insignificant space handling.
+	EncodingDifferenceSpace
+	//EncodingDifferenceCharacters means the values matched by the mapping or the normalization of the string preparation,
+	//e.g. full-width forms or decomposed characters.
+	EncodingDifferenceCharacters
+)
+
+var encodingDifferenceNames = []string{"tag", "case", "space", "characters"}
+
+//String returns the names of the reasons in d separated by "|", e.g. "tag|case".
+func (d EncodingDifference) String() string {
+	var names []string
+	for i, name := range encodingDifferenceNames {
+		if d&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+//AttributeEncodingDiff describes a pair of matched attributes whose encodings differ.
+type AttributeEncodingDiff struct {
+	RDN         int                   //index of the RDN in the RDNSequence
+	A           int                   //index of the attribute in the RDN of a
+	B           int                   //index of the matched attribute in the RDN of b
+	Type        asn1.ObjectIdentifier //attribute type
+	TagA        int
+	TagB        int
+	LengthA     int //length of the content of the value of a
+	LengthB     int //length of the content of the value of b
+	Differences EncodingDifference
+}
+
+//EncodingDiff is the report of DiffEncodings.
+type EncodingDiff struct {
+	Attributes    []AttributeEncodingDiff //pairs of matched attributes whose encodings differ
+	ReorderedRDNs []int                   //indexes of the multi-valued RDNs whose attributes are in different orders
+	//Other is true if the encodings differ although the attributes and their orders are the same,
+	//e.g. in the encodings of the lengths.
+	Other bool
+}
+
+//IsEmpty reports whether d has no differences.
+func (d *EncodingDiff) IsEmpty() bool {
+	return len(d.Attributes) == 0 && len(d.ReorderedRDNs) == 0 && !d.Other
+}
+
+//DiffEncodings reports why the encodings of a and b, both of which are encoded as Distinguished Name and match by
+//Compare, differ. The report is empty if and only if a and b are byte-identical.
+//It is useful to detect issuers which change the encodings of their names, although the names still match.
+//DiffEncodings returns an error if a and b do not match.
+func DiffEncodings(a []byte, b []byte) (diff *EncodingDiff, err error) {
+	var x, y dn
+	if x, err = parseDn(a); err != nil {
+		return nil, err
+	}
+	if y, err = parseDn(b); err != nil {
+		return nil, err
+	}
+	var result bool
+	var decisions []RDNDecision
+	if result, decisions, err = NewComparer().explainDistinguishedName(x, y); err != nil {
+		return nil, err
+	}
+	if !result {
+		return nil, errors.New("dn: distinguished names do not match")
+	}
+	diff = &EncodingDiff{}
+	for _, d := range decisions {
+		reordered := false
+		for _, ad := range d.Attributes {
+			if ad.Issuer != ad.Subject {
+				reordered = true
+			}
+			xa := x[d.RDN][ad.Issuer]
+			ya := y[d.RDN][ad.Subject]
+			if bytes.Equal(xa.RawValue.FullBytes, ya.RawValue.FullBytes) {
+				continue
+			}
+			var differences EncodingDifference
+			if differences, err = diffValues(xa, ya, ad.Rule); err != nil {
+				return nil, err
+			}
+			diff.Attributes = append(diff.Attributes, AttributeEncodingDiff{
+				RDN:         d.RDN,
+				A:           ad.Issuer,
+				B:           ad.Subject,
+				Type:        cloneOid(xa.Oid),
+				TagA:        xa.RawValue.Tag,
+				TagB:        ya.RawValue.Tag,
+				LengthA:     len(xa.RawValue.Bytes),
+				LengthB:     len(ya.RawValue.Bytes),
+				Differences: differences,
+			})
+		}
+		if reordered {
+			diff.ReorderedRDNs = append(diff.ReorderedRDNs, d.RDN)
+		}
+	}
+	diff.Other = diff.IsEmpty() && !bytes.Equal(a, b)
+	return diff, nil
+}
+
+//diffValues returns the reasons why the encodings of x and y, which matched by rule, differ.
+func diffValues(x Attribute, y Attribute, rule AppliedRule) (differences EncodingDifference, err error) {
+	if x.RawValue.Tag != y.RawValue.Tag {
+		differences |= EncodingDifferenceTag
+	}
+	switch rule {
+	case AppliedRuleCaseInsensitiveExactMatch, AppliedRuleCaseExactMatch, AppliedRuleCaseIgnoreMatch:
+	default:
+		//the other rules compare the values without the string preparation
+		return differences, nil
+	}
+	var s, t string
+	if s, err = toString(x.RawValue.FullBytes); err != nil {
+		return 0, err
+	}
+	if t, err = toString(y.RawValue.FullBytes); err != nil {
+		return 0, err
+	}
+	if s == t {
+		return differences, nil
+	}
+	if rule == AppliedRuleCaseInsensitiveExactMatch {
+		return differences | EncodingDifferenceCase, nil
+	}
+
+	caseFold := rule == AppliedRuleCaseIgnoreMatch
+	if rule == AppliedRuleCaseIgnoreMatch && !equalPrepared(s, t, false, true) {
+		differences |= EncodingDifferenceCase
+	}
+	if !equalPrepared(s, t, caseFold, false) {
+		differences |= EncodingDifferenceSpace
+	}
+	if collapseString(s, caseFold) != collapseString(t, caseFold) {
+		differences |= EncodingDifferenceCharacters
+	}
+	return differences, nil
+}
+
+//equalPrepared reports whether s and t are the same after the string preparation,
+//applying case folding only if caseFold is true and the insignificant space handling only if spaces is true.
+func equalPrepared(s string, t string, caseFold bool, spaces bool) bool {
+	prepare := func(s string) string {
+		u := ldapstrprep.Normalize(ldapstrprep.MapCharacters(ldapstrprep.Transcode(s), caseFold))
+		if spaces {
+			u = ldapstrprep.ApplyInsignificantSpaceHandling(u)
+		}
+		return string(u)
+	}
+	return prepare(s) == prepare(t)
+}
+
+//collapseString returns s whose runs of spaces are replaced with a space and leading and trailing spaces are removed.
+//If lower is true, s is converted to lower case. The values which differ after collapseString differ in other ways than
+//case and spaces.
+func collapseString(s string, lower bool) string {
+	if lower {
+		s = strings.ToLower(s)
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,85 @@
+package dn
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	//C=JP(PrintableString),CN=ＡＢＣ(UTF8String)
+	dnFullWidthb, _ = hex.DecodeString("3021310b3009060355040613024a503112301006035504030c09efbca1efbca2efbca3")
+	//C=JP(PrintableString),DC=COM(IA5String),DC=example(IA5String),CN=abc(UTF8String)
+	dnUpperDCb, _ = hex.DecodeString("3049310b3009060355040613024a5031133011060a0992268993f22c6401191603434f4d31173015060a0992268993f22c64011916076578616d706c65310c300a06035504030c03616263")
+)
+
+func TestDiffEncodings(t *testing.T) {
+	type args struct {
+		a []byte
+		b []byte
+	}
+	type wantAttribute struct {
+		rdn         int
+		differences EncodingDifference
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantAttributes []wantAttribute
+		wantReordered  []int
+		wantErr        bool
+	}{
+		{"Byte-identical", args{dn1b, dn1b}, nil, nil, false},
+		{"Different tags", args{dn2b, dn3b}, []wantAttribute{{1, EncodingDifferenceTag}}, nil, false},
+		{"Different case", args{dn2b, dn4b}, []wantAttribute{{1, EncodingDifferenceCase}}, nil, false},
+		{"Different tags and case", args{dn3b, dn4b}, []wantAttribute{{1, EncodingDifferenceTag | EncodingDifferenceCase}}, nil, false},
+		{"Different spaces", args{dn18b, dn2b}, []wantAttribute{{0, EncodingDifferenceSpace}}, nil, false},
+		{"Different characters", args{dnFullWidthb, dn2b}, []wantAttribute{{1, EncodingDifferenceCharacters}}, nil, false},
+		{"Domain components, Different case", args{dn17b, dnUpperDCb}, []wantAttribute{{1, EncodingDifferenceCase}}, nil, false},
+		{"unstructuredName, Different spaces", args{dn23b, dn25b}, []wantAttribute{{1, EncodingDifferenceSpace}}, nil, false},
+		{"Multi RDN not in DER order", args{dn1b, dn16b}, nil, []int{1}, false},
+		{"Not matched", args{dn2b, dn6b}, nil, nil, true},
+		{"Broken data", args{dn2b, brdnb}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffEncodings(tt.args.a, tt.args.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DiffEncodings() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.IsEmpty() != (string(tt.args.a) == string(tt.args.b)) {
+				t.Errorf("DiffEncodings() IsEmpty() = %v for %x and %x", got.IsEmpty(), tt.args.a, tt.args.b)
+			}
+			if len(got.Attributes) != len(tt.wantAttributes) {
+				t.Fatalf("DiffEncodings() Attributes = %+v, want %d attributes", got.Attributes, len(tt.wantAttributes))
+			}
+			for i, w := range tt.wantAttributes {
+				if got.Attributes[i].RDN != w.rdn || got.Attributes[i].Differences != w.differences {
+					t.Errorf("DiffEncodings() Attributes[%d] = %+v, want RDN %d, Differences %v", i, got.Attributes[i], w.rdn, w.differences)
+				}
+			}
+			if len(got.ReorderedRDNs) != len(tt.wantReordered) || (len(tt.wantReordered) != 0 && got.ReorderedRDNs[0] != tt.wantReordered[0]) {
+				t.Errorf("DiffEncodings() ReorderedRDNs = %v, want %v", got.ReorderedRDNs, tt.wantReordered)
+			}
+		})
+	}
+}
+
+func TestEncodingDifference_String(t *testing.T) {
+	tests := []struct {
+		d    EncodingDifference
+		want string
+	}{
+		{0, ""},
+		{EncodingDifferenceTag, "tag"},
+		{EncodingDifferenceTag | EncodingDifferenceCase | EncodingDifferenceSpace | EncodingDifferenceCharacters, "tag|case|space|characters"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
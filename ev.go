@@ -0,0 +1,150 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+//Names of the lint rules of ProfileEVv1. They run only if Profile.Rules names them.
+const (
+	LintRuleEVv1RequiredAttributes  = "ev-v1-required-attributes"  //absent attributes which EV subjects must contain
+	LintRuleEVv1JurisdictionCountry = "ev-v1-jurisdiction-country" //jurisdictionCountryName values which are not PrintableString ISO 3166-1 codes
+	LintRuleEVv1JurisdictionOrder   = "ev-v1-jurisdiction-order"   //jurisdiction attributes without their enclosing jurisdictions
+	LintRuleEVv1BusinessCategory    = "ev-v1-business-category"    //businessCategory values which are not the defined categories
+)
+
+//ProfileEVv1 is the Profile of the subject of EV TLS certificates by the CA/Browser Forum EV Guidelines version 1.8.
+//It runs the rules of ProfileCABFv2 too, because EV certificates also conform to the Baseline Requirements.
+//Each finding cites the section of the EV Guidelines or the Baseline Requirements which it violates.
+//It returns a new Profile for each call, so that changing the result does not affect the other callers.
+//https://cabforum.org/extended-validation/
+func ProfileEVv1() Profile {
+	return Profile{
+		MaxOccurrences: map[string]int{
+			"1.3.6.1.4.1.311.60.2.1.1": 1, //jurisdictionLocalityName
+			"1.3.6.1.4.1.311.60.2.1.2": 1, //jurisdictionStateOrProvinceName
+			"1.3.6.1.4.1.311.60.2.1.3": 1, //jurisdictionCountryName
+			"2.5.4.15":                 1, //businessCategory
+			"2.5.4.5":                  1, //serialNumber
+		},
+		Rules: append(append([]string{LintRuleMultiplicity}, ProfileCABFv2().Rules...),
+			LintRuleEVv1RequiredAttributes,
+			LintRuleEVv1JurisdictionCountry,
+			LintRuleEVv1JurisdictionOrder,
+			LintRuleEVv1BusinessCategory,
+		),
+	}
+}
+
+//https://cabforum.org/extended-validation/ EV Guidelines section 9.2.4
+//jurisdictionLocalityName: 1.3.6.1.4.1.311.60.2.1.1
+//jurisdictionStateOrProvinceName: 1.3.6.1.4.1.311.60.2.1.2
+var (
+	oidJurisdictionLocalityName        = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 60, 2, 1, 1}
+	oidJurisdictionStateOrProvinceName = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 60, 2, 1, 2}
+)
+
+//https://www.itu.int/rec/T-REC-X.520
+//id-at-businessCategory  AttributeType ::= { id-at 15 }
+var oidBusinessCategory = asn1.ObjectIdentifier{2, 5, 4, 15}
+
+//evBusinessCategories are the values of businessCategory defined by EV Guidelines section 9.2.3.
+var evBusinessCategories = map[string]bool{
+	"Private Organization":  true,
+	"Government Entity":     true,
+	"Business Entity":       true,
+	"Non-Commercial Entity": true,
+}
+
+//lintEVv1RequiredAttributes reports the attribute types which EV subjects must contain but d does not.
+func lintEVv1RequiredAttributes(d dn) (findings []Finding) {
+	for _, required := range []struct {
+		oid     asn1.ObjectIdentifier
+		section string
+	}{
+		{oidOrganizationName, "9.2.1"},
+		{oidBusinessCategory, "9.2.3"},
+		{oidJurisdictionCountryName, "9.2.4"},
+		{oidSerialNumber, "9.2.5"},
+		{oidCountryName, "9.2.6"},
+	} {
+		if countAttribute(d, required.oid) == 0 {
+			findings = append(findings, Finding{
+				RDN:       -1,
+				Attribute: -1,
+				Type:      required.oid,
+				Message:   fmt.Sprintf("attribute %s is absent (EV Guidelines %s: the field MUST be present)", required.oid, required.section),
+			})
+		}
+	}
+	return findings
+}
+
+//lintEVv1JurisdictionCountry reports jurisdictionCountryName attributes which are not encoded as PrintableString, or
+//whose values are not ISO 3166-1 alpha-2 codes.
+func lintEVv1JurisdictionCountry(d dn) (findings []Finding, err error) {
+	for i, r := range d {
+		for j, atv := range r {
+			if !oidEqual(atv.Oid, oidJurisdictionCountryName) {
+				continue
+			}
+			finding := Finding{RDN: i, Attribute: j, Type: atv.Oid}
+			if atv.RawValue.Class != asn1.ClassUniversal || atv.RawValue.Tag != asn1.TagPrintableString {
+				finding.Message = fmt.Sprintf("attribute %s is not PrintableString (EV Guidelines 9.2.4: jurisdictionCountryName is PrintableString (SIZE(2)))", atv.Oid)
+				findings = append(findings, finding)
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				return nil, err
+			}
+			if !iso3166Alpha2[s] {
+				finding.Message = fmt.Sprintf("attribute %s is not an ISO 3166-1 alpha-2 code: %q (EV Guidelines 9.2.4: jurisdictionCountryName MUST contain the ISO 3166-1 country code)", atv.Oid, s)
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings, nil
+}
+
+//lintEVv1JurisdictionOrder reports jurisdictionStateOrProvinceName attributes without jurisdictionCountryName, and
+//jurisdictionLocalityName attributes without jurisdictionStateOrProvinceName or jurisdictionCountryName.
+//The jurisdiction of incorporation is given from the country down to the locality.
+func lintEVv1JurisdictionOrder(d dn) (findings []Finding) {
+	hasCountry := countAttribute(d, oidJurisdictionCountryName) != 0
+	hasState := countAttribute(d, oidJurisdictionStateOrProvinceName) != 0
+	if !hasCountry {
+		findings = append(findings, lintAttributeTypes(d, "is present without jurisdictionCountryName (EV Guidelines 9.2.4)",
+			oidJurisdictionStateOrProvinceName)...)
+	}
+	if !hasCountry || !hasState {
+		findings = append(findings, lintAttributeTypes(d, "is present without jurisdictionStateOrProvinceName and jurisdictionCountryName (EV Guidelines 9.2.4)",
+			oidJurisdictionLocalityName)...)
+	}
+	return findings
+}
+
+//lintEVv1BusinessCategory reports businessCategory attributes whose values are not the categories of
+//evBusinessCategories.
+func lintEVv1BusinessCategory(d dn) (findings []Finding, err error) {
+	for i, r := range d {
+		for j, atv := range r {
+			if !oidEqual(atv.Oid, oidBusinessCategory) || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				return nil, err
+			}
+			if !evBusinessCategories[s] {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("attribute %s is not a business category: %q (EV Guidelines 9.2.3)", atv.Oid, s),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
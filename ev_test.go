@@ -0,0 +1,88 @@
+package dn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateProfile_EVv1(t *testing.T) {
+	const ev = "CN=www.example.com,O=Example Inc.,L=San Francisco,ST=California,C=US,SERIALNUMBER=5157550,jurisdictionST=Delaware,jurisdictionC=US,businessCategory=Private Organization"
+	type args struct {
+		dnBytes []byte
+		p       Profile
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantFindings []Finding
+		wantErr      bool
+	}{
+		{"EV subject", args{dn107b, ProfileEVv1()}, nil, false},
+		{"EV subject, Parsed", args{mustMarshalString(t, ev), ProfileEVv1()}, nil, false},
+		{"Locality-level jurisdiction", args{mustMarshalString(t, "CN=www.example.com,O=Example K.K.,C=JP,SERIALNUMBER=0100-01-000000,jurisdictionL=Chiyoda-ku+jurisdictionST=Tokyo+jurisdictionC=JP,businessCategory=Private Organization"), ProfileEVv1()}, nil, false},
+		{"EV subject, Default profile", args{dn108b, DefaultProfile()}, nil, false},
+		{"jurisdictionC in UTF8String", args{dn108b, ProfileEVv1()},
+			[]Finding{{RDN: 1, Attribute: 0, Type: oidJurisdictionCountryName, Message: "attribute 1.3.6.1.4.1.311.60.2.1.3 is not PrintableString (EV Guidelines 9.2.4: jurisdictionCountryName is PrintableString (SIZE(2)))"}}, false},
+		{"jurisdictionC is not ISO 3166", args{mustMarshalString(t, "CN=www.example.com,O=Example Inc.,C=US,SERIALNUMBER=5157550,jurisdictionC=USA,businessCategory=Private Organization"), ProfileEVv1()},
+			[]Finding{
+				{RDN: 1, Attribute: 0, Type: oidJurisdictionCountryName, Message: "attribute 1.3.6.1.4.1.311.60.2.1.3 is 3 characters long, max 2"},
+				{RDN: 1, Attribute: 0, Type: oidJurisdictionCountryName, Message: `attribute 1.3.6.1.4.1.311.60.2.1.3 is not an ISO 3166-1 alpha-2 code: "USA" (EV Guidelines 9.2.4: jurisdictionCountryName MUST contain the ISO 3166-1 country code)`},
+			}, false},
+		{"jurisdictionST without jurisdictionC", args{mustMarshalString(t, "CN=www.example.com,O=Example Inc.,C=US,SERIALNUMBER=5157550,jurisdictionST=Delaware,businessCategory=Private Organization"), ProfileEVv1()},
+			[]Finding{
+				{RDN: -1, Attribute: -1, Type: oidJurisdictionCountryName, Message: "attribute 1.3.6.1.4.1.311.60.2.1.3 is absent (EV Guidelines 9.2.4: the field MUST be present)"},
+				{RDN: 1, Attribute: 0, Type: oidJurisdictionStateOrProvinceName, Message: "attribute 1.3.6.1.4.1.311.60.2.1.2 is present without jurisdictionCountryName (EV Guidelines 9.2.4)"},
+			}, false},
+		{"jurisdictionL without jurisdictionST", args{mustMarshalString(t, "CN=www.example.com,O=Example Inc.,C=US,SERIALNUMBER=5157550,jurisdictionL=Wilmington,jurisdictionC=US,businessCategory=Private Organization"), ProfileEVv1()},
+			[]Finding{{RDN: 2, Attribute: 0, Type: oidJurisdictionLocalityName, Message: "attribute 1.3.6.1.4.1.311.60.2.1.1 is present without jurisdictionStateOrProvinceName and jurisdictionCountryName (EV Guidelines 9.2.4)"}}, false},
+		{"Unknown business category", args{mustMarshalString(t, "CN=www.example.com,O=Example Inc.,C=US,SERIALNUMBER=5157550,jurisdictionC=US,businessCategory=Private"), ProfileEVv1()},
+			[]Finding{{RDN: 0, Attribute: 0, Type: oidBusinessCategory, Message: `attribute 2.5.4.15 is not a business category: "Private" (EV Guidelines 9.2.3)`}}, false},
+		{"Missing businessCategory and serialNumber", args{mustMarshalString(t, "CN=www.example.com,O=Example Inc.,C=US,jurisdictionC=US"), ProfileEVv1()},
+			[]Finding{
+				{RDN: -1, Attribute: -1, Type: oidBusinessCategory, Message: "attribute 2.5.4.15 is absent (EV Guidelines 9.2.3: the field MUST be present)"},
+				{RDN: -1, Attribute: -1, Type: oidSerialNumber, Message: "attribute 2.5.4.5 is absent (EV Guidelines 9.2.5: the field MUST be present)"},
+			}, false},
+		{"Baseline Requirements", args{mustMarshalString(t, "CN=www.example.com,OU=IT,"+ev[len("CN=www.example.com,"):]), ProfileEVv1()},
+			[]Finding{{RDN: 8, Attribute: 0, Type: oidOrganizationalUnitName, Message: "attribute 2.5.4.11 is prohibited since 2022-09-01 (BR 7.1.2.7.4: organizationalUnitName MUST NOT be present, Ballot SC47)"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFindings, err := ValidateProfile(tt.args.dnBytes, tt.args.p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotFindings, tt.wantFindings) {
+				t.Errorf("ValidateProfile() gotFindings = %v, want %v", gotFindings, tt.wantFindings)
+			}
+		})
+	}
+}
+
+func TestProfileEVv1(t *testing.T) {
+	p := ProfileEVv1()
+	p.MaxOccurrences["2.5.4.5"] = 2
+	p.Rules[1] = LintRuleMultiplicity
+	got := ProfileEVv1()
+	if got.MaxOccurrences["2.5.4.5"] != 1 {
+		t.Errorf("ProfileEVv1().MaxOccurrences[2.5.4.5] = %v, want 1", got.MaxOccurrences["2.5.4.5"])
+	}
+	if got.Rules[1] != LintRuleEmptyValues {
+		t.Errorf("ProfileEVv1().Rules[1] = %v, want %v", got.Rules[1], LintRuleEmptyValues)
+	}
+}
+
+func TestDN_String_EV(t *testing.T) {
+	d, err := ParseDN(dn107b)
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	want := `CN=www.example.com,O=Example\, Inc.,L=San Francisco,ST=California,C=US,SERIALNUMBER=5157550,jurisdictionST=Delaware,jurisdictionC=US,businessCategory=Private Organization`
+	if got := d.String(); got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+	parsed := mustMarshalString(t, want)
+	if result, err := Compare(dn107b, parsed); err != nil || !result {
+		t.Errorf("Compare() = %v, %v, want true", result, err)
+	}
+}
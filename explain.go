@@ -0,0 +1,206 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+//AppliedRule is the rule which is applied to compare a pair of attributes.
+type AppliedRule int
+
+//Rules applied to compare attributes.
+const (
+	AppliedRuleNone                      AppliedRule = 0 //no attributes of the same type to compare
+	AppliedRuleCaseInsensitiveExactMatch AppliedRule = 1 //domainComponent(RFC5280 section-7.3)
+	AppliedRuleCaseIgnoreMatch           AppliedRule = 2 //caseIgnoreMatch(RFC4517 section-4.2.11)
+	AppliedRuleCaseExactMatch            AppliedRule = 3 //caseExactMatch(RFC4517 section-4.2.4)
+	AppliedRuleBinaryComparison          AppliedRule = 4 //binary comparison(RFC5280 section-7.1)
+	AppliedRuleDistinguishedNameMatch    AppliedRule = 5 //distinguishedNameMatch(RFC4517 section-4.2.15)
+	AppliedRuleBitStringMatch            AppliedRule = 6 //bitStringMatch(RFC4517 section-4.2.1)
+	AppliedRuleEmailAddressMatch         AppliedRule = 7 //internationalized email address(RFC6530), see WithInternationalizedEmail
+	AppliedRuleNumericStringMatch        AppliedRule = 8 //numericStringMatch(RFC4517 section-4.2.22)
+	AppliedRuleMatchingFunc              AppliedRule = 9 //MatchingFunc registered by WithMatchingFunc
+)
+
+var appliedRuleNames = []string{"none", "caseInsensitiveExactMatch", "caseIgnoreMatch", "caseExactMatch", "binaryComparison", "distinguishedNameMatch", "bitStringMatch", "emailAddressMatch", "numericStringMatch", "matchingFunc"}
+
+//String returns the name of r.
+func (r AppliedRule) String() string {
+	if r < 0 || int(r) >= len(appliedRuleNames) {
+		return fmt.Sprintf("AppliedRule(%d)", int(r))
+	}
+	return appliedRuleNames[r]
+}
+
+//MarshalText returns the name of r, so that reports are serialized with the names of the rules.
+func (r AppliedRule) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+//AttributeDecision is the result of the comparison of an attribute of the issuer.
+type AttributeDecision struct {
+	Type    asn1.ObjectIdentifier
+	Issuer  int         //index of the attribute in the RDN of the issuer
+	Subject int         //index of the matched attribute in the RDN of the subject, or -1 if no attributes matched
+	Rule    AppliedRule //rule applied to the matched attribute, or to the last attribute of the same type if no attributes matched
+	Matched bool
+}
+
+//RDNDecision is the result of the comparison of an RDN.
+type RDNDecision struct {
+	RDN        int //index of the RDN in the RDNSequence
+	Matched    bool
+	Attributes []AttributeDecision
+	Reason     string //reason of the mismatch, empty if Matched
+}
+
+//CompareExplain reports whether issuer and subject matches in the same way as Compare,
+//and returns the decisions for the RDNs compared. If they do not match, the last decision is the failing one.
+func CompareExplain(issuer []byte, subject []byte) (result bool, decisions []RDNDecision, err error) {
+	return NewComparer().CompareExplain(issuer, subject)
+}
+
+//CompareExplain reports whether issuer and subject matches in the same way as c.Compare,
+//and returns the decisions for the RDNs compared. If they do not match, the last decision is the failing one.
+func (c *Comparer) CompareExplain(issuer []byte, subject []byte) (result bool, decisions []RDNDecision, err error) {
+	result, decisions, _, err = c.compare(issuer, subject, true)
+	return result, decisions, err
+}
+
+//explainDistinguishedName compares xd and yd in the same way as matchDistinguishedName, recording the decisions.
+//The structures of xd and yd are compared first in the same way, so that it returns the same result as
+//matchDistinguishedName: if they differ, the only decision is for the first RDN whose structure differs.
+func (c *Comparer) explainDistinguishedName(xd dn, yd dn) (result bool, decisions []RDNDecision, err error) {
+	n := len(xd)
+	if len(yd) < n {
+		n = len(yd)
+	}
+	if !sameStructure(xd, yd) {
+		d := explainStructure(xd, yd, n)
+		if c.trace != nil {
+			c.traceRDN(d)
+		}
+		return false, []RDNDecision{d}, nil
+	}
+	for i := 0; i < n; i++ {
+		var d RDNDecision
+		if d, err = c.explainRelativeDistinguishedName(i, xd[i], yd[i]); err != nil {
+			return false, nil, err
+		}
+		decisions = append(decisions, d)
+		if c.trace != nil {
+			c.traceRDN(d)
+		}
+		if !d.Matched {
+			return false, decisions, nil
+		}
+	}
+	if len(xd) != len(yd) {
+		decisions = append(decisions, RDNDecision{
+			RDN:    n,
+			Reason: fmt.Sprintf("different number of RDNs: issuer has %d, subject has %d", len(xd), len(yd)),
+		})
+		if c.trace != nil {
+			c.traceRDN(decisions[len(decisions)-1])
+		}
+		return false, decisions, nil
+	}
+	return true, decisions, nil
+}
+
+//explainStructure returns the decision for the first RDN of xd and yd whose structure differs, where n is the
+//smaller number of their RDNs.
+func explainStructure(xd dn, yd dn, n int) RDNDecision {
+	for i := 0; i < n; i++ {
+		if len(xd[i]) != len(yd[i]) {
+			return RDNDecision{RDN: i, Reason: fmt.Sprintf("different number of attributes: issuer has %d, subject has %d", len(xd[i]), len(yd[i]))}
+		}
+		if !sameAttributeTypes(xd[i], yd[i]) {
+			return RDNDecision{RDN: i, Reason: "different attribute types"}
+		}
+	}
+	return RDNDecision{RDN: n, Reason: fmt.Sprintf("different number of RDNs: issuer has %d, subject has %d", len(xd), len(yd))}
+}
+
+//explainRelativeDistinguishedName compares xr and yr in the same way as compareRelativeDistinguishedName,
+//recording the decisions.
+func (c *Comparer) explainRelativeDistinguishedName(index int, xr rdnSET, yr rdnSET) (d RDNDecision, err error) {
+	d.RDN = index
+	if len(xr) != len(yr) {
+		d.Reason = fmt.Sprintf("different number of attributes: issuer has %d, subject has %d", len(xr), len(yr))
+		return d, nil
+	}
+	//rules[i] is the rule applied to the last attribute compared with the i-th attribute
+	rules := make([]AppliedRule, len(xr))
+	a := newAttributeAssignment(len(xr), len(yr), func(i int, j int) (result bool, err error) {
+		x, y := xr[i], yr[j]
+		if !oidEqual(x.Oid, y.Oid) {
+			return false, nil
+		}
+		rules[i] = appliedRule(x, y, c.matchingRule(x.Oid))
+		if result, err = c.compareAttribute(x, y); err != nil {
+			return false, err
+		}
+		if c.trace != nil {
+			c.traceAttribute(index, i, j, x, y, rules[i], result)
+		}
+		return result, nil
+	})
+	d.Matched = true
+	for i, x := range xr {
+		isFound := false
+		if isFound, err = a.assign(i); err != nil {
+			return RDNDecision{}, err
+		}
+		if !isFound && d.Matched {
+			d.Matched = false
+			d.Reason = fmt.Sprintf("attribute %s of the issuer has no matching attribute", x.Oid)
+		}
+	}
+	//the attributes may be reassigned by the later attributes
+	for i, x := range xr {
+		ad := AttributeDecision{Type: cloneOid(x.Oid), Issuer: i, Subject: a.assigned[i], Rule: rules[i], Matched: a.assigned[i] != -1}
+		if ad.Matched {
+			ad.Rule = appliedRule(x, yr[ad.Subject], c.matchingRule(x.Oid))
+		}
+		d.Attributes = append(d.Attributes, ad)
+	}
+	return d, nil
+}
+
+//appliedRule returns the rule which applies to x and y, which have the same type, if their type has rule.
+//It is the one resolver of the rules: the comparison, the constant time comparison, the canonical form, the audit,
+//CompareExplain and DiffEncodings all switch on its result. For a single attribute, x and y are the same.
+func appliedRule(x Attribute, y Attribute, rule MatchingRule) AppliedRule {
+	switch {
+	case rule == MatchingRuleDistinguishedName:
+		return AppliedRuleDistinguishedNameMatch
+	case rule == MatchingRuleBitString && isBitString(x) && isBitString(y):
+		return AppliedRuleBitStringMatch
+	case rule == MatchingRuleBitString:
+		return AppliedRuleBinaryComparison
+	case rule == MatchingRuleEmailAddress || rule == MatchingRuleEmailAddressCaseIgnore:
+		return AppliedRuleEmailAddressMatch
+	case rule == MatchingRuleNumericString:
+		return AppliedRuleNumericStringMatch
+	case rule == matchingRuleFunc:
+		return AppliedRuleMatchingFunc
+	case isIA5Rule(rule) && isIA5StringPair(x, y):
+		if rule == MatchingRuleCaseExactIA5 {
+			return AppliedRuleCaseExactMatch
+		}
+		return AppliedRuleCaseIgnoreMatch
+	case isIA5Rule(rule) || rule == MatchingRuleOctetString:
+		return AppliedRuleBinaryComparison
+	case isDomainComponent(x.Oid) && isDomainComponent(y.Oid):
+		return AppliedRuleCaseInsensitiveExactMatch
+	case oidEqual(x.Oid, oidUnstructuredName) && x.RawValue.Tag == asn1.TagIA5String && y.RawValue.Tag == asn1.TagIA5String:
+		return AppliedRuleCaseExactMatch
+	case isComparableDirectoryString(x.RawValue.Tag, y.RawValue.Tag):
+		if rule == MatchingRuleCaseExact {
+			return AppliedRuleCaseExactMatch
+		}
+		return AppliedRuleCaseIgnoreMatch
+	}
+	return AppliedRuleBinaryComparison
+}
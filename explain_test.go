@@ -0,0 +1,173 @@
+package dn
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCompareExplain(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	type wantLast struct {
+		rdn     int
+		matched bool
+		rules   []AppliedRule
+		subject []int
+	}
+	tests := []struct {
+		name          string
+		args          args
+		wantResult    bool
+		wantDecisions int
+		wantLast      wantLast
+		wantErr       bool
+	}{
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", args{dn2b, dn3b}, true, 2, wantLast{1, true, []AppliedRule{AppliedRuleCaseIgnoreMatch}, []int{0}}, false},
+		{"Multi RDN not in DER order", args{dn1b, dn16b}, true, 3, wantLast{2, true, []AppliedRule{AppliedRuleCaseIgnoreMatch}, []int{0}}, false},
+		{"Domain components", args{dn17b, dn17b}, true, 4, wantLast{3, true, []AppliedRule{AppliedRuleCaseIgnoreMatch}, []int{0}}, false},
+		{"Different characters in first RDN", args{dn2b, dn6b}, false, 1, wantLast{0, false, []AppliedRule{AppliedRuleCaseIgnoreMatch}, []int{-1}}, false},
+		{"Different Encoding(UTF8String,BMPString)", args{dn2b, dn5b}, false, 2, wantLast{1, false, []AppliedRule{AppliedRuleBinaryComparison}, []int{-1}}, false},
+		{"Different number of attributes", args{dn1b, dn2b}, false, 1, wantLast{1, false, nil, nil}, false},
+		{"Different types", args{dn12b, dn15b}, false, 1, wantLast{2, false, nil, nil}, false},
+		{"Different number of RDNs", args{base2b, dn2b}, false, 1, wantLast{1, false, nil, nil}, false},
+		{"Subject is blank", args{dn2b, []byte{}}, false, 0, wantLast{}, false},
+		{"Issuer is blank", args{[]byte{}, dn2b}, false, 0, wantLast{}, true},
+		{"Wrong Encoding domain component", args{dn7b, dn7b}, false, 0, wantLast{}, true},
+		{"Broken data", args{dn2b, brdnb}, false, 0, wantLast{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, gotDecisions, err := CompareExplain(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompareExplain() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			want, _ := Compare(tt.args.issuer, tt.args.subject)
+			if gotResult != tt.wantResult || gotResult != want {
+				t.Errorf("CompareExplain() gotResult = %v, want %v, Compare() = %v", gotResult, tt.wantResult, want)
+			}
+			if len(gotDecisions) != tt.wantDecisions {
+				t.Fatalf("CompareExplain() got %d decisions, want %d: %+v", len(gotDecisions), tt.wantDecisions, gotDecisions)
+			}
+			if len(gotDecisions) == 0 {
+				return
+			}
+			last := gotDecisions[len(gotDecisions)-1]
+			if last.RDN != tt.wantLast.rdn || last.Matched != tt.wantLast.matched {
+				t.Errorf("CompareExplain() last decision = %+v, want RDN %d, Matched %v", last, tt.wantLast.rdn, tt.wantLast.matched)
+			}
+			if last.Matched == (last.Reason != "") {
+				t.Errorf("CompareExplain() last decision Reason = %q, Matched %v", last.Reason, last.Matched)
+			}
+			if len(last.Attributes) != len(tt.wantLast.rules) {
+				t.Fatalf("CompareExplain() last decision Attributes = %+v, want %d attributes", last.Attributes, len(tt.wantLast.rules))
+			}
+			for i, ad := range last.Attributes {
+				if ad.Rule != tt.wantLast.rules[i] || ad.Subject != tt.wantLast.subject[i] {
+					t.Errorf("CompareExplain() Attributes[%d] = %+v, want Rule %v, Subject %d", i, ad, tt.wantLast.rules[i], tt.wantLast.subject[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCompareExplain_MultiValuedRDN(t *testing.T) {
+	_, decisions, err := CompareExplain(dn1b, dn16b)
+	if err != nil {
+		t.Fatalf("CompareExplain() error = %v", err)
+	}
+	//O=BAR+O=FOO matches O=FOO+O=BAR in reverse order
+	got := decisions[1].Attributes
+	if len(got) != 2 || got[0].Subject != 1 || got[1].Subject != 0 {
+		t.Errorf("CompareExplain() decisions[1].Attributes = %+v, want subjects 1 and 0", got)
+	}
+
+	//O=foo(UTF8String)+O=FOO(PrintableString)+O=foo(BMPString) matches O=foo(BMPString)+O= Foo(UTF8String)+O=foo(PrintableString)
+	if _, decisions, err = CompareExplain(dn60b, dn61b); err != nil {
+		t.Fatalf("CompareExplain() error = %v", err)
+	}
+	got = decisions[1].Attributes
+	want := []AttributeDecision{
+		{Type: got[0].Type, Issuer: 0, Subject: 1, Rule: AppliedRuleCaseIgnoreMatch, Matched: true},
+		{Type: got[0].Type, Issuer: 1, Subject: 2, Rule: AppliedRuleCaseIgnoreMatch, Matched: true},
+		{Type: got[0].Type, Issuer: 2, Subject: 0, Rule: AppliedRuleBinaryComparison, Matched: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareExplain() decisions[1].Attributes = %+v, want %+v", got, want)
+	}
+}
+
+func TestComparer_CompareExplain(t *testing.T) {
+	c := NewComparer(WithMatchingRule(oidCountryName, MatchingRuleCaseExact), WithIgnoredTypes(oidSerialNumber))
+	gotResult, gotDecisions, err := c.CompareExplain(dn12b, dn13b)
+	if err != nil {
+		t.Fatalf("CompareExplain() error = %v", err)
+	}
+	if !gotResult || len(gotDecisions) != 2 || gotDecisions[0].Attributes[0].Rule != AppliedRuleCaseExactMatch {
+		t.Errorf("CompareExplain() = %v, %+v, want true with caseExactMatch for C", gotResult, gotDecisions)
+	}
+}
+
+func TestCompareExplain_SameVerdictAsCompare(t *testing.T) {
+	//CN=A@B(PrintableString, broken)
+	brokenCN, _ := hex.DecodeString("300e310c300a06035504031303414042")
+	//CN=ABC(PrintableString),C=JP(PrintableString)
+	twoRDNs, _ := hex.DecodeString("301b310c300a06035504031303414243310b3009060355040613024a50")
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name string
+		args args
+	}{
+		{"Different number of RDNs, Broken issuer value", args{brokenCN, twoRDNs}},
+		{"Different number of RDNs, Broken subject value", args{twoRDNs, brokenCN}},
+		{"Different number of attributes, Broken value", args{dn96b, dn1b}},
+		{"Different types, Broken value", args{dn96b, dn97b}},
+		{"Same structure, Broken value", args{dn96b, dn2b}},
+		{"Match", args{dn2b, dn3b}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, wantErr := Compare(tt.args.issuer, tt.args.subject)
+			check := func(name string, got bool, err error) {
+				t.Helper()
+				if got != want || (err != nil) != (wantErr != nil) {
+					t.Errorf("%s = %v, %v, want %v, %v", name, got, err, want, wantErr)
+				}
+			}
+			got, _, err := CompareExplain(tt.args.issuer, tt.args.subject)
+			check("CompareExplain()", got, err)
+			got, err = NewComparer(WithTrace(func(TraceEvent) {})).Compare(tt.args.issuer, tt.args.subject)
+			check("Compare() with trace", got, err)
+			o := &recordingObserver{}
+			got, err = NewComparer(WithObserver(o)).Compare(tt.args.issuer, tt.args.subject)
+			check("Compare() with observer", got, err)
+			if err == nil && (len(o.results) != 1 || o.results[0].Matched != want) {
+				t.Errorf("OnCompare() = %+v, want Matched %v", o.results, want)
+			}
+		})
+	}
+}
+
+func TestAppliedRule_MarshalText(t *testing.T) {
+	got, err := json.Marshal(AttributeDecision{Type: oidCountryName, Subject: -1, Rule: AppliedRuleCaseIgnoreMatch})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"Type":[2,5,4,6],"Issuer":0,"Subject":-1,"Rule":"caseIgnoreMatch","Matched":false}`
+	if string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+	if s := AppliedRule(10).String(); s != "AppliedRule(10)" {
+		t.Errorf("String() = %s, want AppliedRule(10)", s)
+	}
+}
@@ -0,0 +1,54 @@
+package dn
+
+import (
+	"github.com/tardevnull/ldapstrprep"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+//WithFullCaseFolding makes the Comparer fold the case of the string values by the full case folding of the current
+//version of Unicode and decompose them by NFKD, instead of the mapping of RFC 3454 appendix-B.2 and NFKC of RFC 4518.
+//It reproduces the comparison of the systems which use the compatibility caseless match of Unicode.
+//
+//The mapping of RFC 3454 appendix-B.2 is the full case folding of Unicode 3.2, so that "ß" already matches "ss" by
+//default, unlike strings.EqualFold and WithSkipStringPrep. The verdicts differ for the characters assigned after
+//Unicode 3.2, which RFC 4518 prohibits as unassigned, e.g. the capital sharp s "ẞ" matches "ss" by
+//WithFullCaseFolding, while the comparison returns an error by default.
+//
+//The other steps of the string preparation(RFC4518) are the same, and caseExactMatch is not affected.
+//It is the same as WithStringPreparer by a StringPreparer which folds the case fully, so that it replaces the
+//StringPreparer set before.
+func WithFullCaseFolding() Option {
+	return WithStringPreparer(fullFoldPreparer{})
+}
+
+//fullFoldPreparer is the StringPreparer which folds the case by the full case folding of Unicode.
+type fullFoldPreparer struct{}
+
+//Prepare prepares s by the string preparation algorithm described in [RFC4518], whose case folding and normalization
+//are replaced by the compatibility caseless match of Unicode if caseFold is true.
+//s is prepared by DefaultStringPreparer if caseFold is false.
+func (fullFoldPreparer) Prepare(s string, caseFold bool) (string, error) {
+	if !caseFold {
+		return DefaultStringPreparer.Prepare(s, false)
+	}
+	//1. Transcode
+	u := ldapstrprep.Transcode(s)
+	//2. Map without case folding
+	u = ldapstrprep.MapCharacters(u, false)
+	//3. Fold and normalize
+	//https://www.unicode.org/versions/Unicode15.0.0/ch03.pdf D146
+	//A string X is a compatibility caseless match for a string Y if and only if:
+	//NFKD(toCasefold(NFKD(toCasefold(NFD(X))))) = NFKD(toCasefold(NFKD(toCasefold(NFD(Y)))))
+	fold := cases.Fold()
+	t := norm.NFKD.String(fold.String(norm.NFKD.String(fold.String(norm.NFD.String(string(u))))))
+	u = []rune(t)
+	//4. Prohibit
+	if isProhibited, err := ldapstrprep.IsProhibited(u); isProhibited {
+		return "", err
+	}
+	//5. Check Bidi
+	//Do nothing.
+	//6. Insignificant Character Handling
+	return string(ldapstrprep.ApplyInsignificantSpaceHandling(u)), nil
+}
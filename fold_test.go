@@ -0,0 +1,102 @@
+package dn
+
+import (
+	"testing"
+)
+
+func TestWithFullCaseFolding(t *testing.T) {
+	//The pairs whose verdicts change by WithFullCaseFolding, and the pairs which keep their verdicts.
+	tests := []struct {
+		name           string
+		x              string
+		y              string
+		wantDefault    bool
+		wantDefaultErr bool
+		wantResult     bool
+	}{
+		{"Capital sharp s and double s", "CN=STRAẞE", "CN=strasse", false, true, true},
+		{"Capital sharp s and sharp s", "CN=STRAẞE", "CN=straße", false, true, true},
+		{"Sharp s and double s", "CN=Straße", "CN=STRASSE", true, false, true},
+		{"Ligature and Latin", "CN=ﬁle", "CN=FILE", true, false, true},
+		{"Upper/Lower case characters", "CN=ABC", "CN=abc", true, false, true},
+		{"Umlaut", "CN=Müller", "CN=MÜLLER", true, false, true},
+		{"Composed and decomposed", `CN=Jos\C3\A9`, `CN=JOSE\CC\81`, true, false, true},
+		{"Insignificant spaces", "CN=  a  b ", "CN=A  B", true, false, true},
+		{"Accented and unaccented", "CN=José", "CN=Jose", false, false, false},
+		{"Domain components", "DC=example,DC=com", "DC=EXAMPLE,DC=COM", true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := mustMarshalString(t, tt.x)
+			y := mustMarshalString(t, tt.y)
+			gotDefault, err := Compare(x, y)
+			if (err != nil) != tt.wantDefaultErr {
+				t.Fatalf("Compare() error = %v, wantErr %v", err, tt.wantDefaultErr)
+			}
+			if gotDefault != tt.wantDefault {
+				t.Errorf("Compare() gotResult = %v, want %v", gotDefault, tt.wantDefault)
+			}
+			for _, opts := range [][]Option{{WithFullCaseFolding()}, {WithFullCaseFolding(), WithConstantTime()}} {
+				gotResult, err := NewComparer(opts...).Compare(x, y)
+				if err != nil {
+					t.Fatalf("Comparer.Compare() error = %v", err)
+				}
+				if gotResult != tt.wantResult {
+					t.Errorf("Comparer.Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+				}
+			}
+		})
+	}
+}
+
+func Test_fullFoldPreparer_Prepare(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		caseFold bool
+		want     string
+		wantErr  bool
+	}{
+		{"Sharp s", "Straße", true, " strasse ", false},
+		{"Full-width Latin", "ＡＢ", true, " ab ", false},
+		{"Case exact", "Straße", false, " Straße ", false},
+		{"Prohibited character", "a\ufffdb", true, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fullFoldPreparer{}.Prepare(tt.s, tt.caseFold)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Prepare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Prepare() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithFullCaseFolding_SimpleFolding(t *testing.T) {
+	//"ß" matches "ss" by the full case folding, but not by the simple case folding of WithSkipStringPrep.
+	x := mustMarshalString(t, "CN=Straße")
+	y := mustMarshalString(t, "CN=strasse")
+	if result, err := NewComparer(WithSkipStringPrep()).Compare(x, y); err != nil || result {
+		t.Errorf("Compare() with WithSkipStringPrep = %v, %v, want false", result, err)
+	}
+	if result, err := NewComparer(WithSkipStringPrep(), WithFullCaseFolding()).Compare(x, y); err != nil || !result {
+		t.Errorf("Compare() with WithFullCaseFolding = %v, %v, want true", result, err)
+	}
+}
+
+//mustMarshalString returns the DER encoding of s, which is the string representation of a distinguished name.
+func mustMarshalString(t *testing.T, s string) []byte {
+	t.Helper()
+	d, err := ParseString(s)
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	b, err := d.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	return b
+}
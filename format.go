@@ -0,0 +1,101 @@
+package dn
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+//formatter holds the options of Format.
+type formatter struct {
+	reverse bool
+}
+
+//FormatOption configures Format.
+type FormatOption func(*formatter)
+
+//WithReverseRDNOrder makes Format emit RDNs in the order of the encoding, i.e. from the root such as
+//C=JP,O=Example,CN=abc, instead of the order of RFC 4514, which starts with the last RDN such as CN=abc,O=Example,C=JP.
+func WithReverseRDNOrder() FormatOption {
+	return func(f *formatter) {
+		f.reverse = true
+	}
+}
+
+//String returns the string representation of d described in RFC 4514.
+func (d *DN) String() string {
+	return d.Format()
+}
+
+//Format returns the string representation of d described in RFC 4514, configured by opts.
+func (d *DN) Format(opts ...FormatOption) string {
+	f := &formatter{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	var sb strings.Builder
+	for i := range d.rdns {
+		//https://tools.ietf.org/html/rfc4514#section-2.1
+		//the output consists of the string encodings of each
+		//RelativeDistinguishedName in the RDNSequence (according to Section 2.2),
+		//starting with the last element of the sequence and moving backwards
+		//toward the first.
+		r := d.rdns[len(d.rdns)-1-i]
+		if f.reverse {
+			r = d.rdns[i]
+		}
+		if i != 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(formatRDN(r))
+	}
+	return sb.String()
+}
+
+//formatRDN returns the string representation of r described in RFC 4514.
+func formatRDN(r rdnSET) string {
+	names := make([]string, len(r))
+	for i, atv := range r {
+		names[i] = formatAttribute(atv)
+	}
+	return strings.Join(names, "+")
+}
+
+//formatAttribute returns the string representation of atv described in RFC 4514 section-2.3.
+func formatAttribute(atv Attribute) string {
+	name := attributeTypeName(atv.Oid)
+	//https://tools.ietf.org/html/rfc4514#section-2.4
+	//If the AttributeType is of the dotted-decimal form, the
+	//AttributeValue is represented by an number sign ('#' U+0023)
+	//character followed by the hexadecimal encoding of each of the octets
+	//of the BER encoding of the X.500 AttributeValue.
+	if attributeTypeIndex(atv.Oid) < 0 || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+		return name + "=#" + hex.EncodeToString(atv.RawValue.FullBytes)
+	}
+	s, err := toString(atv.RawValue.FullBytes)
+	if err != nil {
+		return name + "=#" + hex.EncodeToString(atv.RawValue.FullBytes)
+	}
+	return name + "=" + escapeValue(s)
+}
+
+//escapeValue escapes s as described in RFC 4514 section-2.4.
+func escapeValue(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ',' || c == '+' || c == '"' || c == '\\' || c == '<' || c == '>' || c == ';':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == ' ' && (i == 0 || i == len(s)-1):
+			sb.WriteString("\\ ")
+		case c == '#' && i == 0:
+			sb.WriteString("\\#")
+		case c == 0:
+			sb.WriteString("\\00")
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
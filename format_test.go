@@ -0,0 +1,69 @@
+package dn
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDN_Format(t *testing.T) {
+	//C=JP(PrintableString),1.2.3.4=x(UTF8String),x500UniqueIdentifier=(BIT STRING)
+	unknown, _ := hex.DecodeString("3027310b3009060355040613024a50310a300806032a03040c0178310c300a060355042d0303000102")
+	//CN=#a, b+c;<d> (UTF8String)
+	special, _ := hex.DecodeString("30183116301406035504030c0d2361202c20622b633b3c643e20")
+	tests := []struct {
+		name string
+		der  []byte
+		opts []FormatOption
+		want string
+	}{
+		{"Multi RDN", dn1b, nil, "CN=ABC,O=BAR+O=FOO,C=JP"},
+		{"Multi RDN, Reverse", dn1b, []FormatOption{WithReverseRDNOrder()}, "C=JP,O=BAR+O=FOO,CN=ABC"},
+		{"BMPString and domain component", dn8b, nil, "CN=ABC,O=FOO,C=JP"},
+		{"Unknown type and not string value", unknown, nil, "x500UniqueIdentifier=#0303000102,1.2.3.4=#0c0178,C=JP"},
+		{"Special characters", special, nil, `CN=\#a \, b\+c\;\<d\>\ `},
+		{"Jurisdiction country name", dn32b, nil, "O=Example,jurisdictionC=JP,C=JP"},
+		{"Role occupant", dn45b, nil, "roleOccupant=#301d310b3009060355040613024a50310e300c06035504030c05416c696365,O=Example,C=JP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDN(tt.der)
+			if err != nil {
+				t.Fatalf("ParseDN() error = %v", err)
+			}
+			if got := d.Format(tt.opts...); got != tt.want {
+				t.Errorf("Format() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDN_String(t *testing.T) {
+	d, err := ParseDN(dn1b)
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	if got, want := d.String(), "CN=ABC,O=BAR+O=FOO,C=JP"; got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+}
+
+func Test_escapeValue(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"No special characters", "abc", "abc"},
+		{"Leading and trailing spaces", " a b ", `\ a b\ `},
+		{"Leading number sign", "#a#", `\#a#`},
+		{"Special characters", `,+"\<>;`, `\,\+\"\\\<\>\;`},
+		{"NUL", "a\x00", `a\00`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeValue(tt.s); got != tt.want {
+				t.Errorf("escapeValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
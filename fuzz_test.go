@@ -0,0 +1,47 @@
+package dn_test
+
+import (
+	"bytes"
+	"github.com/tardevnull/dn"
+	"github.com/tardevnull/dn/dntest"
+	"math/rand"
+	"testing"
+)
+
+//FuzzCompare checks the properties of Compare and Canonicalize, seeded by the pairs of dntest.
+func FuzzCompare(f *testing.F) {
+	r := rand.New(rand.NewSource(1))
+	for _, cfg := range []dntest.Config{{}, {MaxAttributesPerRDN: 3}, {NonASCII: true}} {
+		for i := 0; i < 8; i++ {
+			a, b := dntest.GenerateEquivalentPair(r, cfg)
+			f.Add(a, b)
+		}
+	}
+	f.Fuzz(func(t *testing.T, a []byte, b []byte) {
+		if len(a) == 0 || len(b) == 0 {
+			return
+		}
+		result, err := dn.Compare(a, b)
+		if err != nil {
+			return
+		}
+		//the errors depend on the order in which the attributes are compared
+		if reverse, err := dn.Compare(b, a); err == nil && reverse != result {
+			t.Fatalf("Compare(%x, %x) = %v, but reverse = %v", a, b, result, reverse)
+		}
+		if !result {
+			return
+		}
+		ca, err := dn.Canonicalize(a)
+		if err != nil {
+			t.Fatalf("Canonicalize(%x) error = %v", a, err)
+		}
+		cb, err := dn.Canonicalize(b)
+		if err != nil {
+			t.Fatalf("Canonicalize(%x) error = %v", b, err)
+		}
+		if !bytes.Equal(ca, cb) {
+			t.Fatalf("Compare(%x, %x) = true, but Canonicalize() = %x and %x", a, b, ca, cb)
+		}
+	})
+}
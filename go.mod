@@ -2,6 +2,7 @@ module github.com/tardevnull/dn
 
 go 1.22.0
 
-require github.com/tardevnull/ldapstrprep v0.0.0-20240302062337-f013461de402
-
-require golang.org/x/text v0.14.0 // indirect
+require (
+	github.com/tardevnull/ldapstrprep v0.0.0-20240302062337-f013461de402
+	golang.org/x/text v0.14.0
+)
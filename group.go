@@ -0,0 +1,153 @@
+package dn
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"hash/maphash"
+)
+
+//IssuerGroup is a group of certificates whose issuers match by Compare.
+type IssuerGroup struct {
+	Key          string //CanonicalKey of the issuer
+	Issuer       *DN    //issuer of the first certificate in the group
+	Certificates []*x509.Certificate
+}
+
+//CertificateError is the error for a certificate in a batch operation.
+type CertificateError struct {
+	Index       int //index of the certificate in the input
+	Certificate *x509.Certificate
+	Err         error
+}
+
+//GroupError is the error returned by GroupByIssuer and GroupByIssuerDN for certificates whose issuers
+//cannot be parsed. The other certificates are grouped regardless of the errors.
+type GroupError struct {
+	Errors []CertificateError
+}
+
+//Error returns the description of e.
+func (e *GroupError) Error() string {
+	return fmt.Sprintf("dn: failed to group %d certificates, first error at index %d: %v", len(e.Errors), e.Errors[0].Index, e.Errors[0].Err)
+}
+
+//Unwrap returns the errors of the certificates.
+func (e *GroupError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, ce := range e.Errors {
+		errs[i] = ce.Err
+	}
+	return errs
+}
+
+//GroupByIssuer groups certs by their issuers, which match by Compare. The keys of the result are CanonicalKey of the issuers.
+//Certificates whose issuers cannot be parsed are not in the result but reported by *GroupError with the certificates.
+func GroupByIssuer(certs []*x509.Certificate) (groups map[string][]*x509.Certificate, err error) {
+	return GroupByIssuerContext(context.Background(), certs)
+}
+
+//GroupByIssuerContext is like GroupByIssuer but stops when ctx is done. ctx is checked before each certificate.
+//If ctx is done, it returns the groups of the certificates before it stopped, and an error which wraps ctx.Err()
+//and *GroupError, if any, of those certificates.
+func GroupByIssuerContext(ctx context.Context, certs []*x509.Certificate) (groups map[string][]*x509.Certificate, err error) {
+	var gs []IssuerGroup
+	gs, err = GroupByIssuerDNContext(ctx, certs)
+	groups = make(map[string][]*x509.Certificate, len(gs))
+	for _, g := range gs {
+		groups[g.Key] = g.Certificates
+	}
+	return groups, err
+}
+
+//GroupByIssuerDN is like GroupByIssuer but returns the groups in the order of their first certificates in certs,
+//with the parsed issuer of the first certificate in each group for display.
+func GroupByIssuerDN(certs []*x509.Certificate) (groups []IssuerGroup, err error) {
+	return GroupByIssuerDNContext(context.Background(), certs)
+}
+
+//GroupByIssuerDNContext is like GroupByIssuerDN but stops when ctx is done, in the same way as GroupByIssuerContext.
+func GroupByIssuerDNContext(ctx context.Context, certs []*x509.Certificate) (groups []IssuerGroup, err error) {
+	index := make(map[string]int)
+	var errs []CertificateError
+	for i, cert := range certs {
+		if ctx.Err() != nil {
+			err = contextError(ctx, i, len(certs))
+			if len(errs) != 0 {
+				err = errors.Join(err, &GroupError{Errors: errs})
+			}
+			return groups, err
+		}
+		var key string
+		if key, err = CanonicalKey(cert.RawIssuer); err != nil {
+			errs = append(errs, CertificateError{Index: i, Certificate: cert, Err: err})
+			continue
+		}
+		if j, ok := index[key]; ok {
+			groups[j].Certificates = append(groups[j].Certificates, cert)
+			continue
+		}
+		var issuer *DN
+		if issuer, err = ParseDN(cert.RawIssuer); err != nil {
+			errs = append(errs, CertificateError{Index: i, Certificate: cert, Err: err})
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, IssuerGroup{Key: key, Issuer: issuer, Certificates: []*x509.Certificate{cert}})
+	}
+	if len(errs) != 0 {
+		return groups, &GroupError{Errors: errs}
+	}
+	return groups, nil
+}
+
+//Dedupe returns the distinguished names in dns which are unique by Compare, in the order of their first appearance.
+//mapping[i] is the index in unique of the distinguished name which dns[i] matches.
+//
+//Each distinguished name is canonicalized once and indexed by the hash of the canonical form,
+//and the distinguished names are compared by Compare only if the hashes collide.
+func Dedupe(dns [][]byte) (unique [][]byte, mapping []int, err error) {
+	return DedupeContext(context.Background(), dns)
+}
+
+//DedupeContext is like Dedupe but stops when ctx is done. ctx is checked before each distinguished name.
+//If ctx is done, it returns the result for the distinguished names before it stopped, i.e. mapping is shorter
+//than dns, and an error which wraps ctx.Err().
+func DedupeContext(ctx context.Context, dns [][]byte) (unique [][]byte, mapping []int, err error) {
+	seed := maphash.MakeSeed()
+	buckets := make(map[uint64][]int, len(dns))
+	mapping = make([]int, len(dns))
+	for i, d := range dns {
+		if ctx.Err() != nil {
+			return unique, mapping[:i], contextError(ctx, i, len(dns))
+		}
+		var key []byte
+		if key, err = Canonicalize(d); err != nil {
+			return nil, nil, fmt.Errorf("dn: dns[%d]: %w", i, err)
+		}
+		h := maphash.Bytes(seed, key)
+		found := false
+		for _, j := range buckets[h] {
+			if found, err = Compare(unique[j], d); err != nil {
+				return nil, nil, fmt.Errorf("dn: dns[%d]: %w", i, err)
+			}
+			if found {
+				mapping[i] = j
+				break
+			}
+		}
+		if !found {
+			mapping[i] = len(unique)
+			buckets[h] = append(buckets[h], len(unique))
+			unique = append(unique, d)
+		}
+	}
+	return unique, mapping, nil
+}
+
+//contextError returns the error which wraps ctx.Err() of a batch operation which stopped after done of total inputs.
+//Callers can distinguish it from the errors of the inputs by errors.Is with context.Canceled or context.DeadlineExceeded.
+func contextError(ctx context.Context, done int, total int) error {
+	return fmt.Errorf("dn: stopped after %d of %d inputs: %w", done, total, ctx.Err())
+}
@@ -0,0 +1,247 @@
+package dn
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGroupByIssuerDN(t *testing.T) {
+	//C=JP,CN=ABC in UTF8String, C=US,CN=DEF, C=JP,CN=ABC in PrintableString, broken, C=JP,CN=abc, wrong domain component
+	certs := []*x509.Certificate{
+		{RawIssuer: dn2b}, {RawIssuer: dn6b}, {RawIssuer: dn3b}, {RawIssuer: brdnb}, {RawIssuer: dn4b}, {RawIssuer: dn7b},
+	}
+	groups, err := GroupByIssuerDN(certs)
+
+	var ge *GroupError
+	if !errors.As(err, &ge) {
+		t.Fatalf("GroupByIssuerDN() error = %v, want *GroupError", err)
+	}
+	if len(ge.Errors) != 2 || ge.Errors[0].Index != 3 || ge.Errors[1].Index != 5 || ge.Errors[0].Certificate != certs[3] {
+		t.Errorf("GroupByIssuerDN() errors = %+v, want errors at index 3 and 5", ge.Errors)
+	}
+
+	wantGroups := []struct {
+		issuer string
+		certs  []*x509.Certificate
+	}{
+		{"CN=ABC,C=JP", []*x509.Certificate{certs[0], certs[2], certs[4]}},
+		{"CN=DEF,C=US", []*x509.Certificate{certs[1]}},
+	}
+	if len(groups) != len(wantGroups) {
+		t.Fatalf("GroupByIssuerDN() got %d groups, want %d", len(groups), len(wantGroups))
+	}
+	for i, want := range wantGroups {
+		if groups[i].Issuer.String() != want.issuer {
+			t.Errorf("GroupByIssuerDN() groups[%d].Issuer = %v, want %v", i, groups[i].Issuer, want.issuer)
+		}
+		if len(groups[i].Certificates) != len(want.certs) {
+			t.Errorf("GroupByIssuerDN() groups[%d] has %d certificates, want %d", i, len(groups[i].Certificates), len(want.certs))
+			continue
+		}
+		for j, c := range want.certs {
+			if groups[i].Certificates[j] != c {
+				t.Errorf("GroupByIssuerDN() groups[%d].Certificates[%d] is not the expected certificate", i, j)
+			}
+		}
+	}
+}
+
+func TestGroupByIssuer(t *testing.T) {
+	certs := []*x509.Certificate{{RawIssuer: dn2b}, {RawIssuer: dn6b}, {RawIssuer: dn5b}, {RawIssuer: dn3b}}
+	groups, err := GroupByIssuer(certs)
+	if err != nil {
+		t.Fatalf("GroupByIssuer() error = %v", err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("GroupByIssuer() got %d groups, want 3", len(groups))
+	}
+	key, err := CanonicalKey(dn3b)
+	if err != nil {
+		t.Fatalf("CanonicalKey() error = %v", err)
+	}
+	if g := groups[key]; len(g) != 2 || g[0] != certs[0] || g[1] != certs[3] {
+		t.Errorf("GroupByIssuer() groups[CanonicalKey(dn3b)] = %v, want certificates 0 and 3", g)
+	}
+}
+
+func TestGroupByIssuer_EncodingVariants(t *testing.T) {
+	//the issuers in each group match by Compare, but differ in the encodings
+	groups := [][]*x509.Certificate{
+		{{RawIssuer: dnExamplePrintableb}, {RawIssuer: dn36b}, {RawIssuer: dn37b}},
+		{{RawIssuer: dn1b}, {RawIssuer: dn16b}},
+		{{RawIssuer: dn54b}, {RawIssuer: dn55b}},
+		{{RawIssuer: dn45b}, {RawIssuer: dn46b}},
+		{{RawIssuer: dn2b}, {RawIssuer: dn3b}, {RawIssuer: dn4b}},
+	}
+	var certs []*x509.Certificate
+	for _, g := range groups {
+		certs = append(certs, g...)
+	}
+	got, err := GroupByIssuer(certs)
+	if err != nil {
+		t.Fatalf("GroupByIssuer() error = %v", err)
+	}
+	if len(got) != len(groups) {
+		t.Fatalf("GroupByIssuer() got %d groups, want %d", len(got), len(groups))
+	}
+	for i, g := range groups {
+		key, err := CanonicalKey(g[0].RawIssuer)
+		if err != nil {
+			t.Fatalf("CanonicalKey() error = %v", err)
+		}
+		if !reflect.DeepEqual(got[key], g) {
+			t.Errorf("GroupByIssuer() group %d = %v, want %v", i, got[key], g)
+		}
+	}
+}
+
+func TestGroupByIssuer_Empty(t *testing.T) {
+	groups, err := GroupByIssuer(nil)
+	if err != nil || len(groups) != 0 {
+		t.Errorf("GroupByIssuer() = %v, %v, want empty", groups, err)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	tests := []struct {
+		name        string
+		dns         [][]byte
+		wantUnique  [][]byte
+		wantMapping []int
+		wantErr     bool
+	}{
+		{"Encoding and case variants", [][]byte{dn2b, dn6b, dn3b, dn5b, dn4b, dn6b}, [][]byte{dn2b, dn6b, dn5b}, []int{0, 1, 0, 2, 0, 1}, false},
+		{"Multi RDN not in DER order", [][]byte{dn16b, dn1b}, [][]byte{dn16b}, []int{0, 0}, false},
+		{"Empty", nil, nil, []int{}, false},
+		{"Broken data", [][]byte{dn2b, brdnb}, nil, nil, true},
+		{"Wrong Encoding domain component", [][]byte{dn7b}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUnique, gotMapping, err := Dedupe(tt.dns)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Dedupe() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotUnique, tt.wantUnique) {
+				t.Errorf("Dedupe() gotUnique = %x, want %x", gotUnique, tt.wantUnique)
+			}
+			if !reflect.DeepEqual(gotMapping, tt.wantMapping) {
+				t.Errorf("Dedupe() gotMapping = %v, want %v", gotMapping, tt.wantMapping)
+			}
+		})
+	}
+}
+
+//cancelAfterContext is a context which is canceled when Err is called more than n times,
+//so that a batch operation is canceled deterministically after n inputs.
+type cancelAfterContext struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfterContext) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestGroupByIssuerDNContext(t *testing.T) {
+	certs := []*x509.Certificate{{RawIssuer: dn2b}, {RawIssuer: brdnb}, {RawIssuer: dn3b}, {RawIssuer: dn6b}, {RawIssuer: dn4b}}
+	tests := []struct {
+		name           string
+		n              int
+		wantGroups     []int //number of certificates in each group
+		wantErr        error
+		wantGroupError bool
+	}{
+		{"Not canceled", 10, []int{3, 1}, nil, true},
+		{"Canceled at first", 0, []int{}, context.Canceled, false},
+		{"Canceled after a certificate", 1, []int{1}, context.Canceled, false},
+		{"Canceled after a broken certificate", 2, []int{1}, context.Canceled, true},
+		{"Canceled mid-batch", 4, []int{2, 1}, context.Canceled, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := GroupByIssuerDNContext(&cancelAfterContext{Context: context.Background(), n: tt.n}, certs)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("GroupByIssuerDNContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && errors.Is(err, context.Canceled) {
+				t.Errorf("GroupByIssuerDNContext() error = %v, want no cancellation", err)
+			}
+			var ge *GroupError
+			if errors.As(err, &ge) != tt.wantGroupError {
+				t.Errorf("GroupByIssuerDNContext() error = %v, wantGroupError %v", err, tt.wantGroupError)
+			}
+			got := make([]int, len(groups))
+			for i, g := range groups {
+				got[i] = len(g.Certificates)
+			}
+			if !reflect.DeepEqual(got, tt.wantGroups) {
+				t.Errorf("GroupByIssuerDNContext() got groups of %v certificates, want %v", got, tt.wantGroups)
+			}
+		})
+	}
+}
+
+func TestGroupByIssuerContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	groups, err := GroupByIssuerContext(ctx, []*x509.Certificate{{RawIssuer: dn2b}})
+	if !errors.Is(err, context.DeadlineExceeded) || len(groups) != 0 {
+		t.Errorf("GroupByIssuerContext() = %v, %v, want no groups and context.DeadlineExceeded", groups, err)
+	}
+}
+
+func TestDedupeContext(t *testing.T) {
+	dns := [][]byte{dn2b, dn6b, dn3b, brdnb, dn5b}
+	tests := []struct {
+		name        string
+		n           int
+		wantUnique  [][]byte
+		wantMapping []int
+		wantErr     error
+	}{
+		{"Canceled at first", 0, nil, []int{}, context.Canceled},
+		{"Canceled mid-batch", 3, [][]byte{dn2b, dn6b}, []int{0, 1, 0}, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUnique, gotMapping, err := DedupeContext(&cancelAfterContext{Context: context.Background(), n: tt.n}, dns)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DedupeContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(gotUnique, tt.wantUnique) {
+				t.Errorf("DedupeContext() gotUnique = %x, want %x", gotUnique, tt.wantUnique)
+			}
+			if !reflect.DeepEqual(gotMapping, tt.wantMapping) {
+				t.Errorf("DedupeContext() gotMapping = %v, want %v", gotMapping, tt.wantMapping)
+			}
+		})
+	}
+
+	//the data error is not a cancellation error
+	_, _, err := DedupeContext(&cancelAfterContext{Context: context.Background(), n: 10}, dns)
+	if err == nil || errors.Is(err, context.Canceled) {
+		t.Errorf("DedupeContext() error = %v, want the error of dns[3]", err)
+	}
+}
+
+func BenchmarkDedupe(b *testing.B) {
+	dns := make([][]byte, 0, 1000)
+	for i := 0; i < 100; i++ {
+		dns = append(dns, dn1b, dn2b, dn3b, dn4b, dn5b, dn6b, dn8b, dn15b, dn16b, dn17b)
+	}
+	for i := 0; i < b.N; i++ {
+		if _, _, err := Dedupe(dns); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//https://tools.ietf.org/html/rfc5280#appendix-A.1
+//id-at-commonName        AttributeType ::= { id-at 3 }
+var oidCommonName = asn1.ObjectIdentifier{2, 5, 4, 3}
+
+//https://tools.ietf.org/html/rfc5280#appendix-A.1
+//id-at-organizationName  AttributeType ::= { id-at 10 }
+var oidOrganizationName = asn1.ObjectIdentifier{2, 5, 4, 10}
+
+//shortLabelMaxLength is the maximum number of characters of the label returned by ShortLabel, including the ellipsis.
+const shortLabelMaxLength = 64
+
+//ShortLabel returns a short human-readable label of d for UI and log lines.
+//The label is the value of the CN, or the value of the O if d has no CN, or the string representation of d
+//described in RFC 4514 if d has neither of them. When d has several of the attributes, the one in the last RDN,
+//i.e. the first one in the string representation, is used. The values which are empty or can not be decoded as
+//strings are ignored.
+//
+//The label is escaped as described in RFC 4514 section-2.4, non-printable characters are also escaped as hex pairs,
+//and it is truncated to 64 characters with an ellipsis. The escape sequences are never cut in the middle.
+func (d *DN) ShortLabel() string {
+	for _, oid := range []asn1.ObjectIdentifier{oidCommonName, oidOrganizationName} {
+		if s, ok := d.lastStringValue(oid); ok {
+			return truncateLabel(escapeLabel(escapeValue(s)))
+		}
+	}
+	return truncateLabel(escapeLabel(d.String()))
+}
+
+//lastStringValue returns the non-empty string value of the attribute of type oid in the last RDN which has one.
+func (d *DN) lastStringValue(oid asn1.ObjectIdentifier) (s string, ok bool) {
+	for i := len(d.rdns) - 1; i >= 0; i-- {
+		for _, atv := range d.rdns[i] {
+			if !oidEqual(atv.Oid, oid) || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			if v, err := toString(atv.RawValue.FullBytes); err == nil && v != "" {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+//escapeLabel escapes the non-printable characters and the invalid UTF-8 bytes of s as hex pairs.
+func escapeLabel(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || !unicode.IsPrint(r) {
+			for _, c := range []byte(s[i : i+size]) {
+				fmt.Fprintf(&sb, "\\%02X", c)
+			}
+		} else {
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+//truncateLabel truncates s, which is escaped, to shortLabelMaxLength characters. An escape sequence counts as one character.
+func truncateLabel(s string) string {
+	var units []string
+	for i := 0; i < len(s); {
+		n := labelUnitLength(s[i:])
+		units = append(units, s[i:i+n])
+		i += n
+	}
+	if len(units) <= shortLabelMaxLength {
+		return s
+	}
+	return strings.Join(units[:shortLabelMaxLength-1], "") + "…"
+}
+
+//labelUnitLength returns the length in bytes of the character or the escape sequence at the beginning of s.
+func labelUnitLength(s string) int {
+	if s[0] != '\\' || len(s) == 1 {
+		_, size := utf8.DecodeRuneInString(s)
+		return size
+	}
+	if len(s) >= 3 && isHexDigit(s[1]) && isHexDigit(s[2]) {
+		return 3
+	}
+	_, size := utf8.DecodeRuneInString(s[1:])
+	return 1 + size
+}
+
+//isHexDigit reports whether c is a hexadecimal digit.
+func isHexDigit(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
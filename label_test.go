@@ -0,0 +1,60 @@
+package dn
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDN_ShortLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"Common name", "CN=abc,O=Example,C=JP", "abc"},
+		{"Common name in the last RDN", "CN=abc,CN=def,C=JP", "abc"},
+		{"Organization", "OU=Unit,O=Example,C=JP", "Example"},
+		{"Empty common name", "CN=,O=Example,C=JP", "Example"},
+		{"Not string common name", "CN=#0403616263,O=Example", "Example"},
+		{"String representation", "DC=example,DC=com", "DC=example,DC=com"},
+		{"Empty", "", ""},
+		{"Special characters", `CN=\#a\,b\ `, `\#a\,b\ `},
+		{"Control characters", `CN=a\0Ab\7F`, `a\0Ab\7F`},
+		{"Non-ASCII", `CN=M\C3\BCller`, "Müller"},
+		{"Truncated", "CN=" + strings.Repeat("a", 65), strings.Repeat("a", 63) + "…"},
+		{"Not truncated", "CN=" + strings.Repeat("a", 64), strings.Repeat("a", 64)},
+		{"Truncated before escape sequence", "CN=" + strings.Repeat("a", 62) + `\,\,\,`, strings.Repeat("a", 62) + `\,…`},
+		{"Truncated string representation", "OU=" + strings.Repeat("a", 70), "OU=" + strings.Repeat("a", 60) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseString(tt.s)
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+			if got := d.ShortLabel(); got != tt.want {
+				t.Errorf("ShortLabel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_escapeLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"Printable", "abc", "abc"},
+		{"Control character", "a\nb", `a\0Ab`},
+		{"Invalid UTF-8", "a\xffb", `a\FFb`},
+		{"Non-printable rune", "a\u200bb", `a\E2\80\8Bb`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeLabel(tt.s); got != tt.want {
+				t.Errorf("escapeLabel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package dn
+
+import (
+	"fmt"
+)
+
+//WithMaxInputBytes makes the Comparer return an error for distinguished names whose encodings are longer than n bytes,
+//before decoding them. n less than or equal to 0 means no limit, which is the default.
+//It protects servers which compare distinguished names received from untrusted peers against oversized inputs.
+func WithMaxInputBytes(n int) Option {
+	return func(c *Comparer) {
+		c.maxInputBytes = n
+	}
+}
+
+//WithMaxRawAttributeBytes makes the Comparer return an error for attributes whose values are encoded in more than
+//n bytes, including the tags and the lengths, before decoding the values. The attributes ignored by WithIgnoredTypes
+//are also checked. n less than or equal to 0 means no limit, which is the default.
+func WithMaxRawAttributeBytes(n int) Option {
+	return func(c *Comparer) {
+		c.maxRawAttributeBytes = n
+	}
+}
+
+//WithMaxMultiValuedAttributes makes the Comparer return an error for distinguished names which have an RDN of more than
+//n attributes, right after parsing them. n less than or equal to 0 means no limit, which is the default.
+//The attributes of multi-valued RDNs are compared with each other, which takes time quadratic in the number of
+//the attributes, so that it protects servers against RDNs packed with many attributes. The RDNs in practice have
+//at most a few attributes. The attributes ignored by WithIgnoredTypes are also counted.
+func WithMaxMultiValuedAttributes(n int) Option {
+	return func(c *Comparer) {
+		c.maxRDNAttributes = n
+	}
+}
+
+//checkInputBytes returns an error if any of inputs is longer than the limit of WithMaxInputBytes.
+func (c *Comparer) checkInputBytes(inputs ...[]byte) error {
+	if c.maxInputBytes <= 0 {
+		return nil
+	}
+	for _, b := range inputs {
+		if len(b) > c.maxInputBytes {
+			return fmt.Errorf("dn: distinguished name is %d bytes, max %d", len(b), c.maxInputBytes)
+		}
+	}
+	return nil
+}
+
+//checkRawAttributeBytes returns an error if the value of atv is longer than the limit of WithMaxRawAttributeBytes.
+func (c *Comparer) checkRawAttributeBytes(atv Attribute) error {
+	if c.maxRawAttributeBytes > 0 && len(atv.RawValue.FullBytes) > c.maxRawAttributeBytes {
+		return fmt.Errorf("dn: value of attribute %s is %d bytes, max %d", atv.Oid, len(atv.RawValue.FullBytes), c.maxRawAttributeBytes)
+	}
+	return nil
+}
+
+//parseDn decodes dnBytes in the same way as parseDn, applying the limits of WithMaxRawAttributeBytes and
+//WithMaxMultiValuedAttributes. The limit of WithMaxInputBytes is checked by the callers before parseDn.
+func (c *Comparer) parseDn(dnBytes []byte) (d dn, err error) {
+	if d, err = parseDn(dnBytes); err != nil {
+		return nil, err
+	}
+	for i, r := range d {
+		if c.maxRDNAttributes > 0 && len(r) > c.maxRDNAttributes {
+			return nil, fmt.Errorf("dn: RDN %d has %d attributes, max %d", i, len(r), c.maxRDNAttributes)
+		}
+		for _, atv := range r {
+			if err = c.checkRawAttributeBytes(atv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return d, nil
+}
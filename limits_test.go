@@ -0,0 +1,73 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"testing"
+)
+
+func TestComparer_Compare_Limits(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"No limits", nil, args{dn1b, dn16b}, true, false},
+		{"Max input bytes, Within limit", []Option{WithMaxInputBytes(len(dn1b))}, args{dn1b, dn16b}, true, false},
+		{"Max input bytes, Issuer exceeds", []Option{WithMaxInputBytes(len(dn2b) - 1)}, args{dn2b, base2b}, false, true},
+		{"Max input bytes, Subject exceeds", []Option{WithMaxInputBytes(len(base2b))}, args{base2b, dn2b}, false, true},
+		{"Max input bytes, Broken data exceeds", []Option{WithMaxInputBytes(1)}, args{dn2b, brdnb}, false, true},
+		{"Max input bytes, Zero is no limit", []Option{WithMaxInputBytes(0)}, args{dn2b, dn3b}, true, false},
+		{"Max raw attribute bytes, Within limit", []Option{WithMaxRawAttributeBytes(5)}, args{dn2b, dn3b}, true, false},
+		{"Max raw attribute bytes, Exceeds", []Option{WithMaxRawAttributeBytes(4)}, args{dn2b, dn3b}, false, true},
+		{"Max raw attribute bytes, Ignored types", []Option{WithMaxRawAttributeBytes(4), WithIgnoredTypes(asn1.ObjectIdentifier{2, 5, 4, 3})}, args{dn2b, dn3b}, false, true},
+		{"Max multi-valued attributes, Within limit", []Option{WithMaxMultiValuedAttributes(2)}, args{dn1b, dn16b}, true, false},
+		{"Max multi-valued attributes, Issuer exceeds", []Option{WithMaxMultiValuedAttributes(1)}, args{dn1b, dn2b}, false, true},
+		{"Max multi-valued attributes, Subject exceeds", []Option{WithMaxMultiValuedAttributes(2)}, args{dn2b, dn60b}, false, true},
+		{"Max multi-valued attributes, Zero is no limit", []Option{WithMaxMultiValuedAttributes(0)}, args{dn60b, dn62b}, true, false},
+		{"Max multi-valued attributes, Ignored types", []Option{WithMaxMultiValuedAttributes(1), WithIgnoredTypes(oidOrganization)}, args{dn1b, dn2b}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer(tt.opts...)
+			gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if _, _, err = c.CompareExplain(tt.args.issuer, tt.args.subject); (err != nil) != tt.wantErr {
+				t.Errorf("CompareExplain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestComparer_Canonicalize_Limits(t *testing.T) {
+	if _, err := NewComparer(WithMaxInputBytes(len(dn1b) - 1)).Canonicalize(dn1b); err == nil {
+		t.Errorf("Canonicalize() error = nil, want error for too long input")
+	}
+	if _, err := NewComparer(WithMaxRawAttributeBytes(4)).Canonicalize(dn1b); err == nil {
+		t.Errorf("Canonicalize() error = nil, want error for too long attribute")
+	}
+	if _, err := NewComparer(WithMaxInputBytes(len(dn1b)), WithMaxRawAttributeBytes(7)).Canonicalize(dn1b); err != nil {
+		t.Errorf("Canonicalize() error = %v", err)
+	}
+	if _, err := NewComparer(WithMaxMultiValuedAttributes(1)).Canonicalize(dn1b); err == nil {
+		t.Errorf("Canonicalize() error = nil, want error for too many attributes in RDN")
+	}
+}
+
+func TestComparer_CompareAttribute_Limits(t *testing.T) {
+	c := NewComparer(WithMaxRawAttributeBytes(len(pAtv.RawValue.FullBytes) - 1))
+	if _, err := c.CompareAttribute(pAtv, pAtv); err == nil {
+		t.Errorf("CompareAttribute() error = nil, want error for too long attribute")
+	}
+}
@@ -0,0 +1,120 @@
+package dn
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+//Names of the built-in lint rules which Validate runs.
+const (
+	LintRuleEmptyValues       = "empty-values"       //attributes whose values are empty after the string preparation
+	LintRuleMultiplicity      = "multiplicity"       //attribute types which appear more than Profile.MaxOccurrences
+	LintRuleUpperBounds       = "upper-bounds"       //values longer than the upper bounds of RFC 5280
+	LintRuleSetOrder          = "set-order"          //multi-valued RDNs whose attributes are not in DER order
+	LintRuleControlCharacters = "control-characters" //values which contain control characters removed by the string preparation, e.g. NUL
+)
+
+//lintRule is a lint rule registered by registerLintRule.
+type lintRule struct {
+	name string
+	fn   func(d dn, p Profile) ([]Finding, error)
+	//optIn rules run only if Profile.Rules names them, e.g. the rules of ProfileCABFv2.
+	optIn bool
+}
+
+//lintRules are the registered lint rules in the order of the registration.
+var lintRules struct {
+	mu    sync.RWMutex
+	rules []lintRule
+}
+
+func init() {
+	for _, r := range []lintRule{
+		{LintRuleEmptyValues, func(d dn, _ Profile) ([]Finding, error) { return lintEmptyValues(d) }, false},
+		{LintRuleMultiplicity, func(d dn, p Profile) ([]Finding, error) { return lintMultiplicity(d, p), nil }, false},
+		{LintRuleUpperBounds, func(d dn, _ Profile) ([]Finding, error) { return lintUpperBounds(d) }, false},
+		{LintRuleSetOrder, func(d dn, _ Profile) ([]Finding, error) { return lintSetOrder(d) }, false},
+		{LintRuleControlCharacters, func(d dn, _ Profile) ([]Finding, error) { return lintControlCharacters(d) }, false},
+		{LintRuleCABFv2EmailAddress, func(d dn, _ Profile) ([]Finding, error) { return lintCABFv2EmailAddress(d), nil }, true},
+		{LintRuleCABFv2CountryCode, func(d dn, _ Profile) ([]Finding, error) { return lintCABFv2CountryCode(d) }, true},
+		{LintRuleCABFv2LocalityWithoutOrg, func(d dn, _ Profile) ([]Finding, error) { return lintCABFv2LocalityWithoutOrg(d), nil }, true},
+		{LintRuleCABFv2CommonName, func(d dn, _ Profile) ([]Finding, error) { return lintCABFv2CommonName(d), nil }, true},
+		{LintRuleCABFv2OrganizationalUnit, func(d dn, _ Profile) ([]Finding, error) { return lintCABFv2OrganizationalUnit(d), nil }, true},
+		{LintRuleEVv1RequiredAttributes, func(d dn, _ Profile) ([]Finding, error) { return lintEVv1RequiredAttributes(d), nil }, true},
+		{LintRuleEVv1JurisdictionCountry, func(d dn, _ Profile) ([]Finding, error) { return lintEVv1JurisdictionCountry(d) }, true},
+		{LintRuleEVv1JurisdictionOrder, func(d dn, _ Profile) ([]Finding, error) { return lintEVv1JurisdictionOrder(d), nil }, true},
+		{LintRuleEVv1BusinessCategory, func(d dn, _ Profile) ([]Finding, error) { return lintEVv1BusinessCategory(d) }, true},
+	} {
+		if err := registerLintRule(r); err != nil {
+			panic(err)
+		}
+	}
+}
+
+//RegisterLintRule registers fn as the lint rule named name, which Validate and ValidateProfile run after the built-in
+//rules and the rules registered before. The findings which fn returns are reported together with the others.
+//RegisterLintRule returns an error if name is empty or already registered, or fn is nil.
+//
+//The rules are global, so RegisterLintRule is usually called in init functions. fn must not modify the DN.
+//Profile.Rules and Profile.SuppressedRules select the rules by their names.
+func RegisterLintRule(name string, fn func(*DN) []Finding) error {
+	if fn == nil {
+		return errors.New("dn: lint rule function is nil")
+	}
+	return registerLintRule(lintRule{name, func(d dn, _ Profile) ([]Finding, error) {
+		return fn(&DN{rdns: d}), nil
+	}, false})
+}
+
+//registerLintRule adds r to lintRules.
+func registerLintRule(r lintRule) error {
+	if r.name == "" {
+		return errors.New("dn: lint rule name is empty")
+	}
+	lintRules.mu.Lock()
+	defer lintRules.mu.Unlock()
+	for _, registered := range lintRules.rules {
+		if registered.name == r.name {
+			return fmt.Errorf("dn: lint rule %q is already registered", r.name)
+		}
+	}
+	lintRules.rules = append(lintRules.rules, r)
+	return nil
+}
+
+//selectLintRules returns the registered lint rules which p selects.
+func selectLintRules(p Profile) (rules []lintRule, err error) {
+	lintRules.mu.RLock()
+	defer lintRules.mu.RUnlock()
+	isRegistered := func(name string) bool {
+		for _, r := range lintRules.rules {
+			if r.name == name {
+				return true
+			}
+		}
+		return false
+	}
+	contains := func(names []string, name string) bool {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+	for _, names := range [][]string{p.Rules, p.SuppressedRules} {
+		for _, name := range names {
+			if !isRegistered(name) {
+				return nil, fmt.Errorf("dn: unknown lint rule %q", name)
+			}
+		}
+	}
+	for _, r := range lintRules.rules {
+		selected := contains(p.Rules, r.name) || (len(p.Rules) == 0 && !r.optIn)
+		if selected && !contains(p.SuppressedRules, r.name) {
+			rules = append(rules, r)
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,100 @@
+package dn
+
+import (
+	"reflect"
+	"testing"
+)
+
+//unregisterLintRule removes the lint rule named name registered by the tests.
+func unregisterLintRule(name string) {
+	lintRules.mu.Lock()
+	defer lintRules.mu.Unlock()
+	for i, r := range lintRules.rules {
+		if r.name == name {
+			lintRules.rules = append(lintRules.rules[:i:i], lintRules.rules[i+1:]...)
+			return
+		}
+	}
+}
+
+func TestRegisterLintRule(t *testing.T) {
+	q, err := Compile(`CN ~ "ab"`)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	rule := func(d *DN) []Finding {
+		b, err := d.Marshal()
+		if err != nil {
+			return nil
+		}
+		if matched, _ := q.Match(b); matched {
+			return []Finding{{RDN: d.Len() - 1, Message: `commonName contains "ab"`}}
+		}
+		return nil
+	}
+	if err = RegisterLintRule("cn-ab", rule); err != nil {
+		t.Fatalf("RegisterLintRule() error = %v", err)
+	}
+	t.Cleanup(func() { unregisterLintRule("cn-ab") })
+
+	cnFinding := Finding{RDN: 2, Message: `commonName contains "ab"`}
+	orderFinding := Finding{RDN: 1, Attribute: 1, Type: oidOrganization, Message: "attribute 2.5.4.10 is not in DER order of multi-valued RDN"}
+	type args struct {
+		dnBytes []byte
+		p       Profile
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantFindings []Finding
+		wantErr      bool
+	}{
+		{"All rules", args{dn16b, DefaultProfile()}, []Finding{orderFinding, cnFinding}, false},
+		{"Registered rule only", args{dn16b, Profile{Rules: []string{"cn-ab"}}}, []Finding{cnFinding}, false},
+		{"Built-in rule only", args{dn16b, Profile{Rules: []string{LintRuleSetOrder}}}, []Finding{orderFinding}, false},
+		{"Suppress registered rule", args{dn16b, Profile{SuppressedRules: []string{"cn-ab"}}}, []Finding{orderFinding}, false},
+		{"Suppress built-in rule", args{dn16b, Profile{SuppressedRules: []string{LintRuleSetOrder}}}, []Finding{cnFinding}, false},
+		{"Select and suppress same rule", args{dn16b, Profile{Rules: []string{"cn-ab"}, SuppressedRules: []string{"cn-ab"}}}, nil, false},
+		{"Unknown rule", args{dn16b, Profile{Rules: []string{"unknown"}}}, nil, true},
+		{"Unknown suppressed rule", args{dn16b, Profile{SuppressedRules: []string{"unknown"}}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFindings, err := ValidateProfile(tt.args.dnBytes, tt.args.p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotFindings, tt.wantFindings) {
+				t.Errorf("ValidateProfile() gotFindings = %v, want %v", gotFindings, tt.wantFindings)
+			}
+		})
+	}
+}
+
+func TestRegisterLintRule_Error(t *testing.T) {
+	noop := func(*DN) []Finding { return nil }
+	tests := []struct {
+		name     string
+		ruleName string
+		fn       func(*DN) []Finding
+	}{
+		{"Built-in rule name", LintRuleEmptyValues, noop},
+		{"Empty name", "", noop},
+		{"Nil function", "nil-function", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterLintRule(tt.ruleName, tt.fn); err == nil {
+				t.Errorf("RegisterLintRule() error = nil, want error")
+			}
+		})
+	}
+	if err := RegisterLintRule("duplicate", noop); err != nil {
+		t.Fatalf("RegisterLintRule() error = %v", err)
+	}
+	t.Cleanup(func() { unregisterLintRule("duplicate") })
+	if err := RegisterLintRule("duplicate", noop); err == nil {
+		t.Errorf("RegisterLintRule() error = nil, want error for duplicate name")
+	}
+}
@@ -0,0 +1,115 @@
+package dn
+
+import (
+	"errors"
+	"fmt"
+)
+
+//CompareMany reports whether each pair of dns, which are encoded as Distinguished Names, matches by Compare.
+//result[i][j] is the result of Compare(dns[i], dns[j]), which is the same as result[j][i].
+//Each of dns is parsed once, and each of the distinct values is prepared once, so that it is much faster than
+//calling Compare for each pair. It returns an error if any of dns is empty or cannot be parsed, or any pair cannot be
+//compared.
+func CompareMany(dns [][]byte) (result [][]bool, err error) {
+	return NewComparer().CompareMany(dns)
+}
+
+//CompareMany reports whether each pair of dns matches in the same way as CompareMany, applying the options of c.
+//The options are applied to each of dns once, and the events of WithAuditHook are reported with SideInput.
+//A StringPreparer given by WithStringPreparer is called for every comparison of the values, as Compare does.
+//If c has an Observer or WithTrace, the pairs are compared by Compare to report them, which parses dns for each pair.
+func (c *Comparer) CompareMany(dns [][]byte) (result [][]bool, err error) {
+	result = make([][]bool, len(dns))
+	for i := range result {
+		result[i] = make([]bool, len(dns))
+	}
+	if c.observer != nil || c.trace != nil {
+		for i := range dns {
+			for j := i; j < len(dns); j++ {
+				if result[i][j], err = c.Compare(dns[i], dns[j]); err != nil {
+					return nil, fmt.Errorf("dn: failed to compare distinguished names %d and %d: %w", i, j, err)
+				}
+				result[j][i] = result[i][j]
+			}
+		}
+		return result, nil
+	}
+
+	parsed := make([]dn, len(dns))
+	for i, der := range dns {
+		if parsed[i], err = c.parseMany(der); err != nil {
+			return nil, fmt.Errorf("dn: failed to parse distinguished name %d: %w", i, err)
+		}
+	}
+	cc := c.withCachingPreparer()
+	for i := range parsed {
+		for j := i; j < len(parsed); j++ {
+			if cc.constantTime {
+				result[i][j], err = matchDistinguishedNameConstantTime(parsed[i], parsed[j], cc.compareAttribute)
+			} else {
+				result[i][j], err = matchDistinguishedName(parsed[i], parsed[j], cc.compareAttribute)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("dn: failed to compare distinguished names %d and %d: %w", i, j, err)
+			}
+			result[j][i] = result[i][j]
+		}
+	}
+	return result, nil
+}
+
+//parseMany decodes der and applies the options of c in the same way as Compare.
+func (c *Comparer) parseMany(der []byte) (d dn, err error) {
+	if err = c.checkInputBytes(der); err != nil {
+		return nil, err
+	}
+	if len(der) == 0 {
+		return nil, errors.New("dn: distinguished name is empty")
+	}
+	if d, err = c.parseDn(der); err != nil {
+		return nil, err
+	}
+	return c.prepare(d, SideInput)
+}
+
+//withCachingPreparer returns the copy of c which prepares each of the distinct strings once by DefaultStringPreparer
+//during a comparison. A StringPreparer given by WithStringPreparer is not cached, so that it is called for every
+//comparison of the values.
+func (c *Comparer) withCachingPreparer() *Comparer {
+	cc := *c
+	if c.preparer == nil {
+		cc.preparer = &cachingPreparer{p: DefaultStringPreparer, cache: make(map[cachingPreparerKey]preparedString)}
+	}
+	return &cc
+}
+
+//cachingPreparer is the StringPreparer which caches the results of p, so that each of the distinct strings is prepared
+//once. It is not safe for concurrent use.
+type cachingPreparer struct {
+	p     StringPreparer
+	cache map[cachingPreparerKey]preparedString
+}
+
+//cachingPreparerKey is the key of the cache of cachingPreparer.
+type cachingPreparerKey struct {
+	s        string
+	caseFold bool
+}
+
+//preparedString is the result of StringPreparer.Prepare.
+type preparedString struct {
+	s   string
+	err error
+}
+
+//Prepare returns the result of p.Prepare(s, caseFold), which is cached.
+func (cp *cachingPreparer) Prepare(s string, caseFold bool) (string, error) {
+	key := cachingPreparerKey{s, caseFold}
+	if r, ok := cp.cache[key]; ok {
+		return r.s, r.err
+	}
+	var r preparedString
+	r.s, r.err = cp.p.Prepare(s, caseFold)
+	cp.cache[key] = r
+	return r.s, r.err
+}
@@ -0,0 +1,124 @@
+package dn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareMany(t *testing.T) {
+	tests := []struct {
+		name       string
+		dns        [][]byte
+		wantResult [][]bool
+		wantErr    bool
+	}{
+		{"No distinguished names", nil, [][]bool{}, false},
+		{"One distinguished name", [][]byte{dn2b}, [][]bool{{true}}, false},
+		{"Same characters, Different Encoding and Upper/Lower case characters", [][]byte{dn2b, dn3b, dn4b, dn6b}, [][]bool{
+			{true, true, true, false},
+			{true, true, true, false},
+			{true, true, true, false},
+			{false, false, false, true},
+		}, false},
+		{"Multi RDN in different order", [][]byte{dn1b, dn16b, dn2b}, [][]bool{
+			{true, true, false},
+			{true, true, false},
+			{false, false, true},
+		}, false},
+		{"Broken data", [][]byte{dn2b, brdnb}, nil, true},
+		{"Empty", [][]byte{dn2b, {}}, nil, true},
+		{"VideotexString", [][]byte{dn2b, dn28b}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := CompareMany(tt.dns)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompareMany() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResult, tt.wantResult) {
+				t.Errorf("CompareMany() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if err != nil {
+				return
+			}
+			for i := range tt.dns {
+				for j := range tt.dns {
+					want, err := Compare(tt.dns[i], tt.dns[j])
+					if err != nil {
+						t.Fatalf("Compare() error = %v", err)
+					}
+					if gotResult[i][j] != want {
+						t.Errorf("CompareMany()[%d][%d] = %v, Compare() = %v", i, j, gotResult[i][j], want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestComparer_CompareMany(t *testing.T) {
+	dns := [][]byte{dn2b, dn4b, dn3b, dn83b, dn84b}
+	want := [][]bool{
+		{true, true, true, false, false},
+		{true, true, true, false, false},
+		{true, true, true, false, false},
+		{false, false, false, true, true},
+		{false, false, false, true, true},
+	}
+	var events []TraceEvent
+	for _, opts := range [][]Option{
+		{},
+		{WithConstantTime()},
+		{WithTrace(func(e TraceEvent) { events = append(events, e) })},
+	} {
+		p := &umlautPreparer{}
+		gotResult, err := NewComparer(append(opts, WithStringPreparer(p))...).CompareMany(dns)
+		if err != nil {
+			t.Fatalf("CompareMany() error = %v", err)
+		}
+		if !reflect.DeepEqual(gotResult, want) {
+			t.Errorf("CompareMany() gotResult = %v, want %v", gotResult, want)
+		}
+	}
+	if len(events) == 0 {
+		t.Errorf("CompareMany() with WithTrace reports no events")
+	}
+}
+
+func TestComparer_CompareMany_PreparesOnce(t *testing.T) {
+	//The distinct values are "JP", "ABC", "abc", "Müller" and "Mueller", which are prepared with case folding.
+	dns := [][]byte{dn2b, dn4b, dn3b, dn83b, dn84b}
+	p := countDefaultStringPreparer(t)
+	if _, err := NewComparer().CompareMany(dns); err != nil {
+		t.Fatalf("CompareMany() error = %v", err)
+	}
+	if p.calls != 5 {
+		t.Errorf("Prepare() is called %d times, want %d", p.calls, 5)
+	}
+
+	//a StringPreparer given by WithStringPreparer is called for every comparison, as Compare does
+	custom := &umlautPreparer{}
+	if _, err := NewComparer(WithStringPreparer(custom)).CompareMany(dns); err != nil {
+		t.Fatalf("CompareMany() error = %v", err)
+	}
+	if custom.calls <= 5 {
+		t.Errorf("Prepare() of WithStringPreparer is called %d times, want more than %d", custom.calls, 5)
+	}
+}
+
+func TestComparer_CompareMany_AuditHook(t *testing.T) {
+	var events []Event
+	c := NewComparer(WithAuditHook(func(e Event) { events = append(events, e) }))
+	if _, err := c.CompareMany([][]byte{dn5b, dn5b, dn5b}); err != nil {
+		t.Fatalf("CompareMany() error = %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("CompareMany() reports %d events, want %d", len(events), 3)
+	}
+	for _, e := range events {
+		if e.Side != SideInput {
+			t.Errorf("Event.Side = %v, want %v", e.Side, SideInput)
+		}
+	}
+}
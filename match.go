@@ -0,0 +1,183 @@
+package dn
+
+import (
+	"errors"
+	"fmt"
+)
+
+//Scope is the scope of LDAP search.
+//https://tools.ietf.org/html/rfc4511#section-4.5.1.2
+type Scope int
+
+//Scopes of LDAP search.
+const (
+	ScopeBaseObject   Scope = 0 //only the base object
+	ScopeSingleLevel  Scope = 1 //only the immediate subordinates of the base object
+	ScopeWholeSubtree Scope = 2 //the base object and all its subordinates
+)
+
+//BaseMatch reports whether dnBytes is the same as or subordinate to base, both of which are encoded as Distinguished Name.
+//
+//In the string representation of RFC 4514, which starts with the most specific RDN, base is a suffix of dnBytes,
+//e.g. "uid=alice,ou=people,dc=example,dc=com" is under "ou=people,dc=example,dc=com".
+//In the encoding, which is an RDNSequence starting with the root, base is a prefix: dnBytes matches if
+//its first RDNs, as many as base has, match base in the same order by the same rules as Compare.
+func BaseMatch(dnBytes []byte, base []byte) (result bool, err error) {
+	var d, b dn
+	if d, b, err = parseDnAndBase(dnBytes, base); err != nil {
+		return false, err
+	}
+	return matchBase(d, b)
+}
+
+//ScopeMatch reports whether dnBytes is within scope of base, both of which are encoded as Distinguished Name.
+//  ScopeBaseObject:   dnBytes matches base.
+//  ScopeSingleLevel:  dnBytes has exactly one more RDN than base and is under base.
+//  ScopeWholeSubtree: dnBytes matches base or is under base at any depth.
+//"under base" has the same meaning as BaseMatch, and the RDNs are matched by the same rules as Compare.
+func ScopeMatch(dnBytes []byte, base []byte, scope Scope) (result bool, err error) {
+	var d, b dn
+	if d, b, err = parseDnAndBase(dnBytes, base); err != nil {
+		return false, err
+	}
+	switch scope {
+	case ScopeBaseObject:
+		if len(d) != len(b) {
+			return false, nil
+		}
+	case ScopeSingleLevel:
+		if len(d) != len(b)+1 {
+			return false, nil
+		}
+	case ScopeWholeSubtree:
+	default:
+		return false, fmt.Errorf("dn: unknown scope %d", scope)
+	}
+	return matchBase(d, b)
+}
+
+//MatchesSubtreeDepth reports whether dnBytes is within the subtree of base, both of which are encoded as Distinguished Name,
+//and the depth of dnBytes is within min and max. The depth is the number of RDNs of dnBytes beyond base,
+//which is 0 if dnBytes matches base. If max is nil, the depth has no upper limit, and if min is also 0, the result is
+//the same as BaseMatch(dnBytes, base).
+//
+//https://tools.ietf.org/html/rfc5280#section-4.2.1.10
+//GeneralSubtree ::= SEQUENCE {
+//     base                    GeneralName,
+//     minimum         [0]     BaseDistance DEFAULT 0,
+//     maximum         [1]     BaseDistance OPTIONAL }
+//Within this profile, the minimum and maximum fields are not used with any name forms,
+//but the certificates of some issuers use them.
+func MatchesSubtreeDepth(base []byte, dnBytes []byte, min int, max *int) (result bool, err error) {
+	if min < 0 || (max != nil && *max < 0) {
+		return false, errors.New("dn: minimum and maximum of subtree must not be negative")
+	}
+	var d, b dn
+	if d, b, err = parseDnAndBase(dnBytes, base); err != nil {
+		return false, err
+	}
+	depth := len(d) - len(b)
+	if depth < min || (max != nil && depth > *max) {
+		return false, nil
+	}
+	return matchBase(d, b)
+}
+
+//IsImmediateSubordinate reports whether child, which is encoded as Distinguished Name, is base plus exactly one RDN:
+//child has one more RDN than base and its first RDNs match base in the same order by the same rules as Compare.
+//The additional RDN may be multi-valued. It is the same as ScopeMatch(child, base, ScopeSingleLevel).
+func IsImmediateSubordinate(base []byte, child []byte) (result bool, err error) {
+	return ScopeMatch(child, base, ScopeSingleLevel)
+}
+
+//ExplainImmediateSubordinate reports whether child is an immediate subordinate of base in the same way as
+//IsImmediateSubordinate, and returns the decisions for the RDNs of base compared with the first RDNs of child
+//as CompareExplain does. If child does not have exactly one more RDN than base, the only decision has the reason.
+func ExplainImmediateSubordinate(base []byte, child []byte) (result bool, decisions []RDNDecision, err error) {
+	var d, b dn
+	if d, b, err = parseDnAndBase(child, base); err != nil {
+		return false, nil, err
+	}
+	if len(d) != len(b)+1 {
+		decisions = append(decisions, RDNDecision{
+			RDN:    len(b),
+			Reason: fmt.Sprintf("child has %d RDNs, want %d", len(d), len(b)+1),
+		})
+		return false, decisions, nil
+	}
+	return NewComparer().explainDistinguishedName(b, d[:len(b)])
+}
+
+//parseDnAndBase parses dnBytes and base.
+func parseDnAndBase(dnBytes []byte, base []byte) (d dn, b dn, err error) {
+	if len(base) == 0 {
+		return nil, nil, errors.New("dn: base must be encoded distinguished name")
+	}
+	if d, err = parseDn(dnBytes); err != nil {
+		return nil, nil, err
+	}
+	if b, err = parseDn(base); err != nil {
+		return nil, nil, err
+	}
+	return d, b, nil
+}
+
+//matchBase reports whether the first RDNs of d match b.
+func matchBase(d dn, b dn) (result bool, err error) {
+	if len(b) > len(d) {
+		return false, nil
+	}
+	return compareDistinguishedName(d[:len(b)], b)
+}
+
+//SameStructure reports whether a and b, both of which are encoded as Distinguished Name, have the same structure:
+//the same number of RDNs, and the same attribute types in each RDN regardless of their order.
+//The values of the attributes are ignored.
+func SameStructure(a []byte, b []byte) (result bool, err error) {
+	var x, y dn
+	if x, err = parseDn(a); err != nil {
+		return false, err
+	}
+	if y, err = parseDn(b); err != nil {
+		return false, err
+	}
+	return sameStructure(x, y), nil
+}
+
+//sameStructure reports whether xd and yd have the same number of RDNs, and each pair of the RDNs at the same position
+//has the same multiset of attribute types.
+func sameStructure(xd []rdnSET, yd []rdnSET) bool {
+	if len(xd) != len(yd) {
+		return false
+	}
+	for i := range xd {
+		if !sameAttributeTypes(xd[i], yd[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+//sameAttributeTypes reports whether xr and yr have the same multiset of attribute types.
+func sameAttributeTypes(xr rdnSET, yr rdnSET) bool {
+	if len(xr) != len(yr) {
+		return false
+	}
+	if len(xr) == 1 {
+		return oidEqual(xr[0].Oid, yr[0].Oid)
+	}
+	used := make([]bool, len(yr))
+	for _, x := range xr {
+		isFound := false
+		for j, y := range yr {
+			if !used[j] && oidEqual(x.Oid, y.Oid) {
+				used[j], isFound = true, true
+				break
+			}
+		}
+		if !isFound {
+			return false
+		}
+	}
+	return true
+}
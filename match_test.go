@@ -0,0 +1,230 @@
+package dn
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	//C=JP(PrintableString),O=foo(UTF8String)+O=bar(PrintableString)
+	hbase1    = "3027310b3009060355040613024a503118300a060355040a0c03666f6f300a060355040a1303626172"
+	base1b, _ = hex.DecodeString(hbase1)
+	//C=JP(PrintableString)
+	hbase2    = "300d310b3009060355040613024a50"
+	base2b, _ = hex.DecodeString(hbase2)
+	//Empty RDNSequence
+	base3b, _ = hex.DecodeString("3000")
+)
+
+func TestBaseMatch(t *testing.T) {
+	type args struct {
+		dnBytes []byte
+		base    []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Base with Multi RDN", args{dn1b, base1b}, true, false},
+		{"Base with 1 RDN", args{dn1b, base2b}, true, false},
+		{"Empty base", args{dn1b, base3b}, true, false},
+		{"Equal length", args{dn1b, dn16b}, true, false},
+		{"Equal length, Different characters", args{dn2b, dn6b}, false, false},
+		{"Base is longer", args{base2b, dn1b}, false, false},
+		{"Not under base", args{dn6b, base2b}, false, false},
+		{"Base with Multi RDN, Not under base", args{dn2b, base1b}, false, false},
+		{"Blank base", args{dn1b, []byte{}}, false, true},
+		{"Broken data", args{brdnb, base2b}, false, true},
+		{"Broken base", args{dn1b, brdnb}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := BaseMatch(tt.args.dnBytes, tt.args.base)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BaseMatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("BaseMatch() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestScopeMatch(t *testing.T) {
+	type args struct {
+		dnBytes []byte
+		base    []byte
+		scope   Scope
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Base, Equal", args{dn1b, dn16b, ScopeBaseObject}, true, false},
+		{"Base, Equal by Compare rules", args{dn2b, dn4b, ScopeBaseObject}, true, false},
+		{"Base, Subordinate", args{dn1b, base1b, ScopeBaseObject}, false, false},
+		{"One level, Equal", args{dn1b, dn1b, ScopeSingleLevel}, false, false},
+		{"One level, Immediate subordinate", args{dn1b, base1b, ScopeSingleLevel}, true, false},
+		{"One level, Deeper subordinate", args{dn1b, base2b, ScopeSingleLevel}, false, false},
+		{"One level, Not under base", args{dn6b, base2b, ScopeSingleLevel}, false, false},
+		{"Subtree, Equal", args{dn1b, dn1b, ScopeWholeSubtree}, true, false},
+		{"Subtree, Deeper subordinate", args{dn1b, base2b, ScopeWholeSubtree}, true, false},
+		{"Subtree, Not under base", args{dn6b, base2b, ScopeWholeSubtree}, false, false},
+		{"Subtree, Base is longer", args{base2b, dn1b, ScopeWholeSubtree}, false, false},
+		{"Unknown scope", args{dn1b, base2b, Scope(3)}, false, true},
+		{"Broken data", args{brdnb, base2b, ScopeWholeSubtree}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := ScopeMatch(tt.args.dnBytes, tt.args.base, tt.args.scope)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ScopeMatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("ScopeMatch() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestSameStructure(t *testing.T) {
+	type args struct {
+		a []byte
+		b []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Different values", args{dn2b, dn6b}, true, false},
+		{"Different encodings", args{dn2b, dn5b}, true, false},
+		{"Multi RDN, Different order", args{dn1b, dn16b}, true, false},
+		{"Multi RDN and Single RDN", args{base1b, dn12b}, false, false},
+		{"Blank data", args{dn2b, []byte{}}, false, true},
+		{"Different number of RDNs", args{dn1b, dn2b}, false, false},
+		{"Different types in RDN", args{dn12b, dn15b}, false, false},
+		{"Multi RDN, Different types", args{dn1b, dn103b}, false, false},
+		{"Broken value is ignored", args{dn7b, dn17b}, true, false},
+		{"Broken data", args{brdnb, dn1b}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := SameStructure(tt.args.a, tt.args.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SameStructure() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("SameStructure() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestIsImmediateSubordinate(t *testing.T) {
+	type args struct {
+		base  []byte
+		child []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"One more RDN", args{base2b, dn2b}, true, false},
+		{"One more multi-valued RDN", args{base2b, base1b}, true, false},
+		{"Matched by Compare rules", args{base2b, dn4b}, true, false},
+		{"Empty base", args{base3b, base2b}, true, false},
+		{"Base equal to child", args{dn2b, dn2b}, false, false},
+		{"Base longer than child", args{dn1b, base2b}, false, false},
+		{"Two more RDNs", args{base2b, dn1b}, false, false},
+		{"Not under base", args{base2b, dn6b}, false, false},
+		{"Blank base", args{[]byte{}, dn2b}, false, true},
+		{"Broken data", args{base2b, brdnb}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := IsImmediateSubordinate(tt.args.base, tt.args.child)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsImmediateSubordinate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("IsImmediateSubordinate() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+
+			explained, decisions, err := ExplainImmediateSubordinate(tt.args.base, tt.args.child)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExplainImmediateSubordinate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if explained != gotResult {
+				t.Errorf("ExplainImmediateSubordinate() = %v, want %v", explained, gotResult)
+			}
+			if !explained && (len(decisions) == 0 || decisions[len(decisions)-1].Reason == "") {
+				t.Errorf("ExplainImmediateSubordinate() decisions = %+v, want a reason", decisions)
+			}
+		})
+	}
+}
+
+func TestMatchesSubtreeDepth(t *testing.T) {
+	zero, one, minus := 0, 1, -1
+	type args struct {
+		base    []byte
+		dnBytes []byte
+		min     int
+		max     *int
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"No limits, Equal to base", args{base2b, base2b, 0, nil}, true, false},
+		{"No limits, Depth 2", args{base2b, dn1b, 0, nil}, true, false},
+		{"No limits, Not under base", args{base2b, dn6b, 0, nil}, false, false},
+		{"Minimum 1, Equal to base", args{base2b, base2b, 1, nil}, false, false},
+		{"Minimum 1, Equal to base by Compare rules", args{dn2b, dn4b, 1, nil}, false, false},
+		{"Minimum 1, Depth 1", args{base2b, dn2b, 1, nil}, true, false},
+		{"Minimum 1, Depth 2", args{base2b, dn1b, 1, nil}, true, false},
+		{"Minimum 3, Depth 2", args{base2b, dn1b, 3, nil}, false, false},
+		{"Maximum 0, Equal to base", args{base2b, base2b, 0, &zero}, true, false},
+		{"Maximum 0, Depth 1", args{base2b, dn2b, 0, &zero}, false, false},
+		{"Maximum 1, Depth 1", args{base2b, dn2b, 0, &one}, true, false},
+		{"Maximum 1, Depth 2", args{base2b, dn1b, 0, &one}, false, false},
+		{"Minimum 1, Maximum 1, Depth 1", args{base2b, dn2b, 1, &one}, true, false},
+		{"Minimum 1, Not under base", args{base2b, dn6b, 1, nil}, false, false},
+		{"Base is longer", args{dn1b, base2b, 0, nil}, false, false},
+		{"Negative minimum", args{base2b, dn2b, -1, nil}, false, true},
+		{"Negative maximum", args{base2b, dn2b, 0, &minus}, false, true},
+		{"Blank base", args{[]byte{}, dn2b, 0, nil}, false, true},
+		{"Broken base", args{brdnb, dn1b, 0, nil}, false, true},
+		{"Broken data", args{base2b, brdnb, 0, nil}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := MatchesSubtreeDepth(tt.args.base, tt.args.dnBytes, tt.args.min, tt.args.max)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MatchesSubtreeDepth() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("MatchesSubtreeDepth() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
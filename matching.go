@@ -0,0 +1,173 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+//MatchingRule is the rule to compare the values of attributes encoded in UTF8String or PrintableString,
+//or the values of distinguished names.
+type MatchingRule int
+
+//Matching rules of DirectoryString.
+const (
+	//MatchingRuleCaseIgnore compares values by caseIgnoreMatch(RFC4517 section-4.2.11), which Compare uses.
+	MatchingRuleCaseIgnore MatchingRule = 0
+	//MatchingRuleCaseExact compares values by caseExactMatch(RFC4517 section-4.2.4), which is the same as
+	//caseIgnoreMatch except that case is not ignored.
+	MatchingRuleCaseExact MatchingRule = 1
+	//MatchingRuleDistinguishedName compares values encoded as Name by distinguishedNameMatch(RFC4517 section-4.2.15),
+	//which compares the nested distinguished names recursively by the matching rules of the Comparer.
+	//The other options of the Comparer are not applied to the nested distinguished names.
+	MatchingRuleDistinguishedName MatchingRule = 2
+	//MatchingRuleBitString compares values encoded in BIT STRING by bitStringMatch(RFC4517 section-4.2.1),
+	//which compares the number of bits and the bits, regardless of the unused bits and the constructed form of BER.
+	MatchingRuleBitString MatchingRule = 3
+	//MatchingRuleEmailAddress compares values encoded in IA5String or UTF8String as internationalized email addresses,
+	//whose domains are compared case-insensitively after the conversion to A-labels, and whose local parts are
+	//compared exactly. See WithInternationalizedEmail.
+	MatchingRuleEmailAddress MatchingRule = 4
+	//MatchingRuleEmailAddressCaseIgnore is the same as MatchingRuleEmailAddress except that the local parts are
+	//compared case-insensitively.
+	MatchingRuleEmailAddressCaseIgnore MatchingRule = 5
+	//MatchingRuleNumericString compares values by numericStringMatch(RFC4517 section-4.2.22), which ignores all the
+	//spaces, e.g. "1 234" matches "12 34". The values may be encoded in any string type which the comparison can decode.
+	MatchingRuleNumericString MatchingRule = 6
+	//MatchingRuleCaseIgnoreIA5 compares values encoded in IA5String by caseIgnoreIA5Match(RFC4517 section-4.2.7),
+	//which prepares them in the same way as caseIgnoreMatch. The values in other encodings are out of the syntax of
+	//IA5String, and are compared by binary comparison.
+	MatchingRuleCaseIgnoreIA5 MatchingRule = 7
+	//MatchingRuleCaseExactIA5 is the same as MatchingRuleCaseIgnoreIA5 except that case is not ignored, which is
+	//caseExactIA5Match(RFC4517 section-4.2.3).
+	MatchingRuleCaseExactIA5 MatchingRule = 8
+	//MatchingRuleOctetString compares values by octetStringMatch(RFC4517 section-4.2.27), which compares them octet by
+	//octet. The values are compared by binary comparison whatever types encode them, except that the values in IA5String
+	//are compared by their contents regardless of the constructed form of BER.
+	MatchingRuleOctetString MatchingRule = 9
+)
+
+//isIA5Rule reports whether rule compares only the values in IA5String by their strings.
+func isIA5Rule(rule MatchingRule) bool {
+	return rule == MatchingRuleCaseIgnoreIA5 || rule == MatchingRuleCaseExactIA5
+}
+
+//isTypeSpecificRule reports whether rule is applied to the values of the attribute type regardless of the encodings
+//of the values, instead of the rules of DirectoryString and the fallback to binary comparison.
+func isTypeSpecificRule(rule MatchingRule) bool {
+	switch rule {
+	case MatchingRuleDistinguishedName, MatchingRuleBitString, MatchingRuleEmailAddress, MatchingRuleEmailAddressCaseIgnore,
+		MatchingRuleNumericString, MatchingRuleCaseIgnoreIA5, MatchingRuleCaseExactIA5, MatchingRuleOctetString, matchingRuleFunc:
+		return true
+	}
+	return false
+}
+
+//defaultMatchingRules maps attribute types whose values are distinguished names to MatchingRuleDistinguishedName,
+//and whose values are bit strings to MatchingRuleBitString.
+//https://tools.ietf.org/html/rfc4519#section-2
+var defaultMatchingRules = map[string]MatchingRule{
+	"2.5.4.31": MatchingRuleDistinguishedName, //member
+	"2.5.4.32": MatchingRuleDistinguishedName, //owner
+	"2.5.4.33": MatchingRuleDistinguishedName, //roleOccupant
+	"2.5.4.34": MatchingRuleDistinguishedName, //seeAlso
+	"2.5.4.45": MatchingRuleBitString,         //x500UniqueIdentifier
+}
+
+//maxNestingDepth is the maximum depth of the distinguished names nested in the values of attributes.
+const maxNestingDepth = 4
+
+//WithMatchingRule registers rule as the matching rule of attribute type oid in the Comparer.
+//Attribute types which are not registered are compared by MatchingRuleCaseIgnore, except member, owner, roleOccupant
+//and seeAlso, whose values are distinguished names compared by MatchingRuleDistinguishedName, and x500UniqueIdentifier,
+//whose values are bit strings compared by MatchingRuleBitString.
+//
+//RFC 5280 compares all DirectoryString values by caseIgnoreMatch, but some schemas define attribute types with
+//caseExactMatch. Applications with such strict schemas should register the overrides, e.g.
+//  NewComparer(WithMatchingRule(oid, MatchingRuleCaseExact))
+func WithMatchingRule(oid asn1.ObjectIdentifier, rule MatchingRule) Option {
+	return func(c *Comparer) {
+		if c.matchingRules == nil {
+			c.matchingRules = make(map[string]MatchingRule)
+		}
+		c.matchingRules[oid.String()] = rule
+	}
+}
+
+//matchingRule returns the matching rule of attribute type oid in c.
+func (c *Comparer) matchingRule(oid asn1.ObjectIdentifier) MatchingRule {
+	if len(c.matchingRules) != 0 {
+		if rule, ok := c.matchingRules[oid.String()]; ok {
+			return rule
+		}
+	}
+	return defaultMatchingRule(oid)
+}
+
+//defaultMatchingRule returns the matching rule of attribute type oid which Compare uses.
+func defaultMatchingRule(oid asn1.ObjectIdentifier) MatchingRule {
+	//the attribute types of defaultMatchingRules are in 2.5.4, so the others are decided without formatting oid
+	if len(oid) != 4 || oid[0] != 2 || oid[1] != 5 || oid[2] != 4 {
+		return MatchingRuleCaseIgnore
+	}
+	return defaultMatchingRules[oid.String()]
+}
+
+//compareAttribute reports whether attribute x and attribute y matches by the matching rules registered in c.
+//Attributes of different types never match, which is decided before their values are decoded, so that broken values
+//of attributes which are never compared with the same type cause no error.
+func (c *Comparer) compareAttribute(x Attribute, y Attribute) (result bool, err error) {
+	return c.compareAttributeByRules(x, y, c.constantTime, 0)
+}
+
+//compareAttributeByRules reports whether x and y, which are in a distinguished name nested at depth, matches by the
+//matching rules, the MatchingFuncs and the StringPreparer of c. The values are compared in constant time if
+//constantTime is true. The rule is resolved by appliedRule, which the canonical form, CompareExplain, the audit and
+//DiffEncodings use too, so that they apply the same rule as the comparison.
+func (c *Comparer) compareAttributeByRules(x Attribute, y Attribute, constantTime bool, depth int) (result bool, err error) {
+	if !oidEqual(x.Oid, y.Oid) {
+		return false, nil
+	}
+	rule := c.matchingRule(x.Oid)
+	applied := appliedRule(x, y, rule)
+	switch applied {
+	case AppliedRuleDistinguishedNameMatch:
+		return c.compareByDistinguishedNameMatch(x, y, depth)
+	case AppliedRuleMatchingFunc:
+		return compareByMatchingFunc(x, y, c.matchingFuncs[x.Oid.String()])
+	}
+	if constantTime {
+		return compareAttributeConstantTime(x, y, applied, rule, c.stringPreparer())
+	}
+	return compareAttributeByRule(x, y, applied, rule, c.stringPreparer())
+}
+
+//compareByDistinguishedNameMatch compares x and y, whose values are distinguished names nested at depth,
+//by distinguishedNameMatch. The attributes of the nested distinguished names are compared by the matching rules,
+//the MatchingFuncs and the StringPreparer of c, and not in constant time.
+func (c *Comparer) compareByDistinguishedNameMatch(x Attribute, y Attribute, depth int) (result bool, err error) {
+	//https://tools.ietf.org/html/rfc4517#section-4.2.15
+	//The rule evaluates to TRUE if and only if the attribute value and the
+	//assertion value have the same number of relative distinguished names
+	//and corresponding relative distinguished names (by position) are the same.
+	var xd, yd dn
+	if xd, err = parseNestedDn(x, depth); err != nil {
+		return false, err
+	}
+	if yd, err = parseNestedDn(y, depth); err != nil {
+		return false, err
+	}
+	return matchDistinguishedName(xd, yd, func(a Attribute, b Attribute) (bool, error) {
+		return c.compareAttributeByRules(a, b, false, depth+1)
+	})
+}
+
+//parseNestedDn decodes the value of atv, which is a distinguished name nested at depth.
+func parseNestedDn(atv Attribute, depth int) (d dn, err error) {
+	if depth >= maxNestingDepth {
+		return nil, fmt.Errorf("dn: distinguished names nested more than %d levels", maxNestingDepth)
+	}
+	if atv.RawValue.Class != asn1.ClassUniversal || atv.RawValue.Tag != asn1.TagSequence {
+		return nil, fmt.Errorf("dn: value of attribute %s is not a distinguished name", atv.Oid)
+	}
+	return parseDn(atv.RawValue.FullBytes)
+}
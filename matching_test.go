@@ -0,0 +1,219 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"testing"
+)
+
+func TestWithMatchingRule(t *testing.T) {
+	oidCommonName := asn1.ObjectIdentifier{2, 5, 4, 3}
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Default, Upper/Lower case characters", nil, args{issuer: dn2b, subject: dn4b}, true, false},
+		{"Case exact CN, Upper/Lower case characters", []Option{WithMatchingRule(oidCommonName, MatchingRuleCaseExact)}, args{issuer: dn2b, subject: dn4b}, false, false},
+		{"Case exact CN, Different Encoding(PrintableString,UTF8String)", []Option{WithMatchingRule(oidCommonName, MatchingRuleCaseExact)}, args{issuer: dn2b, subject: dn3b}, true, false},
+		{"Case exact C, Insignificant spaces", []Option{WithMatchingRule(oidCountryName, MatchingRuleCaseExact)}, args{issuer: dn20b, subject: dn2b}, true, false},
+		{"Case exact O, Upper/Lower case characters of CN", []Option{WithMatchingRule(oidOrganization, MatchingRuleCaseExact)}, args{issuer: dn2b, subject: dn4b}, true, false},
+		{"Case exact O, Multi-valued RDN of the same type, Upper/Lower case characters", []Option{WithMatchingRule(oidOrganization, MatchingRuleCaseExact)}, args{issuer: dn60b, subject: dn61b}, false, false},
+		{"Case exact O, Multi-valued RDN of the same type, Different order", []Option{WithMatchingRule(oidOrganization, MatchingRuleCaseExact)}, args{issuer: dn60b, subject: dn62b}, true, false},
+		{"Case ignore CN overridden again", []Option{WithMatchingRule(oidCommonName, MatchingRuleCaseExact), WithMatchingRule(oidCommonName, MatchingRuleCaseIgnore)}, args{issuer: dn2b, subject: dn4b}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer(tt.opts...)
+			gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+
+			ci, err := c.Canonicalize(tt.args.issuer)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cs, err := c.Canonicalize(tt.args.subject)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(ci) == string(cs); got != tt.wantResult {
+				t.Errorf("Canonicalize() equality = %v, want %v", got, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestMatchingRuleDistinguishedName(t *testing.T) {
+	oidCommonName := asn1.ObjectIdentifier{2, 5, 4, 3}
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"roleOccupant, Same", nil, args{issuer: dn45b, subject: dn45b}, true, false},
+		{"roleOccupant, Upper/Lower case characters, spaces and encodings", nil, args{issuer: dn45b, subject: dn46b}, true, false},
+		{"roleOccupant, Different characters", nil, args{issuer: dn46b, subject: dn47b}, false, false},
+		{"roleOccupant, Case exact CN", []Option{WithMatchingRule(oidCommonName, MatchingRuleCaseExact)}, args{issuer: dn45b, subject: dn46b}, false, false},
+		{"roleOccupant, Nested 4 levels", nil, args{issuer: dn50b, subject: dn50b}, true, false},
+		{"roleOccupant, Nested 5 levels", nil, args{issuer: dn49b, subject: dn49b}, false, true},
+		{"roleOccupant, String value", nil, args{issuer: dn48b, subject: dn48b}, false, true},
+		{"roleOccupant, Distinguished name and string value", nil, args{issuer: dn45b, subject: dn48b}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer(tt.opts...)
+			gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			ci, err := c.Canonicalize(tt.args.issuer)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cs, err := c.Canonicalize(tt.args.subject)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(ci) == string(cs); got != tt.wantResult {
+				t.Errorf("Canonicalize() equality = %v, want %v", got, tt.wantResult)
+			}
+			if tt.opts == nil {
+				if got, _ := Compare(tt.args.issuer, tt.args.subject); got != tt.wantResult {
+					t.Errorf("package Compare() = %v, want %v", got, tt.wantResult)
+				}
+			}
+
+			_, decisions, err := c.CompareExplain(tt.args.issuer, tt.args.subject)
+			if err != nil {
+				t.Fatalf("CompareExplain() error = %v", err)
+			}
+			if rule := decisions[len(decisions)-1].Attributes[0].Rule; rule != AppliedRuleDistinguishedNameMatch {
+				t.Errorf("CompareExplain() last rule = %v, want %v", rule, AppliedRuleDistinguishedNameMatch)
+			}
+		})
+	}
+}
+
+func TestMatchingRuleBitString(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		wantRule   AppliedRule
+		wantErr    bool
+	}{
+		{"x500UniqueIdentifier, Same", nil, args{issuer: dn54b, subject: dn54b}, true, AppliedRuleBitStringMatch, false},
+		{"x500UniqueIdentifier, Non-zero unused bits", nil, args{issuer: dn54b, subject: dn55b}, true, AppliedRuleBitStringMatch, false},
+		{"x500UniqueIdentifier, Constructed form", nil, args{issuer: dn56b, subject: dn54b}, true, AppliedRuleBitStringMatch, false},
+		{"x500UniqueIdentifier, Different number of bits", nil, args{issuer: dn54b, subject: dn57b}, false, AppliedRuleBitStringMatch, false},
+		{"x500UniqueIdentifier, Different bits", nil, args{issuer: dn54b, subject: dn58b}, false, AppliedRuleBitStringMatch, false},
+		{"x500UniqueIdentifier, Constant time, Constructed form", []Option{WithConstantTime()}, args{issuer: dn56b, subject: dn55b}, true, AppliedRuleBitStringMatch, false},
+		{"x500UniqueIdentifier, Constant time, Different number of bits", []Option{WithConstantTime()}, args{issuer: dn54b, subject: dn57b}, false, AppliedRuleBitStringMatch, false},
+		{"x500UniqueIdentifier, UTF8String value", nil, args{issuer: dn59b, subject: dn59b}, true, AppliedRuleBinaryComparison, false},
+		{"x500UniqueIdentifier, BIT STRING and UTF8String value", nil, args{issuer: dn54b, subject: dn59b}, false, AppliedRuleBinaryComparison, false},
+		{"x500UniqueIdentifier, Constant time, UTF8String value", []Option{WithConstantTime()}, args{issuer: dn59b, subject: dn59b}, true, AppliedRuleBinaryComparison, false},
+		{"x500UniqueIdentifier, Constant time, BIT STRING and UTF8String value", []Option{WithConstantTime()}, args{issuer: dn54b, subject: dn59b}, false, AppliedRuleBinaryComparison, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer(tt.opts...)
+			gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			ci, err := c.Canonicalize(tt.args.issuer)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cs, err := c.Canonicalize(tt.args.subject)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(ci) == string(cs); got != tt.wantResult {
+				t.Errorf("Canonicalize() equality = %v, want %v", got, tt.wantResult)
+			}
+
+			_, decisions, err := c.CompareExplain(tt.args.issuer, tt.args.subject)
+			if err != nil {
+				t.Fatalf("CompareExplain() error = %v", err)
+			}
+			if rule := decisions[len(decisions)-1].Attributes[0].Rule; rule != tt.wantRule {
+				t.Errorf("CompareExplain() last rule = %v, want %v", rule, tt.wantRule)
+			}
+		})
+	}
+}
+
+func Test_compareByCaseExactMatch(t *testing.T) {
+	type args struct {
+		s string
+		t string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Same characters", args{"abc", "abc"}, true, false},
+		{"Upper/Lower case characters", args{"abc", "ABC"}, false, false},
+		{"Insignificant spaces", args{"  a b ", "a  b"}, true, false},
+		{"Different characters", args{"abc", "abd"}, false, false},
+		{"Full-width Latin", args{"ＡＢＣ", "ABC"}, true, false},
+		{"Full-width lower case Latin", args{"ａｂｃ", "ABC"}, false, false},
+		{"Half-width katakana", args{"ﾃﾞｽﾄ", "デスト"}, true, false},
+		{"Composed, Decomposed", args{"Jos\u00e9", "Jose\u0301"}, true, false},
+		{"Composed upper case, Decomposed lower case", args{"JOS\u00c9", "jose\u0301"}, false, false},
+		{"Hangul syllable, Hangul jamo", args{"\uac01", "\u1100\u1161\u11a8"}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := compareByCaseExactMatch(DefaultStringPreparer, tt.args.s, tt.args.t)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("compareByCaseExactMatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("compareByCaseExactMatch() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
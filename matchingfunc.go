@@ -0,0 +1,74 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+//MatchingFunc reports whether the values of x and y, which are the attributes of the same type, match.
+//It is registered by WithMatchingFunc for the attribute types whose matching rules are not built in, e.g. the attribute
+//types of a private schema compared by octetStringMatch.
+type MatchingFunc func(x MatchingValue, y MatchingValue) (bool, error)
+
+//MatchingValue is the value of an attribute passed to MatchingFunc.
+type MatchingValue struct {
+	Attribute
+	//Value is the value decoded from RawValue if it is encoded in one of the string types which the comparison can
+	//decode: UTF8String, PrintableString, TeletexString, BMPString, IA5String, NumericString, VisibleString and
+	//GeneralString.
+	//It is not prepared by the string preparation. It is empty if Decoded is false.
+	Value   string
+	Decoded bool
+}
+
+//matchingRuleFunc is the matching rule of the attribute types registered by WithMatchingFunc.
+const matchingRuleFunc MatchingRule = -1
+
+//WithMatchingFunc registers fn as the matching function of attribute type oid in the Comparer. The values of oid are
+//compared by fn instead of the built-in rules, including those of domainComponent and the fallback to binary
+//comparison, and the rule registered by WithMatchingRule. The distinguished names nested in the values of
+//MatchingRuleDistinguishedName are also compared by fn.
+//
+//fn is scoped to the Comparer, so the registrations of the libraries never affect each other. fn must be safe for
+//concurrent use if the Comparer is. Under WithConstantTime, fn is called as it is, so it should compare the values
+//in constant time by itself. Canonicalize returns an error for oid, because fn has no canonical form.
+func WithMatchingFunc(oid asn1.ObjectIdentifier, fn MatchingFunc) Option {
+	return func(c *Comparer) {
+		if c.matchingFuncs == nil {
+			c.matchingFuncs = make(map[string]MatchingFunc)
+		}
+		c.matchingFuncs[oid.String()] = fn
+		WithMatchingRule(oid, matchingRuleFunc)(c)
+	}
+}
+
+//compareByMatchingFunc reports whether x and y matches by fn. It returns an error if fn is nil.
+func compareByMatchingFunc(x Attribute, y Attribute, fn MatchingFunc) (result bool, err error) {
+	if !oidEqual(x.Oid, y.Oid) {
+		return false, nil
+	}
+	if fn == nil {
+		return false, fmt.Errorf("dn: no matching function for attribute %s", x.Oid)
+	}
+	var mx, my MatchingValue
+	if mx, err = newMatchingValue(x); err != nil {
+		return false, err
+	}
+	if my, err = newMatchingValue(y); err != nil {
+		return false, err
+	}
+	return fn(mx, my)
+}
+
+//newMatchingValue returns the MatchingValue of atv.
+func newMatchingValue(atv Attribute) (v MatchingValue, err error) {
+	v.Attribute = Attribute{Oid: cloneOid(atv.Oid), RawValue: atv.RawValue}
+	if !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+		return v, nil
+	}
+	if v.Value, err = decodeAttributeValue(atv); err != nil {
+		return MatchingValue{}, err
+	}
+	v.Decoded = true
+	return v, nil
+}
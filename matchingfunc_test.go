@@ -0,0 +1,153 @@
+package dn
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"testing"
+)
+
+func TestWithMatchingFunc(t *testing.T) {
+	oidCommonName := asn1.ObjectIdentifier{2, 5, 4, 3}
+	valueMatch := func(x MatchingValue, y MatchingValue) (bool, error) {
+		return x.Decoded && y.Decoded && x.Value == y.Value, nil
+	}
+	octetStringMatch := func(x MatchingValue, y MatchingValue) (bool, error) {
+		return bytes.Equal(x.RawValue.FullBytes, y.RawValue.FullBytes), nil
+	}
+	alwaysMatch := func(x MatchingValue, y MatchingValue) (bool, error) {
+		return true, nil
+	}
+	failure := func(x MatchingValue, y MatchingValue) (bool, error) {
+		return false, errors.New("failure")
+	}
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		oid        asn1.ObjectIdentifier
+		fn         MatchingFunc
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Decoded values, Different Encoding(UTF8String,PrintableString)", oidCommonName, valueMatch, args{issuer: dn2b, subject: dn3b}, true, false},
+		{"Decoded values, Upper/Lower case characters", oidCommonName, valueMatch, args{issuer: dn2b, subject: dn4b}, false, false},
+		{"Raw values, Same", oidCommonName, octetStringMatch, args{issuer: dn3b, subject: dn3b}, true, false},
+		{"Raw values, Different Encoding(UTF8String,PrintableString)", oidCommonName, octetStringMatch, args{issuer: dn2b, subject: dn3b}, false, false},
+		{"Different characters", oidCommonName, alwaysMatch, args{issuer: dn4b, subject: dn89b}, true, false},
+		{"Other attribute type", oidCountryName, alwaysMatch, args{issuer: dn2b, subject: dn4b}, true, false},
+		{"Different attribute types", oidCommonName, alwaysMatch, args{issuer: dn1b, subject: dn6b}, false, false},
+		{"roleOccupant, Nested CN", oidCommonName, valueMatch, args{issuer: dn45b, subject: dn46b}, false, false},
+		{"Error", oidCommonName, failure, args{issuer: dn2b, subject: dn3b}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range []*Comparer{
+				NewComparer(WithMatchingFunc(tt.oid, tt.fn)),
+				NewComparer(WithMatchingFunc(tt.oid, tt.fn), WithConstantTime()),
+			} {
+				gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+					continue
+				}
+				if gotResult != tt.wantResult {
+					t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMatchingFunc_Value(t *testing.T) {
+	oidCommonName := asn1.ObjectIdentifier{2, 5, 4, 3}
+	tests := []struct {
+		name        string
+		oid         asn1.ObjectIdentifier
+		der         []byte
+		wantValue   string
+		wantDecoded bool
+	}{
+		{"UTF8String", oidCommonName, dn2b, "ABC", true},
+		{"BMPString", oidCommonName, dn5b, "ABC", true},
+		{"Other attribute type", oidCommonName, dn31b, "", false},
+		{"BIT STRING", oidX500UniqueIdentifier, dn54b, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []MatchingValue
+			fn := func(x MatchingValue, y MatchingValue) (bool, error) {
+				got = append(got, x, y)
+				return true, nil
+			}
+			if _, err := NewComparer(WithMatchingFunc(tt.oid, fn)).Compare(tt.der, tt.der); err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if tt.name == "Other attribute type" {
+				if len(got) != 0 {
+					t.Errorf("MatchingFunc called for other attribute type %v", got[0].Oid)
+				}
+				return
+			}
+			if len(got) != 2 {
+				t.Fatalf("MatchingFunc called %d times, want 1", len(got)/2)
+			}
+			for _, v := range got {
+				if v.Value != tt.wantValue || v.Decoded != tt.wantDecoded {
+					t.Errorf("MatchingValue = (%q, %v), want (%q, %v)", v.Value, v.Decoded, tt.wantValue, tt.wantDecoded)
+				}
+				if !v.Oid.Equal(tt.oid) {
+					t.Errorf("MatchingValue.Oid = %v, want %v", v.Oid, tt.oid)
+				}
+			}
+		})
+	}
+}
+
+func TestWithMatchingFunc_Scoped(t *testing.T) {
+	oidCommonName := asn1.ObjectIdentifier{2, 5, 4, 3}
+	fn := func(x MatchingValue, y MatchingValue) (bool, error) {
+		return x.Value == y.Value, nil
+	}
+	c := NewComparer(WithMatchingFunc(oidCommonName, fn))
+	if got, err := c.Compare(dn2b, dn4b); err != nil || got {
+		t.Errorf("Comparer.Compare() = %v, %v, want false, nil", got, err)
+	}
+	if got, err := NewComparer().Compare(dn2b, dn4b); err != nil || !got {
+		t.Errorf("NewComparer().Compare() = %v, %v, want true, nil", got, err)
+	}
+	if got, err := Compare(dn2b, dn4b); err != nil || !got {
+		t.Errorf("Compare() = %v, %v, want true, nil", got, err)
+	}
+}
+
+func TestWithMatchingFunc_Explain(t *testing.T) {
+	oidCommonName := asn1.ObjectIdentifier{2, 5, 4, 3}
+	fn := func(x MatchingValue, y MatchingValue) (bool, error) {
+		return true, nil
+	}
+	c := NewComparer(WithMatchingFunc(oidCommonName, fn))
+	gotResult, decisions, err := c.CompareExplain(dn3b, dn4b)
+	if err != nil {
+		t.Fatalf("CompareExplain() error = %v", err)
+	}
+	if !gotResult {
+		t.Errorf("CompareExplain() gotResult = %v, want true", gotResult)
+	}
+	if rule := decisions[len(decisions)-1].Attributes[0].Rule; rule != AppliedRuleMatchingFunc {
+		t.Errorf("CompareExplain() rule = %v, want %v", rule, AppliedRuleMatchingFunc)
+	}
+	if got := AppliedRuleMatchingFunc.String(); got != "matchingFunc" {
+		t.Errorf("AppliedRuleMatchingFunc.String() = %q, want %q", got, "matchingFunc")
+	}
+
+	if _, err := c.Canonicalize(dn3b); err == nil {
+		t.Errorf("Canonicalize() error = nil, want error")
+	}
+	if _, err := c.Canonicalize(dn31b); err != nil {
+		t.Errorf("Canonicalize() of other attribute type error = %v", err)
+	}
+}
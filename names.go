@@ -0,0 +1,90 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//AttributeType is an attribute type which has a short name.
+type AttributeType struct {
+	Name string
+	Oid  asn1.ObjectIdentifier
+}
+
+//AttributeTypes is the table of attribute types whose short names are known.
+//https://tools.ietf.org/html/rfc4514#section-3
+var AttributeTypes = []AttributeType{
+	{"CN", asn1.ObjectIdentifier{2, 5, 4, 3}},
+	{"L", asn1.ObjectIdentifier{2, 5, 4, 7}},
+	{"ST", asn1.ObjectIdentifier{2, 5, 4, 8}},
+	{"O", asn1.ObjectIdentifier{2, 5, 4, 10}},
+	{"OU", asn1.ObjectIdentifier{2, 5, 4, 11}},
+	{"C", asn1.ObjectIdentifier{2, 5, 4, 6}},
+	{"STREET", asn1.ObjectIdentifier{2, 5, 4, 9}},
+	{"DC", asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}},
+	{"UID", asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 1}},
+	{"SERIALNUMBER", asn1.ObjectIdentifier{2, 5, 4, 5}},
+	{"unstructuredName", asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 2}},
+	{"jurisdictionL", asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 60, 2, 1, 1}},
+	{"jurisdictionST", asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 60, 2, 1, 2}},
+	{"jurisdictionC", asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 60, 2, 1, 3}},
+	{"member", asn1.ObjectIdentifier{2, 5, 4, 31}},
+	{"owner", asn1.ObjectIdentifier{2, 5, 4, 32}},
+	{"roleOccupant", asn1.ObjectIdentifier{2, 5, 4, 33}},
+	{"seeAlso", asn1.ObjectIdentifier{2, 5, 4, 34}},
+	{"x500UniqueIdentifier", asn1.ObjectIdentifier{2, 5, 4, 45}},
+	{"SN", asn1.ObjectIdentifier{2, 5, 4, 4}},
+	{"givenName", asn1.ObjectIdentifier{2, 5, 4, 42}},
+	{"initials", asn1.ObjectIdentifier{2, 5, 4, 43}},
+	{"businessCategory", asn1.ObjectIdentifier{2, 5, 4, 15}},
+}
+
+//lookupAttributeType returns the attribute type whose short name is name, ignoring case.
+//name may also be the dotted string form of an OID, e.g. "2.5.4.3".
+func lookupAttributeType(name string) (oid asn1.ObjectIdentifier, err error) {
+	for _, t := range AttributeTypes {
+		if strings.EqualFold(t.Name, name) {
+			return t.Oid, nil
+		}
+	}
+	if oid, err = parseOid(name); err != nil {
+		return nil, fmt.Errorf("dn: unknown attribute type %q", name)
+	}
+	return oid, nil
+}
+
+//attributeTypeIndex returns the index of oid in AttributeTypes, or -1 if oid is not in AttributeTypes.
+func attributeTypeIndex(oid asn1.ObjectIdentifier) int {
+	for i, t := range AttributeTypes {
+		if t.Oid.Equal(oid) {
+			return i
+		}
+	}
+	return -1
+}
+
+//attributeTypeName returns the short name of oid in AttributeTypes, or the dotted string form of oid if oid is not in AttributeTypes.
+func attributeTypeName(oid asn1.ObjectIdentifier) string {
+	if i := attributeTypeIndex(oid); i >= 0 {
+		return AttributeTypes[i].Name
+	}
+	return oid.String()
+}
+
+//parseOid parses s, which is the dotted string form of an OID.
+func parseOid(s string) (oid asn1.ObjectIdentifier, err error) {
+	arcs := strings.Split(s, ".")
+	if len(arcs) < 2 {
+		return nil, fmt.Errorf("dn: invalid OID %q", s)
+	}
+	for _, arc := range arcs {
+		var n int
+		if n, err = strconv.Atoi(arc); err != nil || n < 0 || arc[0] == '+' {
+			return nil, fmt.Errorf("dn: invalid OID %q", s)
+		}
+		oid = append(oid, n)
+	}
+	return oid, nil
+}
@@ -0,0 +1,99 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"reflect"
+	"testing"
+)
+
+func Test_lookupAttributeType(t *testing.T) {
+	type args struct {
+		name string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantOid asn1.ObjectIdentifier
+		wantErr bool
+	}{
+		{"CN", args{"CN"}, asn1.ObjectIdentifier{2, 5, 4, 3}, false},
+		{"dc", args{"dc"}, oidDomainComponent, false},
+		{"ST", args{"ST"}, asn1.ObjectIdentifier{2, 5, 4, 8}, false},
+		{"street", args{"street"}, asn1.ObjectIdentifier{2, 5, 4, 9}, false},
+		{"roleOccupant", args{"ROLEOCCUPANT"}, asn1.ObjectIdentifier{2, 5, 4, 33}, false},
+		{"x500UniqueIdentifier", args{"x500UniqueIdentifier"}, oidX500UniqueIdentifier, false},
+		{"unstructuredName", args{"UNSTRUCTUREDNAME"}, oidUnstructuredName, false},
+		{"jurisdictionC", args{"jurisdictionC"}, oidJurisdictionCountryName, false},
+		{"jurisdictionST", args{"JURISDICTIONST"}, asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 60, 2, 1, 2}, false},
+		{"SN", args{"sn"}, asn1.ObjectIdentifier{2, 5, 4, 4}, false},
+		{"givenName", args{"GIVENNAME"}, asn1.ObjectIdentifier{2, 5, 4, 42}, false},
+		{"initials", args{"initials"}, asn1.ObjectIdentifier{2, 5, 4, 43}, false},
+		{"businessCategory", args{"businessCategory"}, oidBusinessCategory, false},
+		{"Dotted OID", args{"1.2.840.113549.1.9.1"}, asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}, false},
+		{"Unknown name", args{"FOO"}, nil, true},
+		{"Invalid OID", args{"1..2"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOid, err := lookupAttributeType(tt.args.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("lookupAttributeType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotOid, tt.wantOid) {
+				t.Errorf("lookupAttributeType() gotOid = %v, want %v", gotOid, tt.wantOid)
+			}
+		})
+	}
+}
+
+func Test_parseOid(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantOid asn1.ObjectIdentifier
+		wantErr bool
+	}{
+		{"2.5.4.3", args{"2.5.4.3"}, asn1.ObjectIdentifier{2, 5, 4, 3}, false},
+		{"Single arc", args{"2"}, nil, true},
+		{"Empty arc", args{"2..4"}, nil, true},
+		{"Negative arc", args{"2.-5"}, nil, true},
+		{"Plus sign", args{"2.+5"}, nil, true},
+		{"Not number", args{"2.a"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOid, err := parseOid(tt.args.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseOid() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotOid, tt.wantOid) {
+				t.Errorf("parseOid() gotOid = %v, want %v", gotOid, tt.wantOid)
+			}
+		})
+	}
+}
+
+func Test_attributeTypeName(t *testing.T) {
+	tests := []struct {
+		name string
+		oid  asn1.ObjectIdentifier
+		want string
+	}{
+		{"CN", asn1.ObjectIdentifier{2, 5, 4, 3}, "CN"},
+		{"DC", oidDomainComponent, "DC"},
+		{"jurisdictionL", asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 60, 2, 1, 1}, "jurisdictionL"},
+		{"Unknown", asn1.ObjectIdentifier{1, 2, 3, 4}, "1.2.3.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attributeTypeName(tt.oid); got != tt.want {
+				t.Errorf("attributeTypeName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package dn
+
+//Observer receives the outcome of each call of Comparer.Compare, e.g. to count the comparisons, the matches by
+//the applied rules and the errors by side in metrics. Its methods are called synchronously, and must be safe for
+//concurrent use if the Comparer is used concurrently.
+type Observer interface {
+	//OnCompare is called when Compare returns without an error.
+	OnCompare(r Result)
+	//OnError is called when Compare returns err, with the side of the input which caused it.
+	OnError(err error, side Side)
+}
+
+//Result is the outcome of Compare passed to Observer.
+type Result struct {
+	Matched bool
+	//Decisions are the decisions for the RDNs compared, which CompareExplain returns.
+	//They are nil if the subject is empty, or the Comparer is configured by WithConstantTime.
+	Decisions []RDNDecision
+	//Reason is the reason of the mismatch, empty if Matched.
+	Reason string
+}
+
+//WithObserver makes the Comparer call o exactly once for each call of Compare: o.OnCompare if Compare returns
+//the result, or o.OnError if Compare returns an error. Without an observer, Compare does no additional work.
+//
+//Compare records the decisions of the RDNs for o in the same way as CompareExplain, except under WithConstantTime.
+func WithObserver(o Observer) Option {
+	return func(c *Comparer) {
+		c.observer = o
+	}
+}
+
+//compareObserved compares issuer and subject in the same way as Compare, and reports the outcome to c.observer.
+//The decisions are recorded unless c compares in constant time.
+func (c *Comparer) compareObserved(issuer []byte, subject []byte) (result bool, err error) {
+	var r Result
+	var side Side
+	if r.Matched, r.Decisions, side, err = c.compare(issuer, subject, !c.constantTime); err != nil {
+		c.observer.OnError(err, side)
+		return false, err
+	}
+	if n := len(r.Decisions); !r.Matched && n != 0 {
+		r.Reason = r.Decisions[n-1].Reason
+	}
+	if !r.Matched && r.Reason == "" {
+		if len(subject) == 0 {
+			r.Reason = "subject is empty"
+		} else {
+			r.Reason = "distinguished names do not match"
+		}
+	}
+	c.observer.OnCompare(r)
+	return r.Matched, nil
+}
@@ -0,0 +1,82 @@
+package dn
+
+import (
+	"testing"
+)
+
+type recordingObserver struct {
+	results []Result
+	errs    []error
+	sides   []Side
+}
+
+func (o *recordingObserver) OnCompare(r Result) {
+	o.results = append(o.results, r)
+}
+
+func (o *recordingObserver) OnError(err error, side Side) {
+	o.errs = append(o.errs, err)
+	o.sides = append(o.sides, side)
+}
+
+func TestWithObserver(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name          string
+		opts          []Option
+		args          args
+		wantResult    bool
+		wantDecisions int
+		wantRule      AppliedRule
+		wantReason    bool
+		wantErr       bool
+		wantSide      Side
+	}{
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", nil, args{dn2b, dn3b}, true, 2, AppliedRuleCaseIgnoreMatch, false, false, 0},
+		{"Different Encoding(UTF8String,BMPString)", nil, args{dn2b, dn5b}, false, 2, AppliedRuleBinaryComparison, true, false, 0},
+		{"Different number of RDNs", nil, args{base2b, dn2b}, false, 1, AppliedRuleNone, true, false, 0},
+		{"Subject is blank", nil, args{dn2b, []byte{}}, false, 0, AppliedRuleNone, true, false, 0},
+		{"Constant time", []Option{WithConstantTime()}, args{dn2b, dn6b}, false, 0, AppliedRuleNone, true, false, 0},
+		{"Issuer is blank", nil, args{[]byte{}, dn2b}, false, 0, AppliedRuleNone, false, true, SideIssuer},
+		{"Broken issuer", nil, args{brdnb, dn2b}, false, 0, AppliedRuleNone, false, true, SideIssuer},
+		{"Broken subject", nil, args{dn2b, brdnb}, false, 0, AppliedRuleNone, false, true, SideSubject},
+		{"Too long subject", []Option{WithMaxInputBytes(len(dn2b))}, args{dn2b, dn1b}, false, 0, AppliedRuleNone, false, true, SideSubject},
+		{"Strict, Empty value in subject", []Option{WithStrict()}, args{dn2b, dn9b}, false, 0, AppliedRuleNone, false, true, SideSubject},
+		{"Wrong Encoding domain component", nil, args{dn7b, dn7b}, false, 0, AppliedRuleNone, false, true, SideBoth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &recordingObserver{}
+			gotResult, err := NewComparer(append(tt.opts, WithObserver(o))...).Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if want, wantErr := NewComparer(tt.opts...).Compare(tt.args.issuer, tt.args.subject); gotResult != want || (wantErr != nil) != tt.wantErr {
+				t.Errorf("Compare() = %v, %v, without observer %v, %v", gotResult, err, want, wantErr)
+			}
+			if len(o.results)+len(o.errs) != 1 {
+				t.Fatalf("observer called %d times, want once", len(o.results)+len(o.errs))
+			}
+			if tt.wantErr {
+				if o.errs[0] != err || o.sides[0] != tt.wantSide {
+					t.Errorf("OnError(%v, %v), want (%v, %v)", o.errs[0], o.sides[0], err, tt.wantSide)
+				}
+				return
+			}
+			r := o.results[0]
+			if r.Matched != tt.wantResult || len(r.Decisions) != tt.wantDecisions || (r.Reason != "") != tt.wantReason {
+				t.Errorf("OnCompare(%+v), want Matched %v, %d decisions, reason %v", r, tt.wantResult, tt.wantDecisions, tt.wantReason)
+			}
+			if n := len(r.Decisions); n != 0 {
+				last := r.Decisions[n-1]
+				if len(last.Attributes) == 0 && tt.wantRule != AppliedRuleNone || len(last.Attributes) != 0 && last.Attributes[0].Rule != tt.wantRule {
+					t.Errorf("OnCompare() last decision = %+v, want rule %v", last, tt.wantRule)
+				}
+			}
+		})
+	}
+}
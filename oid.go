@@ -0,0 +1,85 @@
+package dn
+
+import (
+	"encoding/asn1"
+)
+
+//internedDomainComponent is domainComponent interned by internOid.
+var internedDomainComponent = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
+
+//knownOids are the well-known attribute types interned by internOid.
+//Attributes of the same well-known type share the backing array of the OID after parsing,
+//so that oidEqual decides their equality without walking the arcs.
+//The interned OIDs are shared by all the parsed distinguished names, so they must never be modified, and never be
+//exposed to the callers: the OIDs of the exported results are copied by cloneOid.
+var knownOids = []asn1.ObjectIdentifier{
+	{2, 5, 4, 3},  //CN
+	{2, 5, 4, 5},  //SERIALNUMBER
+	{2, 5, 4, 6},  //C
+	{2, 5, 4, 7},  //L
+	{2, 5, 4, 8},  //ST
+	{2, 5, 4, 9},  //STREET
+	{2, 5, 4, 10}, //O
+	{2, 5, 4, 11}, //OU
+	internedDomainComponent,
+}
+
+//internOid returns the interned OID equal to oid if oid is well-known, otherwise returns oid.
+func internOid(oid asn1.ObjectIdentifier) asn1.ObjectIdentifier {
+	if len(oid) == 0 {
+		return oid
+	}
+	last := oid[len(oid)-1]
+	for _, k := range knownOids {
+		//check the last arc first, which differs among the well-known types
+		if len(k) == len(oid) && k[len(k)-1] == last && k.Equal(oid) {
+			return k
+		}
+	}
+	return oid
+}
+
+//cloneOid returns a copy of oid, so that the callers cannot modify the interned OIDs through the exported results.
+func cloneOid(oid asn1.ObjectIdentifier) asn1.ObjectIdentifier {
+	if oid == nil {
+		return nil
+	}
+	return append(asn1.ObjectIdentifier{}, oid...)
+}
+
+//internOids replaces the OIDs of attributes in d with the interned OIDs.
+func internOids(d dn) {
+	for _, r := range d {
+		for i := range r {
+			r[i].Oid = internOid(r[i].Oid)
+		}
+	}
+}
+
+//oidEqual reports whether x and y are the same OID.
+//It returns the same result as x.Equal(y), deciding interned OIDs by identity.
+//
+//Attribute types are always compared by their decoded arcs, by oidEqual, asn1.ObjectIdentifier.Equal or the dotted
+//string form, and never by their encodings, which are not kept after parsing. encoding/asn1 rejects OIDs which are
+//not minimally encoded, so such distinguished names result in an error rather than a mismatch.
+func oidEqual(x asn1.ObjectIdentifier, y asn1.ObjectIdentifier) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	if len(x) == 0 || &x[0] == &y[0] {
+		return true
+	}
+	return x.Equal(y)
+}
+
+//isDomainComponent reports whether oid is domainComponent. It returns the same result as oid.Equal(oidDomainComponent),
+//checking the length and the arcs from the tail, where the other attribute types differ first.
+func isDomainComponent(oid asn1.ObjectIdentifier) bool {
+	if len(oid) != 7 {
+		return false
+	}
+	if &oid[0] == &internedDomainComponent[0] {
+		return true
+	}
+	return oid[6] == 25 && oid[5] == 1 && oid[4] == 100 && oid[3] == 19200300 && oid[2] == 2342 && oid[1] == 9 && oid[0] == 0
+}
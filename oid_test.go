@@ -0,0 +1,217 @@
+package dn
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+)
+
+func Test_internOid(t *testing.T) {
+	tests := []struct {
+		name         string
+		oid          asn1.ObjectIdentifier
+		wantInterned bool
+	}{
+		{"CN", asn1.ObjectIdentifier{2, 5, 4, 3}, true},
+		{"DC", asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}, true},
+		{"Unknown", asn1.ObjectIdentifier{1, 2, 3, 4}, false},
+		{"Same last arc", asn1.ObjectIdentifier{1, 5, 4, 3}, false},
+		{"Empty", asn1.ObjectIdentifier{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := internOid(tt.oid)
+			if !got.Equal(tt.oid) {
+				t.Errorf("internOid() = %v, want %v", got, tt.oid)
+			}
+			if isInterned := len(got) != 0 && &got[0] != &tt.oid[0]; isInterned != tt.wantInterned {
+				t.Errorf("internOid() interned = %v, want %v", isInterned, tt.wantInterned)
+			}
+		})
+	}
+}
+
+func Test_oidEqual(t *testing.T) {
+	type args struct {
+		x asn1.ObjectIdentifier
+		y asn1.ObjectIdentifier
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"Interned, Same", args{internOid(asn1.ObjectIdentifier{2, 5, 4, 3}), internOid(asn1.ObjectIdentifier{2, 5, 4, 3})}, true},
+		{"Interned, Different", args{internOid(asn1.ObjectIdentifier{2, 5, 4, 3}), internOid(asn1.ObjectIdentifier{2, 5, 4, 10})}, false},
+		{"Not interned, Same", args{asn1.ObjectIdentifier{1, 2, 3, 4}, asn1.ObjectIdentifier{1, 2, 3, 4}}, true},
+		{"Not interned, Different", args{asn1.ObjectIdentifier{1, 2, 3, 4}, asn1.ObjectIdentifier{1, 2, 3, 5}}, false},
+		{"Interned and not interned, Same", args{internOid(asn1.ObjectIdentifier{2, 5, 4, 3}), asn1.ObjectIdentifier{2, 5, 4, 3}}, true},
+		{"Different length", args{asn1.ObjectIdentifier{2, 5, 4}, asn1.ObjectIdentifier{2, 5, 4, 3}}, false},
+		{"Empty", args{asn1.ObjectIdentifier{}, nil}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := oidEqual(tt.args.x, tt.args.y); got != tt.want {
+				t.Errorf("oidEqual() = %v, want %v", got, tt.want)
+			}
+			if got := tt.args.x.Equal(tt.args.y); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Benchmark_oidEqual(b *testing.B) {
+	x := internOid(asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25})
+	y := internOid(asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25})
+	for i := 0; i < b.N; i++ {
+		oidEqual(x, y)
+	}
+}
+
+func Benchmark_oidEqual_Equal(b *testing.B) {
+	x := asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
+	y := asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
+	for i := 0; i < b.N; i++ {
+		x.Equal(y)
+	}
+}
+
+func TestCompare_OidEncoding(t *testing.T) {
+	//the OIDs of the same type parsed from different distinguished names do not share the backing arrays
+	//unless they are interned, and are compared by the decoded arcs
+	x, err := parseDn(dn32b)
+	if err != nil {
+		t.Fatalf("parseDn() error = %v", err)
+	}
+	y, err := parseDn(dn33b)
+	if err != nil {
+		t.Fatalf("parseDn() error = %v", err)
+	}
+	if &x[1][0].Oid[0] == &y[1][0].Oid[0] {
+		t.Fatalf("jurisdictionCountryName of both distinguished names share the backing array")
+	}
+	if got, err := Compare(dn32b, dn33b); err != nil || !got {
+		t.Errorf("Compare() = %v, %v, want true", got, err)
+	}
+
+	//the OID which is not minimally encoded is rejected on either side, and never compared by the encoding
+	for _, args := range [][2][]byte{{dn74b, dn3b}, {dn3b, dn74b}, {dn74b, dn74b}} {
+		if got, err := Compare(args[0], args[1]); err == nil {
+			t.Errorf("Compare(%x, %x) = %v, want error", args[0], args[1], got)
+		}
+	}
+}
+
+func TestInternedOidsAreNotExposed(t *testing.T) {
+	want := mustMarshalString(t, "DC=example,DC=com")
+	modify := func(oid asn1.ObjectIdentifier) {
+		for i := range oid {
+			oid[i] = 99
+		}
+	}
+
+	findings, err := Validate(mustMarshalString(t, "DC=,DC=com"))
+	if err != nil || len(findings) == 0 {
+		t.Fatalf("Validate() = %v, %v, want findings", findings, err)
+	}
+	modify(findings[0].Type)
+	for _, atv := range DCsFromDomain("example.com") {
+		modify(atv.Oid)
+	}
+	c := NewComparer(WithMatchingFunc(oidDomainComponent, func(x MatchingValue, y MatchingValue) (bool, error) {
+		modify(x.Oid)
+		modify(y.Oid)
+		return true, nil
+	}), WithAuditHook(func(e Event) { modify(e.Type) }))
+	if _, err = c.Compare(want, want); err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	_, decisions, err := CompareExplain(want, want)
+	if err != nil {
+		t.Fatalf("CompareExplain() error = %v", err)
+	}
+	modify(decisions[0].Attributes[0].Type)
+
+	if !internedDomainComponent.Equal(oidDomainComponent) || !oidDomainComponent.Equal(asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}) {
+		t.Fatalf("domainComponent is modified: %v, %v", internedDomainComponent, oidDomainComponent)
+	}
+	got, err := FromPkixName(pkix.Name{}, "example.com")
+	if err != nil {
+		t.Fatalf("FromPkixName() error = %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("FromPkixName() = %x, want %x", got, want)
+	}
+	if result, err := Compare(want, mustMarshalString(t, "DC=EXAMPLE,DC=COM")); err != nil || !result {
+		t.Errorf("Compare() = %v, %v, want true", result, err)
+	}
+}
+
+func Test_isDomainComponent(t *testing.T) {
+	tests := []struct {
+		name string
+		oid  asn1.ObjectIdentifier
+		want bool
+	}{
+		{"Interned", internOid(asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}), true},
+		{"Not interned", asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}, true},
+		{"userid", asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 1}, false},
+		{"Different first arc", asn1.ObjectIdentifier{1, 9, 2342, 19200300, 100, 1, 25}, false},
+		{"CN", asn1.ObjectIdentifier{2, 5, 4, 3}, false},
+		{"Empty", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDomainComponent(tt.oid); got != tt.want {
+				t.Errorf("isDomainComponent() = %v, want %v", got, tt.want)
+			}
+			if got := tt.oid.Equal(oidDomainComponent); got != tt.want {
+				t.Errorf("Equal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Benchmark_isDomainComponent(b *testing.B) {
+	oids := []asn1.ObjectIdentifier{
+		{2, 5, 4, 6},
+		{0, 9, 2342, 19200300, 100, 1, 25},
+		{0, 9, 2342, 19200300, 100, 1, 1},
+		{2, 5, 4, 3},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		isDomainComponent(oids[i%len(oids)])
+	}
+}
+
+func Benchmark_isDomainComponent_Equal(b *testing.B) {
+	oids := []asn1.ObjectIdentifier{
+		{2, 5, 4, 6},
+		{0, 9, 2342, 19200300, 100, 1, 25},
+		{0, 9, 2342, 19200300, 100, 1, 1},
+		{2, 5, 4, 3},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		oids[i%len(oids)].Equal(oidDomainComponent)
+	}
+}
+
+func BenchmarkCompare_DomainComponent(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := Compare(dn17b, dn17b); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompare(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := Compare(dn1b, dn16b); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
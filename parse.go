@@ -0,0 +1,276 @@
+package dn
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//DN is a parsed distinguished name.
+type DN struct {
+	rdns dn
+}
+
+//ParseDN parses der, which is encoded as Distinguished Name.
+//
+//The returned DN refers to der: the values of the attributes are sub-slices of der, and are not copied.
+//Modifying or reusing der after parsing, e.g. returning it to a pool, silently changes the DN.
+//Use Clone to get a DN which is independent of der.
+func ParseDN(der []byte) (result *DN, err error) {
+	var d dn
+	if d, err = parseDn(der); err != nil {
+		return nil, err
+	}
+	return &DN{rdns: d}, nil
+}
+
+//ParsePrefix parses the distinguished name at the beginning of der, which may be followed by other data, and returns
+//the number of bytes it occupies, i.e. the length of the DER encoding of the outer SEQUENCE. The data after the
+//distinguished name are not parsed, and der[n:] is where the parsing of them continues.
+//The returned DN refers to der in the same way as ParseDN.
+func ParsePrefix(der []byte) (result *DN, n int, err error) {
+	var d dn
+	if d, n, err = parseDnPrefix(der); err != nil {
+		return nil, 0, err
+	}
+	return &DN{rdns: d}, n, nil
+}
+
+//ParseString parses s, which is the string representation of a distinguished name described in RFC 4514,
+//e.g. "CN=abc,O=Example,C=JP".
+//
+//The attribute types are short names in AttributeTypes, ignoring case, or the dotted string form of OIDs.
+//The values are encoded according to DefaultEncodingPolicy, unless opts change it, e.g. WithForceUTF8 or
+//WithEncodingPolicy, except the values which are "#" followed by the hexadecimal encoding of the BER encoding of the
+//values, which are used as they are. Unescaped spaces around the types and the values are ignored.
+func ParseString(s string, opts ...BuildOption) (result *DN, err error) {
+	var d dn
+	if d, err = parseString(s, newValueEncoder(opts)); err != nil {
+		return nil, err
+	}
+	return &DN{rdns: d}, nil
+}
+
+//parseString parses s, which is the string representation of a distinguished name described in RFC 4514,
+//encoding the values by ve.
+func parseString(s string, ve *valueEncoder) (d dn, err error) {
+	if strings.TrimSpace(s) == "" {
+		return dn{}, nil
+	}
+	var r rdnSET
+	for i := 0; ; i++ {
+		var atv Attribute
+		if atv, i, err = parseStringAttribute(s, i, ve); err != nil {
+			return nil, err
+		}
+		r = append(r, atv)
+		if i == len(s) {
+			if err = sortRDN(r); err != nil {
+				return nil, err
+			}
+			d = append(d, r)
+			break
+		}
+		//https://tools.ietf.org/html/rfc4514#section-3
+		//distinguishedName = [ relativeDistinguishedName
+		//    *( COMMA relativeDistinguishedName ) ]
+		//relativeDistinguishedName = attributeTypeAndValue
+		//    *( PLUS attributeTypeAndValue )
+		if s[i] != '+' {
+			if err = sortRDN(r); err != nil {
+				return nil, err
+			}
+			d = append(d, r)
+			r = nil
+		}
+	}
+	//The string representation starts with the last RDN of the RDNSequence.
+	for i, j := 0, len(d)-1; i < j; i, j = i+1, j-1 {
+		d[i], d[j] = d[j], d[i]
+	}
+	return d, nil
+}
+
+//parseStringAttribute parses attributeTypeAndValue of RFC 4514 in s from i.
+//next is the index of the separator after the attribute, or len(s).
+func parseStringAttribute(s string, i int, ve *valueEncoder) (atv Attribute, next int, err error) {
+	eq := strings.IndexByte(s[i:], '=')
+	if eq < 0 {
+		return Attribute{}, 0, fmt.Errorf("dn: missing \"=\" after position %d", i)
+	}
+	name := strings.TrimSpace(s[i : i+eq])
+	if atv.Oid, err = lookupAttributeType(name); err != nil {
+		return Attribute{}, 0, err
+	}
+	i += eq + 1
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+
+	//https://tools.ietf.org/html/rfc4514#section-2.4
+	//If the AttributeType is of the dotted-decimal form, the
+	//AttributeValue is represented by an number sign ('#' U+0023)
+	//character followed by the hexadecimal encoding of each of the octets
+	//of the BER encoding of the X.500 AttributeValue.
+	if i < len(s) && s[i] == '#' {
+		j := i + 1
+		for j < len(s) && s[j] != ',' && s[j] != '+' && s[j] != ';' && s[j] != ' ' {
+			j++
+		}
+		var b []byte
+		if b, err = hex.DecodeString(s[i+1 : j]); err != nil {
+			return Attribute{}, 0, fmt.Errorf("dn: invalid hexadecimal value at position %d: %w", i, err)
+		}
+		var rest []byte
+		if rest, err = asn1.Unmarshal(b, &atv.RawValue); err != nil {
+			return Attribute{}, 0, fmt.Errorf("dn: invalid BER value at position %d: %w", i, err)
+		}
+		if len(rest) != 0 {
+			return Attribute{}, 0, fmt.Errorf("dn: trailing data after BER value at position %d", i)
+		}
+		for j < len(s) && s[j] == ' ' {
+			j++
+		}
+		if j < len(s) && s[j] != ',' && s[j] != '+' && s[j] != ';' {
+			return Attribute{}, 0, fmt.Errorf("dn: unexpected character at position %d", j)
+		}
+		return atv, j, nil
+	}
+
+	var value []byte
+	significant := 0 //length of value without trailing unescaped spaces
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == ',' || c == '+' || c == ';' {
+			break
+		}
+		if c != '\\' {
+			value = append(value, c)
+			if c != ' ' {
+				significant = len(value)
+			}
+			continue
+		}
+		//https://tools.ietf.org/html/rfc4514#section-3
+		//pair = ESC ( ESC / special / hexpair )
+		//special = escaped / SPACE / SHARP / EQUALS
+		if i+1 == len(s) {
+			return Attribute{}, 0, fmt.Errorf("dn: incomplete escape at position %d", i)
+		}
+		if strings.IndexByte("\\\" +,;<=># ", s[i+1]) >= 0 {
+			value = append(value, s[i+1])
+			i++
+		} else {
+			var b []byte
+			if i+2 >= len(s) {
+				return Attribute{}, 0, fmt.Errorf("dn: invalid escape at position %d", i)
+			}
+			if b, err = hex.DecodeString(s[i+1 : i+3]); err != nil {
+				return Attribute{}, 0, fmt.Errorf("dn: invalid escape at position %d", i)
+			}
+			value = append(value, b...)
+			i += 2
+		}
+		significant = len(value)
+	}
+	if atv.RawValue, err = encodeString(string(value[:significant]), ve.encoding(atv.Oid)); err != nil {
+		return Attribute{}, 0, err
+	}
+	return atv, i, nil
+}
+
+//Len returns the number of RDNs in d.
+func (d *DN) Len() int {
+	return len(d.rdns)
+}
+
+//Marshal encodes d as Distinguished Name.
+//The values of the attributes are encoded verbatim as they were parsed, e.g. in BMPString or TeletexString, and the
+//attributes in each RDN are encoded in the order of d, so that Marshal of a DN parsed by ParseDN returns the same
+//bytes as the input, even if the RDNs are not sorted as DER requires. The attributes of the RDNs made by ParseString
+//and InsertRDN are sorted in the order required by DER when they are made. ReplaceValue keeps the position of the
+//replaced attribute, so that only its encoding and the lengths which enclose it change.
+func (d *DN) Marshal() ([]byte, error) {
+	return marshalDnInOrder(d.rdns)
+}
+
+//marshalDnInOrder encodes d as Distinguished Name in the same way as marshalDn, except that the attributes in each
+//RDN are encoded in the order of d.
+func marshalDnInOrder(d dn) ([]byte, error) {
+	var rdns []byte
+	for _, r := range d {
+		var set []byte
+		for _, atv := range r {
+			b, err := asn1.Marshal(atv)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, b...)
+		}
+		b, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: set})
+		if err != nil {
+			return nil, err
+		}
+		rdns = append(rdns, b...)
+	}
+	return asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: rdns})
+}
+
+//sortRDN sorts the attributes of r in the order required by DER, in the same way as marshalDn.
+func sortRDN(r rdnSET) error {
+	if len(r) < 2 {
+		return nil
+	}
+	encoded := make([][]byte, len(r))
+	for i, atv := range r {
+		var err error
+		if encoded[i], err = asn1.Marshal(atv); err != nil {
+			return err
+		}
+	}
+	sort.Sort(rdnSorter{r: r, encoded: encoded})
+	return nil
+}
+
+//rdnSorter sorts the attributes of an RDN by their encodings.
+type rdnSorter struct {
+	r       rdnSET
+	encoded [][]byte
+}
+
+func (s rdnSorter) Len() int           { return len(s.r) }
+func (s rdnSorter) Less(i, j int) bool { return bytes.Compare(s.encoded[i], s.encoded[j]) < 0 }
+func (s rdnSorter) Swap(i, j int) {
+	s.r[i], s.r[j] = s.r[j], s.r[i]
+	s.encoded[i], s.encoded[j] = s.encoded[j], s.encoded[i]
+}
+
+//Clone returns a deep copy of d which shares no memory with d or the buffer which d was parsed from.
+func (d *DN) Clone() *DN {
+	result := &DN{rdns: make(dn, len(d.rdns))}
+	for i, r := range d.rdns {
+		result.rdns[i] = make(rdnSET, len(r))
+		for j, atv := range r {
+			result.rdns[i][j] = cloneAttribute(atv)
+		}
+	}
+	return result
+}
+
+//cloneAttribute returns a deep copy of atv.
+func cloneAttribute(atv Attribute) Attribute {
+	rv := atv.RawValue
+	rv.FullBytes = append([]byte(nil), atv.RawValue.FullBytes...)
+	//Bytes is the content at the end of FullBytes
+	rv.Bytes = rv.FullBytes[len(rv.FullBytes)-len(atv.RawValue.Bytes):]
+	if atv.RawValue.Bytes == nil {
+		rv.Bytes = nil
+	}
+	return Attribute{
+		Oid:      append(asn1.ObjectIdentifier(nil), atv.Oid...),
+		RawValue: rv,
+	}
+}
@@ -0,0 +1,336 @@
+package dn
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestParseDN(t *testing.T) {
+	type args struct {
+		der []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantLen int
+		wantErr bool
+	}{
+		{"Multi RDN", args{dn1b}, 3, false},
+		{"Broken data", args{brdnb}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDN(tt.args.der)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDN() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Len() != tt.wantLen {
+				t.Errorf("ParseDN() Len = %v, want %v", got.Len(), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	trailing := append(append([]byte{}, dn1b...), dn2b...)
+	tests := []struct {
+		name    string
+		der     []byte
+		wantLen int
+		wantN   int
+		wantErr bool
+	}{
+		{"Multi RDN", dn1b, 3, len(dn1b), false},
+		{"Followed by another distinguished name", trailing, 3, len(dn1b), false},
+		{"Followed by a byte", append(append([]byte{}, dn2b...), 0x00), 2, len(dn2b), false},
+		{"Followed by broken data", brdnb, 3, 0x37, false},
+		{"Broken data", []byte{0x30, 0x03, 0x31, 0x01, 0x30}, 0, 0, true},
+		{"Truncated", dn1b[:len(dn1b)-1], 0, 0, true},
+		{"Empty", nil, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotN, err := ParsePrefix(tt.der)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParsePrefix() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotN != tt.wantN {
+				t.Errorf("ParsePrefix() n = %v, want %v", gotN, tt.wantN)
+			}
+			if err == nil && got.Len() != tt.wantLen {
+				t.Errorf("ParsePrefix() Len = %v, want %v", got.Len(), tt.wantLen)
+			}
+		})
+	}
+
+	//the rest is parsed from n
+	first, n, err := ParsePrefix(trailing)
+	if err != nil {
+		t.Fatalf("ParsePrefix() error = %v", err)
+	}
+	second, err := ParseDN(trailing[n:])
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	firstBytes, err := first.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	secondBytes, err := second.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !bytes.Equal(firstBytes, dn1b) || !bytes.Equal(secondBytes, dn2b) {
+		t.Errorf("ParsePrefix() = %x and %x, want %x and %x", firstBytes, secondBytes, dn1b, dn2b)
+	}
+}
+
+func TestDN_Marshal(t *testing.T) {
+	tests := []struct {
+		name string
+		der  []byte
+		want string
+	}{
+		{"Multi RDN in DER order", dn1b, hdn1},
+		{"Multi RDN not in DER order is kept", dn16b, hdn16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDN(tt.der)
+			if err != nil {
+				t.Fatalf("ParseDN() error = %v", err)
+			}
+			got, err := d.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("Marshal() = %x, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDN_Marshal_RoundTrip(t *testing.T) {
+	//C=JP(PrintableString),O=Example(BMPString),L=Tokyo(PrintableString)+OU=Caf\xe9(TeletexString) not in DER order,
+	//CN=abc(UTF8String)
+	der, _ := hex.DecodeString("3051310b3009060355040613024a5031173015060355040a1e0e004500780061006d0070006c0065311b300c06035504071305546f6b796f300b060355040b1404436166e9310c300a06035504030c03616263")
+	tests := []struct {
+		name string
+		edit func(d *DN) (*DN, error)
+		want string
+	}{
+		{"Untouched", func(d *DN) (*DN, error) {
+			return d, nil
+		}, "3051310b3009060355040613024a5031173015060355040a1e0e004500780061006d0070006c0065311b300c06035504071305546f6b796f300b060355040b1404436166e9310c300a06035504030c03616263"},
+		{"Replace CN", func(d *DN) (*DN, error) {
+			return d.ReplaceValue(oidCommonName, 0, "xyz-replaced", EncodingUTF8String)
+		}, "305a310b3009060355040613024a5031173015060355040a1e0e004500780061006d0070006c0065311b300c06035504071305546f6b796f300b060355040b1404436166e93115301306035504030c0c78797a2d7265706c61636564"},
+		{"Replace attribute of RDN not in DER order", func(d *DN) (*DN, error) {
+			return d.ReplaceValue(asn1.ObjectIdentifier{2, 5, 4, 7}, 0, "Osaka", EncodingUTF8String)
+		}, "3051310b3009060355040613024a5031173015060355040a1e0e004500780061006d0070006c0065311b300c06035504070c054f73616b61300b060355040b1404436166e9310c300a06035504030c03616263"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDN(der)
+			if err != nil {
+				t.Fatalf("ParseDN() error = %v", err)
+			}
+			e, err := tt.edit(d)
+			if err != nil {
+				t.Fatalf("edit error = %v", err)
+			}
+			got, err := e.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("Marshal() = %x, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseString_SortsMultiValuedRDN(t *testing.T) {
+	d, err := ParseString("OU=Caf\\C3\\A9+L=Tokyo,C=JP")
+	if err != nil {
+		t.Fatalf("ParseString() error = %v", err)
+	}
+	got, err := d.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	//the attributes are sorted by their encodings: L(2.5.4.7) precedes OU(2.5.4.11) of the same length
+	if want := "302b310b3009060355040613024a50311c300c06035504071305546f6b796f300c060355040b0c05436166c3a9"; hex.EncodeToString(got) != want {
+		t.Errorf("Marshal() = %x, want %v", got, want)
+	}
+}
+
+func TestDN_Clone(t *testing.T) {
+	buf := append([]byte(nil), dn1b...)
+	d, err := ParseDN(buf)
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	c := d.Clone()
+	for i := range buf {
+		buf[i] = 0
+	}
+
+	got, err := c.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	fresh, err := ParseDN(dn1b)
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	want, err := fresh.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if result, err := Compare(want, got); err != nil || !result {
+		t.Errorf("Compare() result = %v, err = %v, want true", result, err)
+	}
+	if !reflect.DeepEqual(c, fresh) {
+		t.Errorf("Clone() = %v, want %v", c, fresh)
+	}
+	if _, err := d.Marshal(); err == nil && d.String() == fresh.String() {
+		t.Errorf("DN parsed from the zeroed buffer is not changed")
+	}
+}
+
+func TestParseDN_Aliasing(t *testing.T) {
+	buf := append([]byte(nil), dn3b...)
+	d, err := ParseDN(buf)
+	if err != nil {
+		t.Fatalf("ParseDN() error = %v", err)
+	}
+	c := d.Clone()
+	//the last byte is the last character of CN=ABC
+	buf[len(buf)-1] = 'X'
+
+	if got, want := d.String(), "CN=ABX,C=JP"; got != want {
+		t.Errorf("String() after mutating the buffer = %v, want %v", got, want)
+	}
+	if got, want := c.String(), "CN=ABC,C=JP"; got != want {
+		t.Errorf("Clone().String() after mutating the buffer = %v, want %v", got, want)
+	}
+}
+
+//parseCorpus is a few thousand distinguished names for the benchmarks of parsing.
+func parseCorpus() [][]byte {
+	corpus := make([][]byte, 0, 4000)
+	for i := 0; i < 400; i++ {
+		corpus = append(corpus, dn1b, dn2b, dn3b, dn4b, dn5b, dn6b, dn15b, dn16b, dn65b, dn83b)
+	}
+	return corpus
+}
+
+func BenchmarkParseDN(b *testing.B) {
+	corpus := parseCorpus()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, der := range corpus {
+			if _, err := ParseDN(der); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkParseDN_Clone(b *testing.B) {
+	corpus := parseCorpus()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, der := range corpus {
+			d, err := ParseDN(der)
+			if err != nil {
+				b.Fatal(err)
+			}
+			d.Clone()
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{"Single-valued RDNs", "CN=abc,O=Example,C=JP", "302d310b3009060355040613024a503110300e060355040a13074578616d706c65310c300a06035504031303616263", false},
+		{"Spaces around types and values", " cn = abc , o=Example ;C=JP ", "302d310b3009060355040613024a503110300e060355040a13074578616d706c65310c300a06035504031303616263", false},
+		{"Multi-valued RDN", "OU=bar+O=foo,C=JP", "3027310b3009060355040613024a503118300a060355040a1303666f6f300a060355040b1303626172", false},
+		{"Escaped characters", `CN=\ a\,b\+c\\\ `, "30133111300f06035504030c0820612c622b635c20", false},
+		{"Hex pairs of UTF-8", `CN=\E6\97\A5\e6\9c\ac`, "3011310f300d06035504030c06e697a5e69cac", false},
+		{"Inner spaces", "CN=a  b", "300f310d300b0603550403130461202062", false},
+		{"Domain components", "CN=x,DC=com", "302131133011060a0992268993f22c6401191603636f6d310a30080603550403130178", false},
+		{"Hex value", "CN=#0c0178,0.9.2342.19200300.100.1.25=#1603636f6d", "302131133011060a0992268993f22c6401191603636f6d310a300806035504030c0178", false},
+		{"Jurisdiction country name", "O=Example,jurisdictionC=JP,C=JP", "3034310b3009060355040613024a5031133011060b2b0601040182373c02010313024a503110300e060355040a13074578616d706c65", false},
+		{"Empty", "", "3000", false},
+		{"Missing equals sign", "CN=abc,O", "", true},
+		{"Trailing comma", "CN=abc,", "", true},
+		{"Unknown attribute type", "XX=abc", "", true},
+		{"Incomplete escape", `CN=abc\`, "", true},
+		{"Invalid escape", `CN=\zz`, "", true},
+		{"Invalid hex value", "CN=#0c01", "", true},
+		{"Trailing data after hex value", "CN=#0c0178 y", "", true},
+		{"Wrong encoding", "C=日本", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseString(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			der, err := got.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if hex.EncodeToString(der) != tt.want {
+				t.Errorf("ParseString() = %x, want %v", der, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseString_String(t *testing.T) {
+	tests := []struct {
+		name string
+		der  []byte
+	}{
+		{"Multi RDN", dn1b},
+		{"Domain components", dn17b},
+		{"PrintableString", dn3b},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDN(tt.der)
+			if err != nil {
+				t.Fatalf("ParseDN() error = %v", err)
+			}
+			got, err := ParseString(d.String())
+			if err != nil {
+				t.Fatalf("ParseString() error = %v", err)
+			}
+			der, err := got.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if result, err := Compare(tt.der, der); err != nil || !result {
+				t.Errorf("Compare() = %v, %v, want true", result, err)
+			}
+		})
+	}
+}
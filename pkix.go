@@ -0,0 +1,64 @@
+package dn
+
+import (
+	"crypto/x509/pkix"
+	"fmt"
+)
+
+//FromPkixName encodes name as Distinguished Name, adding domain component attributes derived from domain.
+//
+//pkix.Name has no field for domain component. The labels of domain are added as domain component attributes
+//encoded in IA5String, from the top-level label to the leftmost label, right after the countryName RDNs
+//(or at the beginning if there is no countryName), e.g. C=JP,DC=com,DC=example,CN=abc.
+//The labels are converted to lower case in the same way as DCsFromDomain, and a trailing dot of domain is ignored.
+//If domain is empty, no domain component is added.
+//The values of the other attributes are encoded according to DefaultEncodingPolicy, unless opts change it,
+//e.g. WithForceUTF8 or WithEncodingPolicy.
+func FromPkixName(name pkix.Name, domain string, opts ...BuildOption) (result []byte, err error) {
+	var labels []string
+	if labels, err = domainComponents(domain); err != nil {
+		return nil, err
+	}
+
+	ve := newValueEncoder(opts)
+	var d dn
+	for _, r := range name.ToRDNSequence() {
+		var nr rdnSET
+		if nr, err = fromPkixRDN(r, ve); err != nil {
+			return nil, err
+		}
+		d = append(d, nr)
+	}
+
+	//insert domain components after the leading countryName RDNs
+	pos := 0
+	for pos < len(d) && len(d[pos]) == 1 && d[pos][0].Oid.Equal(oidCountryName) {
+		pos++
+	}
+	dcs := make(dn, 0, len(labels))
+	for _, label := range labels {
+		var atv Attribute
+		if atv, err = newStringAttribute(oidDomainComponent, label, EncodingIA5String); err != nil {
+			return nil, err
+		}
+		dcs = append(dcs, rdnSET{atv})
+	}
+	d = append(d[:pos], append(dcs, d[pos:]...)...)
+	return marshalDn(d)
+}
+
+//fromPkixRDN converts r to rdnSET, encoding the values by ve.
+func fromPkixRDN(r pkix.RelativeDistinguishedNameSET, ve *valueEncoder) (result rdnSET, err error) {
+	for _, tv := range r {
+		s, ok := tv.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("dn: value of attribute %s is not string", tv.Type)
+		}
+		var atv Attribute
+		if atv, err = newStringAttribute(tv.Type, s, ve.encoding(tv.Type)); err != nil {
+			return nil, err
+		}
+		result = append(result, atv)
+	}
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package dn
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"testing"
+)
+
+func TestFromPkixName(t *testing.T) {
+	type args struct {
+		name   pkix.Name
+		domain string
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult string
+		wantErr    bool
+	}{
+		{"Country, CommonName and domain", args{pkix.Name{Country: []string{"JP"}, CommonName: "abc"}, "example.com"}, "3049310b3009060355040613024a5031133011060a0992268993f22c6401191603636f6d31173015060a0992268993f22c64011916076578616d706c65310c300a06035504031303616263", false},
+		{"Without domain", args{pkix.Name{Country: []string{"JP"}, CommonName: "ABC"}, ""}, hdn3, false},
+		{"Not printable country", args{pkix.Name{Country: []string{"J@"}}, ""}, "", true},
+		{"Empty label", args{pkix.Name{CommonName: "abc"}, "example..com"}, "", true},
+		{"Not IA5 domain", args{pkix.Name{CommonName: "abc"}, "例.jp"}, "", true},
+		{"Not string ExtraNames", args{pkix.Name{ExtraNames: []pkix.AttributeTypeAndValue{{Type: asn1.ObjectIdentifier{2, 5, 4, 3}, Value: 1}}}, ""}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := FromPkixName(tt.args.name, tt.args.domain)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FromPkixName() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if hex.EncodeToString(gotResult) != tt.wantResult {
+				t.Errorf("FromPkixName() gotResult = %x, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestFromPkixName_Compare(t *testing.T) {
+	got, err := FromPkixName(pkix.Name{Country: []string{"JP"}, CommonName: "ABC"}, "Example.COM")
+	if err != nil {
+		t.Fatalf("FromPkixName() error = %v", err)
+	}
+	if result, err := Compare(dn17b, got); err != nil || !result {
+		t.Errorf("Compare() result = %v, err = %v, want true", result, err)
+	}
+}
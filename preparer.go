@@ -0,0 +1,133 @@
+package dn
+
+import (
+	"strings"
+	"unicode"
+)
+
+//NormalizationForm is the Unicode normalization form which the string preparation applies.
+type NormalizationForm int
+
+//Normalization forms of the string preparation.
+const (
+	//NormalizationNFKC is Normalization Form KC, which RFC 4518 requires. The compatibility characters match their
+	//canonical forms, e.g. full-width "ＡＢＣ" matches "ABC", and the ligature "\ufb01" matches "fi".
+	NormalizationNFKC NormalizationForm = 0
+	//NormalizationNFC is Normalization Form C, which only composes the characters, e.g. "e\u0301" matches "\u00e9".
+	//The compatibility characters are distinct from their canonical forms. Note that the case folding of
+	//caseIgnoreMatch(RFC3454 appendix-B.2) still maps some of them, e.g. the ligature "\ufb01" to "fi".
+	NormalizationNFC NormalizationForm = 1
+)
+
+//StringPreparer prepares the string values of attributes before they are compared, e.g. by caseIgnoreMatch.
+//Prepare returns s prepared so that the values which match have the same result.
+//The result is case-folded if caseFold is true. It returns an error if s cannot be prepared, e.g. for prohibited
+//characters. Comparer calls Prepare of a StringPreparer given by WithStringPreparer for every comparison of the
+//values, and reuses only the results of DefaultStringPreparer for the same strings during a comparison.
+type StringPreparer interface {
+	Prepare(s string, caseFold bool) (string, error)
+}
+
+//DefaultStringPreparer is the StringPreparer which Compare uses. It performs the string preparation algorithm
+//described in [RFC4518], mapping the insignificant spaces so that "  a  b " is prepared to " a  b ".
+//Custom StringPreparers may apply their own mappings before or after calling it.
+var DefaultStringPreparer StringPreparer = ldapStringPreparer{}
+
+//PrepareExactString prepares s for caseExactMatch by the string preparation algorithm described in [RFC4518]:
+//transcode, map without case folding, normalize, prohibit and insignificant space handling, so that "  Ａb  c " is
+//prepared to " Ab  c ". The values which match by caseExactMatch have the same result.
+//It returns an error if s contains prohibited characters.
+func PrepareExactString(s string) (string, error) {
+	return DefaultStringPreparer.Prepare(s, false)
+}
+
+//NewStringPreparer returns the StringPreparer which performs the string preparation algorithm described in
+//[RFC4518] in the same way as DefaultStringPreparer, except that the strings are normalized to form.
+//The StringPreparer returns an error if form is unknown.
+func NewStringPreparer(form NormalizationForm) StringPreparer {
+	return ldapStringPreparer{form: form}
+}
+
+//WithNormalizationForm makes the Comparer normalize the string values to form in the string preparation, e.g.
+//NormalizationNFC to reproduce the comparison of the systems which do not fold the compatibility characters.
+//Both of the distinguished names are prepared by the same form. By default, they are normalized to NormalizationNFKC.
+//It is the same as WithStringPreparer(NewStringPreparer(form)), so that it replaces the StringPreparer set before.
+func WithNormalizationForm(form NormalizationForm) Option {
+	return WithStringPreparer(NewStringPreparer(form))
+}
+
+//ldapStringPreparer is the StringPreparer by ldapstrprep.
+type ldapStringPreparer struct {
+	form NormalizationForm
+}
+
+//Prepare prepares s by prepareStringForm.
+func (p ldapStringPreparer) Prepare(s string, caseFold bool) (string, error) {
+	u, err := prepareStringForm(s, caseFold, p.form)
+	if err != nil {
+		return "", err
+	}
+	return string(u), nil
+}
+
+//WithStringPreparer makes the Comparer prepare the string values by p instead of DefaultStringPreparer, e.g. for the
+//extra character mappings of a national profile. p is used by caseIgnoreMatch, caseExactMatch and numericStringMatch,
+//the values of emailAddress without '@', and Canonicalize. The values of domainComponent are compared by
+//case-insensitive exact match without p.
+func WithStringPreparer(p StringPreparer) Option {
+	return func(c *Comparer) {
+		c.preparer = p
+	}
+}
+
+//WithSkipStringPrep makes the Comparer skip the string preparation(RFC4518) and compare the values by simple Unicode
+//case folding, e.g. "ABC" matches "abc", but "  ABC" does not match "ABC", full-width "ＡＢＣ" does not match "ABC",
+//and decomposed "e\u0301" does not match composed "\u00e9". Prohibited characters are not rejected.
+//
+//It trades the correctness of the comparison for speed, and is only for the hot paths where both distinguished
+//names are known to be prepared, e.g. encoded by the same CA software which normalizes the values. It must not be
+//used for distinguished names from untrusted sources. None of the presets include it.
+//It is the same as WithStringPreparer by a StringPreparer which only folds the case.
+func WithSkipStringPrep() Option {
+	return WithStringPreparer(simpleFoldPreparer{})
+}
+
+//simpleFoldPreparer is the StringPreparer which only folds the case by simple Unicode case folding.
+type simpleFoldPreparer struct{}
+
+//Prepare maps each character of s to the smallest character which is equivalent under simple case folding,
+//so that the results of s and t are the same if and only if strings.EqualFold(s, t) is true.
+//s is returned as it is if caseFold is false.
+func (simpleFoldPreparer) Prepare(s string, caseFold bool) (string, error) {
+	if !caseFold {
+		return s, nil
+	}
+	return strings.Map(func(r rune) rune {
+		min := r
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			if f < min {
+				min = f
+			}
+		}
+		return min
+	}, s), nil
+}
+
+//stringPreparer returns the StringPreparer of c.
+func (c *Comparer) stringPreparer() StringPreparer {
+	if c.preparer == nil {
+		return DefaultStringPreparer
+	}
+	return c.preparer
+}
+
+//prepareNumericString prepares s by p for numericStringMatch. All the spaces are removed from the result of p,
+//which is the same as the insignificant character handling of numericString(RFC4518 section-2.6.2) for
+//DefaultStringPreparer.
+func prepareNumericString(p StringPreparer, s string) (string, error) {
+	u, err := p.Prepare(s, true)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(u, " ", ""), nil
+}
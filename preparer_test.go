@@ -0,0 +1,369 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"errors"
+	"strings"
+	"testing"
+)
+
+//umlautPreparer maps the umlauts to their two-letter forms before DefaultStringPreparer,
+//like the extra mappings of a national profile.
+type umlautPreparer struct {
+	calls int
+}
+
+func (p *umlautPreparer) Prepare(s string, caseFold bool) (string, error) {
+	p.calls++
+	return DefaultStringPreparer.Prepare(strings.NewReplacer("Ä", "Ae", "Ö", "Oe", "Ü", "Ue", "ä", "ae", "ö", "oe", "ü", "ue").Replace(s), caseFold)
+}
+
+//countingPreparer counts the calls of p.
+type countingPreparer struct {
+	p     StringPreparer
+	calls int
+}
+
+func (p *countingPreparer) Prepare(s string, caseFold bool) (string, error) {
+	p.calls++
+	return p.p.Prepare(s, caseFold)
+}
+
+//countDefaultStringPreparer replaces DefaultStringPreparer with countingPreparer until the end of t.
+func countDefaultStringPreparer(t *testing.T) *countingPreparer {
+	p := &countingPreparer{p: DefaultStringPreparer}
+	DefaultStringPreparer = p
+	t.Cleanup(func() {
+		DefaultStringPreparer = p.p
+	})
+	return p
+}
+
+//errorPreparer fails to prepare any string.
+type errorPreparer struct{}
+
+func (errorPreparer) Prepare(s string, caseFold bool) (string, error) {
+	return "", errors.New("prepare failed")
+}
+
+func TestDefaultStringPreparer(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		caseFold bool
+	}{
+		{"abc123-", "abc123-", true},
+		{"Abc123-", "Abc123-", true},
+		{"Abc123-, Case exact", "Abc123-", false},
+		{"    foo bar   ", "    foo bar   ", true},
+		{" foo            bar ", " foo            bar ", true},
+		{"パパ", "パパ", true},
+		{"Full-width", "ＡＢＣ", true},
+		{"Only spaces", "   ", true},
+		{"Empty", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, wantErr := prepareString(tt.s, tt.caseFold)
+			got, err := DefaultStringPreparer.Prepare(tt.s, tt.caseFold)
+			if (err != nil) != (wantErr != nil) {
+				t.Fatalf("Prepare() error = %v, want %v", err, wantErr)
+			}
+			if got != string(want) {
+				t.Errorf("Prepare() got = %q, want %q", got, string(want))
+			}
+		})
+	}
+}
+
+func TestDefaultStringPreparer_Prohibited(t *testing.T) {
+	if _, err := DefaultStringPreparer.Prepare("a\uFFFDb", true); err == nil {
+		t.Errorf("Prepare() error = nil, want error")
+	}
+}
+
+func TestWithStringPreparer(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+	}{
+		{"Default, Umlaut", nil, args{issuer: dn83b, subject: dn84b}, false},
+		{"Custom, Umlaut", nil, args{issuer: dn83b, subject: dn84b}, true},
+		{"Custom, Upper/Lower case characters", nil, args{issuer: dn2b, subject: dn4b}, true},
+		{"Custom, Case exact CN, Upper/Lower case characters", []Option{WithMatchingRule(asn1.ObjectIdentifier{2, 5, 4, 3}, MatchingRuleCaseExact)}, args{issuer: dn2b, subject: dn4b}, false},
+		{"Custom, numericStringMatch O, Spaces in NumericString", []Option{WithMatchingRule(oidOrganization, MatchingRuleNumericString)}, args{issuer: dn31b, subject: dn80b}, true},
+		{"Custom, Multi-valued RDN", nil, args{issuer: dn60b, subject: dn62b}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			p := &umlautPreparer{}
+			if strings.HasPrefix(tt.name, "Custom") {
+				opts = append(opts, WithStringPreparer(p))
+			}
+			c := NewComparer(opts...)
+			gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+
+			ci, err := c.Canonicalize(tt.args.issuer)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cs, err := c.Canonicalize(tt.args.subject)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(ci) == string(cs); got != tt.wantResult {
+				t.Errorf("Canonicalize() equality = %v, want %v", got, tt.wantResult)
+			}
+
+			gotResult, err = NewComparer(append(opts, WithConstantTime())...).Compare(tt.args.issuer, tt.args.subject)
+			if err != nil {
+				t.Fatalf("Compare() with WithConstantTime error = %v", err)
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() with WithConstantTime gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+
+			if strings.HasPrefix(tt.name, "Custom") && p.calls == 0 {
+				t.Errorf("Prepare() is not called")
+			}
+		})
+	}
+}
+
+func TestWithStringPreparer_Error(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithStringPreparer(errorPreparer{})},
+		{WithStringPreparer(errorPreparer{}), WithConstantTime()},
+	} {
+		if _, err := NewComparer(opts...).Compare(dn2b, dn4b); err == nil {
+			t.Errorf("Compare() error = nil, want error")
+		}
+		if _, err := NewComparer(opts...).Canonicalize(dn2b); err == nil {
+			t.Errorf("Canonicalize() error = nil, want error")
+		}
+	}
+}
+
+func TestWithStringPreparer_DomainComponent(t *testing.T) {
+	//domainComponent values are compared by case-insensitive exact match without the StringPreparer.
+	result, err := NewComparer(WithStringPreparer(errorPreparer{})).Compare(dn65b, dn65b)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if !result {
+		t.Errorf("Compare() result = false, want true")
+	}
+}
+
+func TestWithSkipStringPrep(t *testing.T) {
+	//The pairs whose verdicts change by WithSkipStringPrep, and the pairs which keep their verdicts.
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantDefault bool
+		wantResult  bool
+	}{
+		{"Insignificant spaces", args{issuer: dn20b, subject: dn2b}, true, false},
+		{"Leading space", args{issuer: dn18b, subject: dn2b}, true, false},
+		{"Half-width katakana and full-width Latin", args{issuer: dn70b, subject: dn71b}, true, false},
+		{"Half-width katakana with voiced sound mark", args{issuer: dn72b, subject: dn73b}, true, false},
+		{"Composed and decomposed", args{issuer: dn75b, subject: dn76b}, true, false},
+		{"Hangul syllable and Hangul jamo", args{issuer: dn78b, subject: dn79b}, true, false},
+		{"Upper/Lower case characters", args{issuer: dn2b, subject: dn4b}, true, true},
+		{"Different Encoding(PrintableString,UTF8String)", args{issuer: dn2b, subject: dn3b}, true, true},
+		{"Different characters", args{issuer: dn2b, subject: dn6b}, false, false},
+		{"Accented and unaccented", args{issuer: dn75b, subject: dn77b}, false, false},
+		{"Domain components", args{issuer: dn65b, subject: dn65b}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDefault, err := Compare(tt.args.issuer, tt.args.subject)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if gotDefault != tt.wantDefault {
+				t.Errorf("Compare() gotResult = %v, want %v", gotDefault, tt.wantDefault)
+			}
+			for _, opts := range [][]Option{{WithSkipStringPrep()}, {WithSkipStringPrep(), WithConstantTime()}} {
+				c := NewComparer(opts...)
+				gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+				if err != nil {
+					t.Fatalf("Comparer.Compare() error = %v", err)
+				}
+				if gotResult != tt.wantResult {
+					t.Errorf("Comparer.Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+				}
+			}
+		})
+	}
+}
+
+func TestSimpleFoldPreparer(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		t        string
+		caseFold bool
+		want     bool
+	}{
+		{"ASCII", "ABC", "abc", true, true},
+		{"ASCII, Case exact", "ABC", "abc", false, false},
+		{"Kelvin sign", "\u212a", "k", true, true},
+		{"Greek sigma", "\u03a3", "\u03c2", true, true},
+		{"Spaces", " abc", "abc", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps, _ := simpleFoldPreparer{}.Prepare(tt.s, tt.caseFold)
+			pt, _ := simpleFoldPreparer{}.Prepare(tt.t, tt.caseFold)
+			if got := ps == pt; got != tt.want {
+				t.Errorf("Prepare() equality = %v, want %v", got, tt.want)
+			}
+			if tt.caseFold && strings.EqualFold(tt.s, tt.t) != tt.want {
+				t.Errorf("strings.EqualFold() = %v, want %v", !tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSkipStringPrep_Presets(t *testing.T) {
+	for _, opts := range [][]Option{PresetStrictRFC5280(), PresetWebPKI(), PresetLegacyLDAP()} {
+		if c := NewComparer(opts...); c.preparer != nil {
+			t.Errorf("preset sets the StringPreparer %T", c.preparer)
+		}
+	}
+}
+
+func TestWithNormalizationForm(t *testing.T) {
+	caseExactCN := WithMatchingRule(asn1.ObjectIdentifier{2, 5, 4, 3}, MatchingRuleCaseExact)
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name     string
+		opts     []Option
+		args     args
+		wantNFKC bool
+		wantNFC  bool
+	}{
+		{"Ligature, Case exact CN", []Option{caseExactCN}, args{issuer: dn88b, subject: dn89b}, true, false},
+		{"Ligature, Case ignore CN", nil, args{issuer: dn88b, subject: dn89b}, true, true},
+		{"Half-width katakana and full-width Latin", nil, args{issuer: dn70b, subject: dn71b}, true, false},
+		{"Composed and decomposed", nil, args{issuer: dn75b, subject: dn76b}, true, true},
+		{"Hangul syllable and Hangul jamo", nil, args{issuer: dn78b, subject: dn79b}, true, true},
+		{"Upper/Lower case characters", nil, args{issuer: dn2b, subject: dn4b}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, f := range []struct {
+				form NormalizationForm
+				want bool
+			}{{NormalizationNFKC, tt.wantNFKC}, {NormalizationNFC, tt.wantNFC}} {
+				opts := append(tt.opts[:len(tt.opts):len(tt.opts)], WithNormalizationForm(f.form))
+				for _, c := range []*Comparer{NewComparer(opts...), NewComparer(append(opts, WithConstantTime())...)} {
+					gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+					if err != nil {
+						t.Fatalf("Compare() form %d error = %v", f.form, err)
+					}
+					if gotResult != f.want {
+						t.Errorf("Compare() form %d gotResult = %v, want %v", f.form, gotResult, f.want)
+					}
+				}
+
+				c := NewComparer(opts...)
+				ci, err := c.Canonicalize(tt.args.issuer)
+				if err != nil {
+					t.Fatalf("Canonicalize() error = %v", err)
+				}
+				cs, err := c.Canonicalize(tt.args.subject)
+				if err != nil {
+					t.Fatalf("Canonicalize() error = %v", err)
+				}
+				if got := string(ci) == string(cs); got != f.want {
+					t.Errorf("Canonicalize() form %d equality = %v, want %v", f.form, got, f.want)
+				}
+			}
+			if len(tt.opts) == 0 {
+				gotDefault, err := Compare(tt.args.issuer, tt.args.subject)
+				if err != nil {
+					t.Fatalf("Compare() error = %v", err)
+				}
+				if gotDefault != tt.wantNFKC {
+					t.Errorf("Compare() gotResult = %v, want %v", gotDefault, tt.wantNFKC)
+				}
+			}
+		})
+	}
+}
+
+func TestNewStringPreparer(t *testing.T) {
+	for _, s := range []string{"abc", "  ABC  def ", "\ufb01", "\uff21", "e\u0301"} {
+		for _, caseFold := range []bool{true, false} {
+			want, wantErr := DefaultStringPreparer.Prepare(s, caseFold)
+			got, err := NewStringPreparer(NormalizationNFKC).Prepare(s, caseFold)
+			if got != want || (err != nil) != (wantErr != nil) {
+				t.Errorf("Prepare(%q, %v) = %q, %v, want %q, %v", s, caseFold, got, err, want, wantErr)
+			}
+		}
+	}
+	if got, _ := NewStringPreparer(NormalizationNFC).Prepare("\ufb01", false); got != " \ufb01 " {
+		t.Errorf("Prepare() with NormalizationNFC got = %q, want %q", got, " \ufb01 ")
+	}
+	if _, err := NewStringPreparer(NormalizationForm(2)).Prepare("abc", true); err == nil {
+		t.Errorf("Prepare() with unknown form error = nil, want error")
+	}
+}
+
+func TestPrepareExactString(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{"Case preserved", "Abc", " Abc ", false},
+		{"Insignificant spaces", "  Ab  c ", " Ab  c ", false},
+		{"Only spaces", "   ", "  ", false},
+		{"Empty", "", "  ", false},
+		{"Full-width", "ＡＢＣ", " ABC ", false},
+		{"Decomposed characters", "Jose\u0301", " Jos\u00e9 ", false},
+		{"Ligature", "\ufb01le", " file ", false},
+		{"Control character", "AB\x00C", " ABC ", false},
+		{"Prohibited character", "a\uFFFDb", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PrepareExactString(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PrepareExactString() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PrepareExactString() got = %q, want %q", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			if u, _ := prepareString(tt.s, false); string(u) != got {
+				t.Errorf("PrepareExactString() got = %q, but prepareString() = %q", got, string(u))
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package dn
+
+import (
+	"fmt"
+	"strings"
+)
+
+//PrepStep is a step of the string preparation algorithm(RFC4518 section-2).
+type PrepStep int
+
+//Steps of the string preparation. The step of Check Bidi is omitted because it does nothing for LDAP.
+const (
+	PrepStepTranscode                      PrepStep = 0 //1. Transcode, which converts the value to Unicode
+	PrepStepMap                            PrepStep = 1 //2. Map, which includes case folding
+	PrepStepNormalize                      PrepStep = 2 //3. Normalize
+	PrepStepProhibit                       PrepStep = 3 //4. Prohibit, which rejects the value without changing it
+	PrepStepInsignificantCharacterHandling PrepStep = 4 //6. Insignificant Character Handling of spaces
+)
+
+var prepStepNames = []string{"transcode", "map", "normalize", "prohibit", "insignificantCharacterHandling"}
+
+//String returns the name of s.
+func (s PrepStep) String() string {
+	if s < 0 || int(s) >= len(prepStepNames) {
+		return fmt.Sprintf("PrepStep(%d)", int(s))
+	}
+	return prepStepNames[s]
+}
+
+//PrepStage is the result of a step of the string preparation.
+type PrepStage struct {
+	Step   PrepStep
+	Runes  []rune
+	String string //Runes as a string
+}
+
+//PrepTrace is the intermediate results of the string preparation of Input.
+type PrepTrace struct {
+	Input  string
+	Stages []PrepStage //results of the steps performed, in order
+	//Prohibited reports whether Input is rejected in PrepStepProhibit. The last of Stages is PrepStepNormalize then.
+	Prohibited bool
+}
+
+//Result returns the result of the last step performed.
+func (t *PrepTrace) Result() string {
+	if len(t.Stages) == 0 {
+		return ""
+	}
+	return t.Stages[len(t.Stages)-1].String
+}
+
+//Stage returns the result of step. ok is false if step is not performed.
+func (t *PrepTrace) Stage(step PrepStep) (stage PrepStage, ok bool) {
+	for _, stage = range t.Stages {
+		if stage.Step == step {
+			return stage, true
+		}
+	}
+	return PrepStage{}, false
+}
+
+//PrepareDebug prepares s for caseIgnoreMatch in the same way as the comparison and returns the intermediate results of
+//the steps. If s contains prohibited characters, it returns the trace of the steps until PrepStepNormalize with
+//the error of the preparation.
+func PrepareDebug(s string) (*PrepTrace, error) {
+	return prepareDebug(s, true, NormalizationNFKC)
+}
+
+//prepareDebug prepares s in the same way as prepareStringForm and returns the intermediate results of the steps.
+func prepareDebug(s string, caseFold bool, form NormalizationForm) (*PrepTrace, error) {
+	t := &PrepTrace{Input: s}
+	_, err := prepareStringSteps(s, caseFold, form, func(step PrepStep, u []rune) {
+		t.Stages = append(t.Stages, PrepStage{Step: step, Runes: append([]rune(nil), u...), String: string(u)})
+	})
+	if err != nil {
+		_, normalized := t.Stage(PrepStepNormalize)
+		t.Prohibited = normalized
+		return t, err
+	}
+	return t, nil
+}
+
+//PrepDiff is the comparison of the intermediate results of the string preparation of two values.
+type PrepDiff struct {
+	X *PrepTrace
+	Y *PrepTrace
+	//Step is the first step whose results are the same. The values are different until Step, and the same after it.
+	//It is meaningful only if Converged.
+	Step PrepStep
+	//Converged reports whether the results of the last step performed for both of the values are the same.
+	Converged bool
+}
+
+//DiffPrepare prepares x and y for caseIgnoreMatch by PrepareDebug and compares the intermediate results of the steps.
+//Once the results of a step are the same, they are the same in the following steps, so the values converge at most
+//once. If x or y contains prohibited characters, it returns the comparison of the steps performed with the error.
+func DiffPrepare(x string, y string) (*PrepDiff, error) {
+	return diffPrepare(x, y, true, NormalizationNFKC)
+}
+
+//diffPrepare compares the intermediate results of prepareDebug of x and y.
+func diffPrepare(x string, y string, caseFold bool, form NormalizationForm) (*PrepDiff, error) {
+	d := &PrepDiff{}
+	var errX, errY error
+	d.X, errX = prepareDebug(x, caseFold, form)
+	d.Y, errY = prepareDebug(y, caseFold, form)
+	for i := 0; i < len(d.X.Stages) && i < len(d.Y.Stages); i++ {
+		if d.X.Stages[i].String == d.Y.Stages[i].String {
+			d.Step, d.Converged = d.X.Stages[i].Step, true
+			break
+		}
+	}
+	if len(d.X.Stages) != len(d.Y.Stages) {
+		d.Converged = false
+	}
+	if errX != nil {
+		return d, errX
+	}
+	return d, errY
+}
+
+//String returns the intermediate results of d side by side, one step per line, marking the step where the values
+//converge, e.g.
+//  transcode: "ＡＢＣ" vs "abc"
+//  map: "ａｂｃ" vs "abc"
+//  normalize: "abc" vs "abc" (converged)
+func (d *PrepDiff) String() string {
+	var sb strings.Builder
+	for i := 0; i < len(d.X.Stages) || i < len(d.Y.Stages); i++ {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		x, y := "(prohibited)", "(prohibited)"
+		var step PrepStep
+		if i < len(d.X.Stages) {
+			step, x = d.X.Stages[i].Step, fmt.Sprintf("%q", d.X.Stages[i].String)
+		}
+		if i < len(d.Y.Stages) {
+			step, y = d.Y.Stages[i].Step, fmt.Sprintf("%q", d.Y.Stages[i].String)
+		}
+		fmt.Fprintf(&sb, "%s: %s vs %s", step, x, y)
+		if d.Converged && step == d.Step {
+			sb.WriteString(" (converged)")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+package dn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrepareDebug(t *testing.T) {
+	tests := []struct {
+		name           string
+		s              string
+		want           []string
+		wantProhibited bool
+		wantErr        bool
+	}{
+		{"ASCII", "Abc", []string{"Abc", "abc", "abc", "abc", " abc "}, false, false},
+		{"Full-width Latin and ideographic space", "ＡＢ　Ｃ", []string{"ＡＢ　Ｃ", "ａｂ ｃ", "ab c", "ab c", " ab  c "}, false, false},
+		{"Embedded NUL", "A\x00B", []string{"A\x00B", "ab", "ab", "ab", " ab "}, false, false},
+		{"Prohibited", "A\uFFFDB", []string{"A\uFFFDB", "a\uFFFDb", "a\uFFFDb"}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PrepareDebug(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PrepareDebug() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var stages []string
+			for i, stage := range got.Stages {
+				if stage.Step != PrepStep(i) {
+					t.Errorf("Stages[%d].Step = %v, want %v", i, stage.Step, PrepStep(i))
+				}
+				if string(stage.Runes) != stage.String {
+					t.Errorf("Stages[%d].Runes = %q, String = %q", i, string(stage.Runes), stage.String)
+				}
+				stages = append(stages, stage.String)
+			}
+			if !reflect.DeepEqual(stages, tt.want) {
+				t.Errorf("PrepareDebug() stages = %q, want %q", stages, tt.want)
+			}
+			if got.Prohibited != tt.wantProhibited {
+				t.Errorf("PrepareDebug() Prohibited = %v, want %v", got.Prohibited, tt.wantProhibited)
+			}
+			if err == nil {
+				want, _ := stringPrepare(tt.s)
+				if got.Result() != string(want) {
+					t.Errorf("PrepareDebug() Result() = %q, want %q", got.Result(), string(want))
+				}
+			}
+		})
+	}
+}
+
+func TestDiffPrepare(t *testing.T) {
+	tests := []struct {
+		name          string
+		x             string
+		y             string
+		wantConverged bool
+		wantStep      PrepStep
+		wantString    string
+		wantErr       bool
+	}{
+		{"Same values", "abc", "abc", true, PrepStepTranscode,
+			"transcode: \"abc\" vs \"abc\" (converged)\nmap: \"abc\" vs \"abc\"\nnormalize: \"abc\" vs \"abc\"\nprohibit: \"abc\" vs \"abc\"\ninsignificantCharacterHandling: \" abc \" vs \" abc \"", false},
+		{"Upper/Lower case characters", "ABC", "abc", true, PrepStepMap,
+			"transcode: \"ABC\" vs \"abc\"\nmap: \"abc\" vs \"abc\" (converged)\nnormalize: \"abc\" vs \"abc\"\nprohibit: \"abc\" vs \"abc\"\ninsignificantCharacterHandling: \" abc \" vs \" abc \"", false},
+		{"Full-width Latin", "ＡＢＣ", "abc", true, PrepStepNormalize,
+			"transcode: \"ＡＢＣ\" vs \"abc\"\nmap: \"ａｂｃ\" vs \"abc\"\nnormalize: \"abc\" vs \"abc\" (converged)\nprohibit: \"abc\" vs \"abc\"\ninsignificantCharacterHandling: \" abc \" vs \" abc \"", false},
+		{"Insignificant spaces", "  abc ", "abc", true, PrepStepInsignificantCharacterHandling,
+			"transcode: \"  abc \" vs \"abc\"\nmap: \"  abc \" vs \"abc\"\nnormalize: \"  abc \" vs \"abc\"\nprohibit: \"  abc \" vs \"abc\"\ninsignificantCharacterHandling: \" abc \" vs \" abc \" (converged)", false},
+		{"Different characters", "abc", "abd", false, PrepStepTranscode,
+			"transcode: \"abc\" vs \"abd\"\nmap: \"abc\" vs \"abd\"\nnormalize: \"abc\" vs \"abd\"\nprohibit: \"abc\" vs \"abd\"\ninsignificantCharacterHandling: \" abc \" vs \" abd \"", false},
+		{"Prohibited", "a\uFFFD", "a", false, PrepStepTranscode,
+			"transcode: \"a\uFFFD\" vs \"a\"\nmap: \"a\uFFFD\" vs \"a\"\nnormalize: \"a\uFFFD\" vs \"a\"\nprohibit: (prohibited) vs \"a\"\ninsignificantCharacterHandling: (prohibited) vs \" a \"", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffPrepare(tt.x, tt.y)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DiffPrepare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Converged != tt.wantConverged || got.Step != tt.wantStep {
+				t.Errorf("DiffPrepare() Converged = %v, Step = %v, want %v, %v", got.Converged, got.Step, tt.wantConverged, tt.wantStep)
+			}
+			if s := got.String(); s != tt.wantString {
+				t.Errorf("DiffPrepare() String() = %q, want %q", s, tt.wantString)
+			}
+		})
+	}
+}
+
+func TestPrepStep_String(t *testing.T) {
+	if got := PrepStepNormalize.String(); got != "normalize" {
+		t.Errorf("String() = %q, want %q", got, "normalize")
+	}
+	if got := PrepStep(5).String(); got != "PrepStep(5)" {
+		t.Errorf("String() = %q, want %q", got, "PrepStep(5)")
+	}
+}
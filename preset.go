@@ -0,0 +1,54 @@
+package dn
+
+//Limits of PresetWebPKI.
+const (
+	webPKIMaxInputBytes        = 64 * 1024
+	webPKIMaxRawAttributeBytes = 16 * 1024
+)
+
+//PresetStrictRFC5280 returns the options which compare distinguished names by the rules of RFC 5280 and reject
+//encodings which violate DER, e.g.
+//  NewComparer(PresetStrictRFC5280()...)
+//It consists of WithStrictDER.
+func PresetStrictRFC5280() []Option {
+	return []Option{WithStrictDER()}
+}
+
+//PresetWebPKI returns the options which reproduce the comparison of the certificates issued on the Web PKI,
+//which tolerates the encodings of issuers that are common in practice but rejects the string types
+//which RFC 5280 does not allow in DirectoryString, e.g.
+//  NewComparer(PresetWebPKI()...)
+//It consists of WithLenientDomainComponent, WithTeletexString, WithBMPString, WithLenientPrintableString and
+//WithRejectUnknownStringTags, and limits the sizes of the inputs by WithMaxInputBytes(64 KiB) and
+//WithMaxRawAttributeBytes(16 KiB), which are far larger than the names of the certificates in practice.
+//The values of the attribute types which are not strings, e.g. x500UniqueIdentifier in BIT STRING, are not rejected.
+func PresetWebPKI() []Option {
+	return []Option{
+		WithLenientDomainComponent(),
+		WithTeletexString(),
+		WithBMPString(),
+		WithLenientPrintableString(),
+		WithRejectUnknownStringTags(),
+		WithMaxInputBytes(webPKIMaxInputBytes),
+		WithMaxRawAttributeBytes(webPKIMaxRawAttributeBytes),
+	}
+}
+
+//PresetLegacyLDAP returns the options which reproduce the comparison of legacy LDAP directories, whose entries
+//were often migrated from X.500 directories with TeletexString values and domainComponent values in
+//DirectoryString, e.g.
+//  NewComparer(PresetLegacyLDAP()...)
+//It consists of WithLenientDomainComponent and the matching of LDAP, which compares the values of DirectoryString by
+//caseIgnoreMatch whatever string types encode them, as LDAP transfers them in UTF-8(RFC4517 section-3.3.6):
+//WithTeletexString, WithVisibleString and WithBMPString.
+//The encodings of BER are tolerated as the default of Compare does, e.g. the constructed form of strings and the
+//attributes of multi-valued RDNs not in DER order, because the preset does not contain WithStrictDER.
+//Values of other string types are not rejected.
+func PresetLegacyLDAP() []Option {
+	return []Option{
+		WithLenientDomainComponent(),
+		WithTeletexString(),
+		WithVisibleString(),
+		WithBMPString(),
+	}
+}
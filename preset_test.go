@@ -0,0 +1,81 @@
+package dn
+
+import (
+	"strings"
+	"testing"
+)
+
+type presetTestCase struct {
+	name           string
+	issuer         []byte
+	subject        []byte
+	wantDefault    bool
+	wantDefaultErr bool
+	wantResult     bool
+	wantErr        bool
+}
+
+//testPreset checks that the Comparer configured by opts returns the results of tests, and that Compare returns
+//the default results of them.
+func testPreset(t *testing.T, opts []Option, tests []presetTestCase) {
+	t.Helper()
+	c := NewComparer(opts...)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDefault, err := Compare(tt.issuer, tt.subject)
+			if (err != nil) != tt.wantDefaultErr || gotDefault != tt.wantDefault {
+				t.Errorf("Compare() = %v, error = %v, want %v, wantErr %v", gotDefault, err, tt.wantDefault, tt.wantDefaultErr)
+			}
+			gotResult, err := c.Compare(tt.issuer, tt.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Comparer.Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Comparer.Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestPresetStrictRFC5280(t *testing.T) {
+	testPreset(t, PresetStrictRFC5280(), []presetTestCase{
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", dn2b, dn3b, true, false, true, false},
+		{"Multi RDN not in DER order", dn1b, dn16b, true, false, false, true},
+		{"TeletexString UTF8String", dnTeletexb, dnTeletexUTF8b, false, false, false, false},
+		{"Wrong Encoding domain component", dn7b, dn7b, false, true, false, true},
+		{"GraphicString in subject", dn2b, dn29b, false, false, false, false},
+	})
+}
+
+func TestPresetWebPKI(t *testing.T) {
+	longCN, _ := BuildFromMap(map[string]string{"CN": strings.Repeat("a", webPKIMaxRawAttributeBytes)})
+	testPreset(t, PresetWebPKI(), []presetTestCase{
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", dn2b, dn3b, true, false, true, false},
+		{"Multi RDN not in DER order", dn1b, dn16b, true, false, true, false},
+		{"TeletexString UTF8String", dnTeletexb, dnTeletexUTF8b, false, false, true, false},
+		{"TeletexString with C1 control characters", dnTeletexC1b, dnTeletexC1b, true, false, false, true},
+		{"Wrong Encoding domain component", dn7b, dn7b, false, true, true, false},
+		{"GraphicString in subject", dn2b, dn29b, false, false, false, true},
+		{"BMPString UTF8String", dn5b, dn2b, false, false, true, false},
+		{"'@' in PrintableString, UTF8String", dnPrintableAtb, dnUTF8Atb, false, true, true, false},
+		{"x500UniqueIdentifier in BIT STRING", dn54b, dn54b, true, false, true, false},
+		{"roleOccupant in Name", dn45b, dn46b, true, false, true, false},
+		{"Too long attribute", longCN, longCN, true, false, false, true},
+	})
+}
+
+func TestPresetLegacyLDAP(t *testing.T) {
+	testPreset(t, PresetLegacyLDAP(), []presetTestCase{
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", dn2b, dn3b, true, false, true, false},
+		{"Multi RDN not in DER order", dn1b, dn16b, true, false, true, false},
+		{"TeletexString UTF8String", dnTeletexb, dnTeletexUTF8b, false, false, true, false},
+		{"TeletexString with C1 control characters", dnTeletexC1b, dnTeletexC1b, true, false, false, true},
+		{"Wrong Encoding domain component", dn7b, dn7b, false, true, true, false},
+		{"GraphicString in subject", dn2b, dn29b, false, false, false, false},
+		{"BMPString UTF8String", dn5b, dn2b, false, false, true, false},
+		{"VisibleString PrintableString", dn90b, dn3b, false, false, true, false},
+		{"'@' in PrintableString, UTF8String", dnPrintableAtb, dnUTF8Atb, false, true, false, true},
+		{"PrintableString in the constructed form of BER", dn36b, dnExamplePrintableb, true, false, true, false},
+	})
+}
@@ -0,0 +1,65 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+//WithLenientPrintableString makes the Comparer accept the values encoded in PrintableString which contain the
+//printable characters of ASCII that PrintableString does not allow, such as '@' or '_'. Some issuers put them in
+//PrintableString, and the values are found in the certificates on the Web PKI.
+//The values are converted to UTF8String before the comparison, so that they are compared by caseIgnoreMatch.
+//By default, the comparison returns an error for them. '*' and '&' are accepted without the option, as encoding/asn1
+//does, and the values which contain control characters or bytes above 0x7E are rejected even with the option.
+func WithLenientPrintableString() Option {
+	return func(c *Comparer) {
+		c.lenientPrintable = true
+	}
+}
+
+//transcodePrintableStrings returns d whose values encoded in PrintableString with the characters which
+//PrintableString does not allow are converted to UTF8String.
+func transcodePrintableStrings(d dn) (result dn, err error) {
+	result = make(dn, len(d))
+	for i, r := range d {
+		result[i] = make(rdnSET, len(r))
+		for j, atv := range r {
+			if result[i][j], err = transcodePrintableString(atv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+//transcodePrintableString returns atv whose value is converted to UTF8String if it is encoded in PrintableString and
+//contains the characters which PrintableString does not allow. The valid values of PrintableString are not changed.
+func transcodePrintableString(atv Attribute) (result Attribute, err error) {
+	if !isLenientPrintableString(atv) {
+		return atv, nil
+	}
+	var content []byte
+	if content, err = stringContent(atv.RawValue); err != nil {
+		return Attribute{}, err
+	}
+	var s string
+	if s, err = decodeASCIIString(content, "PrintableString", func(c byte) bool { return c >= 0x20 && c <= 0x7e }); err != nil {
+		return Attribute{}, fmt.Errorf("dn: attribute %s: %w", atv.Oid, err)
+	}
+	return newStringAttribute(atv.Oid, s, EncodingUTF8String)
+}
+
+//isLenientPrintableString reports whether atv is encoded in PrintableString which cannot be decoded as
+//PrintableString, so that WithLenientPrintableString converts it.
+func isLenientPrintableString(atv Attribute) bool {
+	if atv.RawValue.Class != asn1.ClassUniversal || atv.RawValue.Tag != asn1.TagPrintableString {
+		return false
+	}
+	content, err := stringContent(atv.RawValue)
+	if err != nil {
+		//the comparison returns the error
+		return true
+	}
+	_, err = decodeStringContent(asn1.TagPrintableString, content)
+	return err != nil
+}
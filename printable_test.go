@@ -0,0 +1,58 @@
+package dn
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	//C=JP(PrintableString),O=Ex@mple(PrintableString)
+	dnPrintableAtb, _ = hex.DecodeString("301f310b3009060355040613024a503110300e060355040a13074578406d706c65")
+	//C=JP(PrintableString),O=EX@MPLE(UTF8String)
+	dnUTF8Atb, _ = hex.DecodeString("301f310b3009060355040613024a503110300e060355040a0c074558404d504c45")
+	//C=JP(PrintableString),O=Ex DEL mple(PrintableString)
+	dnPrintableDELb, _ = hex.DecodeString("301f310b3009060355040613024a503110300e060355040a130745787f6d706c65")
+)
+
+func TestWithLenientPrintableString(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantResult     bool
+		wantDefault    bool
+		wantErr        bool
+		wantDefaultErr bool
+	}{
+		{"'@' in PrintableString, UTF8String", args{dnPrintableAtb, dnUTF8Atb}, true, false, false, true},
+		{"UTF8String, '@' in PrintableString", args{dnUTF8Atb, dnPrintableAtb}, true, false, false, true},
+		{"'@' in PrintableString, Same", args{dnPrintableAtb, dnPrintableAtb}, true, false, false, true},
+		{"'@' in PrintableString, Different characters", args{dnPrintableAtb, dnExamplePrintableb}, false, false, false, true},
+		{"Valid PrintableString", args{dnExamplePrintableb, dnExamplePrintableb}, true, true, false, false},
+		{"Control character in PrintableString", args{dnPrintableDELb, dnPrintableDELb}, false, false, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(WithLenientPrintableString()).Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+
+			gotResult, err = Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantDefaultErr {
+				t.Errorf("Compare() without WithLenientPrintableString error = %v, wantErr %v", err, tt.wantDefaultErr)
+				return
+			}
+			if gotResult != tt.wantDefault {
+				t.Errorf("Compare() without WithLenientPrintableString gotResult = %v, want %v", gotResult, tt.wantDefault)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package dn
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"fmt"
+	"strings"
+)
+
+//Query is a compiled query expression which tests distinguished names.
+//
+//The syntax of the expression is:
+//  expr       = and *( "or" and )
+//  and        = unary *( "and" unary )
+//  unary      = "not" unary / "(" expr ")" / comparison
+//  comparison = type ( "=" / "~" ) string
+//type is a short name in AttributeTypes or a dotted OID, and string is a double-quoted string in which '"' and '\'
+//are escaped by '\'. The keywords are case-insensitive, and "not" binds tighter than "and", which binds tighter than "or".
+//
+//type = string is true if the distinguished name has an attribute of type whose value matches string by the same
+//rules as Compare. string is encoded by DefaultEncodingPolicy and converted to the canonical form by Compile, and
+//each value of type is compared with it in the canonical form, which Canonicalize returns.
+//type ~ string is true if the distinguished name has an attribute of type whose value contains string after both
+//are processed with the string preparation algorithm(RFC4518), i.e. ignoring case and insignificant spaces.
+//string of "~" must not be empty after the preparation, because every value contains the empty string.
+//
+//For example:
+//  C = "JP" and (O = "Example" or O = "Example KK") and not CN ~ "test"
+type Query struct {
+	expr string
+	root queryNode
+}
+
+//QueryError is the error returned by Compile, which has the position of the error in the expression.
+type QueryError struct {
+	Pos int //byte offset of the error in the expression
+	Msg string
+}
+
+//Error returns the description of e.
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("dn: query: position %d: %s", e.Pos, e.Msg)
+}
+
+//Compile parses expr and returns Query.
+func Compile(expr string) (q *Query, err error) {
+	p := &queryParser{}
+	if p.tokens, err = tokenizeQuery(expr); err != nil {
+		return nil, err
+	}
+	var root queryNode
+	if root, err = p.parseOr(); err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, &QueryError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s", t)}
+	}
+	return &Query{expr: expr, root: root}, nil
+}
+
+//String returns the expression which q is compiled from.
+func (q *Query) String() string {
+	return q.expr
+}
+
+//Match reports whether dnBytes, which is encoded as Distinguished Name, satisfies q.
+func (q *Query) Match(dnBytes []byte) (result bool, err error) {
+	var d dn
+	if d, err = parseDn(dnBytes); err != nil {
+		return false, err
+	}
+	return q.root.eval(d)
+}
+
+type queryNode interface {
+	eval(d dn) (result bool, err error)
+}
+
+type andNode struct{ x, y queryNode }
+
+func (n *andNode) eval(d dn) (result bool, err error) {
+	if result, err = n.x.eval(d); err != nil || !result {
+		return false, err
+	}
+	return n.y.eval(d)
+}
+
+type orNode struct{ x, y queryNode }
+
+func (n *orNode) eval(d dn) (result bool, err error) {
+	if result, err = n.x.eval(d); err != nil || result {
+		return result, err
+	}
+	return n.y.eval(d)
+}
+
+type notNode struct{ x queryNode }
+
+func (n *notNode) eval(d dn) (result bool, err error) {
+	if result, err = n.x.eval(d); err != nil {
+		return false, err
+	}
+	return !result, nil
+}
+
+type equalNode struct {
+	canonical Attribute //the canonical form of the value, which is prepared once by Compile
+}
+
+func (n *equalNode) eval(d dn) (result bool, err error) {
+	//the zero Comparer canonicalizes in the same way as Compare
+	var c Comparer
+	for _, r := range d {
+		for _, atv := range r {
+			if !oidEqual(atv.Oid, n.canonical.Oid) {
+				continue
+			}
+			var canonical Attribute
+			if canonical, err = c.canonicalizeAttribute(atv, 0); err != nil {
+				return false, err
+			}
+			if bytes.Equal(canonical.RawValue.FullBytes, n.canonical.RawValue.FullBytes) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+type substringNode struct {
+	oid       asn1.ObjectIdentifier
+	substring string //prepared and trimmed
+}
+
+func (n *substringNode) eval(d dn) (result bool, err error) {
+	for _, r := range d {
+		for _, atv := range r {
+			if !oidEqual(atv.Oid, n.oid) || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				return false, err
+			}
+			var u []rune
+			if u, err = stringPrepare(s); err != nil {
+				return false, err
+			}
+			if strings.Contains(string(u), n.substring) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenEqual
+	tokenTilde
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+type queryToken struct {
+	kind  tokenKind
+	pos   int
+	value string
+}
+
+//String returns the description of t for error messages.
+func (t queryToken) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.value)
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+//tokenizeQuery splits expr into tokens.
+func tokenizeQuery(expr string) (tokens []queryToken, err error) {
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '=':
+			tokens = append(tokens, queryToken{kind: tokenEqual, pos: i, value: "="})
+			i++
+		case c == '~':
+			tokens = append(tokens, queryToken{kind: tokenTilde, pos: i, value: "~"})
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokenLParen, pos: i, value: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokenRParen, pos: i, value: ")"})
+			i++
+		case c == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(expr) && expr[j] != '"'; j++ {
+				if expr[j] == '\\' {
+					if j+1 == len(expr) || (expr[j+1] != '"' && expr[j+1] != '\\') {
+						return nil, &QueryError{Pos: j, Msg: "invalid escape sequence"}
+					}
+					j++
+				}
+				sb.WriteByte(expr[j])
+			}
+			if j == len(expr) {
+				return nil, &QueryError{Pos: i, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, queryToken{kind: tokenString, pos: i, value: sb.String()})
+			i = j + 1
+		case isIdentCharacter(c):
+			j := i
+			for j < len(expr) && isIdentCharacter(expr[j]) {
+				j++
+			}
+			t := queryToken{kind: tokenIdent, pos: i, value: expr[i:j]}
+			switch strings.ToLower(t.value) {
+			case "and":
+				t.kind = tokenAnd
+			case "or":
+				t.kind = tokenOr
+			case "not":
+				t.kind = tokenNot
+			}
+			tokens = append(tokens, t)
+			i = j
+		default:
+			return nil, &QueryError{Pos: i, Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	return append(tokens, queryToken{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+//isIdentCharacter reports whether c can be used in attribute types of query expressions.
+func isIdentCharacter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '.' || c == '-'
+}
+
+type queryParser struct {
+	tokens []queryToken
+	i      int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.i]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.tokens[p.i]
+	if t.kind != tokenEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *queryParser) parseOr() (n queryNode, err error) {
+	if n, err = p.parseAnd(); err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		var y queryNode
+		if y, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		n = &orNode{x: n, y: y}
+	}
+	return n, nil
+}
+
+func (p *queryParser) parseAnd() (n queryNode, err error) {
+	if n, err = p.parseUnary(); err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		var y queryNode
+		if y, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		n = &andNode{x: n, y: y}
+	}
+	return n, nil
+}
+
+func (p *queryParser) parseUnary() (n queryNode, err error) {
+	switch t := p.next(); t.kind {
+	case tokenNot:
+		if n, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		return &notNode{x: n}, nil
+	case tokenLParen:
+		if n, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != tokenRParen {
+			return nil, &QueryError{Pos: r.pos, Msg: fmt.Sprintf("expected \")\" but got %s", r)}
+		}
+		return n, nil
+	case tokenIdent:
+		return p.parseComparison(t)
+	default:
+		return nil, &QueryError{Pos: t.pos, Msg: fmt.Sprintf("expected attribute type but got %s", t)}
+	}
+}
+
+func (p *queryParser) parseComparison(typ queryToken) (n queryNode, err error) {
+	var oid asn1.ObjectIdentifier
+	if oid, err = lookupAttributeType(typ.value); err != nil {
+		return nil, &QueryError{Pos: typ.pos, Msg: fmt.Sprintf("unknown attribute type %q", typ.value)}
+	}
+	op := p.next()
+	if op.kind != tokenEqual && op.kind != tokenTilde {
+		return nil, &QueryError{Pos: op.pos, Msg: fmt.Sprintf("expected \"=\" or \"~\" but got %s", op)}
+	}
+	value := p.next()
+	if value.kind != tokenString {
+		return nil, &QueryError{Pos: value.pos, Msg: fmt.Sprintf("expected string but got %s", value)}
+	}
+
+	if op.kind == tokenEqual {
+		atv := Attribute{Oid: oid}
+		if atv.RawValue, err = DefaultEncodingPolicy().Encode(oid, value.value); err != nil {
+			return nil, &QueryError{Pos: value.pos, Msg: strings.TrimPrefix(err.Error(), "dn: ")}
+		}
+		var c Comparer
+		if atv, err = c.canonicalizeAttribute(atv, 0); err != nil {
+			return nil, &QueryError{Pos: value.pos, Msg: strings.TrimPrefix(err.Error(), "dn: ")}
+		}
+		return &equalNode{canonical: atv}, nil
+	}
+	var u []rune
+	if u, err = stringPrepare(value.value); err != nil {
+		return nil, &QueryError{Pos: value.pos, Msg: strings.TrimPrefix(err.Error(), "dn: ")}
+	}
+	substring := strings.TrimSpace(string(u))
+	if substring == "" {
+		//every value contains the empty string
+		return nil, &QueryError{Pos: value.pos, Msg: "empty substring"}
+	}
+	return &substringNode{oid: oid, substring: substring}, nil
+}
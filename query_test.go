@@ -0,0 +1,104 @@
+package dn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestQuery_Match(t *testing.T) {
+	type args struct {
+		expr    string
+		dnBytes []byte
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Equal", args{`C = "JP"`, dn15b}, true, false},
+		{"Equal, Different characters", args{`C = "US"`, dn15b}, false, false},
+		{"Equal, Upper/Lower case characters", args{`o = "EXAMPLE"`, dn15b}, true, false},
+		{"Equal, Dotted OID", args{`2.5.4.10 = "example"`, dn15b}, true, false},
+		{"Equal, Missing attribute", args{`OU = "Example"`, dn15b}, false, false},
+		{"Substring", args{`CN ~ "host"`, dnRevokedHostb}, true, false},
+		{"Substring, Insignificant spaces", args{`CN ~ " REVOKED   HOST "`, dnRevokedHostb}, true, false},
+		{"Substring, Different characters", args{`CN ~ "test"`, dnRevokedHostb}, false, false},
+		{"And, Or, Not", args{`C = "JP" and (O = "Example" or O = "Example KK") and not CN ~ "test"`, dn15b}, true, false},
+		{"And, Or, Not, Denied by not", args{`C = "JP" and (O = "Example" or O = "Example KK") and not CN ~ "host"`, dnRevokedHostb}, false, false},
+		{"Or, Second operand", args{`C = "US" or C = "JP"`, dn15b}, true, false},
+		{"Precedence of and over or", args{`C = "JP" or C = "US" and CN = "XYZ"`, dn15b}, true, false},
+		{"Precedence of not over and", args{`not C = "US" and CN = "ABC"`, dn15b}, true, false},
+		{"Case-insensitive keywords", args{`NOT C = "US" AND CN = "ABC"`, dn15b}, true, false},
+		{"Escaped string", args{`CN = "a\"b\\c"`, dn15b}, false, false},
+		{"Equal, UTF8String value", args{`CN = "abc"`, dn2b}, true, false},
+		{"Equal, BMPString value", args{`CN = "ABC"`, dn5b}, false, false},
+		{"Equal, unstructuredName IA5String value", args{`unstructuredName = "router1"`, dn24b}, false, false},
+		{"Equal, domain component", args{`DC = "EXAMPLE"`, dn17b}, true, false},
+		{"Wrong Encoding domain component", args{`DC = "example"`, dn7b}, false, true},
+		{"Broken data", args{`C = "JP"`, brdnb}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Compile(tt.args.expr)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			gotResult, err := q.Match(tt.args.dnBytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Match() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Match() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantPos int
+	}{
+		{"Empty", ``, 0},
+		{"Unexpected character", `C = "JP" & O = "Example"`, 9},
+		{"Unterminated string", `C = "JP`, 4},
+		{"Invalid escape sequence", `C = "J\P"`, 6},
+		{"Unknown attribute type", `C = "JP" and XX = "abc"`, 13},
+		{"Missing operator", `C "JP"`, 2},
+		{"Missing string", `C = and`, 4},
+		{"Missing right parenthesis", `(C = "JP" or C = "US"`, 21},
+		{"Unexpected right parenthesis", `C = "JP")`, 8},
+		{"Missing operand", `C = "JP" or`, 11},
+		{"Wrong encoding", `C = "日本"`, 4},
+		{"Empty substring", `CN ~ ""`, 5},
+		{"Substring of spaces", `CN ~ "   "`, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.expr)
+			var qe *QueryError
+			if !errors.As(err, &qe) {
+				t.Fatalf("Compile() error = %v, want *QueryError", err)
+			}
+			if qe.Pos != tt.wantPos {
+				t.Errorf("Compile() error = %v, wantPos %v", err, tt.wantPos)
+			}
+		})
+	}
+}
+
+func BenchmarkQuery_Match(b *testing.B) {
+	q, err := Compile(`C = "JP" and (O = "Example" or O = "Example KK") and not CN ~ "test"`)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := q.Match(dn15b); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package dn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//schemaMatchingRules are the matching rules which WithSchema accepts, by the names and the OIDs of the equality
+//matching rules of LDAP(RFC4517 section-4.2).
+var schemaMatchingRules = []struct {
+	name string
+	oid  string
+	rule MatchingRule
+}{
+	{"caseIgnoreMatch", "2.5.13.2", MatchingRuleCaseIgnore},
+	{"caseExactMatch", "2.5.13.5", MatchingRuleCaseExact},
+	{"distinguishedNameMatch", "2.5.13.1", MatchingRuleDistinguishedName},
+	{"bitStringMatch", "2.5.13.16", MatchingRuleBitString},
+	{"numericStringMatch", "2.5.13.8", MatchingRuleNumericString},
+	{"caseIgnoreIA5Match", "1.3.6.1.4.1.1466.109.114.2", MatchingRuleCaseIgnoreIA5},
+	{"caseExactIA5Match", "1.3.6.1.4.1.1466.109.114.1", MatchingRuleCaseExactIA5},
+	{"octetStringMatch", "2.5.13.17", MatchingRuleOctetString},
+}
+
+//WithSchema returns the Option which sets the matching rules of the attribute types by schema, which maps the attribute
+//types to the names of their equality matching rules, e.g. {"cn": "caseIgnoreMatch", "2.5.4.5": "caseExactMatch"}.
+//The attribute types are the short names of AttributeTypes or the dotted string forms of the OIDs.
+//The rules are caseIgnoreMatch, caseExactMatch, distinguishedNameMatch, bitStringMatch, numericStringMatch,
+//caseIgnoreIA5Match, caseExactIA5Match and octetStringMatch, by the names case-insensitively or by the OIDs of the rules.
+//The attribute types which are not in schema keep their matching rules.
+//It is the same as WithMatchingRule for each of the attribute types in schema.
+//It returns an error if schema has an unknown attribute type or an unknown matching rule, or if two keys of schema
+//have the same attribute type, e.g. "cn" and "2.5.4.3".
+func WithSchema(schema map[string]string) (Option, error) {
+	//the keys are sorted, so that the error of the same attribute type is deterministic
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	opts := make([]Option, 0, len(schema))
+	seen := make(map[string]string, len(schema))
+	for _, name := range names {
+		ruleName := schema[name]
+		oid, err := lookupAttributeType(name)
+		if err != nil {
+			return nil, err
+		}
+		if prev, ok := seen[oid.String()]; ok {
+			return nil, fmt.Errorf("dn: keys %q and %q have the same attribute type", prev, name)
+		}
+		seen[oid.String()] = name
+		rule, err := lookupMatchingRule(ruleName)
+		if err != nil {
+			return nil, fmt.Errorf("dn: attribute type %q: %w", name, err)
+		}
+		opts = append(opts, WithMatchingRule(oid, rule))
+	}
+	return func(c *Comparer) {
+		for _, opt := range opts {
+			opt(c)
+		}
+	}, nil
+}
+
+//lookupMatchingRule returns the matching rule of name, which is the name or the OID of a matching rule in
+//schemaMatchingRules.
+func lookupMatchingRule(name string) (rule MatchingRule, err error) {
+	for _, r := range schemaMatchingRules {
+		if strings.EqualFold(r.name, name) || r.oid == name {
+			return r.rule, nil
+		}
+	}
+	return 0, fmt.Errorf("dn: unknown matching rule %q", name)
+}
@@ -0,0 +1,112 @@
+package dn
+
+import (
+	"testing"
+)
+
+func TestWithSchema(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		schema     map[string]string
+		args       args
+		wantResult bool
+	}{
+		{"No schema, Upper/Lower case characters", nil, args{issuer: dn2b, subject: dn4b}, true},
+		{"caseExactMatch CN, Upper/Lower case characters", map[string]string{"CN": "caseExactMatch"}, args{issuer: dn2b, subject: dn4b}, false},
+		{"caseExactMatch by OID of attribute type and rule", map[string]string{"2.5.4.3": "2.5.13.5"}, args{issuer: dn2b, subject: dn4b}, false},
+		{"Rule name in different case", map[string]string{"cn": "CASEEXACTMATCH"}, args{issuer: dn2b, subject: dn4b}, false},
+		{"caseIgnoreMatch CN", map[string]string{"CN": "caseIgnoreMatch"}, args{issuer: dn2b, subject: dn4b}, true},
+		{"caseExactMatch of other attribute type", map[string]string{"O": "caseExactMatch"}, args{issuer: dn2b, subject: dn4b}, true},
+		{"No schema, Spaces in NumericString", nil, args{issuer: dn31b, subject: dn80b}, false},
+		{"numericStringMatch O, Spaces in NumericString", map[string]string{"O": "numericStringMatch"}, args{issuer: dn31b, subject: dn80b}, true},
+		{"numericStringMatch O, Different Encoding(NumericString,UTF8String)", map[string]string{"O": "numericStringMatch"}, args{issuer: dn31b, subject: dn81b}, true},
+		{"numericStringMatch O, Different digits", map[string]string{"O": "numericStringMatch"}, args{issuer: dn31b, subject: dn82b}, false},
+		{"numericStringMatch by OID", map[string]string{"O": "2.5.13.8"}, args{issuer: dn80b, subject: dn81b}, true},
+		{"No schema, unstructuredName, Upper/Lower case characters(IA5String)", nil, args{issuer: dn23b, subject: dn24b}, false},
+		{"caseIgnoreIA5Match unstructuredName, Upper/Lower case characters", map[string]string{"1.2.840.113549.1.9.2": "caseIgnoreIA5Match"}, args{issuer: dn23b, subject: dn24b}, true},
+		{"caseIgnoreIA5Match unstructuredName, Insignificant spaces", map[string]string{"1.2.840.113549.1.9.2": "caseIgnoreIA5Match"}, args{issuer: dn25b, subject: dn24b}, true},
+		{"caseIgnoreIA5Match unstructuredName, Different Encoding(IA5String,UTF8String)", map[string]string{"1.2.840.113549.1.9.2": "caseIgnoreIA5Match"}, args{issuer: dn24b, subject: dn26b}, false},
+		{"caseExactIA5Match unstructuredName, Upper/Lower case characters", map[string]string{"1.2.840.113549.1.9.2": "1.3.6.1.4.1.1466.109.114.1"}, args{issuer: dn23b, subject: dn24b}, false},
+		{"caseExactIA5Match unstructuredName, Insignificant spaces", map[string]string{"1.2.840.113549.1.9.2": "caseExactIA5Match"}, args{issuer: dn25b, subject: dn23b}, true},
+		{"octetStringMatch CN, Same", map[string]string{"CN": "octetStringMatch"}, args{issuer: dn2b, subject: dn2b}, true},
+		{"octetStringMatch CN, Upper/Lower case characters", map[string]string{"CN": "octetStringMatch"}, args{issuer: dn2b, subject: dn4b}, false},
+		{"octetStringMatch CN, Different Encoding(UTF8String,PrintableString)", map[string]string{"CN": "2.5.13.17"}, args{issuer: dn2b, subject: dn3b}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := WithSchema(tt.schema)
+			if err != nil {
+				t.Fatalf("WithSchema() error = %v", err)
+			}
+			c := NewComparer(opt)
+			gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+
+			ci, err := c.Canonicalize(tt.args.issuer)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			cs, err := c.Canonicalize(tt.args.subject)
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+			if got := string(ci) == string(cs); got != tt.wantResult {
+				t.Errorf("Canonicalize() equality = %v, want %v", got, tt.wantResult)
+			}
+
+			gotResult, err = NewComparer(opt, WithConstantTime()).Compare(tt.args.issuer, tt.args.subject)
+			if err != nil {
+				t.Fatalf("Compare() with WithConstantTime error = %v", err)
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() with WithConstantTime gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestWithSchema_Error(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema map[string]string
+	}{
+		{"Unknown matching rule", map[string]string{"CN": "caseIgnoreSubstringsMatch"}},
+		{"Unknown attribute type", map[string]string{"unknownType": "caseIgnoreMatch"}},
+		{"Empty matching rule", map[string]string{"CN": ""}},
+		{"Same attribute type", map[string]string{"cn": "caseExactMatch", "2.5.4.3": "caseIgnoreMatch"}},
+		{"Same attribute type, Same matching rule", map[string]string{"CN": "caseIgnoreMatch", "cn": "caseIgnoreMatch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := WithSchema(tt.schema); err == nil {
+				t.Errorf("WithSchema() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestCompareExplain_NumericStringMatch(t *testing.T) {
+	opt, err := WithSchema(map[string]string{"O": "numericStringMatch"})
+	if err != nil {
+		t.Fatalf("WithSchema() error = %v", err)
+	}
+	result, decisions, err := NewComparer(opt).CompareExplain(dn31b, dn80b)
+	if err != nil {
+		t.Fatalf("CompareExplain() error = %v", err)
+	}
+	if !result {
+		t.Errorf("CompareExplain() result = false, want true")
+	}
+	if got := decisions[1].Attributes[0].Rule; got != AppliedRuleNumericStringMatch {
+		t.Errorf("CompareExplain() Rule = %v, want %v", got, AppliedRuleNumericStringMatch)
+	}
+}
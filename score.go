@@ -0,0 +1,130 @@
+package dn
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+//MatchStrictness is the set of the relaxed rules which a match relied on, reported by CompareScored.
+type MatchStrictness int
+
+//Relaxed rules of MatchStrictness. MatchStrict is the empty set, and the others are combined by bitwise OR.
+const (
+	//MatchStrict means the distinguished names match by the rules of RFC 5280 and RFC 4518 alone, as Compare
+	//compares them without options.
+	MatchStrict MatchStrictness = 0
+	//MatchRelaxedTeletexString means a value in TeletexString is converted by WithTeletexString.
+	MatchRelaxedTeletexString MatchStrictness = 1 << 0
+	//MatchRelaxedVisibleString means a value in VisibleString is converted by WithVisibleString.
+	MatchRelaxedVisibleString MatchStrictness = 1 << 1
+	//MatchRelaxedDomainComponent means a domainComponent value is converted by WithLenientDomainComponent or
+	//WithTrimDCWhitespace.
+	MatchRelaxedDomainComponent MatchStrictness = 1 << 2
+	//MatchRelaxedDroppedAttributes means attributes are removed by WithIgnoredTypes or WithDropEmptyAttributes.
+	MatchRelaxedDroppedAttributes MatchStrictness = 1 << 3
+	//MatchRelaxedValueRule means a pair of values match by the matching rules or the string preparation of the
+	//Comparer, e.g. WithFullCaseFolding or WithMatchingFunc, but not by those of Compare.
+	MatchRelaxedValueRule MatchStrictness = 1 << 4
+	//MatchRelaxedBMPString means a value in BMPString is converted by WithBMPString.
+	MatchRelaxedBMPString MatchStrictness = 1 << 5
+	//MatchRelaxedPrintableString means a value in PrintableString is converted by WithLenientPrintableString.
+	MatchRelaxedPrintableString MatchStrictness = 1 << 6
+)
+
+var matchStrictnessNames = []string{"teletexString", "visibleString", "domainComponent", "droppedAttributes", "valueRule", "bmpString", "printableString"}
+
+//IsStrict reports whether s has no relaxed rules.
+func (s MatchStrictness) IsStrict() bool {
+	return s == MatchStrict
+}
+
+//String returns the names of the relaxed rules of s separated by '|', or "strict" if s has none.
+func (s MatchStrictness) String() string {
+	if s == MatchStrict {
+		return "strict"
+	}
+	var names []string
+	for i, name := range matchStrictnessNames {
+		if s&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if rest := s &^ (1<<len(matchStrictnessNames) - 1); rest != 0 {
+		names = append(names, fmt.Sprintf("MatchStrictness(%d)", int(rest)))
+	}
+	return strings.Join(names, "|")
+}
+
+//CompareScored reports whether issuer and subject matches in the same way as Compare, and the relaxed rules of c
+//which the match relied on. strictness is MatchStrict if the match does not depend on any option of c which accepts
+//more than Compare does, and it is always MatchStrict if result is false.
+//
+//Security-sensitive callers may log or reject the matches which are not strict. A rule is reported when it changed
+//the distinguished names before the comparison or decided a pair of values which the match assigned to each other,
+//even if the distinguished names would also match without it, e.g. WithIgnoredTypes of the attributes which have the
+//same values. If c has custom rules of the values, the distinguished names are compared in the same way as
+//CompareExplain, and the assigned values are compared again by the rules of Compare, which is not in constant time
+//even if c has WithConstantTime.
+func (c *Comparer) CompareScored(issuer []byte, subject []byte) (result bool, strictness MatchStrictness, err error) {
+	cc := *c
+	cc.strictness = &strictness
+	if result, err = cc.Compare(issuer, subject); err != nil || !result {
+		return false, MatchStrict, err
+	}
+	return true, strictness, nil
+}
+
+//relaxIfChanged records the relaxed rule s if a step of the preparation changed before to after.
+func (c *Comparer) relaxIfChanged(s MatchStrictness, before dn, after dn) {
+	if c.strictness != nil && !isSameDn(before, after) {
+		*c.strictness |= s
+	}
+}
+
+//relaxValueRules records MatchRelaxedValueRule if any pair of the attributes of xd and yd which decisions assign to each
+//other does not match by the rules of Compare. The pairs which the assignment of the attributes of multi-valued RDNs
+//compared but did not choose are not checked, because the match does not rely on them.
+func (c *Comparer) relaxValueRules(xd dn, yd dn, decisions []RDNDecision) {
+	for _, d := range decisions {
+		for _, ad := range d.Attributes {
+			if !ad.Matched {
+				continue
+			}
+			if result, err := compareAttribute(xd[d.RDN][ad.Issuer], yd[d.RDN][ad.Subject]); err != nil || !result {
+				*c.strictness |= MatchRelaxedValueRule
+				return
+			}
+		}
+	}
+}
+
+//hasCustomValueRules reports whether c compares the values by other rules than Compare.
+func (c *Comparer) hasCustomValueRules() bool {
+	p := c.stringPreparer()
+	if cp, ok := p.(*cachingPreparer); ok {
+		p = cp.p
+	}
+	if lp, ok := p.(ldapStringPreparer); !ok || lp.form != NormalizationNFKC {
+		return true
+	}
+	return len(c.matchingRules) != 0 || len(c.matchingFuncs) != 0
+}
+
+//isSameDn reports whether a and b consist of the same attributes encoded in the same bytes.
+func isSameDn(a dn, b dn) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if len(a[i]) != len(b[i]) {
+			return false
+		}
+		for j := range a[i] {
+			if !oidEqual(a[i][j].Oid, b[i][j].Oid) || !bytes.Equal(a[i][j].RawValue.FullBytes, b[i][j].RawValue.FullBytes) {
+				return false
+			}
+		}
+	}
+	return true
+}
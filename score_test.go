@@ -0,0 +1,98 @@
+package dn
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComparer_CompareScored(t *testing.T) {
+	always := func(x MatchingValue, y MatchingValue) (bool, error) { return true, nil }
+	//"B" also matches "a" but not vice versa, so that the assignment of the attributes of multi-valued RDNs tries the
+	//pair and reassigns them
+	oneWay := func(x MatchingValue, y MatchingValue) (bool, error) {
+		return strings.EqualFold(x.Value, y.Value) || x.Value == "B" && y.Value == "a", nil
+	}
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name           string
+		opts           []Option
+		args           args
+		wantResult     bool
+		wantStrictness MatchStrictness
+		wantErr        bool
+	}{
+		{"Default", nil, args{dn2b, dn3b}, true, MatchStrict, false},
+		{"Default, Different DN", nil, args{dn2b, dn6b}, false, MatchStrict, false},
+		{"Empty issuer", nil, args{nil, dn2b}, false, MatchStrict, true},
+		{"TeletexString", []Option{WithTeletexString()}, args{dnTeletexb, dnTeletexUTF8b}, true, MatchRelaxedTeletexString, false},
+		{"TeletexString, No TeletexString values", []Option{WithTeletexString()}, args{dn2b, dn3b}, true, MatchStrict, false},
+		{"TeletexString, Different characters", []Option{WithTeletexString()}, args{dnTeletexb, dnExamplePrintableb}, false, MatchStrict, false},
+		{"VisibleString", []Option{WithVisibleString()}, args{dn90b, dn3b}, true, MatchRelaxedVisibleString, false},
+		{"BMPString", []Option{WithBMPString()}, args{dn5b, dn2b}, true, MatchRelaxedBMPString, false},
+		{"Lenient PrintableString", []Option{WithLenientPrintableString()}, args{dnPrintableAtb, dnUTF8Atb}, true, MatchRelaxedPrintableString, false},
+		{"Lenient PrintableString, Valid PrintableString", []Option{WithLenientPrintableString()}, args{dn2b, dn3b}, true, MatchStrict, false},
+		{"Lenient domain component", []Option{WithLenientDomainComponent()}, args{dn7b, dn17b}, true, MatchRelaxedDomainComponent, false},
+		{"Trim domain component", []Option{WithTrimDCWhitespace()}, args{mustMarshalString(t, "DC=exa  mple,DC=com"), mustMarshalString(t, "DC=exa mple,DC=com")}, true, MatchRelaxedDomainComponent, false},
+		{"Ignored types", []Option{WithIgnoredTypes(oidSerialNumber)}, args{mustMarshalString(t, "CN=abc,2.5.4.5=1"), mustMarshalString(t, "CN=abc,2.5.4.5=2")}, true, MatchRelaxedDroppedAttributes, false},
+		{"Ignored types, Absent", []Option{WithIgnoredTypes(oidSerialNumber)}, args{dn2b, dn3b}, true, MatchStrict, false},
+		{"Drop empty attributes", []Option{WithDropEmptyAttributes()}, args{mustMarshalString(t, "OU=,O=Example"), mustMarshalString(t, "O=Example")}, true, MatchRelaxedDroppedAttributes, false},
+		{"Full case folding", []Option{WithFullCaseFolding()}, args{mustMarshalString(t, "CN=ẞ"), mustMarshalString(t, "CN=ss")}, true, MatchRelaxedValueRule, false},
+		{"Full case folding, Same as default", []Option{WithFullCaseFolding()}, args{mustMarshalString(t, "CN=ABC"), mustMarshalString(t, "CN=abc")}, true, MatchStrict, false},
+		{"Case exact is stricter", []Option{WithMatchingRule(oidCommonName, MatchingRuleCaseExact)}, args{mustMarshalString(t, "CN=abc"), mustMarshalString(t, "CN=abc")}, true, MatchStrict, false},
+		{"Matching function", []Option{WithMatchingFunc(oidCommonName, always)}, args{mustMarshalString(t, "CN=abc"), mustMarshalString(t, "CN=xyz")}, true, MatchRelaxedValueRule, false},
+		{"Multi-valued RDN, Default", nil, args{mustMarshalString(t, "CN=abc+OU=x"), mustMarshalString(t, "OU=X+CN=ABC")}, true, MatchStrict, false},
+		{"Multi-valued RDN, Reassigned to the values which match by default", []Option{WithMatchingFunc(oidCommonName, oneWay)}, args{mustMarshalString(t, "CN=B+CN=a"), mustMarshalString(t, "CN=a+CN=b")}, true, MatchStrict, false},
+		{"Multi-valued RDN, Assigned to the values which match by the matching function", []Option{WithMatchingFunc(oidCommonName, oneWay)}, args{mustMarshalString(t, "CN=B+CN=a"), mustMarshalString(t, "CN=a+CN=a")}, true, MatchRelaxedValueRule, false},
+		{"Several rules", []Option{WithFullCaseFolding(), WithIgnoredTypes(oidSerialNumber)}, args{mustMarshalString(t, "CN=ẞ,2.5.4.5=1"), mustMarshalString(t, "CN=ss,2.5.4.5=2")}, true, MatchRelaxedDroppedAttributes | MatchRelaxedValueRule, false},
+		{"Constant time", []Option{WithConstantTime(), WithTeletexString()}, args{dnTeletexb, dnTeletexUTF8b}, true, MatchRelaxedTeletexString, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewComparer(tt.opts...)
+			gotResult, gotStrictness, err := c.CompareScored(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CompareScored() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("CompareScored() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if gotStrictness != tt.wantStrictness {
+				t.Errorf("CompareScored() gotStrictness = %v, want %v", gotStrictness, tt.wantStrictness)
+			}
+			//the result is the same as Compare, which records nothing
+			if result, err := c.Compare(tt.args.issuer, tt.args.subject); result != gotResult || (err != nil) != tt.wantErr {
+				t.Errorf("Compare() = %v, %v, want %v", result, err, gotResult)
+			}
+			if c.strictness != nil {
+				t.Errorf("CompareScored() modified the Comparer")
+			}
+		})
+	}
+}
+
+func TestMatchStrictness_String(t *testing.T) {
+	tests := []struct {
+		name string
+		s    MatchStrictness
+		want string
+	}{
+		{"Strict", MatchStrict, "strict"},
+		{"Single", MatchRelaxedTeletexString, "teletexString"},
+		{"Several", MatchRelaxedDomainComponent | MatchRelaxedValueRule, "domainComponent|valueRule"},
+		{"Unknown", MatchRelaxedVisibleString | 1<<10, "visibleString|MatchStrictness(1024)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+			if got := tt.s.IsStrict(); got != (tt.s == MatchStrict) {
+				t.Errorf("IsStrict() = %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package dn
+
+import (
+	"sync"
+)
+
+//DNSet is a set of distinguished names which match by Compare, keyed by CanonicalKey.
+//Distinguished names which differ only in the encodings, case or spaces of their values are stored as one entry,
+//and only the canonical forms are kept. The zero value is an empty set. DNSet is safe for concurrent use.
+type DNSet struct {
+	mu   sync.RWMutex
+	c    *Comparer //Comparer whose canonical forms are the keys, nil for CanonicalKey
+	keys map[string]struct{}
+}
+
+//NewDNSet returns an empty DNSet of the distinguished names which match by c, keyed by the canonical forms of
+//c.Canonicalize instead of CanonicalKey. c must not be modified after the call.
+func (c *Comparer) NewDNSet() *DNSet {
+	return &DNSet{c: c}
+}
+
+//key returns the key of der in s.
+func (s *DNSet) key(der []byte) (key string, err error) {
+	if s.c == nil {
+		return CanonicalKey(der)
+	}
+	var b []byte
+	if b, err = s.c.Canonicalize(der); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+//Add adds der, which is encoded as Distinguished Name, to s and returns its CanonicalKey, or its canonical form by the
+//Comparer of s made by Comparer.NewDNSet. isNew is false if s already has a distinguished name which matches der.
+func (s *DNSet) Add(der []byte) (canonicalKey string, isNew bool, err error) {
+	if canonicalKey, err = s.key(der); err != nil {
+		return "", false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[canonicalKey]; ok {
+		return canonicalKey, false, nil
+	}
+	if s.keys == nil {
+		s.keys = make(map[string]struct{})
+	}
+	s.keys[canonicalKey] = struct{}{}
+	return canonicalKey, true, nil
+}
+
+//Contains reports whether s has a distinguished name which matches der, which is encoded as Distinguished Name.
+func (s *DNSet) Contains(der []byte) (result bool, err error) {
+	var key string
+	if key, err = s.key(der); err != nil {
+		return false, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, result = s.keys[key]
+	return result, nil
+}
+
+//Len returns the number of distinguished names in s.
+func (s *DNSet) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
@@ -0,0 +1,122 @@
+package dn
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDNSet_Add(t *testing.T) {
+	tests := []struct {
+		name      string
+		der       []byte
+		wantIsNew bool
+		wantErr   bool
+	}{
+		{"New", dn2b, true, false},
+		{"Same characters, Different Encoding(PrintableString,UTF8String)", dn3b, false, false},
+		{"Upper/Lower case characters", dn4b, false, false},
+		{"Country name with spaces", dn20b, false, false},
+		{"Different characters", dn6b, true, false},
+		{"Multi RDN", dn1b, true, false},
+		{"Multi RDN not in DER order", dn16b, false, false},
+		{"Wrong Encoding domain component", dn7b, false, true},
+		{"Broken data", brdnb, false, true},
+	}
+	var s DNSet
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotKey, gotIsNew, err := s.Add(tt.der)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Add() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotIsNew != tt.wantIsNew {
+				t.Errorf("Add() gotIsNew = %v, want %v", gotIsNew, tt.wantIsNew)
+			}
+			if tt.wantErr {
+				return
+			}
+			if wantKey, _ := CanonicalKey(tt.der); gotKey != wantKey {
+				t.Errorf("Add() gotKey = %x, want %x", gotKey, wantKey)
+			}
+		})
+	}
+	if s.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", s.Len())
+	}
+}
+
+func TestDNSet_Contains(t *testing.T) {
+	var s DNSet
+	if result, err := s.Contains(dn2b); err != nil || result {
+		t.Errorf("Contains() of the zero value = %v, %v, want false", result, err)
+	}
+	if _, _, err := s.Add(dn2b); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	tests := []struct {
+		name       string
+		der        []byte
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Same DN", dn2b, true, false},
+		{"Upper/Lower case characters", dn4b, true, false},
+		{"Different characters", dn6b, false, false},
+		{"Broken data", brdnb, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := s.Contains(tt.der)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Contains() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Contains() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestComparer_NewDNSet(t *testing.T) {
+	s := NewComparer(WithIgnoredTypes(oidSerialNumber)).NewDNSet()
+	if _, _, err := s.Add(dn12b); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	//dn13b differs from dn12b only in serialNumber
+	if result, err := s.Contains(dn13b); err != nil || !result {
+		t.Errorf("Contains() = %v, %v, want true", result, err)
+	}
+	if _, isNew, err := s.Add(dn13b); err != nil || isNew {
+		t.Errorf("Add() gotIsNew = %v, %v, want false", isNew, err)
+	}
+	var z DNSet
+	if _, _, err := z.Add(dn12b); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if result, err := z.Contains(dn13b); err != nil || result {
+		t.Errorf("Contains() of the zero value = %v, %v, want false", result, err)
+	}
+}
+
+func TestDNSet_Concurrent(t *testing.T) {
+	var s DNSet
+	var wg sync.WaitGroup
+	for _, der := range [][]byte{dn2b, dn3b, dn4b, dn6b, dn2b, dn3b, dn4b, dn6b} {
+		wg.Add(1)
+		go func(der []byte) {
+			defer wg.Done()
+			if _, _, err := s.Add(der); err != nil {
+				t.Errorf("Add() error = %v", err)
+			}
+			if _, err := s.Contains(der); err != nil {
+				t.Errorf("Contains() error = %v", err)
+			}
+		}(der)
+	}
+	wg.Wait()
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+}
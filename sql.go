@@ -0,0 +1,120 @@
+package dn
+
+import (
+	"database/sql/driver"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+//SQLForm is the form in which SQLDN is stored in databases.
+type SQLForm int
+
+//Forms of SQLDN in databases.
+const (
+	//SQLFormString stores the canonical form as the string representation described in RFC 4514.
+	SQLFormString SQLForm = 0
+	//SQLFormDER stores the canonical form encoded as Distinguished Name.
+	SQLFormDER SQLForm = 1
+)
+
+//SQLDN is a distinguished name which implements sql.Scanner and driver.Valuer.
+//
+//SQLDN is stored in the canonical form of Canonicalize, so that distinguished names which match by Compare are
+//equal in databases, e.g. for unique indexes. The nil DN is stored as NULL.
+type SQLDN struct {
+	DN   *DN
+	Form SQLForm
+}
+
+//Value converts s to the canonical form selected by s.Form.
+func (s SQLDN) Value() (driver.Value, error) {
+	if s.DN == nil {
+		return nil, nil
+	}
+	var der []byte
+	var err error
+	if der, err = s.DN.Marshal(); err != nil {
+		return nil, err
+	}
+	var canonical []byte
+	if canonical, err = Canonicalize(der); err != nil {
+		return nil, err
+	}
+	switch s.Form {
+	case SQLFormDER:
+		return canonical, nil
+	case SQLFormString:
+		var d dn
+		if d, err = parseDn(canonical); err != nil {
+			return nil, err
+		}
+		return formatCanonical(d), nil
+	}
+	return nil, fmt.Errorf("dn: unknown SQL form %d", s.Form)
+}
+
+//Scan parses src, which is a string described in RFC 4514 or bytes encoded as Distinguished Name.
+//Bytes which cannot be parsed as Distinguished Name are parsed as a string, because some drivers return text as bytes.
+//The canonical forms stored by Value are accepted as well. s.Form is not changed.
+func (s *SQLDN) Scan(src any) (err error) {
+	var d dn
+	switch v := src.(type) {
+	case nil:
+		s.DN = nil
+		return nil
+	case string:
+		if d, err = parseString(v, newValueEncoder(nil)); err != nil {
+			return err
+		}
+	case []byte:
+		//The driver may reuse v after Scan returns.
+		if d, err = parseDn(append([]byte(nil), v...)); err != nil {
+			if d, err = parseString(string(v), newValueEncoder(nil)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("dn: cannot scan %T into SQLDN", src)
+	}
+	s.DN = &DN{rdns: d}
+	return nil
+}
+
+//formatCanonicalAttribute returns the string representation of atv, which is in the canonical form.
+//Only the values which ParseString encodes in the same way are represented as strings, and the other values are
+//represented in the hexadecimal form so that the string is parsed into the same canonical form.
+func formatCanonicalAttribute(atv Attribute) string {
+	name := attributeTypeName(atv.Oid)
+	v := string(atv.RawValue.Bytes)
+	if attributeTypeIndex(atv.Oid) >= 0 && atv.RawValue.Class == asn1.ClassUniversal {
+		if atv.RawValue.Tag == asn1.TagUTF8String && len(v) >= 2 && v[0] == ' ' && v[len(v)-1] == ' ' {
+			return name + "=" + escapeValue(v[1:len(v)-1])
+		}
+		if atv.RawValue.Tag == asn1.TagIA5String && oidEqual(atv.Oid, oidDomainComponent) {
+			return name + "=" + escapeValue(v)
+		}
+	}
+	return name + "=#" + hex.EncodeToString(atv.RawValue.FullBytes)
+}
+
+//formatCanonical returns the string representation of d, which is in the canonical form.
+//The insignificant spaces which the string preparation adds around the values are removed,
+//which is reversible because they are always a space at each end.
+func formatCanonical(d dn) string {
+	var sb strings.Builder
+	for i := range d {
+		r := d[len(d)-1-i]
+		if i != 0 {
+			sb.WriteByte(',')
+		}
+		for j, atv := range r {
+			if j != 0 {
+				sb.WriteByte('+')
+			}
+			sb.WriteString(formatCanonicalAttribute(atv))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,220 @@
+package dn
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+//memConnector is an in-memory database/sql driver which has a table of one column.
+//"INSERT" inserts the argument and "SELECT" returns all rows.
+type memConnector struct {
+	mu     sync.Mutex
+	values []driver.Value
+}
+
+func (c *memConnector) Connect(context.Context) (driver.Conn, error) { return &memConn{c}, nil }
+func (c *memConnector) Driver() driver.Driver                        { return nil }
+
+type memConn struct{ c *memConnector }
+
+func (c *memConn) Prepare(query string) (driver.Stmt, error) { return &memStmt{c.c, query}, nil }
+func (c *memConn) Close() error                              { return nil }
+func (c *memConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type memStmt struct {
+	c     *memConnector
+	query string
+}
+
+func (s *memStmt) Close() error { return nil }
+
+func (s *memStmt) NumInput() int {
+	if s.query == "INSERT" {
+		return 1
+	}
+	return 0
+}
+
+func (s *memStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	s.c.values = append(s.c.values, args[0])
+	return driver.RowsAffected(1), nil
+}
+
+func (s *memStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+	return &memRows{values: append([]driver.Value(nil), s.c.values...)}, nil
+}
+
+type memRows struct {
+	values []driver.Value
+	i      int
+}
+
+func (r *memRows) Columns() []string { return []string{"dn"} }
+func (r *memRows) Close() error      { return nil }
+
+func (r *memRows) Next(dest []driver.Value) error {
+	if r.i == len(r.values) {
+		return io.EOF
+	}
+	dest[0] = r.values[r.i]
+	r.i++
+	return nil
+}
+
+func TestSQLDN_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		form SQLForm
+	}{
+		{"String", SQLFormString},
+		{"DER", SQLFormDER},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &memConnector{}
+			db := sql.OpenDB(c)
+			defer db.Close()
+
+			//C=JP,CN=ABC in UTF8String, PrintableString and lower case
+			for _, der := range [][]byte{dn2b, dn3b, dn4b} {
+				d, err := ParseDN(der)
+				if err != nil {
+					t.Fatalf("ParseDN() error = %v", err)
+				}
+				if _, err = db.Exec("INSERT", SQLDN{DN: d, Form: tt.form}); err != nil {
+					t.Fatalf("Exec() error = %v", err)
+				}
+			}
+			if _, err := db.Exec("INSERT", SQLDN{Form: tt.form}); err != nil {
+				t.Fatalf("Exec() error = %v", err)
+			}
+
+			for i := 1; i < 3; i++ {
+				if !reflect.DeepEqual(c.values[i], c.values[0]) {
+					t.Errorf("stored value = %q, want %q", c.values[i], c.values[0])
+				}
+			}
+			if c.values[3] != nil {
+				t.Errorf("stored value = %q, want nil", c.values[3])
+			}
+
+			rows, err := db.Query("SELECT")
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			defer rows.Close()
+			var got []SQLDN
+			for rows.Next() {
+				var s SQLDN
+				if err := rows.Scan(&s); err != nil {
+					t.Fatalf("Scan() error = %v", err)
+				}
+				got = append(got, s)
+			}
+			if len(got) != 4 {
+				t.Fatalf("Query() got %d rows, want 4", len(got))
+			}
+			for _, s := range got[:3] {
+				der, err := s.DN.Marshal()
+				if err != nil {
+					t.Fatalf("Marshal() error = %v", err)
+				}
+				if result, err := Compare(dn2b, der); err != nil || !result {
+					t.Errorf("Compare() = %v, %v, want true", result, err)
+				}
+			}
+			got[0].Form = tt.form
+			if v, err := got[0].Value(); err != nil || !reflect.DeepEqual(v, c.values[0]) {
+				t.Errorf("Value() of scanned DN = %q, %v, want %q", v, err, c.values[0])
+			}
+			if got[3].DN != nil {
+				t.Errorf("Scan() DN = %v, want nil", got[3].DN)
+			}
+		})
+	}
+}
+
+func TestSQLDN_Value(t *testing.T) {
+	//C=JP,CN= A,B+C\ (UTF8String)
+	dnSpecialb, _ := hex.DecodeString("3020310b3009060355040613024a503111300f06035504030c0820412c422b435c20")
+	tests := []struct {
+		name    string
+		der     []byte
+		form    SQLForm
+		want    driver.Value
+		wantErr bool
+	}{
+		{"String, Multi RDN", dn16b, SQLFormString, "CN=abc,O=bar+O=foo,C=jp", false},
+		{"String, Domain components", dn17b, SQLFormString, "CN=abc,DC=example,DC=com,C=jp", false},
+		{"String, Empty value", dn9b, SQLFormString, "CN=,C=jp", false},
+		{"String, BMPString", dn8b, SQLFormString, "CN=abc,O=#1e060046004f004f,C=jp", false},
+		{"String, Special characters", dnSpecialb, SQLFormString, `CN=a\,b\+c\\,C=jp`, false},
+		{"DER", dn2b, SQLFormDER, []byte{0x30, 0x1f, 0x31, 0x0d, 0x30, 0x0b, 0x06, 0x03, 0x55, 0x04, 0x06, 0x0c, 0x04, 0x20, 0x6a, 0x70, 0x20, 0x31, 0x0e, 0x30, 0x0c, 0x06, 0x03, 0x55, 0x04, 0x03, 0x0c, 0x05, 0x20, 0x61, 0x62, 0x63, 0x20}, false},
+		{"Wrong Encoding domain component", dn7b, SQLFormString, nil, true},
+		{"Unknown form", dn2b, SQLForm(2), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDN(tt.der)
+			if err != nil {
+				t.Fatalf("ParseDN() error = %v", err)
+			}
+			got, err := SQLDN{DN: d, Form: tt.form}.Value()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Value() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Value() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLDN_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    []byte
+		wantErr bool
+	}{
+		{"String", "CN=ABC,C=JP", dn2b, false},
+		{"Canonical string", "CN=abc,C=jp", dn2b, false},
+		{"DER", dn3b, dn2b, false},
+		{"String as bytes", []byte("CN=ABC,C=JP"), dn2b, false},
+		{"Broken string", "CN=ABC,C", nil, true},
+		{"Broken bytes", []byte{0x30, 0x01}, nil, true},
+		{"Unsupported type", 1, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s SQLDN
+			err := s.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Scan() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			der, err := s.DN.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if result, err := Compare(tt.want, der); err != nil || !result {
+				t.Errorf("Compare() = %v, %v, want true", result, err)
+			}
+		})
+	}
+}
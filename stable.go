@@ -0,0 +1,48 @@
+package dn
+
+//IsStable reports whether der is comparison-stable, i.e. der matches by Compare the distinguished name which is
+//parsed from der and encoded again, as an encoder which decodes and re-encodes the values would do: the string values
+//are encoded again by DefaultEncodingPolicy, the other values, e.g. BIT STRING, are kept, and the attributes of each RDN
+//are sorted in the order required by DER. For example, a commonName encoded in BMPString or TeletexString is not
+//stable, because it does not match its UTF8String encoding. A value which DefaultEncodingPolicy cannot encode, e.g.
+//countryName "J@", is not stable either. It returns an error if der cannot be parsed.
+func IsStable(der []byte) (result bool, err error) {
+	return NewComparer().IsStable(der)
+}
+
+//IsStable reports whether der is comparison-stable in the same way as IsStable, comparing by c.
+//The options of c, e.g. WithStrictDER, are applied to der. The limits of c, e.g. WithMaxInputBytes, are checked before
+//the values are decoded and encoded again.
+func (c *Comparer) IsStable(der []byte) (result bool, err error) {
+	if err = c.checkInputBytes(der); err != nil {
+		return false, err
+	}
+	var d dn
+	if d, err = c.parseDn(der); err != nil {
+		return false, err
+	}
+	policy := DefaultEncodingPolicy()
+	encoded := make(dn, len(d))
+	for i, r := range d {
+		encoded[i] = make(rdnSET, len(r))
+		for j, atv := range r {
+			encoded[i][j] = atv
+			if !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			var s string
+			if s, err = decodeAttributeValue(atv); err != nil {
+				return false, err
+			}
+			if encoded[i][j].RawValue, err = policy.Encode(atv.Oid, s); err != nil {
+				//the value is lost by the encoding
+				return false, nil
+			}
+		}
+	}
+	var b []byte
+	if b, err = marshalDn(encoded); err != nil {
+		return false, err
+	}
+	return c.Compare(der, b)
+}
@@ -0,0 +1,106 @@
+package dn
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//unstablePreparer prepares each string differently, so that no values match.
+type unstablePreparer struct {
+	calls int
+}
+
+func (p *unstablePreparer) Prepare(s string, caseFold bool) (string, error) {
+	p.calls++
+	return s + strconv.Itoa(p.calls), nil
+}
+
+func TestIsStable(t *testing.T) {
+	//C=JP(PrintableString),CN=abc(TeletexString)
+	teletex, _ := hex.DecodeString("301b310b3009060355040613024a50310c300a06035504031403616263")
+	//C=JP(UTF8String),CN=abc(UTF8String)
+	utf8Country, _ := hex.DecodeString("301b310b300906035504060c024a50310c300a06035504030c03616263")
+	//C=J@(UTF8String),CN=abc(UTF8String)
+	brokenCountry, _ := hex.DecodeString("301b310b300906035504060c024a40310c300a06035504030c03616263")
+	//C=JP(PrintableString),CN=abc(IA5String)
+	ia5CommonName, _ := hex.DecodeString("301b310b3009060355040613024a50310c300a06035504031603616263")
+	tests := []struct {
+		name       string
+		der        []byte
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Simple", dn1b, true, false},
+		{"Different Encoding(PrintableString,UTF8String)", dn3b, true, false},
+		{"BMPString", dn5b, false, false},
+		{"TeletexString", teletex, false, false},
+		{"countryName in UTF8String", utf8Country, true, false},
+		{"countryName not in PrintableString characters", brokenCountry, false, false},
+		{"commonName in IA5String", ia5CommonName, false, false},
+		{"Domain components", dn65b, true, false},
+		{"Multi-valued RDN", dn60b, false, false},
+		{"Multi-valued RDN, Not sorted", dn62b, false, false},
+		{"Multi-valued RDN without BMPString", dn1b, true, false},
+		{"Multi-valued RDN without BMPString, Not sorted", dn16b, true, false},
+		{"BIT STRING", dn54b, true, false},
+		{"Broken data", brdnb, false, true},
+		{"Empty", []byte{}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := IsStable(tt.der)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("IsStable() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("IsStable() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestComparer_IsStable(t *testing.T) {
+	gotResult, err := NewComparer(WithStringPreparer(&unstablePreparer{})).IsStable(dn1b)
+	if err != nil {
+		t.Fatalf("IsStable() error = %v", err)
+	}
+	if gotResult {
+		t.Errorf("IsStable() gotResult = true, want false")
+	}
+
+	//single-valued RDNs
+	if gotResult, err = NewComparer(WithStringPreparer(&unstablePreparer{})).IsStable(dn2b); err != nil {
+		t.Fatalf("IsStable() error = %v", err)
+	}
+	if gotResult {
+		t.Errorf("IsStable() with single-valued RDNs gotResult = true, want false")
+	}
+
+	if _, err = NewComparer(WithStrictDER()).IsStable(dn62b); err == nil {
+		t.Errorf("IsStable() with WithStrictDER error = nil, want error")
+	}
+
+	//C=J@(UTF8String),CN=abc(UTF8String), which is not stable without the limits
+	brokenCountry, _ := hex.DecodeString("301b310b300906035504060c024a40310c300a06035504030c03616263")
+	if _, err = NewComparer(WithMaxInputBytes(16)).IsStable(brokenCountry); err == nil {
+		t.Errorf("IsStable() with WithMaxInputBytes error = nil, want error")
+	}
+	country, err := newStringAttribute(oidCountryName, "J@", EncodingUTF8String)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cn, err := newStringAttribute(oidCommonName, strings.Repeat("a", 17*1024), EncodingUTF8String)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oversized, err := marshalDn(dn{{country}, {cn}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewComparer(PresetWebPKI()...).IsStable(oversized); err == nil {
+		t.Errorf("IsStable() with PresetWebPKI error = nil, want error")
+	}
+}
@@ -0,0 +1,243 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//encodingNames maps the names used in struct tags to encodings.
+var encodingNames = map[string]Encoding{
+	"utf8":      EncodingUTF8String,
+	"printable": EncodingPrintableString,
+	"ia5":       EncodingIA5String,
+	"bmp":       EncodingBMPString,
+}
+
+//fieldTag is a parsed struct tag.
+type fieldTag struct {
+	name      string
+	omitEmpty bool
+	multi     bool
+	rest      bool
+	encoding  Encoding
+}
+
+//parseFieldTag parses tag, which is the value of the struct tag "dn".
+func parseFieldTag(tag string) (ft fieldTag, err error) {
+	parts := strings.Split(tag, ",")
+	ft.name = parts[0]
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			ft.omitEmpty = true
+		case "multi":
+			ft.multi = true
+		case "rest":
+			ft.rest = true
+		default:
+			e, ok := encodingNames[opt]
+			if !ok {
+				return fieldTag{}, fmt.Errorf("unknown tag option %q", opt)
+			}
+			ft.encoding = e
+		}
+	}
+	return ft, nil
+}
+
+//MarshalStruct encodes v, which is a struct or a pointer to a struct, as Distinguished Name.
+//
+//Each exported field which has the struct tag "dn" becomes RDNs in the order of the declaration.
+//The tag is the short name in AttributeTypes or the dotted string form of an OID, followed by comma separated options:
+//  omitempty              the field is skipped if it is empty.
+//  multi                  the values of []string field become a multi-valued RDN, instead of an RDN for each value.
+//  utf8,printable,ia5,bmp the value is encoded in UTF8String, PrintableString, IA5String or BMPString
+//                         instead of DefaultEncodingPolicy and opts.
+//Fields must be string or []string. Fields with tag "-" are skipped.
+//The values without the encoding options are encoded according to DefaultEncodingPolicy, unless opts change it,
+//e.g. WithForceUTF8 or WithEncodingPolicy.
+//
+//Example:
+//  type Subject struct {
+//  	Country string   `dn:"C"`
+//  	Org     string   `dn:"O"`
+//  	Units   []string `dn:"OU,omitempty"`
+//  	CN      string   `dn:"CN,printable"`
+//  }
+func MarshalStruct(v any, opts ...BuildOption) (result []byte, err error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("dn: MarshalStruct(nil)")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("dn: MarshalStruct of non-struct type %s", rv.Type())
+	}
+
+	ve := newValueEncoder(opts)
+	var d dn
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("dn")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		var rdns dn
+		if rdns, err = marshalField(rv.Field(i), tag, ve); err != nil {
+			return nil, fmt.Errorf("dn: field %s: %w", sf.Name, err)
+		}
+		d = append(d, rdns...)
+	}
+	return marshalDn(d)
+}
+
+//marshalField converts the field fv whose struct tag is tag to RDNs, encoding the values by ve.
+func marshalField(fv reflect.Value, tag string, ve *valueEncoder) (result dn, err error) {
+	var ft fieldTag
+	if ft, err = parseFieldTag(tag); err != nil {
+		return nil, err
+	}
+	var oid asn1.ObjectIdentifier
+	if oid, err = lookupAttributeType(ft.name); err != nil {
+		return nil, err
+	}
+	enc := ft.encoding
+	if enc == 0 {
+		enc = ve.encoding(oid)
+	}
+
+	var values []string
+	switch {
+	case fv.Kind() == reflect.String:
+		if ft.multi {
+			return nil, errors.New("option multi requires []string")
+		}
+		if ft.omitEmpty && fv.Len() == 0 {
+			return nil, nil
+		}
+		values = []string{fv.String()}
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < fv.Len(); i++ {
+			values = append(values, fv.Index(i).String())
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %s", fv.Type())
+	}
+
+	var r rdnSET
+	for _, s := range values {
+		var atv Attribute
+		if atv, err = newStringAttribute(oid, s, enc); err != nil {
+			return nil, err
+		}
+		if ft.multi {
+			r = append(r, atv)
+			continue
+		}
+		result = append(result, rdnSET{atv})
+	}
+	if len(r) != 0 {
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+//UnmarshalStruct decodes dnBytes, which is encoded as Distinguished Name, into v, which is a pointer to a struct.
+//
+//The fields are specified by the struct tag "dn" in the same way as MarshalStruct. The encoding options are ignored.
+//A string field is set to the value of the attribute of the type, and a []string field is set to the values
+//of all attributes of the type in the order of the distinguished name. UnmarshalStruct returns an error if the
+//distinguished name has more than one attribute of the type of a string field, rather than drop the values silently. The values are decoded from any string
+//type such as UTF8String, PrintableString, BMPString or TeletexString.
+//
+//Attributes which have no corresponding field are ignored, unless the struct has a map[string][]string field
+//with tag ",rest". The field collects them keyed by the short name in AttributeTypes or the dotted string form of the OID.
+func UnmarshalStruct(dnBytes []byte, v any) (err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("dn: UnmarshalStruct requires a non-nil pointer to a struct")
+	}
+	rv = rv.Elem()
+
+	var d dn
+	if d, err = parseDn(dnBytes); err != nil {
+		return err
+	}
+
+	type field struct {
+		name  string
+		value reflect.Value
+	}
+	fields := make(map[string]field)
+	var rest reflect.Value
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, ok := sf.Tag.Lookup("dn")
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+		var ft fieldTag
+		if ft, err = parseFieldTag(tag); err != nil {
+			return fmt.Errorf("dn: field %s: %w", sf.Name, err)
+		}
+		fv := rv.Field(i)
+		if ft.rest {
+			if fv.Type() != reflect.TypeOf(map[string][]string(nil)) {
+				return fmt.Errorf("dn: field %s: rest requires map[string][]string, not %s", sf.Name, fv.Type())
+			}
+			rest = fv
+			continue
+		}
+		if fv.Kind() != reflect.String && !(fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.String) {
+			return fmt.Errorf("dn: field %s: unsupported type %s", sf.Name, fv.Type())
+		}
+		var oid asn1.ObjectIdentifier
+		if oid, err = lookupAttributeType(ft.name); err != nil {
+			return fmt.Errorf("dn: field %s: %w", sf.Name, err)
+		}
+		fields[oid.String()] = field{name: sf.Name, value: fv}
+	}
+
+	isSet := make(map[string]bool)
+	for _, r := range d {
+		for _, atv := range r {
+			key := atv.Oid.String()
+			f, ok := fields[key]
+			if !ok && !rest.IsValid() {
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				if ok {
+					return fmt.Errorf("dn: field %s: attribute %s: %w", f.name, atv.Oid, err)
+				}
+				return fmt.Errorf("dn: attribute %s: %w", atv.Oid, err)
+			}
+			switch {
+			case !ok:
+				if rest.IsNil() {
+					rest.Set(reflect.ValueOf(make(map[string][]string)))
+				}
+				m := rest.Interface().(map[string][]string)
+				name := attributeTypeName(atv.Oid)
+				m[name] = append(m[name], s)
+			case f.value.Kind() == reflect.String:
+				if isSet[key] {
+					return fmt.Errorf("dn: field %s: attribute %s appears more than once", f.name, atv.Oid)
+				}
+				f.value.SetString(s)
+				isSet[key] = true
+			default:
+				f.value.Set(reflect.Append(f.value, reflect.ValueOf(s).Convert(f.value.Type().Elem())))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package dn
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalStruct(t *testing.T) {
+	type subject struct {
+		Country string   `dn:"C"`
+		Org     string   `dn:"O"`
+		Units   []string `dn:"OU,omitempty"`
+		CN      string   `dn:"CN,omitempty,printable"`
+		Note    string
+		Skipped string `dn:"-"`
+	}
+	type multi struct {
+		Country string   `dn:"C"`
+		Org     string   `dn:"O"`
+		Units   []string `dn:"OU,multi"`
+	}
+	type unsupported struct {
+		Number int `dn:"CN"`
+	}
+	type unknownName struct {
+		Foo string `dn:"FOO"`
+	}
+	type unknownOption struct {
+		CN string `dn:"CN,bar"`
+	}
+	type multiString struct {
+		CN string `dn:"CN,multi"`
+	}
+	type args struct {
+		v any
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult string
+		wantErr    bool
+	}{
+		{"Declaration order", args{subject{Country: "JP", Org: "Example", Units: []string{"a", "b"}, CN: "svc-1"}}, "3047310b3009060355040613024a503110300e060355040a13074578616d706c65310a3008060355040b130161310a3008060355040b130162310e300c060355040313057376632d31", false},
+		{"Pointer, omitempty", args{&subject{Country: "JP", Org: "Example"}}, "301f310b3009060355040613024a503110300e060355040a13074578616d706c65", false},
+		{"Multi-valued RDN", args{multi{Country: "JP", Org: "Example", Units: []string{"b", "a"}}}, "3035310b3009060355040613024a503110300e060355040a13074578616d706c6531143008060355040b1301613008060355040b130162", false},
+		{"Not printable", args{subject{Country: "JP", Org: "Example", CN: "svc@1"}}, "", true},
+		{"Unsupported type", args{unsupported{1}}, "", true},
+		{"Unknown name", args{unknownName{"a"}}, "", true},
+		{"Unknown option", args{unknownOption{"a"}}, "", true},
+		{"multi for string", args{multiString{"a"}}, "", true},
+		{"Not struct", args{"CN=a"}, "", true},
+		{"Nil pointer", args{(*subject)(nil)}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := MarshalStruct(tt.args.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MarshalStruct() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if hex.EncodeToString(gotResult) != tt.wantResult {
+				t.Errorf("MarshalStruct() gotResult = %x, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	//C=JP(PrintableString),O=Example(BMPString),OU=a(UTF8String)+OU=b(TeletexString),1.2.3.4=x(UTF8String),DC=com(IA5String),CN=svc-1(UTF8String)
+	h, _ := hex.DecodeString("306d310b3009060355040613024a5031173015060355040a1e0e004500780061006d0070006c006531143008060355040b0c01613008060355040b140162310a300806032a03040c017831133011060a0992268993f22c6401191603636f6d310e300c06035504030c057376632d31")
+	//C=JP(PrintableString),x500UniqueIdentifier=(BIT STRING)
+	bitString, _ := hex.DecodeString("301a310b3009060355040613024a50310b3009060355042d03020001")
+	type subject struct {
+		Country string   `dn:"C"`
+		Org     string   `dn:"O"`
+		Units   []string `dn:"OU"`
+		CN      string   `dn:"CN"`
+	}
+	type names struct {
+		CNs []string `dn:"CN"`
+	}
+	type withRest struct {
+		Country string              `dn:"C"`
+		Rest    map[string][]string `dn:",rest"`
+	}
+	type uniqueID struct {
+		ID string `dn:"2.5.4.45"`
+	}
+	type wrongRest struct {
+		Rest map[string]string `dn:",rest"`
+	}
+	type unsupported struct {
+		Number int `dn:"CN"`
+	}
+	type args struct {
+		dnBytes []byte
+		v       any
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    any
+		wantErr bool
+	}{
+		{"Fields", args{h, &subject{}}, &subject{Country: "JP", Org: "Example", Units: []string{"a", "b"}, CN: "svc-1"}, false},
+		{"Duplicate attributes of string field", args{dn11b, &subject{}}, &subject{Country: "JP", CN: "FOO"}, true},
+		{"Duplicate attributes of []string field", args{dn11b, &names{}}, &names{CNs: []string{"FOO", "BAR"}}, false},
+		{"Rest", args{h, &withRest{}}, &withRest{Country: "JP", Rest: map[string][]string{"O": {"Example"}, "OU": {"a", "b"}, "1.2.3.4": {"x"}, "DC": {"com"}, "CN": {"svc-1"}}}, false},
+		{"Not string value", args{bitString, &uniqueID{}}, &uniqueID{}, true},
+		{"Not string value in rest", args{bitString, &withRest{}}, &withRest{Country: "JP"}, true},
+		{"Wrong type of rest", args{h, &wrongRest{}}, &wrongRest{}, true},
+		{"Unsupported type", args{h, &unsupported{}}, &unsupported{}, true},
+		{"Not pointer", args{h, subject{}}, subject{}, true},
+		{"Broken data", args{brdnb, &subject{}}, &subject{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := UnmarshalStruct(tt.args.dnBytes, tt.args.v)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalStruct() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(tt.args.v, tt.want) {
+				t.Errorf("UnmarshalStruct() got = %v, want %v", tt.args.v, tt.want)
+			}
+		})
+	}
+}
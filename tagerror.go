@@ -0,0 +1,64 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+//TagError is the error for an attribute value which is not encoded in one of the universal string types which the
+//comparison can decode, e.g. a value with an implicit context-specific tag in a non-conforming certificate, or
+//a value of a structured type such as SEQUENCE.
+type TagError struct {
+	Type       asn1.ObjectIdentifier //attribute type of the value, or nil if it is unknown
+	Class      int
+	Tag        int
+	IsCompound bool
+}
+
+//Error returns the description of e.
+func (e *TagError) Error() string {
+	var value string
+	if e.Type == nil {
+		value = "value"
+	} else {
+		value = fmt.Sprintf("value of attribute %s", e.Type)
+	}
+	switch e.Class {
+	case asn1.ClassUniversal:
+		return fmt.Sprintf("dn: %s has universal tag %d(%s), which is not a string type", value, e.Tag, tagName(e.Tag))
+	case asn1.ClassContextSpecific:
+		//https://www.itu.int/rec/T-REC-X.680 section-31.2.7
+		//The implicit tag replaces the tag of the string type, which cannot be recovered from the encoding.
+		return fmt.Sprintf("dn: %s has context-specific tag [%d], which may be an implicit tag replacing the tag of a string type", value, e.Tag)
+	default:
+		return fmt.Sprintf("dn: %s has %s tag %d, which is not a universal string type", value, className(e.Class), e.Tag)
+	}
+}
+
+//className returns the name of ASN.1 class.
+func className(class int) string {
+	switch class {
+	case asn1.ClassUniversal:
+		return "universal"
+	case asn1.ClassApplication:
+		return "application"
+	case asn1.ClassContextSpecific:
+		return "context-specific"
+	default:
+		return "private"
+	}
+}
+
+//decodeAttributeValue decodes the value of atv by toString. If the value is not a string, the error is *TagError
+//with the type of atv.
+func decodeAttributeValue(atv Attribute) (s string, err error) {
+	if s, err = toString(atv.RawValue.FullBytes); err != nil {
+		var tagErr *TagError
+		if errors.As(err, &tagErr) {
+			tagErr.Type = cloneOid(atv.Oid)
+		}
+		return "", err
+	}
+	return s, nil
+}
@@ -0,0 +1,90 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompare_TagError(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"Default", nil},
+		{"Constant time", []Option{WithConstantTime()}},
+		{"Case exact", []Option{WithMatchingRule(asn1.ObjectIdentifier{2, 5, 4, 3}, MatchingRuleCaseExact)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewComparer(tt.opts...).Compare(dn2b, dn104b)
+			var tagErr *TagError
+			if !errors.As(err, &tagErr) {
+				t.Fatalf("Compare() error = %v, want *TagError", err)
+			}
+			if !tagErr.Type.Equal(asn1.ObjectIdentifier{2, 5, 4, 3}) || tagErr.Class != asn1.ClassContextSpecific || tagErr.Tag != 0 || tagErr.IsCompound {
+				t.Errorf("Compare() error = %+v, want context-specific tag 0 of 2.5.4.3", tagErr)
+			}
+		})
+	}
+
+	if got, err := Compare(dn6b, dn104b); err != nil || got {
+		t.Errorf("Compare() of different countries = %v, %v, want false, nil", got, err)
+	}
+}
+
+func TestTagError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *TagError
+		want string
+	}{
+		{"Context-specific", &TagError{Type: asn1.ObjectIdentifier{2, 5, 4, 3}, Class: asn1.ClassContextSpecific, Tag: 0},
+			"dn: value of attribute 2.5.4.3 has context-specific tag [0], which may be an implicit tag replacing the tag of a string type"},
+		{"Universal", &TagError{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true},
+			"dn: value has universal tag 16(SEQUENCE), which is not a string type"},
+		{"Unknown universal", &TagError{Class: asn1.ClassUniversal, Tag: asn1.TagInteger},
+			"dn: value has universal tag 2(tag 2), which is not a string type"},
+		{"Application", &TagError{Type: asn1.ObjectIdentifier{2, 5, 4, 10}, Class: asn1.ClassApplication, Tag: 12},
+			"dn: value of attribute 2.5.4.10 has application tag 12, which is not a universal string type"},
+		{"Private", &TagError{Class: asn1.ClassPrivate, Tag: 1},
+			"dn: value has private tag 1, which is not a universal string type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_toString_TagError(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       []byte
+		wantClass int
+		wantTag   int
+	}{
+		{"Context-specific primitive", []byte{0x80, 0x03, 'A', 'B', 'C'}, asn1.ClassContextSpecific, 0},
+		{"Context-specific constructed", []byte{0xa1, 0x05, 0x04, 0x03, 'A', 'B', 'C'}, asn1.ClassContextSpecific, 1},
+		{"VideotexString", []byte{0x15, 0x03, 'A', 'B', 'C'}, asn1.ClassUniversal, 21},
+		{"INTEGER", []byte{0x02, 0x01, 0x01}, asn1.ClassUniversal, asn1.TagInteger},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := toString(tt.src)
+			var tagErr *TagError
+			if !errors.As(err, &tagErr) {
+				t.Fatalf("toString() error = %v, want *TagError", err)
+			}
+			if tagErr.Class != tt.wantClass || tagErr.Tag != tt.wantTag || tagErr.Type != nil {
+				t.Errorf("toString() error = %+v, want class %d tag %d", tagErr, tt.wantClass, tt.wantTag)
+			}
+		})
+	}
+	if _, err := toString([]byte{0x80, 0x05, 'A'}); err == nil || strings.Contains(err.Error(), "tag [0]") {
+		t.Errorf("toString() of broken data error = %v, want ASN.1 error", err)
+	}
+}
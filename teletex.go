@@ -0,0 +1,72 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"strings"
+)
+
+//WithTeletexString makes the Comparer compare the values encoded in TeletexString as DirectoryString,
+//so that they match the same values encoded in UTF8String or PrintableString by caseIgnoreMatch.
+//By default, TeletexString values are compared by binary comparison.
+//
+//TeletexString values are decoded as ISO/IEC 8859-1, which is the common practice of certificate issuers.
+//Values which contain the escape sequences, shift functions or C1 control characters of T.61 cannot be decoded
+//reliably, and the comparison returns an error for them rather than a wrong result.
+func WithTeletexString() Option {
+	return func(c *Comparer) {
+		c.teletex = true
+	}
+}
+
+//transcodeTeletexStrings returns d whose values encoded in TeletexString are converted to UTF8String.
+func transcodeTeletexStrings(d dn) (result dn, err error) {
+	result = make(dn, len(d))
+	for i, r := range d {
+		result[i] = make(rdnSET, len(r))
+		for j, atv := range r {
+			if result[i][j], err = transcodeTeletexString(atv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+//transcodeTeletexString returns atv whose value is converted to UTF8String if it is encoded in TeletexString.
+func transcodeTeletexString(atv Attribute) (result Attribute, err error) {
+	if atv.RawValue.Class != asn1.ClassUniversal || atv.RawValue.Tag != asn1.TagT61String {
+		return atv, nil
+	}
+	var content []byte
+	if content, err = stringContent(atv.RawValue); err != nil {
+		return Attribute{}, err
+	}
+	var s string
+	if s, err = decodeTeletexString(content); err != nil {
+		return Attribute{}, fmt.Errorf("dn: attribute %s: %w", atv.Oid, err)
+	}
+	return newStringAttribute(atv.Oid, s, EncodingUTF8String)
+}
+
+//decodeTeletexString decodes b, which is the content of TeletexString, as ISO/IEC 8859-1.
+func decodeTeletexString(b []byte) (s string, err error) {
+	return decodeLatin1String(b, "TeletexString")
+}
+
+//decodeLatin1String decodes b, which is the content of the string type typeName based on ISO 2022, as ISO/IEC 8859-1.
+func decodeLatin1String(b []byte, typeName string) (s string, err error) {
+	//https://www.itu.int/rec/T-REC-T.61
+	//ESC(0x1B) introduces escape sequences which designate other character sets, and LS1(0x0E) and LS0(0x0F)
+	//invoke them. The C1 control characters include the single shifts SS2(0x8E) and SS3(0x8F).
+	//The characters which they select have no fixed mapping to Unicode.
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for i, c := range b {
+		if c == 0x0e || c == 0x0f || c == 0x1b || (c >= 0x80 && c <= 0x9f) {
+			return "", fmt.Errorf("cannot decode %s: unsupported control character 0x%02x at %d", typeName, c, i)
+		}
+		sb.WriteRune(rune(c))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,124 @@
+package dn
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	//C=JP(PrintableString),O=Société Générale(TeletexString)
+	dnTeletexb, _ = hex.DecodeString("3028310b3009060355040613024a5031193017060355040a1410536f6369e974e92047e96ee972616c65")
+	//C=JP(PrintableString),O=SOCIÉTÉ  GÉNÉRALE(UTF8String)
+	dnTeletexUTF8b, _ = hex.DecodeString("302d310b3009060355040613024a50311e301c060355040a0c15534f4349c38954c389202047c3894ec38952414c45")
+	//C=JP(PrintableString),O=Example(TeletexString)
+	dnTeletexASCIIb, _ = hex.DecodeString("301f310b3009060355040613024a503110300e060355040a14074578616d706c65")
+	//C=JP(PrintableString),O=EXAMPLE(PrintableString)
+	dnExamplePrintableb, _ = hex.DecodeString("301f310b3009060355040613024a503110300e060355040a13074558414d504c45")
+	//C=JP(PrintableString),O=ESC ( B Example(TeletexString)
+	dnTeletexEscapeb, _ = hex.DecodeString("3022310b3009060355040613024a5031133011060355040a140a1b28424578616d706c65")
+	//C=JP(PrintableString),O=Ex CSI ample(TeletexString)
+	dnTeletexC1b, _ = hex.DecodeString("3020310b3009060355040613024a503111300f060355040a140845789b616d706c65")
+	//C=JP(PrintableString),O=Example(BMPString)
+	dnExampleBMPb, _ = hex.DecodeString("3026310b3009060355040613024a5031173015060355040a1e0e004500780061006d0070006c0065")
+)
+
+func TestWithTeletexString(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantResult     bool
+		wantDefault    bool
+		wantErr        bool
+		wantDefaultErr bool
+	}{
+		{"TeletexString UTF8String, Latin-1", args{dnTeletexb, dnTeletexUTF8b}, true, false, false, false},
+		{"UTF8String TeletexString, Latin-1", args{dnTeletexUTF8b, dnTeletexb}, true, false, false, false},
+		{"TeletexString PrintableString", args{dnTeletexASCIIb, dnExamplePrintableb}, true, false, false, false},
+		{"TeletexString TeletexString", args{dnTeletexb, dnTeletexb}, true, true, false, false},
+		{"TeletexString BMPString", args{dnTeletexASCIIb, dnExampleBMPb}, false, false, false, false},
+		{"Different characters", args{dnTeletexb, dnExamplePrintableb}, false, false, false, false},
+		{"Escape sequence", args{dnTeletexEscapeb, dnExamplePrintableb}, false, false, true, false},
+		{"C1 control character", args{dnExamplePrintableb, dnTeletexC1b}, false, false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(WithTeletexString()).Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+
+			gotResult, err = Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantDefaultErr {
+				t.Errorf("Compare() without WithTeletexString error = %v, wantErr %v", err, tt.wantDefaultErr)
+				return
+			}
+			if gotResult != tt.wantDefault {
+				t.Errorf("Compare() without WithTeletexString gotResult = %v, want %v", gotResult, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestWithTeletexString_Canonicalize(t *testing.T) {
+	c := NewComparer(WithTeletexString())
+	x, err := c.Canonicalize(dnTeletexb)
+	if err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	y, err := c.Canonicalize(dnTeletexUTF8b)
+	if err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	if string(x) != string(y) {
+		t.Errorf("Canonicalize() = %x, %x, want the same canonical form", x, y)
+	}
+	if _, err = c.Canonicalize(dnTeletexEscapeb); err == nil {
+		t.Errorf("Canonicalize() error = nil, want error")
+	}
+}
+
+func TestWithTeletexString_CompareAttribute(t *testing.T) {
+	d, _ := parseDn(dnTeletexb)
+	e, _ := parseDn(dnTeletexUTF8b)
+	gotResult, err := NewComparer(WithTeletexString()).CompareAttribute(d[1][0], e[1][0])
+	if err != nil || !gotResult {
+		t.Errorf("CompareAttribute() = %v, %v, want true", gotResult, err)
+	}
+}
+
+func Test_decodeTeletexString(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    string
+		wantErr bool
+	}{
+		{"ASCII", []byte("Example"), "Example", false},
+		{"Latin-1", []byte{0x53, 0x6f, 0x63, 0x69, 0xe9, 0x74, 0xe9}, "Société", false},
+		{"No-break space", []byte{0xa0}, " ", false},
+		{"Empty", []byte{}, "", false},
+		{"ESC", []byte{0x1b, 0x28, 0x42}, "", true},
+		{"LS1", []byte{0x0e, 0x41}, "", true},
+		{"SS2", []byte{0x8e, 0x41}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeTeletexString(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeTeletexString() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("decodeTeletexString() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
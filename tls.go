@@ -0,0 +1,109 @@
+package dn
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+//PeerDNError is the error returned by the verifiers of NewPeerDNVerifier and VerifyPeerDN when the subject of the peer is not allowed.
+type PeerDNError struct {
+	Subject string //string representation of the subject described in RFC 4514, redacted by WithPeerDNRedaction
+}
+
+//Error returns the description of e.
+func (e *PeerDNError) Error() string {
+	return fmt.Sprintf("dn: peer subject %q is not allowed", e.Subject)
+}
+
+//WithPeerDNRedaction makes the verifiers of NewPeerDNVerifier and VerifyPeerDN set PeerDNError.Subject to
+//redact(subject), e.g. to keep personal names in the subjects of client certificates out of logs. subject is the
+//string representation described in RFC 4514, or empty if the subject cannot be parsed.
+func WithPeerDNRedaction(redact func(subject string) string) Option {
+	return func(c *Comparer) {
+		c.redactPeerDN = redact
+	}
+}
+
+//NewPeerDNVerifier returns a function for tls.Config.VerifyPeerCertificate which accepts the peer only if the subject
+//of the leaf certificate matches one of allowed, which are encoded as Distinguished Name.
+//The subjects are matched by the Comparer configured by opts.
+//
+//The leaf certificate is the first certificate of the first verified chain. If there are no verified chains,
+//e.g. tls.Config.ClientAuth is tls.RequireAnyClientCert, the leaf certificate is parsed from the first of rawCerts.
+//If the subject is not allowed, the function returns *PeerDNError.
+func NewPeerDNVerifier(allowed [][]byte, opts ...Option) (verifier func(rawCerts [][]byte, chains [][]*x509.Certificate) error, err error) {
+	c := NewComparer(opts...)
+	set := c.NewDNSet()
+	for i, a := range allowed {
+		if _, _, err = set.Add(a); err != nil {
+			return nil, fmt.Errorf("dn: allowed[%d]: %w", i, err)
+		}
+	}
+	return c.peerDNVerifier(set.Contains), nil
+}
+
+//VerifyPeerDN returns a function for tls.Config.VerifyPeerCertificate which accepts the peer only if allow contains
+//the subject of the leaf certificate, e.g.
+//
+//	config.VerifyPeerCertificate = dn.VerifyPeerDN(allow)
+//
+//The leaf certificate is chosen in the same way as NewPeerDNVerifier, and the subject is matched by
+//AllowList.Contains, i.e. by the Comparer which made allow. If the subject is not allowed, the function returns
+//*PeerDNError. It returns an error without a peer certificate, for a certificate or a subject which cannot be parsed,
+//and for every peer if allow is nil.
+func VerifyPeerDN(allow *AllowList) func(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+	if allow == nil {
+		return func(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+			return errors.New("dn: no allow list for peer subject")
+		}
+	}
+	return allow.c.peerDNVerifier(allow.Contains)
+}
+
+//peerDNVerifier returns the function of NewPeerDNVerifier and VerifyPeerDN, which accepts the peer only if contains
+//reports true for the subject of the leaf certificate.
+func (c *Comparer) peerDNVerifier(contains func(der []byte) (bool, error)) func(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, chains [][]*x509.Certificate) error {
+		leaf, err := peerLeafCertificate(rawCerts, chains)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if ok, err = contains(leaf.RawSubject); err != nil {
+			return fmt.Errorf("dn: failed to parse peer subject: %w", err)
+		}
+		if ok {
+			return nil
+		}
+		return c.newPeerDNError(leaf)
+	}
+}
+
+//peerLeafCertificate returns the leaf certificate of the peer, which is the first certificate of the first verified
+//chain, or the first of rawCerts if there are no verified chains.
+func peerLeafCertificate(rawCerts [][]byte, chains [][]*x509.Certificate) (leaf *x509.Certificate, err error) {
+	switch {
+	case len(chains) != 0 && len(chains[0]) != 0:
+		return chains[0][0], nil
+	case len(rawCerts) != 0:
+		if leaf, err = x509.ParseCertificate(rawCerts[0]); err != nil {
+			return nil, fmt.Errorf("dn: failed to parse peer certificate: %w", err)
+		}
+		return leaf, nil
+	default:
+		return nil, errors.New("dn: no peer certificate")
+	}
+}
+
+//newPeerDNError returns *PeerDNError for the subject of leaf, redacted by WithPeerDNRedaction of c.
+func (c *Comparer) newPeerDNError(leaf *x509.Certificate) *PeerDNError {
+	subject := ""
+	if d, err := ParseDN(leaf.RawSubject); err == nil {
+		subject = d.String()
+	}
+	if c.redactPeerDN != nil {
+		subject = c.redactPeerDN(subject)
+	}
+	return &PeerDNError{Subject: subject}
+}
@@ -0,0 +1,171 @@
+package dn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestCertificate(t *testing.T, rawSubject []byte) (raw []byte, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		RawSubject:   rawSubject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if raw, err = x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key); err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = x509.ParseCertificate(raw); err != nil {
+		t.Fatal(err)
+	}
+	return raw, cert
+}
+
+//redactPeerDN hides the commonName of subject.
+func redactPeerDN(subject string) string {
+	if i := strings.Index(subject, ","); strings.HasPrefix(subject, "CN=") && i != -1 {
+		return "CN=*" + subject[i:]
+	}
+	return subject
+}
+
+func TestNewPeerDNVerifier(t *testing.T) {
+	//C=JP,CN=abc
+	raw4, cert4 := newTestCertificate(t, dn4b)
+	//C=US,CN=DEF
+	raw6, cert6 := newTestCertificate(t, dn6b)
+	//C=JP,O=Example,serialNumber=0002
+	raw13, _ := newTestCertificate(t, dn13b)
+
+	type args struct {
+		allowed [][]byte
+		opts    []Option
+	}
+	type peer struct {
+		rawCerts [][]byte
+		chains   [][]*x509.Certificate
+	}
+	tests := []struct {
+		name             string
+		args             args
+		peer             peer
+		wantErr          bool
+		wantVerifyErr    bool
+		wantPeerDNErr    bool
+		wantPeerDNString string
+	}{
+		{"Allowed subject with chains", args{[][]byte{dn6b, dn2b}, nil}, peer{[][]byte{raw4}, [][]*x509.Certificate{{cert4}}}, false, false, false, ""},
+		{"Allowed subject without chains", args{[][]byte{dn6b, dn2b}, nil}, peer{[][]byte{raw4}, nil}, false, false, false, ""},
+		{"Not allowed subject with chains", args{[][]byte{dn2b}, nil}, peer{[][]byte{raw6}, [][]*x509.Certificate{{cert6}}}, false, true, true, "CN=DEF,C=US"},
+		{"Not allowed subject without chains", args{[][]byte{dn2b}, nil}, peer{[][]byte{raw6}, nil}, false, true, true, "CN=DEF,C=US"},
+		{"Ignored types", args{[][]byte{dn12b}, []Option{WithIgnoredTypes(oidSerialNumber)}}, peer{[][]byte{raw13}, nil}, false, false, false, ""},
+		{"No certificates", args{[][]byte{dn2b}, nil}, peer{nil, nil}, false, true, false, ""},
+		{"Broken certificate", args{[][]byte{dn2b}, nil}, peer{[][]byte{{0x30, 0x00}}, nil}, false, true, false, ""},
+		{"Broken allowed data", args{[][]byte{dn2b, brdnb}, nil}, peer{}, true, false, false, ""},
+		{"Empty allowed data", args{nil, nil}, peer{[][]byte{raw4}, nil}, false, true, true, "CN=abc,C=JP"},
+		{"Redacted subject", args{[][]byte{dn2b}, []Option{WithPeerDNRedaction(redactPeerDN)}}, peer{[][]byte{raw6}, nil}, false, true, true, "CN=*,C=US"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := NewPeerDNVerifier(tt.args.allowed, tt.args.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewPeerDNVerifier() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			err = verifier(tt.peer.rawCerts, tt.peer.chains)
+			if (err != nil) != tt.wantVerifyErr {
+				t.Errorf("verifier() error = %v, wantVerifyErr %v", err, tt.wantVerifyErr)
+				return
+			}
+			var pe *PeerDNError
+			if errors.As(err, &pe) != tt.wantPeerDNErr {
+				t.Errorf("verifier() error = %v, wantPeerDNErr %v", err, tt.wantPeerDNErr)
+				return
+			}
+			if pe != nil && pe.Subject != tt.wantPeerDNString {
+				t.Errorf("verifier() Subject = %v, want %v", pe.Subject, tt.wantPeerDNString)
+			}
+		})
+	}
+}
+
+func TestVerifyPeerDN(t *testing.T) {
+	//C=JP,CN=abc
+	raw4, cert4 := newTestCertificate(t, dn4b)
+	//C=US,CN=DEF
+	raw6, cert6 := newTestCertificate(t, dn6b)
+	//C=JP,O=Example,serialNumber=0002
+	raw13, _ := newTestCertificate(t, dn13b)
+
+	allow, err := NewAllowList([][]byte{dn6b, dn2b})
+	if err != nil {
+		t.Fatalf("NewAllowList() error = %v", err)
+	}
+	ignoring, err := NewComparer(WithIgnoredTypes(oidSerialNumber)).NewAllowList([][]byte{dn12b})
+	if err != nil {
+		t.Fatalf("NewAllowList() error = %v", err)
+	}
+	empty, err := NewAllowList(nil)
+	if err != nil {
+		t.Fatalf("NewAllowList() error = %v", err)
+	}
+	redacting, err := NewComparer(WithPeerDNRedaction(redactPeerDN)).NewAllowList(nil)
+	if err != nil {
+		t.Fatalf("NewAllowList() error = %v", err)
+	}
+	type peer struct {
+		rawCerts [][]byte
+		chains   [][]*x509.Certificate
+	}
+	tests := []struct {
+		name             string
+		allow            *AllowList
+		peer             peer
+		wantErr          bool
+		wantPeerDNErr    bool
+		wantPeerDNString string
+	}{
+		{"Allowed subject with chains", allow, peer{[][]byte{raw4}, [][]*x509.Certificate{{cert4}}}, false, false, ""},
+		{"Allowed subject without chains", allow, peer{[][]byte{raw4}, nil}, false, false, ""},
+		{"Allowed subject with empty chain", allow, peer{[][]byte{raw6}, [][]*x509.Certificate{{}}}, false, false, ""},
+		{"Not allowed subject with chains", empty, peer{[][]byte{raw6}, [][]*x509.Certificate{{cert6}}}, true, true, "CN=DEF,C=US"},
+		{"Not allowed subject without chains", ignoring, peer{[][]byte{raw4}, nil}, true, true, "CN=abc,C=JP"},
+		{"Comparer of the allow list", ignoring, peer{[][]byte{raw13}, nil}, false, false, ""},
+		{"No certificates", allow, peer{nil, nil}, true, false, ""},
+		{"Broken certificate", allow, peer{[][]byte{{0x30, 0x00}}, nil}, true, false, ""},
+		{"Nil allow list", nil, peer{[][]byte{raw4}, nil}, true, false, ""},
+		{"Redacted subject", redacting, peer{[][]byte{raw6}, nil}, true, true, "CN=*,C=US"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyPeerDN(tt.allow)(tt.peer.rawCerts, tt.peer.chains)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyPeerDN() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var pe *PeerDNError
+			if errors.As(err, &pe) != tt.wantPeerDNErr {
+				t.Errorf("VerifyPeerDN() error = %v, wantPeerDNErr %v", err, tt.wantPeerDNErr)
+				return
+			}
+			if pe != nil && pe.Subject != tt.wantPeerDNString {
+				t.Errorf("VerifyPeerDN() Subject = %v, want %v", pe.Subject, tt.wantPeerDNString)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package dn
+
+import (
+	"errors"
+)
+
+//maxLengthOctets is the maximum number of the subsequent octets of the long form of ASN.1 length, which limits
+//the length to 2^32-1 as encoding/asn1 does.
+const maxLengthOctets = 4
+
+//readTLV reads the ASN.1 tag, length and value at the beginning of b, which must be encoded in DER, and returns the
+//content and the data after the value.
+func readTLV(b []byte) (class int, tag int, isCompound bool, content []byte, rest []byte, err error) {
+	//https://www.itu.int/rec/T-REC-X.690 section-8.1.2
+	if len(b) < 2 {
+		return 0, 0, false, nil, nil, errors.New("dn: truncated ASN.1 header")
+	}
+	class = int(b[0] >> 6)
+	isCompound = b[0]&0x20 != 0
+	tag = int(b[0] & 0x1f)
+	offset := 1
+	if tag == 0x1f {
+		//the high tag number form: base 128, most significant digit first, and bit 8 set except the last octet
+		tag = 0
+		for {
+			if offset == len(b) {
+				return 0, 0, false, nil, nil, errors.New("dn: truncated ASN.1 tag")
+			}
+			c := b[offset]
+			offset++
+			if tag == 0 && c == 0x80 {
+				return 0, 0, false, nil, nil, errors.New("dn: non-minimal ASN.1 tag")
+			}
+			if tag > 0xffffff {
+				return 0, 0, false, nil, nil, errors.New("dn: too large ASN.1 tag")
+			}
+			tag = tag<<7 | int(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		if tag < 0x1f {
+			return 0, 0, false, nil, nil, errors.New("dn: non-minimal ASN.1 tag")
+		}
+	}
+
+	//https://www.itu.int/rec/T-REC-X.690 section-8.1.3 and section-10.1
+	if offset == len(b) {
+		return 0, 0, false, nil, nil, errors.New("dn: truncated ASN.1 length")
+	}
+	c := b[offset]
+	offset++
+	length := int(c)
+	if c == 0x80 {
+		return 0, 0, false, nil, nil, errors.New("dn: indefinite length found (not DER)")
+	}
+	if c > 0x80 {
+		n := int(c & 0x7f)
+		if n > maxLengthOctets {
+			return 0, 0, false, nil, nil, errors.New("dn: too large ASN.1 length")
+		}
+		if len(b)-offset < n {
+			return 0, 0, false, nil, nil, errors.New("dn: truncated ASN.1 length")
+		}
+		if b[offset] == 0 {
+			return 0, 0, false, nil, nil, errors.New("dn: non-minimal ASN.1 length")
+		}
+		length = 0
+		for _, c := range b[offset : offset+n] {
+			length = length<<8 | int(c)
+		}
+		offset += n
+		if length < 0x80 {
+			return 0, 0, false, nil, nil, errors.New("dn: non-minimal ASN.1 length")
+		}
+	}
+	if length < 0 || len(b)-offset < length {
+		return 0, 0, false, nil, nil, errors.New("dn: truncated ASN.1 value")
+	}
+	return class, tag, isCompound, b[offset : offset+length], b[offset+length:], nil
+}
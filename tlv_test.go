@@ -0,0 +1,59 @@
+package dn
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func Test_readTLV(t *testing.T) {
+	long := append([]byte{0x0c, 0x81, 0x80}, bytes.Repeat([]byte{'a'}, 0x80)...)
+	tests := []struct {
+		name           string
+		b              string
+		wantClass      int
+		wantTag        int
+		wantIsCompound bool
+		wantContent    string
+		wantRest       string
+		wantErr        bool
+	}{
+		{"Primitive", "0c0141", 0, 12, false, "41", "", false},
+		{"Constructed", "2403040141", 0, 4, true, "040141", "", false},
+		{"Context-specific", "800141", 2, 0, false, "41", "", false},
+		{"Trailing data", "0c014100", 0, 12, false, "41", "00", false},
+		{"Empty content", "0c00", 0, 12, false, "", "", false},
+		{"High tag number", "1f8101" + "0141", 0, 129, false, "41", "", false},
+		{"Long form length", hex.EncodeToString(long), 0, 12, false, hex.EncodeToString(long[3:]), "", false},
+		{"Empty", "", 0, 0, false, "", "", true},
+		{"Only tag", "0c", 0, 0, false, "", "", true},
+		{"Truncated high tag number", "1f81", 0, 0, false, "", "", true},
+		{"Non-minimal high tag number", "1f800141", 0, 0, false, "", "", true},
+		{"High tag number form of low tag number", "1f0c0141", 0, 0, false, "", "", true},
+		{"Too large tag number", "1f8f8f8f8f0f0141", 0, 0, false, "", "", true},
+		{"Indefinite length", "2c80040141" + "0000", 0, 0, false, "", "", true},
+		{"Non-minimal long form length", "0c810141", 0, 0, false, "", "", true},
+		{"Leading zero of length", "0c82000141", 0, 0, false, "", "", true},
+		{"Too large length", "0c850100000000", 0, 0, false, "", "", true},
+		{"Truncated length", "0c8201", 0, 0, false, "", "", true},
+		{"Truncated value", "0c0241", 0, 0, false, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := hex.DecodeString(tt.b)
+			class, tag, isCompound, content, rest, err := readTLV(b)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readTLV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if class != tt.wantClass || tag != tt.wantTag || isCompound != tt.wantIsCompound {
+				t.Errorf("readTLV() = %d, %d, %v, want %d, %d, %v", class, tag, isCompound, tt.wantClass, tt.wantTag, tt.wantIsCompound)
+			}
+			if hex.EncodeToString(content) != tt.wantContent || hex.EncodeToString(rest) != tt.wantRest {
+				t.Errorf("readTLV() content = %x, rest = %x, want %v, %v", content, rest, tt.wantContent, tt.wantRest)
+			}
+		})
+	}
+}
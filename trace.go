@@ -0,0 +1,257 @@
+package dn
+
+import (
+	"context"
+	"encoding/asn1"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+//TraceStep is the step of the comparison which a TraceEvent describes.
+type TraceStep int
+
+//Steps of TraceEvent.
+const (
+	//TraceStepRule means a pair of attributes of the same type is compared by Rule.
+	TraceStepRule TraceStep = 0
+	//TraceStepPrepare means the values are converted for Rule to PreparedIssuer and PreparedSubject.
+	//It is emitted only for the rules which convert the values.
+	TraceStepPrepare TraceStep = 1
+	//TraceStepAttribute means the pair of attributes is compared, and Matched is the result.
+	TraceStepAttribute TraceStep = 2
+	//TraceStepRDN means the RDN is compared, and Matched and Reason are the result.
+	TraceStepRDN TraceStep = 3
+)
+
+var traceStepNames = []string{"rule", "prepare", "attribute", "rdn"}
+
+//String returns the name of s.
+func (s TraceStep) String() string {
+	if s < 0 || int(s) >= len(traceStepNames) {
+		return fmt.Sprintf("TraceStep(%d)", int(s))
+	}
+	return traceStepNames[s]
+}
+
+//TraceEvent describes a step of the comparison emitted to the function of WithTrace.
+//The fields about the attributes are zero for TraceStepRDN.
+type TraceEvent struct {
+	Step            TraceStep
+	RDN             int //index of the RDN
+	Issuer          int //index of the attribute in the RDN of the issuer
+	Subject         int //index of the attribute in the RDN of the subject
+	Type            asn1.ObjectIdentifier
+	IssuerTag       int //tag of the value of the issuer
+	SubjectTag      int //tag of the value of the subject
+	Rule            AppliedRule
+	PreparedIssuer  string    //value of the issuer converted for Rule, for TraceStepPrepare
+	PreparedSubject string    //value of the subject converted for Rule, for TraceStepPrepare
+	Preparation     *PrepDiff //steps of the string preparation, for TraceStepPrepare under WithVerboseTrace
+	Matched         bool
+	Reason          string //reason of the mismatch of the RDN, for TraceStepRDN
+}
+
+//String returns the description of e, e.g.
+//  RDN 1: comparing attribute 2.5.4.10 (UTF8String) against 2.5.4.10 (PrintableString) using caseIgnoreMatch
+func (e TraceEvent) String() string {
+	switch e.Step {
+	case TraceStepRule:
+		return fmt.Sprintf("RDN %d: comparing attribute %s (%s) against %s (%s) using %s",
+			e.RDN, e.Type, tagName(e.IssuerTag), e.Type, tagName(e.SubjectTag), e.Rule)
+	case TraceStepPrepare:
+		if e.Preparation != nil && e.Preparation.Converged {
+			return fmt.Sprintf("RDN %d: prepared %q vs %q, same after %s", e.RDN, e.PreparedIssuer, e.PreparedSubject, e.Preparation.Step)
+		}
+		return fmt.Sprintf("RDN %d: prepared %q vs %q", e.RDN, e.PreparedIssuer, e.PreparedSubject)
+	case TraceStepAttribute:
+		return fmt.Sprintf("RDN %d: attribute %s of issuer[%d] and subject[%d] %s", e.RDN, e.Type, e.Issuer, e.Subject, matchString(e.Matched))
+	case TraceStepRDN:
+		if e.Reason != "" {
+			return fmt.Sprintf("RDN %d: %s (%s)", e.RDN, matchString(e.Matched), e.Reason)
+		}
+		return fmt.Sprintf("RDN %d: %s", e.RDN, matchString(e.Matched))
+	}
+	return e.Step.String()
+}
+
+//matchString returns the description of matched.
+func matchString(matched bool) string {
+	if matched {
+		return "match"
+	}
+	return "mismatch"
+}
+
+//universalTagNames are the names of the universal tags of the string types.
+var universalTagNames = map[int]string{
+	asn1.TagUTF8String:      "UTF8String",
+	asn1.TagNumericString:   "NumericString",
+	asn1.TagPrintableString: "PrintableString",
+	asn1.TagT61String:       "TeletexString",
+	21:                      "VideotexString",
+	asn1.TagIA5String:       "IA5String",
+	25:                      "GraphicString",
+	26:                      "VisibleString",
+	27:                      "GeneralString",
+	28:                      "UniversalString",
+	asn1.TagBMPString:       "BMPString",
+	asn1.TagSequence:        "SEQUENCE",
+}
+
+//tagName returns the name of the universal tag, or the number of tag if it is unknown.
+func tagName(tag int) string {
+	if name, ok := universalTagNames[tag]; ok {
+		return name
+	}
+	return fmt.Sprintf("tag %d", tag)
+}
+
+//WithTrace makes the Comparer call trace at each step of the comparison of the attributes: the selection of the rule,
+//the preparation of the values and the result of each pair, and the result of each RDN, e.g. to see why
+//a comparison returned a surprising result. The values after the preparation are included in the events,
+//so the events must be handled as carefully as the distinguished names.
+//
+//Without trace, the comparison does no additional work. Under WithConstantTime, the steps of the comparison are not
+//traced, because tracing would reveal them. SlogTrace adapts a slog.Logger to trace.
+func WithTrace(trace func(TraceEvent)) Option {
+	return func(c *Comparer) {
+		c.trace = trace
+	}
+}
+
+//WithVerboseTrace makes the events of WithTrace for TraceStepPrepare include the intermediate results of the steps of
+//the string preparation in TraceEvent.Preparation, as DiffPrepare reports them. The values are prepared again for
+//the events, so it is slower than WithTrace alone. It has no effect without WithTrace.
+//TraceEvent.Preparation is nil for the rules which do not use the string preparation, and for the StringPreparers
+//other than DefaultStringPreparer and those of NewStringPreparer.
+func WithVerboseTrace() Option {
+	return func(c *Comparer) {
+		c.verboseTrace = true
+	}
+}
+
+//SlogTrace returns the function for WithTrace which logs the events to logger at level, with the fields of the events
+//as the attributes.
+func SlogTrace(logger *slog.Logger, level slog.Level) func(TraceEvent) {
+	return func(e TraceEvent) {
+		ctx := context.Background()
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+		logger.LogAttrs(ctx, level, e.String(), slog.Any("dn", e))
+	}
+}
+
+//LogValue returns the fields of e as a group, so that slog logs e with its fields.
+func (e TraceEvent) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("step", e.Step.String()), slog.Int("rdn", e.RDN)}
+	switch e.Step {
+	case TraceStepRDN:
+		attrs = append(attrs, slog.Bool("matched", e.Matched))
+		if e.Reason != "" {
+			attrs = append(attrs, slog.String("reason", e.Reason))
+		}
+		return slog.GroupValue(attrs...)
+	case TraceStepPrepare:
+		attrs = append(attrs, slog.String("prepared_issuer", e.PreparedIssuer), slog.String("prepared_subject", e.PreparedSubject))
+		if e.Preparation != nil {
+			attrs = append(attrs, slog.String("preparation", e.Preparation.String()))
+		}
+	case TraceStepAttribute:
+		attrs = append(attrs, slog.Bool("matched", e.Matched))
+	}
+	attrs = append(attrs,
+		slog.Int("issuer", e.Issuer),
+		slog.Int("subject", e.Subject),
+		slog.String("type", e.Type.String()),
+		slog.String("issuer_tag", tagName(e.IssuerTag)),
+		slog.String("subject_tag", tagName(e.SubjectTag)),
+		slog.String("rule", e.Rule.String()),
+	)
+	return slog.GroupValue(attrs...)
+}
+
+//traceAttribute emits the events of the comparison of x and y, which is the i-th attribute of the issuer and
+//the j-th attribute of the subject in the RDN index, to c.trace.
+func (c *Comparer) traceAttribute(index int, i int, j int, x Attribute, y Attribute, rule AppliedRule, matched bool) {
+	e := TraceEvent{
+		Step:       TraceStepRule,
+		RDN:        index,
+		Issuer:     i,
+		Subject:    j,
+		Type:       cloneOid(x.Oid),
+		IssuerTag:  x.RawValue.Tag,
+		SubjectTag: y.RawValue.Tag,
+		Rule:       rule,
+	}
+	c.trace(e)
+	if px, py, ok := preparedValues(x, y, rule, c.stringPreparer()); ok {
+		e.Step = TraceStepPrepare
+		e.PreparedIssuer, e.PreparedSubject = px, py
+		if c.verboseTrace {
+			e.Preparation = c.preparationDiff(x, y, rule)
+		}
+		c.trace(e)
+		e.PreparedIssuer, e.PreparedSubject, e.Preparation = "", "", nil
+	}
+	e.Step = TraceStepAttribute
+	e.Matched = matched
+	c.trace(e)
+}
+
+//preparationDiff returns the steps of the string preparation of x and y for rule, or nil if they are not prepared by
+//the string preparation of RFC4518.
+func (c *Comparer) preparationDiff(x Attribute, y Attribute, rule AppliedRule) *PrepDiff {
+	p, ok := c.stringPreparer().(ldapStringPreparer)
+	if !ok || (rule != AppliedRuleCaseIgnoreMatch && rule != AppliedRuleCaseExactMatch) {
+		return nil
+	}
+	s, err := toString(x.RawValue.FullBytes)
+	if err != nil {
+		return nil
+	}
+	t, err := toString(y.RawValue.FullBytes)
+	if err != nil {
+		return nil
+	}
+	d, _ := diffPrepare(s, t, rule == AppliedRuleCaseIgnoreMatch, p.form)
+	return d
+}
+
+//preparedValues returns the values of x and y converted for rule. ok is false if rule does not convert the values,
+//or they cannot be converted. The string values are prepared by p.
+func preparedValues(x Attribute, y Attribute, rule AppliedRule, p StringPreparer) (px string, py string, ok bool) {
+	prepare := func(atv Attribute) (string, bool) {
+		s, err := toString(atv.RawValue.FullBytes)
+		if err != nil {
+			return "", false
+		}
+		switch rule {
+		case AppliedRuleCaseInsensitiveExactMatch:
+			return strings.ToLower(s), true
+		case AppliedRuleNumericStringMatch:
+			u, err := prepareNumericString(p, s)
+			if err != nil {
+				return "", false
+			}
+			return u, true
+		case AppliedRuleCaseIgnoreMatch, AppliedRuleCaseExactMatch:
+			u, err := p.Prepare(s, rule == AppliedRuleCaseIgnoreMatch)
+			if err != nil {
+				return "", false
+			}
+			return u, true
+		}
+		return "", false
+	}
+	var okx, oky bool
+	px, okx = prepare(x)
+	py, oky = prepare(y)
+	return px, py, okx && oky
+}
+
+//traceRDN emits the event of the result of d to c.trace.
+func (c *Comparer) traceRDN(d RDNDecision) {
+	c.trace(TraceEvent{Step: TraceStepRDN, RDN: d.RDN, Matched: d.Matched, Reason: d.Reason})
+}
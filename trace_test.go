@@ -0,0 +1,166 @@
+package dn
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithTrace(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name       string
+		opts       []Option
+		args       args
+		wantResult bool
+		want       []string
+	}{
+		{"Multi RDN", nil, args{dn1b, dn1b}, true, []string{
+			`RDN 0: comparing attribute 2.5.4.6 (PrintableString) against 2.5.4.6 (PrintableString) using caseIgnoreMatch`,
+			`RDN 0: prepared " jp " vs " jp "`,
+			`RDN 0: attribute 2.5.4.6 of issuer[0] and subject[0] match`,
+			`RDN 0: match`,
+			`RDN 1: comparing attribute 2.5.4.10 (UTF8String) against 2.5.4.10 (UTF8String) using caseIgnoreMatch`,
+			`RDN 1: prepared " bar " vs " bar "`,
+			`RDN 1: attribute 2.5.4.10 of issuer[0] and subject[0] match`,
+			`RDN 1: comparing attribute 2.5.4.10 (UTF8String) against 2.5.4.10 (UTF8String) using caseIgnoreMatch`,
+			`RDN 1: prepared " foo " vs " foo "`,
+			`RDN 1: attribute 2.5.4.10 of issuer[1] and subject[1] match`,
+			`RDN 1: match`,
+			`RDN 2: comparing attribute 2.5.4.3 (UTF8String) against 2.5.4.3 (UTF8String) using caseIgnoreMatch`,
+			`RDN 2: prepared " abc " vs " abc "`,
+			`RDN 2: attribute 2.5.4.3 of issuer[0] and subject[0] match`,
+			`RDN 2: match`,
+		}},
+		{"Multi RDN not in DER order", nil, args{dn1b, dn16b}, true, []string{
+			`RDN 0: comparing attribute 2.5.4.6 (PrintableString) against 2.5.4.6 (PrintableString) using caseIgnoreMatch`,
+			`RDN 0: prepared " jp " vs " jp "`,
+			`RDN 0: attribute 2.5.4.6 of issuer[0] and subject[0] match`,
+			`RDN 0: match`,
+			`RDN 1: comparing attribute 2.5.4.10 (UTF8String) against 2.5.4.10 (UTF8String) using caseIgnoreMatch`,
+			`RDN 1: prepared " bar " vs " foo "`,
+			`RDN 1: attribute 2.5.4.10 of issuer[0] and subject[0] mismatch`,
+			`RDN 1: comparing attribute 2.5.4.10 (UTF8String) against 2.5.4.10 (UTF8String) using caseIgnoreMatch`,
+			`RDN 1: prepared " bar " vs " bar "`,
+			`RDN 1: attribute 2.5.4.10 of issuer[0] and subject[1] match`,
+			`RDN 1: comparing attribute 2.5.4.10 (UTF8String) against 2.5.4.10 (UTF8String) using caseIgnoreMatch`,
+			`RDN 1: prepared " foo " vs " foo "`,
+			`RDN 1: attribute 2.5.4.10 of issuer[1] and subject[0] match`,
+			`RDN 1: match`,
+			`RDN 2: comparing attribute 2.5.4.3 (UTF8String) against 2.5.4.3 (UTF8String) using caseIgnoreMatch`,
+			`RDN 2: prepared " abc " vs " abc "`,
+			`RDN 2: attribute 2.5.4.3 of issuer[0] and subject[0] match`,
+			`RDN 2: match`,
+		}},
+		{"Different Encoding(UTF8String,BMPString)", nil, args{dn2b, dn5b}, false, []string{
+			`RDN 0: comparing attribute 2.5.4.6 (PrintableString) against 2.5.4.6 (PrintableString) using caseIgnoreMatch`,
+			`RDN 0: prepared " jp " vs " jp "`,
+			`RDN 0: attribute 2.5.4.6 of issuer[0] and subject[0] match`,
+			`RDN 0: match`,
+			`RDN 1: comparing attribute 2.5.4.3 (UTF8String) against 2.5.4.3 (BMPString) using binaryComparison`,
+			`RDN 1: attribute 2.5.4.3 of issuer[0] and subject[0] mismatch`,
+			`RDN 1: mismatch (attribute 2.5.4.3 of the issuer has no matching attribute)`,
+		}},
+		{"Different number of RDNs", nil, args{base2b, dn2b}, false, []string{
+			`RDN 1: mismatch (different number of RDNs: issuer has 1, subject has 2)`,
+		}},
+		{"Constant time", []Option{WithConstantTime()}, args{dn1b, dn1b}, true, nil},
+		{"Verbose, Upper/Lower case characters", []Option{WithVerboseTrace()}, args{dn2b, dn4b}, true, []string{
+			`RDN 0: comparing attribute 2.5.4.6 (PrintableString) against 2.5.4.6 (PrintableString) using caseIgnoreMatch`,
+			`RDN 0: prepared " jp " vs " jp ", same after transcode`,
+			`RDN 0: attribute 2.5.4.6 of issuer[0] and subject[0] match`,
+			`RDN 0: match`,
+			`RDN 1: comparing attribute 2.5.4.3 (UTF8String) against 2.5.4.3 (UTF8String) using caseIgnoreMatch`,
+			`RDN 1: prepared " abc " vs " abc ", same after map`,
+			`RDN 1: attribute 2.5.4.3 of issuer[0] and subject[0] match`,
+			`RDN 1: match`,
+		}},
+		{"Verbose, Different characters", []Option{WithVerboseTrace()}, args{dn2b, dn6b}, false, []string{
+			`RDN 0: comparing attribute 2.5.4.6 (PrintableString) against 2.5.4.6 (PrintableString) using caseIgnoreMatch`,
+			`RDN 0: prepared " jp " vs " us "`,
+			`RDN 0: attribute 2.5.4.6 of issuer[0] and subject[0] mismatch`,
+			`RDN 0: mismatch (attribute 2.5.4.6 of the issuer has no matching attribute)`,
+		}},
+		{"Verbose, Custom StringPreparer", []Option{WithVerboseTrace(), WithSkipStringPrep()}, args{dn2b, dn4b}, true, []string{
+			`RDN 0: comparing attribute 2.5.4.6 (PrintableString) against 2.5.4.6 (PrintableString) using caseIgnoreMatch`,
+			`RDN 0: prepared "JP" vs "JP"`,
+			`RDN 0: attribute 2.5.4.6 of issuer[0] and subject[0] match`,
+			`RDN 0: match`,
+			`RDN 1: comparing attribute 2.5.4.3 (UTF8String) against 2.5.4.3 (UTF8String) using caseIgnoreMatch`,
+			`RDN 1: prepared "ABC" vs "ABC"`,
+			`RDN 1: attribute 2.5.4.3 of issuer[0] and subject[0] match`,
+			`RDN 1: match`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			c := NewComparer(append(tt.opts, WithTrace(func(e TraceEvent) { got = append(got, e.String()) }))...)
+			gotResult, err := c.Compare(tt.args.issuer, tt.args.subject)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trace = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTrace_Event(t *testing.T) {
+	var got []TraceEvent
+	c := NewComparer(WithTrace(func(e TraceEvent) { got = append(got, e) }))
+	if _, err := c.Compare(dn2b, dn3b); err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	//the events of CN=ABC(UTF8String) and CN=ABC(PrintableString)
+	want := TraceEvent{Step: TraceStepPrepare, RDN: 1, Type: got[4].Type, IssuerTag: 12, SubjectTag: 19, Rule: AppliedRuleCaseIgnoreMatch, PreparedIssuer: " abc ", PreparedSubject: " abc "}
+	if len(got) != 8 || !reflect.DeepEqual(got[5], want) || !got[4].Type.Equal(asn1.ObjectIdentifier{2, 5, 4, 3}) {
+		t.Errorf("trace = %+v, want %+v at 5", got, want)
+	}
+}
+
+func TestSlogTrace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c := NewComparer(WithTrace(SlogTrace(logger, slog.LevelDebug)))
+	if _, err := c.Compare(dn2b, dn5b); err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("SlogTrace() logged %d lines, want 7:\n%s", len(lines), buf.String())
+	}
+	for i, want := range []string{
+		"dn.step=rule dn.rdn=1 dn.issuer=0 dn.subject=0 dn.type=2.5.4.3 dn.issuer_tag=UTF8String dn.subject_tag=BMPString dn.rule=binaryComparison",
+		"dn.step=rdn dn.rdn=1 dn.matched=false",
+	} {
+		if line := lines[4+2*i]; !strings.Contains(line, want) {
+			t.Errorf("SlogTrace() logged %q, want %q", line, want)
+		}
+	}
+
+	buf.Reset()
+	c = NewComparer(WithTrace(SlogTrace(logger, slog.LevelDebug-1)))
+	if _, err := c.Compare(dn2b, dn5b); err != nil || buf.Len() != 0 {
+		t.Errorf("SlogTrace() logged %q below the level of the logger, error = %v", buf.String(), err)
+	}
+}
+
+func TestTraceStep_String(t *testing.T) {
+	if s := TraceStepRDN.String(); s != "rdn" {
+		t.Errorf("String() = %s, want rdn", s)
+	}
+	if s := TraceStep(9).String(); s != "TraceStep(9)" {
+		t.Errorf("String() = %s, want TraceStep(9)", s)
+	}
+}
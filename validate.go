@@ -0,0 +1,419 @@
+package dn
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+//Finding describes a problem found in a distinguished name by Validate.
+type Finding struct {
+	RDN       int                   //index of the RDN which contains the Attribute, or -1 if a required attribute is absent
+	Attribute int                   //index of the attribute in the RDN, or -1 if a required attribute is absent
+	Type      asn1.ObjectIdentifier //attribute type
+	Message   string
+}
+
+//String returns the description of f.
+func (f Finding) String() string {
+	return fmt.Sprintf("RDN %d: %s", f.RDN, f.Message)
+}
+
+//err converts f to an error.
+func (f Finding) err() error {
+	return errors.New("dn: " + f.String())
+}
+
+//Profile is a set of rules which ValidateProfile checks in addition to the built-in checks.
+type Profile struct {
+	//MaxOccurrences limits the number of attributes of each type in a distinguished name.
+	//The key is the dotted string form of the attribute type, e.g. "2.5.4.3".
+	MaxOccurrences map[string]int
+	//Rules are the names of the lint rules to run, e.g. LintRuleSetOrder. If it is empty, all the registered rules run
+	//except the rules of the versioned profiles, e.g. LintRuleCABFv2OrganizationalUnit, which run only if they are named.
+	Rules []string
+	//SuppressedRules are the names of the lint rules not to run.
+	SuppressedRules []string
+}
+
+//DefaultProfile returns the Profile which Validate uses.
+//It returns a new Profile for each call, so that changing the result does not affect Validate.
+func DefaultProfile() Profile {
+	return Profile{
+		MaxOccurrences: map[string]int{
+			//https://cabforum.org/baseline-requirements-documents/
+			//commonName: if present, MUST contain exactly one entry.
+			"2.5.4.3": 1,
+		},
+	}
+}
+
+//upperBounds maps attribute types to the upper bounds of the length of their values.
+//https://tools.ietf.org/html/rfc5280#appendix-A.1
+var upperBounds = map[string]int{
+	"2.5.4.41":             32768, //ub-name
+	"2.5.4.4":              32768, //ub-surname(ub-name)
+	"2.5.4.42":             32768, //ub-given-name(ub-name)
+	"2.5.4.43":             32768, //ub-initials(ub-name)
+	"2.5.4.44":             32768, //ub-generation-qualifier(ub-name)
+	"2.5.4.3":              64,    //ub-common-name
+	"2.5.4.7":              128,   //ub-locality-name
+	"2.5.4.8":              128,   //ub-state-name
+	"2.5.4.10":             64,    //ub-organization-name
+	"2.5.4.11":             64,    //ub-organizational-unit-name
+	"2.5.4.12":             64,    //ub-title
+	"2.5.4.5":              64,    //ub-serial-number
+	"2.5.4.6":              2,     //ub-country-name-alpha-length
+	"2.5.4.65":             128,   //ub-pseudonym
+	"1.2.840.113549.1.9.1": 255,   //ub-emailaddress-length
+
+	"1.3.6.1.4.1.311.60.2.1.3": 2, //jurisdictionCountryName(EV Guidelines section 9.2.4)
+}
+
+//Validate checks dnBytes, which is encoded as Distinguished Name, with DefaultProfile and reports problems which
+//does not prevent the comparison but are likely to be mistakes.
+func Validate(dnBytes []byte) (findings []Finding, err error) {
+	return ValidateProfile(dnBytes, DefaultProfile())
+}
+
+//ValidateProfile checks dnBytes, which is encoded as Distinguished Name, with p and reports problems which
+//does not prevent the comparison but are likely to be mistakes. It runs the lint rules which p selects from
+//the built-in rules and the rules registered by RegisterLintRule.
+func ValidateProfile(dnBytes []byte, p Profile) (findings []Finding, err error) {
+	var d dn
+	if d, err = parseDn(dnBytes); err != nil {
+		return nil, err
+	}
+	var rules []lintRule
+	if rules, err = selectLintRules(p); err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		var ruleFindings []Finding
+		if ruleFindings, err = r.fn(d, p); err != nil {
+			return nil, err
+		}
+		for _, f := range ruleFindings {
+			f.Type = cloneOid(f.Type)
+			findings = append(findings, f)
+		}
+	}
+	return findings, nil
+}
+
+//CountAttribute returns the number of attributes whose type is oid in dnBytes, which is encoded as Distinguished Name.
+func CountAttribute(dnBytes []byte, oid asn1.ObjectIdentifier) (n int, err error) {
+	var d dn
+	if d, err = parseDn(dnBytes); err != nil {
+		return 0, err
+	}
+	return countAttribute(d, oid), nil
+}
+
+//countAttribute returns the number of attributes whose type is oid in d.
+func countAttribute(d dn, oid asn1.ObjectIdentifier) (n int) {
+	for _, r := range d {
+		for _, atv := range r {
+			if atv.Oid.Equal(oid) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+//lintEmptyValues reports attributes whose values are empty after the string preparation.
+func lintEmptyValues(d dn) (findings []Finding, err error) {
+	for i, r := range d {
+		for j, atv := range r {
+			isEmpty := false
+			if isEmpty, err = isEmptyValue(atv); err != nil {
+				return nil, err
+			}
+			if isEmpty {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("attribute %s has an empty value", atv.Oid),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+//lintCountryNames reports countryName and jurisdictionCountryName attributes whose values are not exactly two letters,
+//including spaces.
+//https://tools.ietf.org/html/rfc5280#appendix-A.1
+//X520countryName ::=     PrintableString (SIZE (2))
+func lintCountryNames(d dn) (findings []Finding, err error) {
+	for i, r := range d {
+		for j, atv := range r {
+			if !isCountryNameType(atv.Oid) || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				return nil, err
+			}
+			if !isCountryCode(s) {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("attribute %s is not two letters: %q", atv.Oid, s),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+//isCountryNameType reports whether oid is countryName or jurisdictionCountryName, whose values are country codes.
+func isCountryNameType(oid asn1.ObjectIdentifier) bool {
+	return oidEqual(oid, oidCountryName) || oidEqual(oid, oidJurisdictionCountryName)
+}
+
+//isCountryCode reports whether s consists of exactly two ASCII letters.
+func isCountryCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+//lintMultiplicity reports attribute types which appear more times than p allows.
+//The finding points to the first attribute exceeding the limit.
+func lintMultiplicity(d dn, p Profile) (findings []Finding) {
+	counts := make(map[string]int)
+	index := make(map[string]int)
+	for i, r := range d {
+		for j, atv := range r {
+			key := atv.Oid.String()
+			counts[key]++
+			max, ok := p.MaxOccurrences[key]
+			if !ok || counts[key] != max+1 {
+				continue
+			}
+			index[key] = len(findings)
+			findings = append(findings, Finding{RDN: i, Attribute: j, Type: atv.Oid})
+		}
+	}
+	for key, n := range index {
+		findings[n].Message = fmt.Sprintf("attribute %s appears %d times, max %d", key, counts[key], p.MaxOccurrences[key])
+	}
+	return findings
+}
+
+//lintUpperBounds reports attributes whose values are longer than the upper bounds defined in X.520.
+//The length is the number of characters of the decoded value.
+func lintUpperBounds(d dn) (findings []Finding, err error) {
+	for i, r := range d {
+		for j, atv := range r {
+			max, ok := upperBounds[atv.Oid.String()]
+			if !ok || !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				return nil, err
+			}
+			if n := utf8.RuneCountInString(s); n > max {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("attribute %s is %d characters long, max %d", atv.Oid, n, max),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+//lintControlCharacters reports attributes whose values contain control characters which the string preparation maps
+//to nothing(RFC4518 section-2.2), e.g. "AB\x00C", which matches "ABC" by caseIgnoreMatch.
+//The control characters which are mapped to SPACE, e.g. TAB and LF, are not reported.
+func lintControlCharacters(d dn) (findings []Finding, err error) {
+	for i, r := range d {
+		for j, atv := range r {
+			if !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				continue
+			}
+			var s string
+			if s, err = toString(atv.RawValue.FullBytes); err != nil {
+				return nil, err
+			}
+			if k := strings.IndexFunc(s, isMappedToNothingControl); k >= 0 {
+				c, _ := utf8.DecodeRuneInString(s[k:])
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("attribute %s contains control character %U", atv.Oid, c),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+//lintOverlongUTF8 reports UTF8String values which contain overlong sequences, e.g. NUL encoded as 0xC0 0x80.
+func lintOverlongUTF8(d dn) (findings []Finding, err error) {
+	for i, r := range d {
+		for j, atv := range r {
+			if atv.RawValue.Class != asn1.ClassUniversal || atv.RawValue.Tag != asn1.TagUTF8String {
+				continue
+			}
+			var content []byte
+			if content, err = stringContent(atv.RawValue); err != nil {
+				return nil, err
+			}
+			if k := overlongUTF8Index(content); k >= 0 {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("attribute %s contains overlong UTF-8 sequence at %d", atv.Oid, k),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+//overlongUTF8Index returns the index of the first overlong sequence in b, or -1 if b has no overlong sequences.
+//A sequence is overlong only if its leading byte is followed by all of its continuation bytes, e.g. E0 41 is an
+//invalid byte followed by 'A', not an overlong sequence.
+func overlongUTF8Index(b []byte) int {
+	//https://tools.ietf.org/html/rfc3629#section-3
+	//Implementations of the decoding algorithm above MUST protect against
+	//decoding invalid sequences. For instance, a naive implementation may
+	//decode the overlong UTF-8 sequence C0 80 into the character U+0000
+	for i, c := range b {
+		n, overlong := 0, false
+		switch c {
+		case 0xc0, 0xc1:
+			//the 2-byte sequences of U+0000-U+007F
+			n, overlong = 2, true
+		case 0xe0:
+			//the 3-byte sequences of U+0000-U+07FF
+			n, overlong = 3, i+1 < len(b) && b[i+1] < 0xa0
+		case 0xf0:
+			//the 4-byte sequences of U+0000-U+FFFF
+			n, overlong = 4, i+1 < len(b) && b[i+1] < 0x90
+		}
+		if overlong && hasContinuationBytes(b[i+1:], n-1) {
+			return i
+		}
+	}
+	return -1
+}
+
+//hasContinuationBytes reports whether b starts with n continuation bytes of UTF-8, which are 0x80-0xBF.
+func hasContinuationBytes(b []byte, n int) bool {
+	if len(b) < n {
+		return false
+	}
+	for _, c := range b[:n] {
+		if c < 0x80 || c > 0xbf {
+			return false
+		}
+	}
+	return true
+}
+
+//isMappedToNothingControl reports whether c is a control character which the string preparation maps to nothing.
+func isMappedToNothingControl(c rune) bool {
+	//https://tools.ietf.org/html/rfc4518#section-2.2
+	//All other control code (e.g., Cc) points or code points with a
+	//control function (e.g., Cf) are mapped to nothing.
+	//...0000-0008, 000E-001F, 007F-0084, 0086-009F...
+	return c <= 0x08 || 0x0e <= c && c <= 0x1f || 0x7f <= c && c <= 0x84 || 0x86 <= c && c <= 0x9f
+}
+
+//lintSetOrder reports attributes of multi-valued RDNs which are not in the order required by DER.
+func lintSetOrder(d dn) (findings []Finding, err error) {
+	//https://www.itu.int/rec/T-REC-X.690 section-11.6
+	//The encodings of the component values of a set-of value shall appear in ascending order.
+	for i, r := range d {
+		for j := 1; j < len(r); j++ {
+			var x, y []byte
+			if x, err = asn1.Marshal(r[j-1]); err != nil {
+				return nil, err
+			}
+			if y, err = asn1.Marshal(r[j]); err != nil {
+				return nil, err
+			}
+			if bytes.Compare(x, y) > 0 {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      r[j].Oid,
+					Message:   fmt.Sprintf("attribute %s is not in DER order of multi-valued RDN", r[j].Oid),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+//lintStringTags reports attributes whose types have string syntax by the matching rules of c, but whose values are
+//not encoded in one of the string types of isStringTag.
+func (c *Comparer) lintStringTags(d dn) (findings []Finding) {
+	for i, r := range d {
+		for j, atv := range r {
+			if c.hasStringSyntax(atv.Oid) && !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+				findings = append(findings, Finding{
+					RDN:       i,
+					Attribute: j,
+					Type:      atv.Oid,
+					Message:   fmt.Sprintf("attribute %s has a value of unsupported class %d tag %d", atv.Oid, atv.RawValue.Class, atv.RawValue.Tag),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+//isEmptyValue reports whether the value of atv is empty or consists of only insignificant spaces.
+//Values which are not encoded as string are never empty.
+func isEmptyValue(atv Attribute) (result bool, err error) {
+	if !isStringTag(atv.RawValue.Class, atv.RawValue.Tag) {
+		return false, nil
+	}
+	var s string
+	if s, err = toString(atv.RawValue.FullBytes); err != nil {
+		return false, err
+	}
+	var u []rune
+	if u, err = stringPrepare(s); err != nil {
+		return false, err
+	}
+	//https://tools.ietf.org/html/rfc4518#section-2.6.1
+	//If the input string contains no non-space character, then the output is exactly two SPACEs.
+	return strings.TrimSpace(string(u)) == "", nil
+}
+
+//isStringTag reports whether class and tag are one of the universal ASN.1 string types.
+func isStringTag(class int, tag int) bool {
+	if class != asn1.ClassUniversal {
+		return false
+	}
+	switch tag {
+	case asn1.TagUTF8String, asn1.TagPrintableString, asn1.TagIA5String, asn1.TagT61String,
+		asn1.TagBMPString, asn1.TagNumericString, tagVisibleString, asn1.TagGeneralString:
+		return true
+	default:
+		return false
+	}
+}
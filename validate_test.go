@@ -0,0 +1,245 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type args struct {
+		dnBytes []byte
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantFindings []Finding
+		wantErr      bool
+	}{
+		{"No findings", args{dn1b}, nil, false},
+		{"Empty UTF8String CN", args{dn9b}, []Finding{{RDN: 1, Attribute: 0, Type: asn1.ObjectIdentifier{2, 5, 4, 3}, Message: "attribute 2.5.4.3 has an empty value"}}, false},
+		{"PrintableString O with only spaces", args{dn10b}, []Finding{{RDN: 1, Attribute: 0, Type: asn1.ObjectIdentifier{2, 5, 4, 10}, Message: "attribute 2.5.4.10 has an empty value"}}, false},
+		{"Multiple CN", args{dn11b}, []Finding{{RDN: 2, Attribute: 0, Type: asn1.ObjectIdentifier{2, 5, 4, 3}, Message: "attribute 2.5.4.3 appears 2 times, max 1"}}, false},
+		{"Multi RDN not in DER order", args{dn16b}, []Finding{{RDN: 1, Attribute: 1, Type: asn1.ObjectIdentifier{2, 5, 4, 10}, Message: "attribute 2.5.4.10 is not in DER order of multi-valued RDN"}}, false},
+		{"Embedded NUL", args{dn85b}, []Finding{{RDN: 1, Attribute: 0, Type: asn1.ObjectIdentifier{2, 5, 4, 3}, Message: "attribute 2.5.4.3 contains control character U+0000"}}, false},
+		{"NEXT LINE", args{dn87b}, nil, false},
+		{"Broken data", args{brdnb}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFindings, err := Validate(tt.args.dnBytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotFindings, tt.wantFindings) {
+				t.Errorf("Validate() gotFindings = %v, want %v", gotFindings, tt.wantFindings)
+			}
+		})
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	type args struct {
+		dnBytes []byte
+		p       Profile
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantFindings []Finding
+		wantErr      bool
+	}{
+		{"No limits", args{dn11b, Profile{}}, nil, false},
+		{"CN max 2", args{dn11b, Profile{MaxOccurrences: map[string]int{"2.5.4.3": 2}}}, nil, false},
+		{"C max 0", args{dn11b, Profile{MaxOccurrences: map[string]int{"2.5.4.6": 0, "2.5.4.3": 2}}}, []Finding{{RDN: 0, Attribute: 0, Type: asn1.ObjectIdentifier{2, 5, 4, 6}, Message: "attribute 2.5.4.6 appears 1 times, max 0"}}, false},
+		{"O max 1, Multi RDN", args{dn1b, Profile{MaxOccurrences: map[string]int{"2.5.4.10": 1}}}, []Finding{{RDN: 1, Attribute: 1, Type: asn1.ObjectIdentifier{2, 5, 4, 10}, Message: "attribute 2.5.4.10 appears 2 times, max 1"}}, false},
+		{"Broken data", args{brdnb, DefaultProfile()}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFindings, err := ValidateProfile(tt.args.dnBytes, tt.args.p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotFindings, tt.wantFindings) {
+				t.Errorf("ValidateProfile() gotFindings = %v, want %v", gotFindings, tt.wantFindings)
+			}
+		})
+	}
+}
+
+func TestDefaultProfile(t *testing.T) {
+	DefaultProfile().MaxOccurrences["2.5.4.3"] = 5
+	//C=JP,CN=FOO,CN=BAR
+	findings, err := Validate(dn11b)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(findings) != 1 {
+		t.Errorf("Validate() findings = %v, want a finding of commonName", findings)
+	}
+}
+
+func Test_lintUpperBounds(t *testing.T) {
+	//CN=65 characters(UTF8String)
+	longCn := parseAtv("304806035504030C41" + strings.Repeat("61", 65))
+	//CN=64 characters(UTF8String)
+	maxCn := parseAtv("304706035504030C40" + strings.Repeat("61", 64))
+	//O=65 characters(BMPString)
+	longO := parseAtv("30818A060355040A1E8182" + strings.Repeat("0061", 65))
+	type args struct {
+		d dn
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantFindings []Finding
+		wantErr      bool
+	}{
+		{"CN is 64 characters", args{dn{rdn1, rdnSET{maxCn}}}, nil, false},
+		{"CN is 65 characters", args{dn{rdn1, rdnSET{longCn}}}, []Finding{{RDN: 1, Attribute: 0, Type: asn1.ObjectIdentifier{2, 5, 4, 3}, Message: "attribute 2.5.4.3 is 65 characters long, max 64"}}, false},
+		{"O is 65 characters in BMPString", args{dn{rdnSET{pAtv, longO}}}, []Finding{{RDN: 0, Attribute: 1, Type: asn1.ObjectIdentifier{2, 5, 4, 10}, Message: "attribute 2.5.4.10 is 65 characters long, max 64"}}, false},
+		{"Broken String", args{dn{rdnSET{brokenAtv}}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFindings, err := lintUpperBounds(tt.args.d)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("lintUpperBounds() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotFindings, tt.wantFindings) {
+				t.Errorf("lintUpperBounds() gotFindings = %v, want %v", gotFindings, tt.wantFindings)
+			}
+		})
+	}
+}
+
+func TestCountAttribute(t *testing.T) {
+	type args struct {
+		dnBytes []byte
+		oid     asn1.ObjectIdentifier
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantN   int
+		wantErr bool
+	}{
+		{"CN appears 2 times", args{dn11b, asn1.ObjectIdentifier{2, 5, 4, 3}}, 2, false},
+		{"O appears 2 times in Multi RDN", args{dn1b, asn1.ObjectIdentifier{2, 5, 4, 10}}, 2, false},
+		{"L does not appear", args{dn1b, asn1.ObjectIdentifier{2, 5, 4, 7}}, 0, false},
+		{"Broken data", args{brdnb, asn1.ObjectIdentifier{2, 5, 4, 3}}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotN, err := CountAttribute(tt.args.dnBytes, tt.args.oid)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CountAttribute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotN != tt.wantN {
+				t.Errorf("CountAttribute() gotN = %v, want %v", gotN, tt.wantN)
+			}
+		})
+	}
+}
+
+func Test_isEmptyValue(t *testing.T) {
+	type args struct {
+		atv Attribute
+	}
+	tests := []struct {
+		name       string
+		args       args
+		wantResult bool
+		wantErr    bool
+	}{
+		{"Not empty", args{pAtv}, false, false},
+		{"Empty UTF8String", args{parseAtv("300706035504030C00")}, true, false},
+		{"PrintableString with only spaces", args{parseAtv("300A060355040A1303202020")}, true, false},
+		{"Not string", args{parseAtv("3008060355042D030100")}, false, false},
+		{"Broken String", args{brokenAtv}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := isEmptyValue(tt.args.atv)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("isEmptyValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("isEmptyValue() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
+
+func Test_lintCountryNames(t *testing.T) {
+	tests := []struct {
+		name         string
+		dnBytes      []byte
+		wantFindings int
+		wantErr      bool
+	}{
+		{"Two letters", dn2b, 0, false},
+		{"Leading space", dn18b, 1, false},
+		{"Trailing space", dn19b, 1, false},
+		{"Leading and trailing spaces", dn20b, 1, false},
+		{"Three letters", dn21b, 1, false},
+		{"Inner space", dn22b, 1, false},
+		{"Jurisdiction country name", dn32b, 0, false},
+		{"Jurisdiction country name with leading space", dn34b, 1, false},
+		{"Jurisdiction country name of three letters", dn35b, 1, false},
+		{"Empty distinguished name", base3b, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := parseDn(tt.dnBytes)
+			if err != nil {
+				t.Fatalf("parseDn() error = %v", err)
+			}
+			gotFindings, err := lintCountryNames(d)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("lintCountryNames() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(gotFindings) != tt.wantFindings {
+				t.Errorf("lintCountryNames() gotFindings = %v, want %v findings", gotFindings, tt.wantFindings)
+			}
+		})
+	}
+}
+
+func Test_overlongUTF8Index(t *testing.T) {
+	tests := []struct {
+		name string
+		b    []byte
+		want int
+	}{
+		{"ASCII", []byte("abc"), -1},
+		{"Shortest forms", []byte("a\u00e9\u3042\U0001f600"), -1},
+		{"2-byte NUL", []byte{'a', 0xc0, 0x80}, 1},
+		{"2-byte ASCII", []byte{0xc1, 0xbf}, 0},
+		{"3-byte", []byte{'a', 'b', 0xe0, 0x81, 0x81}, 2},
+		{"3-byte lowest shortest form", []byte{0xe0, 0xa0, 0x80}, -1},
+		{"4-byte", []byte{0xf0, 0x8f, 0xbf, 0xbf}, 0},
+		{"4-byte lowest shortest form", []byte{0xf0, 0x90, 0x80, 0x80}, -1},
+		{"2-byte leading byte without continuation byte", []byte{0xc0, 'A'}, -1},
+		{"3-byte leading byte without continuation byte", []byte{0xe0, 'A'}, -1},
+		{"3-byte leading byte and ASCII", []byte{0xe0, 0x80, 'A'}, -1},
+		{"Truncated 3-byte", []byte{'a', 0xe0, 0x80}, -1},
+		{"4-byte leading byte and ASCII", []byte{0xf0, 0x8f, 'A', 0xbf}, -1},
+		{"Truncated 2-byte after overlong 3-byte", []byte{0xc0, 0xe0, 0x81, 0x81}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overlongUTF8Index(tt.b); got != tt.want {
+				t.Errorf("overlongUTF8Index() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
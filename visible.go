@@ -0,0 +1,73 @@
+package dn
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+//tagVisibleString is the universal tag of VisibleString, which encoding/asn1 does not define.
+const tagVisibleString = 26
+
+//WithVisibleString makes the Comparer compare the values encoded in VisibleString as DirectoryString,
+//so that they match the same values encoded in UTF8String or PrintableString by caseIgnoreMatch.
+//By default, VisibleString values are compared by binary comparison, as RFC 5280 requires for values which are not
+//DirectoryString.
+//
+//VisibleString consists of the graphic characters of ISO 646 and SPACE, which are the printable characters of ASCII,
+//so the values are decoded without ambiguity. The comparison returns an error for values which contain other bytes.
+//Values encoded in GeneralString are not affected: it may switch character sets by the escape sequences of ISO 2022,
+//so its values are always compared by binary comparison.
+func WithVisibleString() Option {
+	return func(c *Comparer) {
+		c.visible = true
+	}
+}
+
+//transcodeVisibleStrings returns d whose values encoded in VisibleString are converted to UTF8String.
+func transcodeVisibleStrings(d dn) (result dn, err error) {
+	result = make(dn, len(d))
+	for i, r := range d {
+		result[i] = make(rdnSET, len(r))
+		for j, atv := range r {
+			if result[i][j], err = transcodeVisibleString(atv); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+//transcodeVisibleString returns atv whose value is converted to UTF8String if it is encoded in VisibleString.
+func transcodeVisibleString(atv Attribute) (result Attribute, err error) {
+	if atv.RawValue.Class != asn1.ClassUniversal || atv.RawValue.Tag != tagVisibleString {
+		return atv, nil
+	}
+	var content []byte
+	if content, err = stringContent(atv.RawValue); err != nil {
+		return Attribute{}, err
+	}
+	var s string
+	if s, err = decodeVisibleString(content); err != nil {
+		return Attribute{}, fmt.Errorf("dn: attribute %s: %w", atv.Oid, err)
+	}
+	return newStringAttribute(atv.Oid, s, EncodingUTF8String)
+}
+
+//decodeVisibleString decodes b, which is the content of VisibleString.
+func decodeVisibleString(b []byte) (s string, err error) {
+	//https://www.itu.int/rec/T-REC-X.680 section-41.4
+	//VisibleString: the graphic characters of the International Register entry 6(ISO 646) and SPACE.
+	for i, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return "", fmt.Errorf("cannot decode VisibleString: invalid character 0x%02x at %d", c, i)
+		}
+	}
+	return string(b), nil
+}
+
+//decodeGeneralString decodes b, which is the content of GeneralString, as ISO/IEC 8859-1.
+//As TeletexString, the values which switch character sets by the escape sequences or the shift functions of
+//ISO 2022 cannot be decoded reliably.
+func decodeGeneralString(b []byte) (s string, err error) {
+	return decodeLatin1String(b, "GeneralString")
+}
@@ -0,0 +1,129 @@
+package dn
+
+import (
+	"testing"
+)
+
+func TestWithVisibleString(t *testing.T) {
+	type args struct {
+		issuer  []byte
+		subject []byte
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantResult     bool
+		wantDefault    bool
+		wantErr        bool
+		wantDefaultErr bool
+	}{
+		{"VisibleString PrintableString", args{dn90b, dn3b}, true, false, false, false},
+		{"UTF8String VisibleString, Upper/Lower case characters", args{dn4b, dn90b}, true, false, false, false},
+		{"VisibleString VisibleString", args{dn90b, dn90b}, true, true, false, false},
+		{"VisibleString in the constructed form", args{dn95b, dn3b}, true, false, false, false},
+		{"Different characters", args{dn90b, dn91b}, false, false, false, false},
+		{"VisibleString BMPString", args{dn90b, dn5b}, false, false, false, false},
+		{"VisibleString non-ASCII", args{dn92b, dn3b}, false, false, true, true},
+		{"GeneralString is compared by binary comparison", args{dn93b, dn93b}, true, true, false, false},
+		{"GeneralString UTF8String", args{dn93b, dn83b}, false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := NewComparer(WithVisibleString()).Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Compare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("Compare() gotResult = %v, want %v", gotResult, tt.wantResult)
+			}
+
+			gotResult, err = Compare(tt.args.issuer, tt.args.subject)
+			if (err != nil) != tt.wantDefaultErr {
+				t.Errorf("Compare() without WithVisibleString error = %v, wantErr %v", err, tt.wantDefaultErr)
+				return
+			}
+			if gotResult != tt.wantDefault {
+				t.Errorf("Compare() without WithVisibleString gotResult = %v, want %v", gotResult, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestWithVisibleString_Canonicalize(t *testing.T) {
+	c := NewComparer(WithVisibleString())
+	x, err := c.Canonicalize(dn90b)
+	if err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	y, err := c.Canonicalize(dn4b)
+	if err != nil {
+		t.Fatalf("Canonicalize() error = %v", err)
+	}
+	if string(x) != string(y) {
+		t.Errorf("Canonicalize() = %x, %x, want the same canonical form", x, y)
+	}
+	if _, err = c.Canonicalize(dn92b); err == nil {
+		t.Errorf("Canonicalize() error = nil, want error")
+	}
+}
+
+func TestWithVisibleString_CompareAttribute(t *testing.T) {
+	d, _ := parseDn(dn90b)
+	e, _ := parseDn(dn4b)
+	gotResult, err := NewComparer(WithVisibleString()).CompareAttribute(d[1][0], e[1][0])
+	if err != nil || !gotResult {
+		t.Errorf("CompareAttribute() = %v, %v, want true", gotResult, err)
+	}
+}
+
+func TestVisibleAndGeneralString_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		der     []byte
+		want    string
+		wantErr bool
+	}{
+		{"VisibleString", dn91b, "CN=a@b_c,C=JP", false},
+		{"VisibleString in the constructed form", dn95b, "CN=ABC,C=JP", false},
+		{"GeneralString", dn93b, "CN=Café,C=JP", false},
+		{"VisibleString non-ASCII", dn92b, "CN=#1a04436166e9,C=JP", false},
+		{"GeneralString escape sequence", dn94b, "CN=#1b061b2842414243,C=JP", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDN(tt.der)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDN() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := d.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRejectUnknownStringTags_VisibleString(t *testing.T) {
+	c := NewComparer(WithRejectUnknownStringTags())
+	for _, der := range [][]byte{dn90b, dn93b} {
+		if _, err := c.Compare(der, der); err != nil {
+			t.Errorf("Compare() error = %v", err)
+		}
+	}
+}
+
+func TestWithVisibleString_Audit(t *testing.T) {
+	var events []Event
+	c := NewComparer(WithVisibleString(), WithAuditHook(func(e Event) {
+		events = append(events, e)
+	}))
+	if _, err := c.Compare(dn90b, dn3b); err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Rule != AuditRuleVisibleString {
+		t.Errorf("audit events = %v, want one %v", events, AuditRuleVisibleString)
+	}
+	if s := AuditRuleVisibleString.String(); s != "visibleString" {
+		t.Errorf("String() = %s, want visibleString", s)
+	}
+}